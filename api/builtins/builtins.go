@@ -25,6 +25,8 @@ type (
 	SuffixTransformerPlugin              = internal.SuffixTransformerPlugin
 	ReplacementTransformerPlugin         = internal.ReplacementTransformerPlugin
 	ReplicaCountTransformerPlugin        = internal.ReplicaCountTransformerPlugin
+	RequiredMetadataTransformerPlugin    = internal.RequiredMetadataTransformerPlugin
+	PolicyTransformerPlugin              = internal.PolicyTransformerPlugin
 	SecretGeneratorPlugin                = internal.SecretGeneratorPlugin
 	ValueAddTransformerPlugin            = internal.ValueAddTransformerPlugin
 )
@@ -46,6 +48,8 @@ var (
 	NewSuffixTransformerPlugin              = internal.NewSuffixTransformerPlugin
 	NewReplacementTransformerPlugin         = internal.NewReplacementTransformerPlugin
 	NewReplicaCountTransformerPlugin        = internal.NewReplicaCountTransformerPlugin
+	NewRequiredMetadataTransformerPlugin    = internal.NewRequiredMetadataTransformerPlugin
+	NewPolicyTransformerPlugin              = internal.NewPolicyTransformerPlugin
 	NewSecretGeneratorPlugin                = internal.NewSecretGeneratorPlugin
 	NewValueAddTransformerPlugin            = internal.NewValueAddTransformerPlugin
 )
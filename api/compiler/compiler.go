@@ -0,0 +1,23 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package compiler builds Go plugins from source,
+// e.g. to rebuild them against a new kustomize.
+package compiler
+
+import (
+	internal "sigs.k8s.io/kustomize/api/internal/plugins/compiler"
+	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
+)
+
+// Compiler builds ${g}/${v}/$lower(${k})/${k}.go below a
+// plugin root into a ${k}.so next to it.
+type Compiler = internal.Compiler
+
+// NewCompiler returns a Compiler for plugins below the given root.
+var NewCompiler = internal.NewCompiler
+
+// GoBin returns the go command that Compilers run, that of
+// the Go installation this program was built with if it's
+// still there, else the one on the PATH.
+var GoBin = utils.GoBin
@@ -55,6 +55,40 @@ spec:
 			},
 		},
 
+		"wildcard to another registry": {
+			input: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: instance
+spec:
+  containers:
+  - image: nginx:1.21
+  - image: app@sha256:abc
+`,
+			expectedOutput: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: instance
+spec:
+  containers:
+  - image: registry.example.com/nginx:1.21
+  - image: registry.example.com/app@sha256:abc
+`,
+			filter: Filter{
+				ImageTag: types.Image{
+					Name:    "*",
+					NewName: "registry.example.com/*",
+				},
+			},
+			fsSlice: []types.FieldSpec{
+				{
+					Path: "spec/containers[]/image",
+				},
+			},
+		},
+
 		"legacy multiple images in containers": {
 			input: `
 apiVersion: example.com/v1
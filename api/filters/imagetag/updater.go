@@ -4,6 +4,8 @@
 package imagetag
 
 import (
+	"strings"
+
 	"sigs.k8s.io/kustomize/api/filters/filtersutil"
 	"sigs.k8s.io/kustomize/api/image"
 	"sigs.k8s.io/kustomize/api/types"
@@ -32,7 +34,9 @@ func (u imageTagUpdater) SetImageValue(rn *yaml.RNode) error {
 
 	name, tag, digest := image.Split(value)
 	if u.ImageTag.NewName != "" {
-		name = u.ImageTag.NewName
+		// A "*" in the new name stands for the old name,
+		// e.g. to move all images to another registry.
+		name = strings.ReplaceAll(u.ImageTag.NewName, "*", name)
 	}
 
 	// overriding tag or digest will replace both original tag and digest values
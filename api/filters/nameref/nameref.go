@@ -36,6 +36,11 @@ type Filter struct {
 
 	// Set of resources to scan to find the ReferralTarget.
 	ReferralCandidates resmap.ResMap
+
+	// ReferralCandidateIndex, if not nil, indexes the
+	// ReferralCandidates, to find the ReferralTarget
+	// without scanning them all.
+	ReferralCandidateIndex *resmap.Index
 }
 
 // At time of writing, in practice this is called with a slice with only
@@ -110,11 +115,11 @@ func (f Filter) setMapping(node *yaml.RNode) error {
 		// field to update with a new name.
 		return fmt.Errorf("path config error; no 'name' field in node")
 	}
-	candidates, err := f.filterMapCandidatesByNamespace(node)
+	oldName := nameNode.YNode().Value
+	candidates, err := f.filterMapCandidatesByNamespace(node, oldName)
 	if err != nil {
 		return err
 	}
-	oldName := nameNode.YNode().Value
 	referral, err := f.selectReferral(oldName, candidates)
 	if err != nil || referral == nil {
 		// Nil referral means nothing to do.
@@ -145,15 +150,26 @@ func (f Filter) setMapping(node *yaml.RNode) error {
 }
 
 func (f Filter) filterMapCandidatesByNamespace(
-	node *yaml.RNode) ([]*resource.Resource, error) {
+	node *yaml.RNode, name string) ([]*resource.Resource, error) {
 	namespaceNode, err := node.Pipe(yaml.FieldMatcher{Name: "namespace"})
 	if err != nil {
 		return nil, errors.Wrap(err, "trying to match 'namespace' field")
 	}
 	if namespaceNode == nil {
-		return f.ReferralCandidates.Resources(), nil
+		return f.candidatesNamed(name), nil
 	}
 	namespace := namespaceNode.YNode().Value
+	if x := f.ReferralCandidateIndex; x != nil {
+		// As below, but only for the candidates named name.
+		original := x.HasOriginalNamespace(namespace)
+		return doSieve(x.Named(name), func(r *resource.Resource) bool {
+			if original {
+				return r.OrgId().EffectiveNamespace() == namespace
+			}
+			return namespace != resid.TotallyNotANamespace &&
+				r.CurId().EffectiveNamespace() == namespace
+		}), nil
+	}
 	nsMap := f.ReferralCandidates.GroupedByOriginalNamespace()
 	if candidates, ok := nsMap[namespace]; ok {
 		return candidates, nil
@@ -165,7 +181,7 @@ func (f Filter) filterMapCandidatesByNamespace(
 
 func (f Filter) setScalar(node *yaml.RNode) error {
 	referral, err := f.selectReferral(
-		node.YNode().Value, f.ReferralCandidates.Resources())
+		node.YNode().Value, f.candidatesNamed(node.YNode().Value))
 	if err != nil || referral == nil {
 		// Nil referral means nothing to do.
 		return err
@@ -178,6 +194,15 @@ func (f Filter) setScalar(node *yaml.RNode) error {
 	return node.PipeE(yaml.FieldSetter{StringValue: referral.GetName()})
 }
 
+// candidatesNamed returns the candidates that may have been
+// named name: all of them, unless they're indexed.
+func (f Filter) candidatesNamed(name string) []*resource.Resource {
+	if f.ReferralCandidateIndex == nil {
+		return f.ReferralCandidates.Resources()
+	}
+	return f.ReferralCandidateIndex.Named(name)
+}
+
 // In the resource, make a note that it is referred to by the Referrer.
 func (f Filter) recordTheReferral(referral *resource.Resource) {
 	referral.AppendRefBy(f.Referrer.CurId())
@@ -0,0 +1,363 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package patchjson6902
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// The operations are applied to the node tree of the resource,
+// rather than to a JSON copy of it that replaces the resource,
+// as they once were with github.com/evanphx/json-patch. They
+// behave as they did with that library, which still decodes
+// the patch, and fail with the same errors.
+
+var pointerDecoder = strings.NewReplacer("~1", "/", "~0", "~")
+
+// applyOperation applies the operation to the node.
+func applyOperation(node *yaml.Node, op jsonpatch.Operation) error {
+	switch op.Kind() {
+	case "add":
+		return add(node, op)
+	case "remove":
+		return remove(node, op)
+	case "replace":
+		return replace(node, op)
+	case "move":
+		return move(node, op)
+	case "test":
+		return test(node, op)
+	case "copy":
+		return copyValue(node, op)
+	default:
+		return errors.Errorf("Unexpected kind: %s", op.Kind())
+	}
+}
+
+func add(node *yaml.Node, op jsonpatch.Operation) error {
+	path, err := op.Path()
+	if err != nil {
+		return errors.Wrapf(jsonpatch.ErrMissing, "add operation failed to decode path")
+	}
+	con, key := findContainer(node, path)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"add operation does not apply: doc is missing path: \"%s\"", path)
+	}
+	value, err := valueOf(op)
+	if err != nil {
+		return err
+	}
+	if err = addChild(con, key, value); err != nil {
+		return errors.Wrapf(err, "error in add for path: '%s'", path)
+	}
+	return nil
+}
+
+func remove(node *yaml.Node, op jsonpatch.Operation) error {
+	path, err := op.Path()
+	if err != nil {
+		return errors.Wrapf(jsonpatch.ErrMissing, "remove operation failed to decode path")
+	}
+	con, key := findContainer(node, path)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"remove operation does not apply: doc is missing path: \"%s\"", path)
+	}
+	if err = removeChild(con, key); err != nil {
+		return errors.Wrapf(err, "error in remove for path: '%s'", path)
+	}
+	return nil
+}
+
+func replace(node *yaml.Node, op jsonpatch.Operation) error {
+	path, err := op.Path()
+	if err != nil {
+		return errors.Wrapf(err, "replace operation failed to decode path")
+	}
+	con, key := findContainer(node, path)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"replace operation does not apply: doc is missing path: %s", path)
+	}
+	if _, err = child(con, key); err != nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"replace operation does not apply: doc is missing key: %s", path)
+	}
+	value, err := valueOf(op)
+	if err != nil {
+		return err
+	}
+	setChild(con, key, value)
+	return nil
+}
+
+func move(node *yaml.Node, op jsonpatch.Operation) error {
+	from, err := op.From()
+	if err != nil {
+		return errors.Wrapf(err, "move operation failed to decode from")
+	}
+	con, key := findContainer(node, from)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"move operation does not apply: doc is missing from path: %s", from)
+	}
+	value, err := child(con, key)
+	if err != nil {
+		return errors.Wrapf(err, "error in move for path: '%s'", key)
+	}
+	if err = removeChild(con, key); err != nil {
+		return errors.Wrapf(err, "error in move for path: '%s'", key)
+	}
+	path, err := op.Path()
+	if err != nil {
+		return errors.Wrapf(err, "move operation failed to decode path")
+	}
+	con, key = findContainer(node, path)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"move operation does not apply: doc is missing destination path: %s", path)
+	}
+	if err = addChild(con, key, value); err != nil {
+		return errors.Wrapf(err, "error in move for path: '%s'", path)
+	}
+	return nil
+}
+
+func test(node *yaml.Node, op jsonpatch.Operation) error {
+	path, err := op.Path()
+	if err != nil {
+		return errors.Wrapf(err, "test operation failed to decode path")
+	}
+	con, key := findContainer(node, path)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"test operation does not apply: is missing path: %s", path)
+	}
+	actual, err := child(con, key)
+	if err != nil {
+		return errors.Wrapf(err, "error in test for path: '%s'", path)
+	}
+	expected, err := valueOf(op)
+	if err != nil {
+		return err
+	}
+	equal, err := equalValues(actual, expected)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		return errors.Wrapf(jsonpatch.ErrTestFailed, "testing value %s failed", path)
+	}
+	return nil
+}
+
+func copyValue(node *yaml.Node, op jsonpatch.Operation) error {
+	from, err := op.From()
+	if err != nil {
+		return errors.Wrapf(err, "copy operation failed to decode from")
+	}
+	con, key := findContainer(node, from)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"copy operation does not apply: doc is missing from path: %s", from)
+	}
+	value, err := child(con, key)
+	if err != nil {
+		return errors.Wrapf(err, "error in copy for from: '%s'", from)
+	}
+	path, err := op.Path()
+	if err != nil {
+		return errors.Wrapf(jsonpatch.ErrMissing, "copy operation failed to decode path")
+	}
+	con, key = findContainer(node, path)
+	if con == nil {
+		return errors.Wrapf(jsonpatch.ErrMissing,
+			"copy operation does not apply: doc is missing destination path: %s", path)
+	}
+	if value != nil {
+		value = yaml.NewRNode(value).Copy().YNode()
+	}
+	if err = addChild(con, key, value); err != nil {
+		return errors.Wrapf(err, "error while adding value during copy")
+	}
+	return nil
+}
+
+// findContainer returns the mapping or sequence holding the
+// value the JSON pointer points to, and the key of the value
+// in it, or nil if there's no such container.
+func findContainer(node *yaml.Node, pointer string) (*yaml.Node, string) {
+	parts := strings.Split(pointer, "/")
+	if len(parts) < 2 {
+		return nil, ""
+	}
+	con := node
+	for _, part := range parts[1 : len(parts)-1] {
+		next, err := child(con, pointerDecoder.Replace(part))
+		if err != nil || next == nil ||
+			(next.Kind != yaml.MappingNode && next.Kind != yaml.SequenceNode) {
+			return nil, ""
+		}
+		con = next
+	}
+	return con, pointerDecoder.Replace(parts[len(parts)-1])
+}
+
+// child returns the value of the key in the container, or nil
+// if it's a mapping lacking the key, or if the value is null.
+func child(con *yaml.Node, key string) (*yaml.Node, error) {
+	if con.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(con.Content); i += 2 {
+			if con.Content[i].Value == key {
+				return nullToNil(con.Content[i+1]), nil
+			}
+		}
+		return nil, nil
+	}
+	idx, err := strconv.Atoi(key)
+	if err != nil {
+		return nil, err
+	}
+	if idx < 0 || idx >= len(con.Content) {
+		return nil, invalidIndex(idx)
+	}
+	return nullToNil(con.Content[idx]), nil
+}
+
+// setChild sets the value of the key, which child found,
+// in the container.
+func setChild(con *yaml.Node, key string, value *yaml.Node) {
+	value = nilToNull(value)
+	if con.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(con.Content); i += 2 {
+			if con.Content[i].Value == key {
+				con.Content[i+1] = value
+				return
+			}
+		}
+		con.Content = append(con.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagString, Value: key}, value)
+		return
+	}
+	idx, _ := strconv.Atoi(key)
+	con.Content[idx] = value
+}
+
+// addChild adds the value at the key of the container, i.e.
+// sets it in a mapping, or inserts it in a sequence.
+func addChild(con *yaml.Node, key string, value *yaml.Node) error {
+	if con.Kind == yaml.MappingNode {
+		setChild(con, key, value)
+		return nil
+	}
+	value = nilToNull(value)
+	if key == "-" {
+		con.Content = append(con.Content, value)
+		return nil
+	}
+	idx, err := strconv.Atoi(key)
+	if err != nil {
+		return errors.Wrapf(err, "value was not a proper array index: '%s'", key)
+	}
+	size := len(con.Content) + 1
+	if idx >= size || idx < -size {
+		return invalidIndex(idx)
+	}
+	if idx < 0 {
+		idx += size
+	}
+	con.Content = append(con.Content[:idx],
+		append([]*yaml.Node{value}, con.Content[idx:]...)...)
+	return nil
+}
+
+// removeChild removes the key from the container.
+func removeChild(con *yaml.Node, key string) error {
+	if con.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(con.Content); i += 2 {
+			if con.Content[i].Value == key {
+				con.Content = append(con.Content[:i], con.Content[i+2:]...)
+				return nil
+			}
+		}
+		return errors.Wrapf(jsonpatch.ErrMissing, "Unable to remove nonexistent key: %s", key)
+	}
+	idx, err := strconv.Atoi(key)
+	if err != nil {
+		return err
+	}
+	size := len(con.Content)
+	if idx >= size || idx < -size {
+		return invalidIndex(idx)
+	}
+	if idx < 0 {
+		idx += size
+	}
+	con.Content = append(con.Content[:idx], con.Content[idx+1:]...)
+	return nil
+}
+
+func invalidIndex(idx int) error {
+	return errors.Wrapf(jsonpatch.ErrInvalidIndex, "Unable to access invalid index: %d", idx)
+}
+
+// valueOf returns the value of the operation as a node, or nil
+// if it has none, or it's null.
+func valueOf(op jsonpatch.Operation) (*yaml.Node, error) {
+	raw, found := op["value"]
+	if !found || raw == nil {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(*raw, &value); err != nil {
+		return nil, err
+	}
+	b, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	rn, err := yaml.Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	return nullToNil(rn.YNode()), nil
+}
+
+// equalValues returns true if the values are equal once
+// decoded, as they are in JSON.
+func equalValues(a, b *yaml.Node) (bool, error) {
+	if a == nil || b == nil {
+		return a == b, nil
+	}
+	var va, vb interface{}
+	if err := a.Decode(&va); err != nil {
+		return false, err
+	}
+	if err := b.Decode(&vb); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(va, vb), nil
+}
+
+func nullToNil(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.ScalarNode && node.Tag == yaml.NodeTagNull {
+		return nil
+	}
+	return node
+}
+
+func nilToNull(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagNull, Value: "null"}
+	}
+	return node
+}
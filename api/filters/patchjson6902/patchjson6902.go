@@ -48,18 +48,10 @@ func (pf Filter) decodePatch() (jsonpatch.Patch, error) {
 }
 
 func (pf Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
-	// We don't actually use the kyaml library for manipulating the
-	// yaml here. We just marshal it to json and rely on the
-	// jsonpatch library to take care of applying the patch.
-	// This means ordering might not be preserved with this filter.
-	b, err := node.MarshalJSON()
-	if err != nil {
-		return nil, err
-	}
-	res, err := pf.decodedPatch.Apply(b)
-	if err != nil {
-		return nil, err
+	for _, op := range pf.decodedPatch {
+		if err := applyOperation(node.YNode(), op); err != nil {
+			return nil, err
+		}
 	}
-	err = node.UnmarshalJSON(res)
-	return node, err
+	return node, nil
 }
@@ -83,12 +83,12 @@ spec:
         old-label: old-value
     spec:
       containers:
-      - command:
+      - image: nginx
+        name: my-nginx
+        command:
         - arg1
         - arg2
         - arg3
-        image: nginx
-        name: my-nginx
 `,
 		},
 		{
@@ -150,12 +150,95 @@ spec:
         old-label: old-value
     spec:
       containers:
-      - command:
+      - image: nginx
+        name: my-nginx
+        command:
         - arg1
         - arg2
         - arg3
-        image: nginx
-        name: my-nginx
+`,
+		},
+		{
+			testName: "remove, move and copy",
+			input:    input,
+			filter: Filter{
+				Patch: `[
+{"op": "copy", "from": "/spec/template/metadata/labels", "path": "/metadata/labels"},
+{"op": "move", "from": "/spec/replica", "path": "/spec/replicas"},
+{"op": "remove", "path": "/spec/template/metadata"}
+]`,
+			},
+			expectedOutput: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeploy
+  labels:
+    old-label: old-value
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx
+        name: nginx
+  replicas: 2
+`,
+		},
+		{
+			testName: "sequence insertion, and escaped keys",
+			input:    input,
+			filter: Filter{
+				Patch: `[
+{"op": "test", "path": "/spec/replica", "value": 2},
+{"op": "add", "path": "/spec/template/spec/containers/-", "value": {"name": "last", "image": "busybox"}},
+{"op": "add", "path": "/spec/template/spec/containers/0", "value": {"name": "first", "image": "busybox"}},
+{"op": "add", "path": "/spec/template/metadata/labels/app.kubernetes.io~1name", "value": "web"}
+]`,
+			},
+			expectedOutput: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeploy
+spec:
+  replica: 2
+  template:
+    metadata:
+      labels:
+        old-label: old-value
+        app.kubernetes.io/name: web
+    spec:
+      containers:
+      - image: busybox
+        name: first
+      - image: nginx
+        name: nginx
+      - image: busybox
+        name: last
+`,
+		},
+		{
+			testName: "comments kept",
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings # the name
+data:
+  # the mode
+  mode: fast
+`,
+			filter: Filter{
+				Patch: `[{"op": "replace", "path": "/data/mode", "value": "slow"}]`,
+			},
+			expectedOutput: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings # the name
+data:
+  # the mode
+  mode: slow
 `,
 		},
 	}
@@ -171,3 +254,37 @@ spec:
 		})
 	}
 }
+
+func TestErrors(t *testing.T) {
+	testCases := map[string]struct {
+		patch    string
+		expected string
+	}{
+		"replace missing path": {
+			patch:    `[{"op": "replace", "path": "/status/phase", "value": "x"}]`,
+			expected: "replace operation does not apply: doc is missing path: /status/phase: missing value",
+		},
+		"remove missing key": {
+			patch:    `[{"op": "remove", "path": "/spec/replicas"}]`,
+			expected: "error in remove for path: '/spec/replicas': Unable to remove nonexistent key: replicas: missing value",
+		},
+		"add out of range": {
+			patch:    `[{"op": "add", "path": "/spec/template/spec/containers/5", "value": {}}]`,
+			expected: "error in add for path: '/spec/template/spec/containers/5': Unable to access invalid index: 5: invalid index referenced",
+		},
+		"test failed": {
+			patch:    `[{"op": "test", "path": "/spec/replica", "value": 3}]`,
+			expected: "testing value /spec/replica failed: test failed",
+		},
+		"unknown operation": {
+			patch:    `[{"op": "merge", "path": "/spec"}]`,
+			expected: "Unexpected kind: merge",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := filtertest.RunFilterE(t, input, Filter{Patch: tc.patch})
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
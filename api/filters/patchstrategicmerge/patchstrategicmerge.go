@@ -11,6 +11,9 @@ import (
 
 type Filter struct {
 	Patch *yaml.RNode
+
+	// ListStrategy is the way lists are merged; by key by default.
+	ListStrategy yaml.MergeOptionsListStrategy
 }
 
 var _ kio.Filter = Filter{}
@@ -23,6 +26,7 @@ func (pf Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 			pf.Patch, nodes[i],
 			yaml.MergeOptions{
 				ListIncreaseDirection: yaml.MergeOptionsListPrepend,
+				ListStrategy:          pf.ListStrategy,
 			},
 		)
 		if err != nil {
@@ -5,8 +5,9 @@ package refvar
 
 import (
 	"fmt"
-	"log"
 	"strings"
+
+	"sigs.k8s.io/kustomize/api/logging"
 )
 
 const (
@@ -58,9 +59,8 @@ func MakePrimitiveReplacer(
 				// so just pretend it was a cache miss.
 				// Likely this should be an error instead of a silent failure,
 				// since the programmer passed an impossible value.
-				log.Printf(
-					"MakePrimitiveReplacer: bad replacement type=%T val=%v",
-					typedV, typedV)
+				logging.Warning("MakePrimitiveReplacer: bad replacement",
+					"type", fmt.Sprintf("%T", typedV), "value", typedV)
 				return syntaxWrap(key)
 			}
 		}
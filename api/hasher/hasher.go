@@ -4,10 +4,13 @@
 package hasher
 
 import (
+	"bufio"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"sort"
+	"unicode/utf8"
 
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -61,8 +64,14 @@ func (h *Hasher) Hash(node *yaml.RNode) (r string, err error) {
 	var encoded string
 	switch node.GetKind() {
 	case "ConfigMap":
+		if fields, ok := configMapFields(node); ok {
+			return encode(hashFields(fields))
+		}
 		encoded, err = encodeConfigMap(node)
 	case "Secret":
+		if fields, ok := secretFields(node); ok {
+			return encode(hashFields(fields))
+		}
 		encoded, err = encodeSecret(node)
 	default:
 		var encodedBytes []byte
@@ -153,3 +162,207 @@ func encodeSecret(node *yaml.RNode) (string, error) {
 	}
 	return string(data), nil
 }
+
+// The ConfigMaps and Secrets whose data, and other fields, are
+// all strings, e.g. those generated, are hashed by streaming
+// the JSON encoding of encodeConfigMap and encodeSecret to the
+// hash, rather than building it, which for data embedding
+// large files is slow and makes a lot of garbage.  The hash is
+// the same.
+
+// field is a field of the object hashed: a string, or if node
+// isn't nil, the mapping of strings it holds.
+type field struct {
+	key   string
+	value string
+	node  *yaml.Node
+}
+
+// configMapFields returns the fields encodeConfigMap encodes,
+// sorted by key, and false if they aren't all strings.
+func configMapFields(node *yaml.RNode) ([]field, bool) {
+	binaryData, ok := lookupField(node, "binaryData")
+	if !ok {
+		return nil, false
+	}
+	data, ok := lookupField(node, "data")
+	if !ok {
+		return nil, false
+	}
+	name, ok := lookupField(node, "metadata/name")
+	if !ok || name.node != nil {
+		return nil, false
+	}
+	var fields []field
+	if binaryData.node != nil {
+		fields = append(fields, binaryData)
+	}
+	return append(fields,
+		data,
+		field{key: "kind", value: "ConfigMap"},
+		field{key: "name", value: name.value}), true
+}
+
+// secretFields returns the fields encodeSecret encodes, sorted
+// by key, and false if they aren't all strings.
+func secretFields(node *yaml.RNode) ([]field, bool) {
+	data, ok := lookupField(node, "data")
+	if !ok {
+		return nil, false
+	}
+	name, ok := lookupField(node, "metadata/name")
+	if !ok || name.node != nil {
+		return nil, false
+	}
+	stringData, ok := lookupField(node, "stringData")
+	if !ok {
+		return nil, false
+	}
+	typ, ok := lookupField(node, "type")
+	if !ok || typ.node != nil {
+		return nil, false
+	}
+	fields := []field{
+		data,
+		{key: "kind", value: "Secret"},
+		{key: "name", value: name.value},
+	}
+	if stringData.node != nil {
+		fields = append(fields, stringData)
+	}
+	return append(fields, field{key: "type", value: typ.value}), true
+}
+
+// lookupField returns the field with the key as getNodeValues
+// takes it: the empty string if it's missing, the value of a
+// scalar, or a mapping.  The key isn't split on slashes, e.g.
+// metadata/name is missing from resources.  It returns false if
+// the mapping doesn't hold strings only, with unique keys, or
+// the strings aren't valid UTF-8, which json.Marshal changes.
+func lookupField(node *yaml.RNode, key string) (field, bool) {
+	f := field{key: key}
+	n := node.YNode()
+	if n.Kind != yaml.MappingNode {
+		return f, false
+	}
+	var v *yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			v = n.Content[i+1]
+			break
+		}
+	}
+	switch {
+	case v == nil:
+		return f, true
+	case v.Kind == yaml.ScalarNode:
+		f.value = v.Value
+		return f, utf8.ValidString(f.value)
+	case v.Kind != yaml.MappingNode:
+		return f, false
+	}
+	seen := make(map[string]bool, len(v.Content)/2)
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		k, e := v.Content[i], v.Content[i+1]
+		if k.Kind != yaml.ScalarNode || k.ShortTag() != yaml.NodeTagString ||
+			e.Kind != yaml.ScalarNode || e.ShortTag() != yaml.NodeTagString ||
+			seen[k.Value] || !utf8.ValidString(k.Value) || !utf8.ValidString(e.Value) {
+			return f, false
+		}
+		seen[k.Value] = true
+	}
+	f.node = v
+	return f, true
+}
+
+// hashFields returns the hex form of the sha256 of the JSON
+// encoding of an object with the fields, sorted by key.
+func hashFields(fields []field) string {
+	h := sha256.New()
+	w := bufio.NewWriterSize(h, 32<<10)
+	w.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		writeJSONString(w, f.key)
+		w.WriteByte(':')
+		if f.node == nil {
+			writeJSONString(w, f.value)
+			continue
+		}
+		writeStringMap(w, f.node)
+	}
+	w.WriteByte('}')
+	w.Flush()
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeStringMap writes the JSON encoding of a mapping of
+// strings, sorted by key.
+func writeStringMap(w *bufio.Writer, n *yaml.Node) {
+	keys := make([]int, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keys = append(keys, i)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return n.Content[keys[i]].Value < n.Content[keys[j]].Value
+	})
+	w.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		writeJSONString(w, n.Content[k].Value)
+		w.WriteByte(':')
+		writeJSONString(w, n.Content[k+1].Value)
+	}
+	w.WriteByte('}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeJSONString writes the valid UTF-8 string s as json.Marshal
+// encodes it, escaping HTML characters.
+func writeJSONString(w *bufio.Writer, s string) {
+	w.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b >= utf8.RuneSelf {
+			c, size := utf8.DecodeRuneInString(s[i:])
+			if c == '\u2028' || c == '\u2029' {
+				w.WriteString(s[start:i])
+				w.WriteString(`\u202`)
+				w.WriteByte(hexDigits[c&0xF])
+				start = i + size
+			}
+			i += size
+			continue
+		}
+		if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+			i++
+			continue
+		}
+		w.WriteString(s[start:i])
+		w.WriteByte('\\')
+		switch b {
+		case '"', '\\':
+			w.WriteByte(b)
+		case '\n':
+			w.WriteByte('n')
+		case '\r':
+			w.WriteByte('r')
+		case '\t':
+			w.WriteByte('t')
+		default:
+			w.WriteString("u00")
+			w.WriteByte(hexDigits[b>>4])
+			w.WriteByte(hexDigits[b&0xF])
+		}
+		i++
+		start = i
+	}
+	w.WriteString(s[start:])
+	w.WriteByte('"')
+}
@@ -354,3 +354,142 @@ func SkipRest(t *testing.T, desc string, err error, contains string) bool {
 	}
 	return false
 }
+
+func TestStreamedHashMatchesEncoding(t *testing.T) {
+	special := "quote\" backslash\\ html<>& tab\t cr\r lf\n ctl\x01\x1f del\x7f " +
+		"é 日本 \U0001F600 sep   end"
+	docs := []string{`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  b: two
+  a: "one"
+  c: |
+    multi
+    line
+binaryData:
+  bin: aGVsbG8=
+`, `
+apiVersion: v1
+kind: ConfigMap
+data: null
+binaryData:
+`, `
+apiVersion: v1
+kind: Secret
+type: Opaque
+data:
+  password: c2VjcmV0
+stringData:
+  user: admin
+`, `
+apiVersion: v1
+kind: Secret
+`}
+	cm := yaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+`)
+	// As generated.
+	if err := cm.LoadMapIntoConfigMapData(map[string]string{
+		special:          special,
+		"large":          strings.Repeat("0123456789abcdef\n", 1<<16),
+		"<key>&1":        "",
+		"spaces":         "  leading\ntrailing  \n  ",
+		"newlines":       "\n\nfirst\n\n",
+		"number":         "42",
+		"bool":           "true",
+		"tilde":          "~",
+		"binary":         "\x00\xff\xfe",
+		"app.properties": "a=b\n# comment: x\n- item\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.LoadMapIntoConfigMapBinaryData(map[string]string{
+		"bin": "\x00\x01\xff",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nodes := []*yaml.RNode{cm}
+	for _, doc := range docs {
+		nodes = append(nodes, yaml.MustParse(doc))
+	}
+	for _, node := range nodes {
+		var fields []field
+		var ok bool
+		var encoded string
+		var err error
+		if node.GetKind() == "ConfigMap" {
+			fields, ok = configMapFields(node)
+			encoded, err = encodeConfigMap(node)
+		} else {
+			fields, ok = secretFields(node)
+			encoded, err = encodeSecret(node)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("expected the fields of %.200s to be streamed", node.MustString())
+		}
+		if expected, actual := hex256(encoded), hashFields(fields); expected != actual {
+			t.Errorf("expected hash %s of %.100s, got %s", expected, encoded, actual)
+		}
+	}
+}
+
+func TestStreamedHashNotStrings(t *testing.T) {
+	for _, doc := range []string{`
+kind: ConfigMap
+data:
+  two: 2
+`, `
+kind: ConfigMap
+data:
+  a: x
+  a: y
+`, `
+kind: ConfigMap
+binaryData: [a]
+`, `
+kind: ConfigMap
+data:
+  nested:
+    a: x
+`} {
+		if _, ok := configMapFields(yaml.MustParse(doc)); ok {
+			t.Errorf("expected the fields of %s not to be streamed", doc)
+		}
+	}
+}
+
+func TestStreamedHashTabIndentedData(t *testing.T) {
+	cm := yaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+`)
+	if err := cm.LoadMapIntoConfigMapData(map[string]string{
+		"tabs": "\tindented\n\tlines\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The YAML encoding of the data, which encodeConfigMap
+	// reads back, can't be read.
+	if _, err := encodeConfigMap(cm); err == nil {
+		t.Fatalf("expected an error")
+	}
+	hashed, err := (&Hasher{}).Hash(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed != "kb58h27cdk" {
+		t.Errorf("unexpected hash %s", hashed)
+	}
+}
@@ -38,6 +38,16 @@ type Loader interface {
 	Cleanup() error
 }
 
+// DirLoader is a Loader that can also list directories.
+type DirLoader interface {
+	Loader
+	// IsDir returns true if the location is a directory.
+	IsDir(location string) bool
+	// ReadDir returns the sorted names of the files and
+	// directories in the directory at the location.
+	ReadDir(location string) ([]string, error)
+}
+
 // KustHasher returns a hash of the argument
 // or an error.
 type KustHasher interface {
@@ -9,8 +9,12 @@ import (
 )
 
 // IsImageMatched returns true if the value of t is identical to the
-// image name in the full image name and tag as given by s.
+// image name in the full image name and tag as given by s, or if
+// t is "*", which matches all images.
 func IsImageMatched(s, t string) bool {
+	if t == "*" {
+		return true
+	}
 	// Tag values are limited to [a-zA-Z0-9_.{}-].
 	// Some tools like Bazel rules_k8s allow tag patterns with {} characters.
 	// More info: https://github.com/bazelbuild/rules_k8s/pull/423
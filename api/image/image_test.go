@@ -40,6 +40,12 @@ func TestIsImageMatched(t *testing.T) {
 			name:      "nginx",
 			isMatched: true,
 		},
+		{
+			testName:  "wildcard",
+			value:     "registry.example.com/apache:12345",
+			name:      "*",
+			isMatched: true,
+		},
 		{
 			testName:  "name is not a match",
 			value:     "apache:12345",
@@ -5,6 +5,7 @@ package accumulator
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -13,8 +14,10 @@ import (
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/resid"
-	"sigs.k8s.io/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
 )
 
 // OpenAPIDefinition describes single type.
@@ -28,7 +31,9 @@ type OpenAPIDefinition struct {
 type myProperties = map[string]spec.Schema
 type nameToApiMap map[string]OpenAPIDefinition
 
-// LoadConfigFromCRDs parse CRD schemas from paths into a TransformerConfig
+// LoadConfigFromCRDs parse CRD schemas from paths into a TransformerConfig.
+// A path, or URL, holds either open API definitions, or the
+// CustomResourceDefinition objects themselves.
 func LoadConfigFromCRDs(
 	ldr ifc.Loader, paths []string) (*builtinconfig.TransformerConfig, error) {
 	tc := builtinconfig.MakeEmptyConfig()
@@ -37,13 +42,19 @@ func LoadConfigFromCRDs(
 		if err != nil {
 			return nil, err
 		}
-		m, err := makeNameToApiMap(content)
+		otherTc, err := makeConfigFromCRDObjects(content)
 		if err != nil {
-			return nil, errors.Wrapf(err, "unable to parse open API definition from '%s'", path)
+			return nil, errors.Wrapf(err, "unable to load CustomResourceDefinitions from '%s'", path)
 		}
-		otherTc, err := makeConfigFromApiMap(m)
-		if err != nil {
-			return nil, err
+		if otherTc == nil {
+			m, err := makeNameToApiMap(content)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to parse open API definition from '%s'", path)
+			}
+			otherTc, err = makeConfigFromApiMap(m)
+			if err != nil {
+				return nil, err
+			}
 		}
 		tc, err = tc.Merge(otherTc)
 		if err != nil {
@@ -53,11 +64,140 @@ func LoadConfigFromCRDs(
 	return tc, nil
 }
 
+// makeConfigFromCRDObjects returns the config for the schemas
+// of the CustomResourceDefinition objects in content, or nil
+// if there are none.
+func makeConfigFromCRDObjects(content []byte) (*builtinconfig.TransformerConfig, error) {
+	nodes, err := kio.FromBytes(content)
+	if err != nil {
+		// Not YAML objects; maybe a JSON map of definitions.
+		return nil, nil
+	}
+	var result *builtinconfig.TransformerConfig
+	for _, n := range nodes {
+		if n.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		if result == nil {
+			result = builtinconfig.MakeEmptyConfig()
+		}
+		kind, err := n.Pipe(yaml.Lookup("spec", "names", "kind"))
+		if err != nil || kind == nil {
+			return nil, fmt.Errorf(
+				"CustomResourceDefinition %s has no spec.names.kind", n.GetName())
+		}
+		group, err := n.Pipe(yaml.Lookup("spec", "group"))
+		if err != nil {
+			return nil, err
+		}
+		gvk := resid.Gvk{Group: yaml.GetValue(group), Kind: yaml.GetValue(kind)}
+		versions, err := crdSchemas(n)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range versions {
+			tc := builtinconfig.MakeEmptyConfig()
+			gvk.Version = v.version
+			if err = loadSchemaIntoConfig(tc, gvk, v.schema, nil); err != nil {
+				return nil, err
+			}
+			if result, err = result.Merge(tc); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+type versionSchema struct {
+	version string
+	schema  spec.Schema
+}
+
+// crdSchemas returns the schema of each version of the
+// CustomResourceDefinition, in either apiextensions v1 or
+// v1beta1 form.
+func crdSchemas(crd *yaml.RNode) ([]versionSchema, error) {
+	var result []versionSchema
+	versions, err := crd.Pipe(yaml.Lookup("spec", "versions"))
+	if err != nil {
+		return nil, err
+	}
+	if versions != nil {
+		elements, err := versions.Elements()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range elements {
+			s, err := v.Pipe(yaml.Lookup("schema", "openAPIV3Schema"))
+			if err != nil {
+				return nil, err
+			}
+			if s == nil {
+				continue
+			}
+			schema, err := toSchema(s)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, versionSchema{
+				version: yaml.GetValue(v.Field("name").Value), schema: schema})
+		}
+	}
+	// v1beta1 allows one schema for all versions.
+	s, err := crd.Pipe(yaml.Lookup("spec", "validation", "openAPIV3Schema"))
+	if err != nil || s == nil {
+		return result, err
+	}
+	schema, err := toSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, versionSchema{schema: schema}), nil
+}
+
+func toSchema(n *yaml.RNode) (spec.Schema, error) {
+	var schema spec.Schema
+	j, err := n.MarshalJSON()
+	if err != nil {
+		return schema, err
+	}
+	err = json.Unmarshal(j, &schema)
+	return schema, err
+}
+
+// loadSchemaIntoConfig adds the field specs that the extensions
+// of the properties of the schema, and of their properties in
+// turn, ask for.
+func loadSchemaIntoConfig(theConfig *builtinconfig.TransformerConfig,
+	theGvk resid.Gvk, schema spec.Schema, path []string) error {
+	for propName, property := range schema.SchemaProps.Properties {
+		propPath := append(path[:len(path):len(path)], propName)
+		err := addExtensionFieldSpecs(theConfig, theGvk, property, propPath)
+		if err != nil {
+			return err
+		}
+		err = loadSchemaIntoConfig(theConfig, theGvk, property, propPath)
+		if err != nil {
+			return err
+		}
+		if property.Items != nil && property.Items.Schema != nil {
+			// The elements of lists have the path of the list.
+			err = loadSchemaIntoConfig(
+				theConfig, theGvk, *property.Items.Schema, propPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func makeNameToApiMap(content []byte) (result nameToApiMap, err error) {
 	if content[0] == '{' {
 		err = json.Unmarshal(content, &result)
 	} else {
-		err = yaml.Unmarshal(content, &result)
+		err = k8syaml.Unmarshal(content, &result)
 	}
 	return
 }
@@ -123,6 +263,12 @@ const (
 	// "x-kubernetes-object-ref-name-key": "name"
 	// default is "name"
 	xNameKey = "x-kubernetes-object-ref-name-key"
+
+	// "x-kubernetes-var-reference": ""
+	xVarReference = "x-kubernetes-var-reference"
+
+	// "x-kubernetes-namespace": ""
+	xNamespace = "x-kubernetes-namespace"
 )
 
 // loadCrdIntoConfig loads a CRD spec into a TransformerConfig
@@ -134,53 +280,76 @@ func loadCrdIntoConfig(
 		return nil
 	}
 	for propName, property := range api.Schema.SchemaProps.Properties {
-		_, annotate := property.Extensions.GetString(xAnnotation)
-		if annotate {
-			err = theConfig.AddAnnotationFieldSpec(
-				makeFs(theGvk, append(path, propName)))
-			if err != nil {
-				return
-			}
+		err = addExtensionFieldSpecs(
+			theConfig, theGvk, property, append(path, propName))
+		if err != nil {
+			return
 		}
-		_, label := property.Extensions.GetString(xLabelSelector)
-		if label {
-			err = theConfig.AddLabelFieldSpec(
-				makeFs(theGvk, append(path, propName)))
+		if property.Ref.GetURL() != nil {
+			err = loadCrdIntoConfig(
+				theConfig, theGvk, theMap,
+				property.Ref.String(), append(path, propName))
 			if err != nil {
 				return
 			}
 		}
-		_, identity := property.Extensions.GetString(xIdentity)
-		if identity {
-			err = theConfig.AddPrefixFieldSpec(
-				makeFs(theGvk, append(path, propName)))
-			if err != nil {
-				return
-			}
+	}
+	return nil
+}
+
+// addExtensionFieldSpecs adds the field specs that the
+// extensions of the property at path ask for.
+func addExtensionFieldSpecs(theConfig *builtinconfig.TransformerConfig,
+	theGvk resid.Gvk, property spec.Schema, path []string) (err error) {
+	_, annotate := property.Extensions.GetString(xAnnotation)
+	if annotate {
+		err = theConfig.AddAnnotationFieldSpec(makeFs(theGvk, path))
+		if err != nil {
+			return
+		}
+	}
+	_, label := property.Extensions.GetString(xLabelSelector)
+	if label {
+		err = theConfig.AddLabelFieldSpec(makeFs(theGvk, path))
+		if err != nil {
+			return
+		}
+	}
+	_, identity := property.Extensions.GetString(xIdentity)
+	if identity {
+		err = theConfig.AddPrefixFieldSpec(makeFs(theGvk, path))
+		if err != nil {
+			return
+		}
+	}
+	_, varReference := property.Extensions.GetString(xVarReference)
+	if varReference {
+		err = theConfig.AddVarReferenceFieldSpec(makeFs(theGvk, path))
+		if err != nil {
+			return
+		}
+	}
+	_, namespace := property.Extensions.GetString(xNamespace)
+	if namespace {
+		err = theConfig.AddNamespaceFieldSpec(makeFs(theGvk, path))
+		if err != nil {
+			return
 		}
-		version, ok := property.Extensions.GetString(xVersion)
+	}
+	version, ok := property.Extensions.GetString(xVersion)
+	if ok {
+		kind, ok := property.Extensions.GetString(xKind)
 		if ok {
-			kind, ok := property.Extensions.GetString(xKind)
-			if ok {
-				nameKey, ok := property.Extensions.GetString(xNameKey)
-				if !ok {
-					nameKey = "name"
-				}
-				err = theConfig.AddNamereferenceFieldSpec(
-					builtinconfig.NameBackReferences{
-						Gvk: resid.Gvk{Kind: kind, Version: version},
-						Referrers: []types.FieldSpec{
-							makeFs(theGvk, append(path, propName, nameKey))},
-					})
-				if err != nil {
-					return
-				}
+			nameKey, ok := property.Extensions.GetString(xNameKey)
+			if !ok {
+				nameKey = "name"
 			}
-		}
-		if property.Ref.GetURL() != nil {
-			err = loadCrdIntoConfig(
-				theConfig, theGvk, theMap,
-				property.Ref.String(), append(path, propName))
+			err = theConfig.AddNamereferenceFieldSpec(
+				builtinconfig.NameBackReferences{
+					Gvk: resid.Gvk{Kind: kind, Version: version},
+					Referrers: []types.FieldSpec{
+						makeFs(theGvk, append(path[:len(path):len(path)], nameKey))},
+				})
 			if err != nil {
 				return
 			}
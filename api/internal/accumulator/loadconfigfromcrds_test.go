@@ -174,3 +174,75 @@ func TestLoadCRDs(t *testing.T) {
 		t.Fatalf("expected\n %v\n but got\n %v\n", expectedTc, actualTc)
 	}
 }
+
+const crdObjects = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: mykinds.example.com
+spec:
+  group: example.com
+  names:
+    kind: MyKind
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              secretRef:
+                type: object
+                x-kubernetes-object-ref-api-version: v1
+                x-kubernetes-object-ref-kind: Secret
+              targetNamespace:
+                type: string
+                x-kubernetes-namespace: ""
+              containers:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    command:
+                      type: string
+                      x-kubernetes-var-reference: ""
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-crd
+`
+
+func TestLoadCRDObjects(t *testing.T) {
+	gvk := resid.Gvk{Group: "example.com", Version: "v1", Kind: "MyKind"}
+	expectedTc := &builtinconfig.TransformerConfig{
+		NameReference: []builtinconfig.NameBackReferences{
+			{
+				Gvk: resid.Gvk{Kind: "Secret", Version: "v1"},
+				Referrers: []types.FieldSpec{
+					{Gvk: gvk, Path: "spec/secretRef/name"},
+				},
+			},
+		},
+		NameSpace: types.FsSlice{
+			{Gvk: gvk, Path: "spec/targetNamespace"},
+		},
+		VarReference: types.FsSlice{
+			{Gvk: gvk, Path: "spec/containers/command"},
+		},
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	err := fSys.WriteFile("/testpath/crd.yaml", []byte(crdObjects))
+	require.NoError(t, err)
+	ldr, err := loader.NewLoader(loader.RestrictionRootOnly, "/testpath", fSys)
+	require.NoError(t, err)
+
+	actualTc, err := LoadConfigFromCRDs(ldr, []string{"crd.yaml"})
+	require.NoError(t, err)
+	if !reflect.DeepEqual(actualTc, expectedTc) {
+		t.Fatalf("expected\n %v\n but got\n %v\n", expectedTc, actualTc)
+	}
+}
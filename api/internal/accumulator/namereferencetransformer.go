@@ -51,14 +51,16 @@ func newNameReferenceTransformer(
 func (t *nameReferenceTransformer) Transform(m resmap.ResMap) error {
 	fMap := t.determineFilters(m.Resources())
 	debug(fMap)
+	cache := make(map[string]*referralCandidates)
 	for r, fList := range fMap {
-		c, err := m.SubsetThatCouldBeReferencedByResource(r)
+		c, err := candidatesFor(m, r, cache)
 		if err != nil {
 			return err
 		}
 		for _, f := range fList {
 			f.Referrer = r
-			f.ReferralCandidates = c
+			f.ReferralCandidates = c.m
+			f.ReferralCandidateIndex = c.index
 			if err := f.Referrer.ApplyFilter(f); err != nil {
 				return err
 			}
@@ -67,6 +69,37 @@ func (t *nameReferenceTransformer) Transform(m resmap.ResMap) error {
 	return nil
 }
 
+// referralCandidates are the resources a referrer may refer to.
+type referralCandidates struct {
+	m     resmap.ResMap
+	index *resmap.Index
+}
+
+// candidatesFor returns the referral candidates of the referrer.
+// Those of referrers other than RoleBindings only depend on their
+// namespace, so they're cached, and indexed, per namespace: fixing
+// references doesn't rename resources, which would stale indexes.
+func candidatesFor(m resmap.ResMap, r *resource.Resource,
+	cache map[string]*referralCandidates) (*referralCandidates, error) {
+	if r.GetKind() == "RoleBinding" {
+		c, err := m.SubsetThatCouldBeReferencedByResource(r)
+		if err != nil {
+			return nil, err
+		}
+		return &referralCandidates{m: c}, nil
+	}
+	ns := r.CurId().EffectiveNamespace()
+	if c, ok := cache[ns]; ok {
+		return c, nil
+	}
+	c, err := m.SubsetThatCouldBeReferencedByResource(r)
+	if err != nil {
+		return nil, err
+	}
+	cache[ns] = &referralCandidates{m: c, index: resmap.NewIndex(c)}
+	return cache[ns], nil
+}
+
 func debug(fMap filterMap) {
 	if !doDebug {
 		return
@@ -4,9 +4,16 @@
 package accumulator
 
 import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
 	"sigs.k8s.io/kustomize/api/filters/refvar"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 type refVarTransformer struct {
@@ -52,6 +59,61 @@ func (rv *refVarTransformer) Transform(m resmap.ResMap) error {
 				return err
 			}
 		}
+		if err := substituteData(res, mf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// substituteData replaces $(VAR) style variables in the data
+// values of the keys that a ConfigMap or Secret lists in its
+// konfig.SubstituteKeysAnnotation.  The values stay strings.
+func substituteData(res *resource.Resource, mf refvar.MappingFunc) error {
+	keys, ok := res.GetAnnotations()[konfig.SubstituteKeysAnnotation]
+	if !ok {
+		return nil
+	}
+	kind := res.GetKind()
+	if kind != "ConfigMap" && kind != "Secret" {
+		return nil
+	}
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		found := false
+		for _, field := range []string{"data", "stringData"} {
+			node, err := res.Pipe(yaml.Lookup(field, key))
+			if err != nil {
+				return err
+			}
+			if node == nil {
+				continue
+			}
+			found = true
+			value := node.YNode().Value
+			// The data of Secrets is base64 encoded.
+			encoded := kind == "Secret" && field == "data"
+			if encoded {
+				b, err := base64.StdEncoding.DecodeString(value)
+				if err != nil {
+					return fmt.Errorf(
+						"decoding key %q of %s: %w", key, res.CurId(), err)
+				}
+				value = string(b)
+			}
+			value = fmt.Sprint(refvar.DoReplacements(value, mf))
+			if encoded {
+				value = base64.StdEncoding.EncodeToString([]byte(value))
+			}
+			node.YNode().Value = value
+		}
+		if !found {
+			return fmt.Errorf("%s has no data key %q listed in annotation %s",
+				res.CurId(), key, konfig.SubstituteKeysAnnotation)
+		}
 	}
 	return nil
 }
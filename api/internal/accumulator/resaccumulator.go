@@ -5,11 +5,12 @@ package accumulator
 
 import (
 	"fmt"
-	"log"
-	"strings"
 
+	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/resid"
 )
@@ -21,6 +22,12 @@ type ResAccumulator struct {
 	resMap  resmap.ResMap
 	tConfig *builtinconfig.TransformerConfig
 	varSet  types.VarSet
+	// varOverrides maps var names to values that replace
+	// the values found in the resources.
+	varOverrides map[string]string
+	// unusedVars are the names of the vars that ResolveVars
+	// found no reference to.
+	unusedVars []string
 }
 
 func MakeEmptyAccumulator() *ResAccumulator {
@@ -31,6 +38,36 @@ func MakeEmptyAccumulator() *ResAccumulator {
 	return ra
 }
 
+// MakeAccumulator returns an accumulator holding the given
+// resources, transformer config and vars, e.g. those of an
+// accumulator saved earlier.
+func MakeAccumulator(m resmap.ResMap,
+	tConfig *builtinconfig.TransformerConfig, vars []types.Var) (*ResAccumulator, error) {
+	ra := MakeEmptyAccumulator()
+	ra.resMap = m
+	if tConfig != nil {
+		ra.tConfig = tConfig
+	}
+	if err := ra.varSet.MergeSlice(vars); err != nil {
+		return nil, err
+	}
+	return ra, nil
+}
+
+// DeepCopy returns a copy of the accumulator, whose resources
+// can be changed without changing those of the original.
+func (ra *ResAccumulator) DeepCopy() *ResAccumulator {
+	// Merging into an empty config can't conflict.
+	tConfig, _ := (&builtinconfig.TransformerConfig{}).Merge(ra.tConfig)
+	return &ResAccumulator{
+		resMap:       ra.resMap.DeepCopy(),
+		tConfig:      tConfig,
+		varSet:       ra.varSet.Copy(),
+		varOverrides: ra.varOverrides,
+		unusedVars:   ra.unusedVars,
+	}
+}
+
 // ResMap returns a copy of the internal resMap.
 func (ra *ResAccumulator) ResMap() resmap.ResMap {
 	return ra.resMap.ShallowCopy()
@@ -70,19 +107,15 @@ func (ra *ResAccumulator) GetTransformerConfig() *builtinconfig.TransformerConfi
 // by digging into a particular fieldpath of "james".
 func (ra *ResAccumulator) MergeVars(incoming []types.Var) error {
 	for _, v := range incoming {
-		targetId := resid.NewResIdWithNamespace(v.ObjRef.GVK(), v.ObjRef.Name, v.ObjRef.Namespace)
-		idMatcher := targetId.GvknEquals
-		if targetId.Namespace != "" || targetId.IsClusterScoped() {
-			// Preserve backward compatibility. An empty namespace means
-			// wildcard search on the namespace hence we still use GvknEquals
-			idMatcher = targetId.Equals
+		matched, err := ra.matchVarObjRef(v)
+		if err != nil {
+			return err
 		}
-		matched := ra.resMap.GetMatchingResourcesByAnyId(idMatcher)
 		if len(matched) > 1 {
 			return fmt.Errorf(
 				"found %d resId matches for var %s "+
 					"(unable to disambiguate)",
-				len(matched), v)
+				len(matched), v.Name)
 		}
 		if len(matched) == 1 {
 			matched[0].AppendRefVarName(v)
@@ -91,6 +124,35 @@ func (ra *ResAccumulator) MergeVars(incoming []types.Var) error {
 	return ra.varSet.MergeSlice(incoming)
 }
 
+// matchVarObjRef returns the resources the ObjRef of the var
+// refers to.  With a label selector, only the first resource
+// with matching labels is returned.
+func (ra *ResAccumulator) matchVarObjRef(v types.Var) ([]*resource.Resource, error) {
+	targetId := resid.NewResIdWithNamespace(v.ObjRef.GVK(), v.ObjRef.Name, v.ObjRef.Namespace)
+	if v.ObjRef.LabelSelector == "" {
+		idMatcher := targetId.GvknEquals
+		if targetId.Namespace != "" || targetId.IsClusterScoped() {
+			// Preserve backward compatibility. An empty namespace means
+			// wildcard search on the namespace hence we still use GvknEquals
+			idMatcher = targetId.Equals
+		}
+		return ra.resMap.GetMatchingResourcesByAnyId(idMatcher), nil
+	}
+	candidates := ra.resMap.GetMatchingResourcesByAnyId(func(id resid.ResId) bool {
+		return id.IsSelectedBy(targetId)
+	})
+	for _, res := range candidates {
+		ok, err := res.MatchesLabelSelector(v.ObjRef.LabelSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "var '%s'", v.Name)
+		}
+		if ok {
+			return []*resource.Resource{res}, nil
+		}
+	}
+	return nil, nil
+}
+
 func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	err = ra.AppendAll(other.resMap)
 	if err != nil {
@@ -109,6 +171,9 @@ func (ra *ResAccumulator) findVarValueFromResources(v types.Var) (interface{}, e
 			if varName == v.Name {
 				//nolint: staticcheck
 				s, err := res.GetFieldValue(v.FieldRef.FieldPath)
+				if err != nil && v.Default != nil {
+					return *v.Default, nil
+				}
 				if err != nil {
 					return "", fmt.Errorf(
 						"field specified in var '%v' "+
@@ -118,6 +183,9 @@ func (ra *ResAccumulator) findVarValueFromResources(v types.Var) (interface{}, e
 			}
 		}
 	}
+	if v.Default != nil {
+		return *v.Default, nil
+	}
 	return "", fmt.Errorf(
 		"var '%v' cannot be mapped to a field "+
 			"in the set of known resources", v)
@@ -129,6 +197,10 @@ func (ra *ResAccumulator) findVarValueFromResources(v types.Var) (interface{}, e
 func (ra *ResAccumulator) makeVarReplacementMap() (map[string]interface{}, error) {
 	result := map[string]interface{}{}
 	for _, v := range ra.Vars() {
+		if value, ok := ra.varOverrides[v.Name]; ok {
+			result[v.Name] = value
+			continue
+		}
 		s, err := ra.findVarValueFromResources(v)
 		if err != nil {
 			return nil, err
@@ -138,27 +210,55 @@ func (ra *ResAccumulator) makeVarReplacementMap() (map[string]interface{}, error
 	return result, nil
 }
 
+// SetVarOverrides makes the given vars take the given values,
+// rather than values found in the resources.
+func (ra *ResAccumulator) SetVarOverrides(values map[string]string) {
+	ra.varOverrides = values
+}
+
 func (ra *ResAccumulator) Transform(t resmap.Transformer) error {
 	return t.Transform(ra.resMap)
 }
 
+// UnusedVars returns the names of the vars that ResolveVars
+// found no reference to.
+func (ra *ResAccumulator) UnusedVars() []string {
+	return ra.unusedVars
+}
+
 func (ra *ResAccumulator) ResolveVars() error {
 	replacementMap, err := ra.makeVarReplacementMap()
 	if err != nil {
 		return err
 	}
 	if len(replacementMap) == 0 {
-		return nil
+		return ra.dropSubstituteKeysAnnotations()
 	}
 	t := newRefVarTransformer(
 		replacementMap, ra.tConfig.VarReference)
 	err = ra.Transform(t)
-	if len(t.UnusedVars()) > 0 {
-		log.Printf(
-			"well-defined vars that were never replaced: %s\n",
-			strings.Join(t.UnusedVars(), ","))
+	ra.unusedVars = t.UnusedVars()
+	if err != nil {
+		return err
 	}
-	return err
+	return ra.dropSubstituteKeysAnnotations()
+}
+
+// dropSubstituteKeysAnnotations removes the annotation with which
+// ConfigMaps and Secrets opt in to the replacement of vars in
+// their data, once vars are resolved.
+func (ra *ResAccumulator) dropSubstituteKeysAnnotations() error {
+	for _, res := range ra.resMap.Resources() {
+		annotations := res.GetAnnotations()
+		if _, ok := annotations[konfig.SubstituteKeysAnnotation]; !ok {
+			continue
+		}
+		delete(annotations, konfig.SubstituteKeysAnnotation)
+		if err := res.SetAnnotations(annotations); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (ra *ResAccumulator) FixBackReferences() (err error) {
@@ -4,9 +4,6 @@
 package accumulator_test
 
 import (
-	"bytes"
-	"log"
-	"os"
 	"strings"
 	"testing"
 
@@ -116,28 +113,19 @@ func TestResolveVarsOneUnused(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	var buf bytes.Buffer
-	log.SetOutput(&buf)
-	defer func() {
-		log.SetOutput(os.Stderr)
-	}()
 	err = ra.ResolveVars()
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	expectLog(t, buf, "well-defined vars that were never replaced: SERVICE_UNUSED")
+	if unused := ra.UnusedVars(); len(unused) != 1 || unused[0] != "SERVICE_UNUSED" {
+		t.Fatalf("unexpected unused vars: %v", unused)
+	}
 	c := getCommand(find("deploy1", ra.ResMap()))
 	if c != "myserver --somebackendService backendOne --yetAnother $(SERVICE_TWO)" {
 		t.Fatalf("unexpected command: %s", c)
 	}
 }
 
-func expectLog(t *testing.T, log bytes.Buffer, expect string) {
-	if !strings.Contains(log.String(), expect) {
-		t.Fatalf("expected log containing '%s', got '%s'", expect, log.String())
-	}
-}
-
 func TestResolveVarsVarNeedsDisambiguation(t *testing.T) {
 	ra := makeResAccumulator(t)
 	rm0 := resmap.New()
@@ -424,3 +412,33 @@ func getCommand(r *resource.Resource) string {
 	}
 	return strings.Join(n, " ")
 }
+
+func TestDeepCopy(t *testing.T) {
+	ra := makeResAccumulator(t)
+	err := ra.MergeVars([]types.Var{
+		{
+			Name: "SERVICE_ONE",
+			ObjRef: types.Target{
+				Gvk:  resid.Gvk{Version: "v1", Kind: "Service"},
+				Name: "backendOne"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	clone := ra.DeepCopy()
+	err = ra.ResolveVars()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	c := getCommand(find("deploy1", clone.ResMap()))
+	if !strings.Contains(c, "$(SERVICE_ONE)") {
+		t.Fatalf("copy changed with the original: %s", c)
+	}
+	if len(clone.Vars()) != 1 || clone.Vars()[0].Name != "SERVICE_ONE" {
+		t.Fatalf("unexpected vars: %v", clone.Vars())
+	}
+	if len(clone.GetTransformerConfig().NameReference) == 0 {
+		t.Fatalf("expected the transformer config to be copied")
+	}
+}
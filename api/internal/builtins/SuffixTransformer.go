@@ -17,9 +17,12 @@ import (
 type SuffixTransformerPlugin struct {
 	Suffix     string        `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 	FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+	// FieldSpecsToSkip select, by Gvk, more resources whose
+	// fields are left alone.
+	FieldSpecsToSkip types.FsSlice `json:"fieldSpecsToSkip,omitempty" yaml:"fieldSpecsToSkip,omitempty"`
 }
 
-// TODO: Make this gvk skip list part of the config.
+// Resources that are always skipped, whatever the config.
 var suffixFieldSpecsToSkip = types.FsSlice{
 	{Gvk: resid.Gvk{Kind: "CustomResourceDefinition"}},
 	{Gvk: resid.Gvk{Group: "apiregistration.k8s.io", Kind: "APIService"}},
@@ -30,6 +33,7 @@ func (p *SuffixTransformerPlugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.Suffix = ""
 	p.FieldSpecs = nil
+	p.FieldSpecsToSkip = nil
 	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return
@@ -83,7 +87,7 @@ func (p *SuffixTransformerPlugin) Transform(m resmap.ResMap) error {
 }
 
 func (p *SuffixTransformerPlugin) shouldSkip(id resid.ResId) bool {
-	for _, path := range suffixFieldSpecsToSkip {
+	for _, path := range append(suffixFieldSpecsToSkip, p.FieldSpecsToSkip...) {
 		if id.IsSelected(&path.Gvk) {
 			return true
 		}
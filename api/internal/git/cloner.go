@@ -4,15 +4,73 @@
 package git
 
 import (
+	"sync"
+
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
+// MaxConcurrentClones bounds how many repositories
+// ClonerUsingGitExec fetches at a time.
+const MaxConcurrentClones = 8
+
+// fetches holds a token per fetch under way.
+var fetches = make(chan struct{}, MaxConcurrentClones)
+
+// hostGate serializes the fetches from a host until one
+// succeeds, so that what they may have to ask for, e.g.
+// confirming an ssh host key, or a password a credential
+// helper keeps, is asked for once rather than by each
+// concurrent fetch.
+type hostGate struct {
+	mu sync.Mutex
+	// open is true once a fetch from the host succeeded.
+	open bool
+}
+
+var hostGates = struct {
+	sync.Mutex
+	m map[string]*hostGate
+}{m: make(map[string]*hostGate)}
+
+func gateOf(host string) *hostGate {
+	hostGates.Lock()
+	defer hostGates.Unlock()
+	g, ok := hostGates.m[host]
+	if !ok {
+		g = &hostGate{}
+		hostGates.m[host] = g
+	}
+	return g
+}
+
+// fetchFrom calls f, fetching from the host, once the host's
+// gate is open, or it's the only fetch from the host, and
+// fewer than MaxConcurrentClones fetches are under way.
+func fetchFrom(host string, f func() error) error {
+	limited := func() error {
+		fetches <- struct{}{}
+		defer func() { <-fetches }()
+		return f()
+	}
+	g := gateOf(host)
+	g.mu.Lock()
+	if g.open {
+		g.mu.Unlock()
+		return limited()
+	}
+	defer g.mu.Unlock()
+	err := limited()
+	g.open = err == nil
+	return err
+}
+
 // Cloner is a function that can clone a git repo.
 type Cloner func(repoSpec *RepoSpec) error
 
 // ClonerUsingGitExec uses a local git install, as opposed
 // to say, some remote API, to obtain a local clone of
-// a remote repo.
+// a remote repo.  It may be called concurrently: see
+// MaxConcurrentClones and fetchFrom.
 func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	r, err := newCmdRunner(repoSpec.Timeout)
 	if err != nil {
@@ -30,14 +88,18 @@ func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	if repoSpec.Ref != "" {
 		ref = repoSpec.Ref
 	}
-	if err = r.run("fetch", "--depth=1", "origin", ref); err != nil {
+	if err = fetchFrom(repoSpec.Host, func() error {
+		return r.run("fetch", "--depth=1", "origin", ref)
+	}); err != nil {
 		return err
 	}
 	if err = r.run("checkout", "FETCH_HEAD"); err != nil {
 		return err
 	}
 	if repoSpec.Submodules {
-		return r.run("submodule", "update", "--init", "--recursive")
+		return fetchFrom(repoSpec.Host, func() error {
+			return r.run("submodule", "update", "--init", "--recursive")
+		})
 	}
 	return nil
 }
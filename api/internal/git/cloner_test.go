@@ -0,0 +1,73 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fetchAll calls fetchFrom for each host concurrently, with
+// fetches that take a while and may fail, and returns the
+// most fetches that were under way at once.
+func fetchAll(hosts []string, fail bool) int32 {
+	var running, most int32
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			_ = fetchFrom(host, func() error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					m := atomic.LoadInt32(&most)
+					if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+						break
+					}
+				}
+				time.Sleep(50 * time.Millisecond)
+				if fail {
+					return fmt.Errorf("failed")
+				}
+				return nil
+			})
+		}(host)
+	}
+	wg.Wait()
+	return most
+}
+
+func repeat(host string, n int) []string {
+	hosts := make([]string, n)
+	for i := range hosts {
+		hosts[i] = host
+	}
+	return hosts
+}
+
+func TestFetchFromHostSerializedUntilSuccess(t *testing.T) {
+	assert.Equal(t, int32(1), fetchAll(repeat("fail.example.com", 4), true))
+	assert.Equal(t, int32(1), fetchAll(repeat("new.example.com", 1), false))
+	assert.Equal(t, int32(4), fetchAll(repeat("new.example.com", 4), false))
+}
+
+func TestFetchFromDifferentHostsConcurrent(t *testing.T) {
+	var hosts []string
+	for i := 0; i < 4; i++ {
+		hosts = append(hosts, fmt.Sprintf("host%d.example.com", i))
+	}
+	assert.Equal(t, int32(4), fetchAll(hosts, false))
+}
+
+func TestFetchFromLimited(t *testing.T) {
+	assert.Equal(t, int32(1), fetchAll(repeat("busy.example.com", 1), false))
+	assert.Equal(t, int32(MaxConcurrentClones),
+		fetchAll(repeat("busy.example.com", 3*MaxConcurrentClones), false))
+}
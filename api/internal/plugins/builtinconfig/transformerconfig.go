@@ -91,6 +91,18 @@ func (t *TransformerConfig) AddAnnotationFieldSpec(fs types.FieldSpec) (err erro
 	return err
 }
 
+// AddVarReferenceFieldSpec adds a FieldSpec to VarReference
+func (t *TransformerConfig) AddVarReferenceFieldSpec(fs types.FieldSpec) (err error) {
+	t.VarReference, err = t.VarReference.MergeOne(fs)
+	return err
+}
+
+// AddNamespaceFieldSpec adds a FieldSpec to NameSpace
+func (t *TransformerConfig) AddNamespaceFieldSpec(fs types.FieldSpec) (err error) {
+	t.NameSpace, err = t.NameSpace.MergeOne(fs)
+	return err
+}
+
 // AddNamereferenceFieldSpec adds a NameBackReferences to NameReference
 func (t *TransformerConfig) AddNamereferenceFieldSpec(
 	nbrs NameBackReferences) (err error) {
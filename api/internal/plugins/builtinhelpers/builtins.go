@@ -32,6 +32,8 @@ const (
 	ValueAddTransformer
 	HelmChartInflationGenerator
 	ReplacementTransformer
+	RequiredMetadataTransformer
+	PolicyTransformer
 )
 
 var stringToBuiltinPluginTypeMap map[string]BuiltinPluginType
@@ -70,6 +72,11 @@ type MultiTransformer struct {
 	transformers []resmap.TransformerPlugin
 }
 
+// Transformers returns the member transformers.
+func (t *MultiTransformer) Transformers() []resmap.TransformerPlugin {
+	return t.transformers
+}
+
 func (t *MultiTransformer) Transform(m resmap.ResMap) error {
 	for _, transformer := range t.transformers {
 		if err := transformer.Transform(m); err != nil {
@@ -110,5 +117,7 @@ var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin
 	SuffixTransformer:              builtins.NewSuffixTransformerPlugin,
 	ReplacementTransformer:         builtins.NewReplacementTransformerPlugin,
 	ReplicaCountTransformer:        builtins.NewReplicaCountTransformerPlugin,
+	RequiredMetadataTransformer:    builtins.NewRequiredMetadataTransformerPlugin,
+	PolicyTransformer:              builtins.NewPolicyTransformerPlugin,
 	ValueAddTransformer:            builtins.NewValueAddTransformerPlugin,
 }
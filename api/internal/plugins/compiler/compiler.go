@@ -6,7 +6,6 @@ package compiler
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
+	"sigs.k8s.io/kustomize/api/logging"
 )
 
 // Compiler creates Go plugin object files.
@@ -92,17 +92,13 @@ func (b *Compiler) Compile() error {
 	}
 	result := filepath.Join(b.workDir, b.objFile())
 	if utils.FileExists(result) {
-		log.Printf("compiler created: %s", result)
+		logging.V(1).Info("compiled Go plugin", "path", result)
 		return nil
 	}
 	return fmt.Errorf("post compile, cannot find '%s'", result)
 }
 
 func (b *Compiler) report() {
-	log.Println("stdout:  -------")
-	log.Println(b.stdout.String())
-	log.Println("----------------")
-	log.Println("stderr:  -------")
-	log.Println(b.stderr.String())
-	log.Println("----------------")
+	logging.V(0).Info("Go plugin compilation failed",
+		"path", b.srcPath(), "stdout", b.stdout.String(), "stderr", b.stderr.String())
 }
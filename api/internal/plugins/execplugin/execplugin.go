@@ -5,23 +5,30 @@ package execplugin
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/google/shlex"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/yaml"
 )
 
 const (
 	tmpConfigFilePrefix = "kust-plugin-config-"
+	tmpWorkDirPrefix    = "kust-plugin-workdir-"
 )
 
 // ExecPlugin record the name and args of an executable
@@ -38,6 +45,16 @@ type ExecPlugin struct {
 	// Plugin configuration data.
 	cfg []byte
 
+	// Optional limit on how long one run of the executable
+	// may take; zero means no limit.
+	timeout time.Duration
+
+	// The paths of the files and directories the executable
+	// reads, if declared with the plugin-inputs annotation.
+	inputs []string
+	// hasInputs is true if the inputs are declared.
+	hasInputs bool
+
 	// PluginHelpers
 	h *resmap.PluginHelpers
 }
@@ -76,6 +93,17 @@ func (p *ExecPlugin) Cfg() []byte {
 	return p.cfg
 }
 
+func (p *ExecPlugin) Timeout() time.Duration {
+	return p.timeout
+}
+
+// Inputs returns the paths, relative to the kustomization root,
+// of the files and directories the plugin reads, and false if
+// its config doesn't declare them.
+func (p *ExecPlugin) Inputs() ([]string, bool) {
+	return p.inputs, p.hasInputs
+}
+
 func (p *ExecPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
 	p.h = h
 	p.cfg = config
@@ -93,6 +121,12 @@ func (p *ExecPlugin) processOptionalArgsFields() error {
 	if err != nil {
 		return err
 	}
+	if p.timeout, err = ParseTimeout(p.cfg); err != nil {
+		return err
+	}
+	if p.inputs, p.hasInputs, err = parseInputs(p.cfg); err != nil {
+		return err
+	}
 	if c.ArgsOneLiner != "" {
 		p.args, _ = shlex.Split(c.ArgsOneLiner)
 	}
@@ -111,6 +145,59 @@ func (p *ExecPlugin) processOptionalArgsFields() error {
 	return nil
 }
 
+// annotations returns the annotations of the plugin config.
+func annotations(config []byte) (map[string]string, error) {
+	var c struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+		} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	}
+	if err := yaml.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	return c.Metadata.Annotations, nil
+}
+
+// parseInputs returns the paths of the plugin-inputs annotation
+// of the plugin config, and false if it has none.
+func parseInputs(config []byte) ([]string, bool, error) {
+	a, err := annotations(config)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := a[konfig.PluginInputsAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+	var inputs []string
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			inputs = append(inputs, path)
+		}
+	}
+	return inputs, true, nil
+}
+
+// ParseTimeout returns the duration of the plugin-timeout
+// annotation of the plugin config, or zero if it has none.
+func ParseTimeout(config []byte) (time.Duration, error) {
+	a, err := annotations(config)
+	if err != nil {
+		return 0, err
+	}
+	t, ok := a[konfig.PluginTimeoutAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(t)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf(
+			"invalid %s annotation %q; expected a positive duration such as 30s",
+			konfig.PluginTimeoutAnnotation, t)
+	}
+	return timeout, nil
+}
+
 func (p *ExecPlugin) Generate() (resmap.ResMap, error) {
 	output, err := p.invokePlugin(nil)
 	if err != nil {
@@ -139,7 +226,7 @@ func (p *ExecPlugin) Transform(rm resmap.ResMap) error {
 	// invoke the plugin with resources as the input
 	output, err := p.invokePlugin(resources)
 	if err != nil {
-		return fmt.Errorf("%v %s", err, string(output))
+		return err
 	}
 
 	// update the original ResMap based on the output
@@ -149,6 +236,8 @@ func (p *ExecPlugin) Transform(rm resmap.ResMap) error {
 // invokePlugin writes plugin config to a temp file, then
 // passes the full temp file path as the first arg to a process
 // running the plugin binary.  Process output is returned.
+// If the process fails, structured results it wrote to stderr
+// are returned as the error; see parseResults.
 func (p *ExecPlugin) invokePlugin(input []byte) ([]byte, error) {
 	f, err := ioutil.TempFile("", tmpConfigFilePrefix)
 	if err != nil {
@@ -165,28 +254,180 @@ func (p *ExecPlugin) invokePlugin(input []byte) ([]byte, error) {
 		return nil, errors.Wrap(
 			err, "closing plugin config file "+f.Name())
 	}
-	//nolint:gosec
-	cmd := exec.Command(
-		p.path, append([]string{f.Name()}, p.args...)...)
-	cmd.Env = p.getEnv()
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	cmd, cleanup, err := p.makeCmd(ctx, f.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
 	cmd.Stdin = bytes.NewReader(input)
-	cmd.Stderr = os.Stderr
-	if _, err := os.Stat(p.h.Loader().Root()); err == nil {
-		cmd.Dir = p.h.Loader().Root()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Start(); err == nil {
+		// The process group is killed if ctx expires before the
+		// plugin exits, so that processes it started, holding
+		// its output open, don't keep it waited for.
+		exited := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				KillProcessGroup(cmd)
+			case <-exited:
+			}
+		}()
+		err = cmd.Wait()
+		close(exited)
 	}
-	result, err := cmd.Output()
+	result := stdout.Bytes()
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf(
+				"plugin %s timed out after %s and was killed",
+				p.path, p.timeout)
+		}
+		if results := parseResults(stderr.Bytes()); results != nil {
+			return nil, errors.Wrapf(
+				results, "failure in plugin %s", p.path)
+		}
 		return nil, errors.Wrapf(
-			err, "failure in plugin configured via %s; %v",
-			f.Name(), err.Error())
+			err, "failure in plugin configured via %s; %v\n%s",
+			f.Name(), err.Error(), stderr.String())
 	}
+	// Pass along whatever the plugin logged.
+	_, _ = os.Stderr.Write(stderr.Bytes())
 	return result, os.Remove(f.Name())
 }
 
-func (p *ExecPlugin) getEnv() []string {
-	env := os.Environ()
-	env = append(env,
+// parseResults interprets the stderr of a failed plugin as
+// structured results, i.e. a JSON array of framework.Result, or
+// one JSON framework.Result per line, e.g.
+//
+//	{"message": "replicas must be positive", "severity": "error",
+//	 "resourceRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "app"}}
+//
+// It returns nil if stderr holds anything else, so that it can
+// be reported verbatim.
+func parseResults(stderr []byte) framework.Results {
+	stderr = bytes.TrimSpace(stderr)
+	if len(stderr) == 0 {
+		return nil
+	}
+	var results framework.Results
+	if stderr[0] == '[' {
+		if err := json.Unmarshal(stderr, &results); err != nil {
+			return nil
+		}
+	} else {
+		for _, line := range bytes.Split(stderr, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			var r framework.Result
+			if err := json.Unmarshal(line, &r); err != nil {
+				return nil
+			}
+			results = append(results, &r)
+		}
+	}
+	for _, r := range results {
+		if r == nil || r.Message == "" {
+			return nil
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
+
+// makeCmd returns the command running the plugin, restricted
+// according to the ExecPluginPolicy, and a func to clean up
+// after it.
+func (p *ExecPlugin) makeCmd(
+	ctx context.Context, cfgFile string) (*exec.Cmd, func(), error) {
+	var policy types.ExecPluginPolicy
+	if pc := p.h.GeneralConfig(); pc != nil {
+		policy = pc.ExecPluginPolicy
+	}
+	cmd, cleanup, err := RestrictedCommand(
+		ctx, policy, p.path, append([]string{cfgFile}, p.args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.Env = append(cmd.Env,
 		"KUSTOMIZE_PLUGIN_CONFIG_STRING="+string(p.cfg),
 		"KUSTOMIZE_PLUGIN_CONFIG_ROOT="+p.h.Loader().Root())
+	if !policy.IsolateWorkingDir {
+		if _, err := os.Stat(p.h.Loader().Root()); err == nil {
+			cmd.Dir = p.h.Loader().Root()
+		}
+	}
+	return cmd, cleanup, nil
+}
+
+// RestrictedCommand returns the command running the executable
+// at path with the args, restricted according to the policy, and
+// a func to clean up after it.  Callers may append to the Env of
+// the command, and set its Dir unless the policy isolates it.
+func RestrictedCommand(
+	ctx context.Context, policy types.ExecPluginPolicy,
+	path string, args ...string) (*exec.Cmd, func(), error) {
+	name := path
+	if policy.DisableNetwork {
+		unshare, err := unsharePath()
+		if err != nil {
+			return nil, nil, err
+		}
+		name, args = unshare, append(
+			[]string{"--net", "--map-root-user", "--", path}, args...)
+	}
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.Env = getEnv(policy)
+	cleanup := func() {}
+	if policy.IsolateWorkingDir {
+		dir, err := ioutil.TempDir("", tmpWorkDirPrefix)
+		if err != nil {
+			return nil, nil, errors.Wrap(
+				err, "creating plugin working directory")
+		}
+		cmd.Dir = dir
+		cleanup = func() { _ = os.RemoveAll(dir) }
+	}
+	return cmd, cleanup, nil
+}
+
+func unsharePath() (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf(
+			"disabling network access of exec plugins is not supported on %s",
+			runtime.GOOS)
+	}
+	path, err := exec.LookPath("unshare")
+	if err != nil {
+		return "", errors.Wrap(
+			err, "disabling network access of exec plugins requires unshare")
+	}
+	return path, nil
+}
+
+func getEnv(policy types.ExecPluginPolicy) []string {
+	if !policy.CleanEnv {
+		return os.Environ()
+	}
+	var env []string
+	for _, name := range append([]string{"PATH"}, policy.EnvAllowlist...) {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
 	return env
 }
@@ -4,10 +4,14 @@
 package execplugin_test
 
 import (
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	. "sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
@@ -126,3 +130,191 @@ func TestExecPlugin_ErrIfNotExecutable(t *testing.T) {
 		t.Fatalf("unexpected err: %v", err)
 	}
 }
+
+func makeScriptPlugin(t *testing.T, script, config string) (*ExecPlugin, error) {
+	t.Helper()
+	return makeScriptPluginWithPolicy(t, script, config, types.ExecPluginPolicy{})
+}
+
+func makeScriptPluginWithPolicy(
+	t *testing.T, script, config string,
+	policy types.ExecPluginPolicy) (*ExecPlugin, error) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script plugin")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Plugin")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700))
+	fSys := filesys.MakeFsOnDisk()
+	ldr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, dir, fSys)
+	require.NoError(t, err)
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(pvd.GetResourceFactory())
+	pc := types.DisabledPluginConfig()
+	pc.ExecPluginPolicy = policy
+	p := NewExecPlugin(path)
+	return p, p.Config(
+		resmap.NewPluginHelpers(ldr, pvd.GetFieldValidator(), rf, pc),
+		[]byte(config))
+}
+
+func TestExecPluginTimeout(t *testing.T) {
+	// sleep, not exec'ed, holds the output of the plugin open
+	// after it's killed.
+	p, err := makeScriptPlugin(t, "sleep 10\n", `
+apiVersion: someteam.example.com/v1
+kind: Sleeper
+metadata:
+  name: sleeper
+  annotations:
+    config.kubernetes.io/plugin-timeout: 100ms
+`)
+	require.NoError(t, err)
+	require.Equal(t, 100*time.Millisecond, p.Timeout())
+	start := time.Now()
+	_, err = p.Generate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out after 100ms")
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestExecPluginInvalidTimeout(t *testing.T) {
+	_, err := makeScriptPlugin(t, "", `
+apiVersion: someteam.example.com/v1
+kind: Sleeper
+metadata:
+  name: sleeper
+  annotations:
+    config.kubernetes.io/plugin-timeout: soon
+`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid config.kubernetes.io/plugin-timeout annotation "soon"`)
+}
+
+func TestExecPluginErrors(t *testing.T) {
+	const config = `
+apiVersion: someteam.example.com/v1
+kind: Checker
+metadata:
+  name: checker
+`
+	testCases := map[string]struct {
+		script string
+		errMsg string
+	}{
+		"structuredResult": {
+			script: `echo '{"message": "replicas must be positive", "severity": "error", ` +
+				`"resourceRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "app"}}' >&2
+exit 1
+`,
+			errMsg: "failure in plugin " +
+				"[error] apps/v1/Deployment/app: replicas must be positive",
+		},
+		"structuredResultList": {
+			script: `echo '[{"message": "first"}, {"message": "second", "severity": "warning"}]' >&2
+exit 1
+`,
+			errMsg: "[info]: first\n\n[warning]: second",
+		},
+		"plainStderr": {
+			script: `echo 'something went wrong' >&2
+exit 1
+`,
+			errMsg: "something went wrong",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := makeScriptPlugin(t, tc.script, config)
+			require.NoError(t, err)
+			_, err = p.Generate()
+			require.Error(t, err)
+			require.Contains(t, strings.ReplaceAll(err.Error(), p.Path()+": ", ""), tc.errMsg)
+		})
+	}
+}
+
+func TestExecPluginPolicy(t *testing.T) {
+	const config = `
+apiVersion: someteam.example.com/v1
+kind: Reporter
+metadata:
+  name: reporter
+`
+	// The plugin reports its environment and working directory.
+	const script = `cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: report
+data:
+  allowed: "$ALLOWED_VAR"
+  other: "$OTHER_VAR"
+  root: "$KUSTOMIZE_PLUGIN_CONFIG_ROOT"
+  pwd: "$(pwd)"
+  interfaces: "$(tail -n +3 /proc/net/dev | wc -l)"
+EOF
+`
+	require.NoError(t, os.Setenv("ALLOWED_VAR", "yes"))
+	defer os.Unsetenv("ALLOWED_VAR")
+	require.NoError(t, os.Setenv("OTHER_VAR", "yes"))
+	defer os.Unsetenv("OTHER_VAR")
+
+	report := func(policy types.ExecPluginPolicy) map[string]string {
+		p, err := makeScriptPluginWithPolicy(t, script, config, policy)
+		require.NoError(t, err)
+		rm, err := p.Generate()
+		require.NoError(t, err)
+		return rm.Resources()[0].GetDataMap()
+	}
+
+	data := report(types.ExecPluginPolicy{})
+	require.Equal(t, "yes", data["allowed"])
+	require.Equal(t, "yes", data["other"])
+	require.Equal(t, data["root"], data["pwd"])
+
+	data = report(types.ExecPluginPolicy{
+		CleanEnv:          true,
+		EnvAllowlist:      []string{"ALLOWED_VAR"},
+		IsolateWorkingDir: true,
+	})
+	require.Equal(t, "yes", data["allowed"])
+	require.Equal(t, "", data["other"])
+	require.NotEqual(t, data["root"], data["pwd"])
+	require.Contains(t, data["pwd"], "kust-plugin-workdir-")
+	_, err := os.Stat(data["pwd"])
+	require.True(t, os.IsNotExist(err), "working directory should be removed")
+
+	if err := exec.Command("unshare", "--net", "--map-root-user", "true").Run(); err != nil {
+		t.Skipf("cannot create network namespaces: %v", err)
+	}
+	data = report(types.ExecPluginPolicy{DisableNetwork: true})
+	// Only loopback is left.
+	require.Equal(t, "1", strings.TrimSpace(data["interfaces"]))
+}
+
+func TestExecPluginInputs(t *testing.T) {
+	p, err := makeScriptPlugin(t, "", `
+apiVersion: someteam.example.com/v1
+kind: Reader
+metadata:
+  name: reader
+  annotations:
+    config.kubernetes.io/plugin-inputs: values.yaml, templates
+`)
+	require.NoError(t, err)
+	inputs, ok := p.Inputs()
+	require.True(t, ok)
+	require.Equal(t, []string{"values.yaml", "templates"}, inputs)
+
+	p, err = makeScriptPlugin(t, "", `
+apiVersion: someteam.example.com/v1
+kind: Reader
+metadata:
+  name: reader
+`)
+	require.NoError(t, err)
+	_, ok = p.Inputs()
+	require.False(t, ok)
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+// +build !windows
+
+package execplugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup makes the command start a process group
+// of its own, so that KillProcessGroup kills the processes
+// it starts too.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// KillProcessGroup kills the started command, and the
+// processes it started, which may hold its output open.
+func KillProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
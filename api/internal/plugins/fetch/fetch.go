@@ -0,0 +1,176 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fetch downloads plugin executables declared
+// by URL or OCI reference, verifies them against a
+// checksum, and caches them on local disk.
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	ociScheme = "oci://"
+
+	// DefaultOrasCommand is used to pull plugins from OCI registries.
+	DefaultOrasCommand = "oras"
+)
+
+// Fetcher downloads plugins into a cache directory.
+type Fetcher struct {
+	// CacheDir holds verified plugins, named by checksum.
+	CacheDir string
+	// Client is used for http and https sources.
+	Client *http.Client
+	// OrasCommand is used for oci sources.
+	OrasCommand string
+}
+
+// NewFetcher returns a Fetcher caching into the given directory.
+func NewFetcher(cacheDir string) *Fetcher {
+	return &Fetcher{
+		CacheDir:    cacheDir,
+		Client:      &http.Client{Timeout: 5 * time.Minute},
+		OrasCommand: DefaultOrasCommand,
+	}
+}
+
+// Fetch returns the path to an executable copy of the plugin
+// at source whose sha256 is checksum, downloading it if it
+// isn't already cached. The source is an http or https URL,
+// or an oci:// reference to an artifact holding one file.
+func (f *Fetcher) Fetch(source, checksum string) (string, error) {
+	checksum = strings.ToLower(strings.TrimPrefix(checksum, "sha256:"))
+	if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+		return "", fmt.Errorf(
+			"plugin %s must be declared with a hex encoded sha256 checksum; got %q",
+			source, checksum)
+	}
+	path := filepath.Join(f.CacheDir, "sha256", checksum)
+	if sum, err := fileChecksum(path); err == nil {
+		if sum == checksum {
+			return path, nil
+		}
+		// Corrupted or tampered with; fetch it again.
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", errors.Wrap(err, "creating plugin cache")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "download-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating plugin cache")
+	}
+	defer os.Remove(tmp.Name())
+	err = f.download(source, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching plugin %s", source)
+	}
+	sum, err := fileChecksum(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	if sum != checksum {
+		return "", fmt.Errorf(
+			"plugin %s has sha256 %s, but %s was declared",
+			source, sum, checksum)
+	}
+	if err = os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp.Name(), path)
+}
+
+func (f *Fetcher) download(source string, w io.Writer) error {
+	switch {
+	case strings.HasPrefix(source, "https://"),
+		strings.HasPrefix(source, "http://"):
+		return types.WithErrorClass(
+			f.downloadHTTP(source, w), types.ErrorClassRemote)
+	case strings.HasPrefix(source, ociScheme):
+		return types.WithErrorClass(
+			f.pullOCI(strings.TrimPrefix(source, ociScheme), w), types.ErrorClassRemote)
+	default:
+		return fmt.Errorf(
+			"unsupported plugin source; expected an http(s) URL or %s reference",
+			ociScheme)
+	}
+}
+
+func (f *Fetcher) downloadHTTP(url string, w io.Writer) error {
+	resp, err := f.Client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// pullOCI pulls the artifact with oras, which must hold
+// exactly one file, the plugin.
+func (f *Fetcher) pullOCI(ref string, w io.Writer) error {
+	dir, err := ioutil.TempDir("", "kust-plugin-oci-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	//nolint:gosec
+	cmd := exec.Command(f.OrasCommand, "pull", ref, "--output", dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "running %s: %s", f.OrasCommand, out)
+	}
+	var files []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return fmt.Errorf(
+			"expected the artifact to hold one file, found %d", len(files))
+	}
+	in, err := os.Open(files[0])
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.Copy(w, in)
+	return err
+}
+
+func fileChecksum(path string) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, in); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fetch_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/api/internal/plugins/fetch"
+)
+
+const plugin = "#!/bin/sh\necho hello\n"
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetchHTTP(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path != "/plugin" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(plugin))
+		}))
+	defer srv.Close()
+	f := NewFetcher(t.TempDir())
+
+	path, err := f.Fetch(srv.URL+"/plugin", checksum(plugin))
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plugin, string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&0100, "plugin should be executable")
+	}
+
+	// Served from the cache.
+	again, err := f.Fetch(srv.URL+"/plugin", "sha256:"+checksum(plugin))
+	require.NoError(t, err)
+	assert.Equal(t, path, again)
+	assert.Equal(t, 1, requests)
+
+	// A tampered cache entry is fetched again.
+	require.NoError(t, ioutil.WriteFile(path, []byte("evil"), 0755))
+	_, err = f.Fetch(srv.URL+"/plugin", checksum(plugin))
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plugin, string(content))
+
+	_, err = f.Fetch(srv.URL+"/missing", checksum("other"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 404")
+}
+
+func TestFetchErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(plugin))
+		}))
+	defer srv.Close()
+	dir := t.TempDir()
+	f := NewFetcher(dir)
+	testCases := map[string]struct {
+		source   string
+		checksum string
+		errMsg   string
+	}{
+		"mismatch": {
+			source:   srv.URL,
+			checksum: checksum("something else"),
+			errMsg:   "has sha256 " + checksum(plugin),
+		},
+		"malformedChecksum": {
+			source:   srv.URL,
+			checksum: "abc",
+			errMsg:   "must be declared with a hex encoded sha256 checksum",
+		},
+		"unsupportedSource": {
+			source:   "ftp://example.com/plugin",
+			checksum: checksum(plugin),
+			errMsg:   "unsupported plugin source",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := f.Fetch(tc.source, tc.checksum)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+	// Nothing unverified is left in the cache.
+	files, err := ioutil.ReadDir(filepath.Join(dir, "sha256"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFetchOCI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script in place of oras")
+	}
+	// A fake oras that writes the plugin to the output directory.
+	bin := t.TempDir()
+	oras := filepath.Join(bin, "oras")
+	require.NoError(t, ioutil.WriteFile(oras, []byte(`#!/bin/sh
+[ "$1" = pull ] && [ "$2" = registry.example.com/plugins/hello:v1 ] || exit 1
+printf '#!/bin/sh\necho hello\n' > "$4/hello"
+`), 0700))
+	f := NewFetcher(t.TempDir())
+	f.OrasCommand = oras
+
+	path, err := f.Fetch("oci://registry.example.com/plugins/hello:v1", checksum(plugin))
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plugin, string(content))
+
+	_, err = f.Fetch("oci://registry.example.com/plugins/other:v1", checksum("other"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "running "+oras)
+}
@@ -57,7 +57,46 @@ func GetFunctionSpec(res *resource.Resource) *runtimeutil.FunctionSpec {
 		return nil
 	}
 
-	return runtimeutil.GetFunctionSpec(rnode)
+	if spec := runtimeutil.GetFunctionSpec(rnode); spec != nil {
+		return spec
+	}
+	return getFunctionSpecFromFields(rnode)
+}
+
+// getFunctionSpecFromFields returns the function spec declared by
+// the top level fields of the plugin config, i.e. either a
+// 'container' field holding the image to run, a 'starlark'
+// field holding the path or url of the script to run, or a
+// 'wasm' field holding the path of the module to run.
+// Returns nil if no such field is present.
+func getFunctionSpecFromFields(rnode *yaml.RNode) *runtimeutil.FunctionSpec {
+	var spec runtimeutil.FunctionSpec
+	if unmarshalField(rnode, "container", &spec.Container) &&
+		spec.Container.Image != "" {
+		return &spec
+	}
+	if unmarshalField(rnode, "starlark", &spec.Starlark) &&
+		(spec.Starlark.Path != "" || spec.Starlark.URL != "") {
+		return &spec
+	}
+	if unmarshalField(rnode, "wasm", &spec.Wasm) && spec.Wasm.Path != "" {
+		return &spec
+	}
+	return nil
+}
+
+// unmarshalField unmarshals the named top level field of rnode
+// into v, returning false if the field is missing or malformed.
+func unmarshalField(rnode *yaml.RNode, field string, v interface{}) bool {
+	n, err := rnode.Pipe(yaml.Lookup(field))
+	if err != nil || yaml.IsMissingOrNull(n) {
+		return false
+	}
+	s, err := n.String()
+	if err != nil {
+		return false
+	}
+	return yaml.Unmarshal([]byte(s), v) == nil
 }
 
 func toStorageMounts(mounts []string) []runtimeutil.StorageMount {
@@ -72,14 +111,19 @@ func toStorageMounts(mounts []string) []runtimeutil.StorageMount {
 func NewFnPlugin(o *types.FnPluginLoadingOptions) *FnPlugin {
 	return &FnPlugin{
 		runFns: runfn.RunFns{
-			Functions:      []*yaml.RNode{},
-			Network:        o.Network,
-			EnableStarlark: o.EnableStar,
-			EnableExec:     o.EnableExec,
-			StorageMounts:  toStorageMounts(o.Mounts),
-			Env:            o.Env,
-			AsCurrentUser:  o.AsCurrentUser,
-			WorkingDir:     o.WorkingDir,
+			// Relative starlark and wasm paths are resolved against Path.
+			Path:             o.WorkingDir,
+			Functions:        []*yaml.RNode{},
+			Network:          o.Network,
+			ContainerRuntime: o.ContainerRuntime,
+			EnableStarlark:   o.EnableStar,
+			EnableExec:       o.EnableExec,
+			EnableWasm:       o.EnableWasm,
+			WasmRuntime:      o.WasmRuntime,
+			StorageMounts:    toStorageMounts(o.Mounts),
+			Env:              o.Env,
+			AsCurrentUser:    o.AsCurrentUser,
+			WorkingDir:       o.WorkingDir,
 		},
 	}
 }
@@ -168,6 +212,20 @@ func (p *FnPlugin) invokePlugin(input []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The function runner only looks for the function spec in
+	// annotations, so move a spec declared in the config fields there.
+	if runtimeutil.GetFunctionSpec(functionConfig) == nil {
+		if spec := getFunctionSpecFromFields(functionConfig); spec != nil {
+			s, err := yaml.Marshal(spec)
+			if err != nil {
+				return nil, err
+			}
+			err = injectAnnotation(functionConfig, runtimeutil.FunctionAnnotationKey, string(s))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 	// we need to add config as input for generators. Some of them don't work with FunctionConfig
 	// and in addition kio.Pipeline won't create anything if there are no objects
 	// see https://github.com/kubernetes-sigs/kustomize/blob/master/kyaml/kio/kio.go#L93
@@ -0,0 +1,115 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fnplugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/provider"
+)
+
+func TestGetFunctionSpec(t *testing.T) {
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	testCases := map[string]struct {
+		config   string
+		image    string
+		starPath string
+		wasmPath string
+		isNil    bool
+	}{
+		"annotation": {
+			config: `
+apiVersion: example.com/v1
+kind: MyTransformer
+metadata:
+  name: demo
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/annotated:v1
+`,
+			image: "example.com/annotated:v1",
+		},
+		"containerField": {
+			config: `
+apiVersion: example.com/v1
+kind: MyTransformer
+metadata:
+  name: demo
+container:
+  image: example.com/field:v1
+  network: true
+`,
+			image: "example.com/field:v1",
+		},
+		"annotationWins": {
+			config: `
+apiVersion: example.com/v1
+kind: MyTransformer
+metadata:
+  name: demo
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/annotated:v1
+container:
+  image: example.com/field:v1
+`,
+			image: "example.com/annotated:v1",
+		},
+		"starlarkField": {
+			config: `
+apiVersion: example.com/v1
+kind: MyTransformer
+metadata:
+  name: demo
+starlark:
+  path: transform.star
+`,
+			starPath: "transform.star",
+		},
+		"wasmField": {
+			config: `
+apiVersion: example.com/v1
+kind: MyTransformer
+metadata:
+  name: demo
+wasm:
+  path: transform.wasm
+`,
+			wasmPath: "transform.wasm",
+		},
+		"noImage": {
+			config: `
+apiVersion: example.com/v1
+kind: MyTransformer
+metadata:
+  name: demo
+container:
+  name: not-a-function-spec
+`,
+			isNil: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			res, err := rf.FromBytes([]byte(tc.config))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			spec := GetFunctionSpec(res)
+			if tc.isNil {
+				assert.Nil(t, spec)
+				return
+			}
+			if !assert.NotNil(t, spec) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.image, spec.Container.Image)
+			assert.Equal(t, tc.starPath, spec.Starlark.Path)
+			assert.Equal(t, tc.wasmPath, spec.Wasm.Path)
+		})
+	}
+}
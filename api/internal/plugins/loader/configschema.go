@@ -0,0 +1,179 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
+)
+
+// validateBuiltinConfig errors if the given config of a builtin
+// plugin holds fields unknown to the plugin, e.g. a misspelled
+// 'target', which would otherwise be silently ignored.
+// Value types are left for the plugin to check.
+func validateBuiltinConfig(c resmap.Configurable, config []byte) error {
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(config, &m); err != nil {
+		return err
+	}
+	// The resource fields are validated when the config is read.
+	delete(m, "apiVersion")
+	delete(m, "kind")
+	delete(m, "metadata")
+	var types []reflect.Type
+	if mt, ok := c.(*builtinhelpers.MultiTransformer); ok {
+		for _, t := range mt.Transformers() {
+			types = append(types, reflect.TypeOf(t))
+		}
+	} else {
+		types = append(types, reflect.TypeOf(c))
+	}
+	// A field is known if any of the types knows it.
+	for k, v := range m {
+		var err error
+		for _, t := range types {
+			fields := jsonFields(derefType(t))
+			if len(fields) == 0 {
+				// Plugins without config fields ignore their config.
+				err = nil
+				break
+			}
+			if err = checkFields(t, map[string]interface{}{k: v}, ""); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFields errors on the first field of v, a value
+// decoded from YAML, that's unknown to the type t.
+func checkFields(t reflect.Type, v interface{}, path string) error {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		for k, fv := range m {
+			ft, known := fields[strings.ToLower(k)]
+			if !known {
+				return fmt.Errorf("unknown field %q", path+k)
+			}
+			if err := checkFields(ft, fv, path+k+"."); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for k, fv := range m {
+			if err := checkFields(t.Elem(), fv, path+k+"."); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		s, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, ev := range s {
+			if err := checkFields(
+				t.Elem(), ev, fmt.Sprintf("%s[%d].", strings.TrimSuffix(path, "."), i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields maps the lower cased JSON names of the fields of the
+// struct type t, including promoted ones, to their types.
+// Names are lower cased because encoding/json, which decodes plugin
+// configs, matches them case insensitively.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	result := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && (f.Anonymous || strings.Contains(tag, ",inline")) {
+			if ft := derefType(f.Type); ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					result[k] = v
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		result[strings.ToLower(name)] = f.Type
+	}
+	return result
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// validateConfigAgainstSchema validates the given config of an exec or
+// Go plugin against the schema shipped with the plugin, if there is one.
+func (l *Loader) validateConfigAgainstSchema(id resid.ResId, config []byte) error {
+	absPluginPath, err := l.AbsolutePluginPath(id)
+	if err != nil {
+		return err
+	}
+	schemaPath := filepath.Join(filepath.Dir(absPluginPath), konfig.PluginSchemaFileName)
+	if !l.fs.Exists(schemaPath) {
+		return nil
+	}
+	content, err := l.fs.ReadFile(schemaPath)
+	if err != nil {
+		return err
+	}
+	j, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return errors.Wrapf(err, "reading plugin schema %s", schemaPath)
+	}
+	var schema spec.Schema
+	if err = json.Unmarshal(j, &schema); err != nil {
+		return errors.Wrapf(err, "reading plugin schema %s", schemaPath)
+	}
+	var data interface{}
+	if err = yaml.Unmarshal(config, &data); err != nil {
+		return err
+	}
+	return validate.AgainstSchema(&schema, data, strfmt.Default)
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+func TestValidateBuiltinConfig(t *testing.T) {
+	testCases := map[string]struct {
+		kind   string
+		config string
+		errMsg string
+	}{
+		"valid": {
+			kind: "PatchTransformer",
+			config: `
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+path: patch.yaml
+target:
+  kind: Deployment
+  labelSelector: app=web
+`,
+		},
+		"caseInsensitive": {
+			kind: "PatchTransformer",
+			config: `
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+Path: patch.yaml
+`,
+		},
+		"misspelledTarget": {
+			kind: "PatchTransformer",
+			config: `
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+path: patch.yaml
+targt:
+  kind: Deployment
+`,
+			errMsg: `unknown field "targt"`,
+		},
+		"nestedTypo": {
+			kind: "LabelTransformer",
+			config: `
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: notImportantHere
+labels:
+  app: web
+fieldSpecs:
+- path: metadata/labels
+  create: true
+- pth: spec/selector
+`,
+			errMsg: `unknown field "fieldSpecs[1].pth"`,
+		},
+		"embeddedArgs": {
+			kind: "ConfigMapGenerator",
+			config: `
+apiVersion: builtin
+kind: ConfigMapGenerator
+metadata:
+  name: notImportantHere
+name: cm
+behavior: create
+literals:
+- a=b
+options:
+  disableNameSuffixHash: true
+`,
+		},
+		"multiTransformer": {
+			kind: "PrefixSuffixTransformer",
+			config: `
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+prefix: p-
+suffix: -s
+fieldSpecs:
+- path: metadata/name
+`,
+		},
+		"multiTransformerTypo": {
+			kind: "PrefixSuffixTransformer",
+			config: `
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+prefx: p-
+`,
+			errMsg: `unknown field "prefx"`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			bpt := builtinhelpers.GetBuiltinPluginType(tc.kind)
+			var c resmap.Configurable
+			if f, ok := builtinhelpers.TransformerFactories[bpt]; ok {
+				c = f()
+			} else {
+				c = builtinhelpers.GeneratorFactories[bpt]()
+			}
+			err := validateBuiltinConfig(c, []byte(tc.config))
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateConfigAgainstSchema(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	pluginHome := "/plugins"
+	assert.NoError(t, os.Setenv(konfig.KustomizePluginHomeEnv, pluginHome))
+	defer os.Unsetenv(konfig.KustomizePluginHomeEnv)
+	dir := filepath.Join(pluginHome, "someteam.example.com", "v1", "greeter")
+	assert.NoError(t, fSys.MkdirAll(dir))
+	assert.NoError(t, fSys.WriteFile(
+		filepath.Join(dir, konfig.PluginSchemaFileName), []byte(`
+type: object
+required: [greeting]
+properties:
+  greeting:
+    type: string
+    enum: [hello, hi]
+`)))
+	rf := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory())
+	l := NewLoader(types.EnabledPluginConfig(types.BploUseStaticallyLinked), rf, fSys)
+	id := resid.NewResId(resid.NewGvk("someteam.example.com", "v1", "Greeter"), "g")
+
+	assert.NoError(t, l.validateConfigAgainstSchema(id, []byte(`
+apiVersion: someteam.example.com/v1
+kind: Greeter
+metadata:
+  name: g
+greeting: hello
+`)))
+	err := l.validateConfigAgainstSchema(id, []byte(`
+apiVersion: someteam.example.com/v1
+kind: Greeter
+metadata:
+  name: g
+greetin: hello
+`))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "greeting in body is required")
+	}
+
+	// No schema, nothing to check.
+	other := resid.NewResId(resid.NewGvk("someteam.example.com", "v1", "Other"), "o")
+	assert.NoError(t, l.validateConfigAgainstSchema(other, []byte(`
+apiVersion: someteam.example.com/v1
+kind: Other
+metadata:
+  name: o
+anything: goes
+`)))
+}
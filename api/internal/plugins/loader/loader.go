@@ -5,20 +5,23 @@ package loader
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"plugin"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/internal/plugins/fetch"
 	"sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
+	"sigs.k8s.io/kustomize/api/internal/plugins/rpcplugin"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -35,6 +38,10 @@ type Loader struct {
 	// absolutePluginHome caches the location of a valid plugin root directory.
 	// It should only be set once the directory's existence has been confirmed.
 	absolutePluginHome string
+
+	// warn, if not nil, reports likely mistakes in plugin
+	// configs, which are otherwise logged.
+	warn func(msg string)
 }
 
 func NewLoader(
@@ -47,9 +54,19 @@ func (l *Loader) Config() *types.PluginConfig {
 	return l.pc
 }
 
-// SetWorkDir sets the working directory for this loader's plugins
+// SetWorkDir sets the working directory for this loader's plugins.
+// The PluginConfig is copied first, so that loaders made for other
+// kustomization roots keep their own working directory.
 func (l *Loader) SetWorkDir(wd string) {
-	l.pc.FnpLoadingOptions.WorkingDir = wd
+	pc := *l.pc
+	pc.FnpLoadingOptions.WorkingDir = wd
+	l.pc = &pc
+}
+
+// SetWarn sets the func reporting likely mistakes in the
+// configs of the plugins loaded, e.g. unknown fields.
+func (l *Loader) SetWarn(warn func(msg string)) {
+	l.warn = warn
 }
 
 func (l *Loader) LoadGenerators(
@@ -172,6 +189,8 @@ func (l *Loader) loadAndConfigurePlugin(
 	ldr ifc.Loader,
 	v ifc.Validator,
 	res *resource.Resource) (c resmap.Configurable, err error) {
+	logging.V(4).Info("loading plugin",
+		"root", ldr.Root(), "kind", res.GetKind(), "name", res.GetName())
 	if isBuiltinPlugin(res) {
 		switch l.pc.BpLoadingOptions {
 		case types.BploLoadFromFileSys:
@@ -191,6 +210,7 @@ func (l *Loader) loadAndConfigurePlugin(
 		switch l.pc.PluginRestrictions {
 		case types.PluginRestrictionsNone:
 			c, err = l.loadPlugin(res)
+			err = types.WithErrorClass(err, types.ErrorClassPlugin)
 		case types.PluginRestrictionsBuiltinsOnly:
 			err = types.NewErrOnlyBuiltinPluginsAllowed(res.OrgId().Kind)
 		default:
@@ -206,14 +226,52 @@ func (l *Loader) loadAndConfigurePlugin(
 	if err != nil {
 		return nil, errors.Wrapf(err, "marshalling yaml from res %s", res.OrgId())
 	}
+	err = l.validateConfig(c, res, yaml)
+	if err != nil {
+		return nil, types.WithErrorClass(errors.Wrapf(
+			err, "plugin %s has invalid configuration", res.OrgId()),
+			types.ErrorClassConfig)
+	}
 	err = c.Config(resmap.NewPluginHelpers(ldr, v, l.rf, l.pc), yaml)
 	if err != nil {
-		return nil, errors.Wrapf(
-			err, "plugin %s fails configuration", res.OrgId())
+		return nil, types.WithErrorClass(errors.Wrapf(
+			err, "plugin %s fails configuration", res.OrgId()),
+			types.ErrorClassConfig)
 	}
 	return c, nil
 }
 
+// validateConfig checks the plugin config before it's
+// handed to the plugin's Config method.
+func (l *Loader) validateConfig(
+	c resmap.Configurable, res *resource.Resource, config []byte) error {
+	if isBuiltinPlugin(res) {
+		// Builtins have always ignored unknown fields, so
+		// they're only warned about.
+		if err := validateBuiltinConfig(c, config); err != nil {
+			msg := fmt.Sprintf("%s %s has %v, which is ignored",
+				res.GetKind(), res.GetName(), err)
+			if l.warn != nil {
+				l.warn(msg)
+			} else {
+				logging.Warning(msg)
+			}
+		}
+		return nil
+	}
+	if _, ok := res.GetAnnotations()[konfig.PluginSourceAnnotation]; ok {
+		// Downloaded plugins come without a schema.
+		return nil
+	}
+	switch c.(type) {
+	case *fnplugin.FnPlugin:
+		// Functions validate their own configs.
+		return nil
+	default:
+		return l.validateConfigAgainstSchema(res.OrgId(), config)
+	}
+}
+
 func (l *Loader) makeBuiltinPlugin(r resid.Gvk) (resmap.Configurable, error) {
 	bpt := builtinhelpers.GetBuiltinPluginType(r.Kind)
 	if f, ok := builtinhelpers.GeneratorFactories[bpt]; ok {
@@ -230,9 +288,50 @@ func (l *Loader) loadPlugin(res *resource.Resource) (resmap.Configurable, error)
 	if spec != nil {
 		return fnplugin.NewFnPlugin(&l.pc.FnpLoadingOptions), nil
 	}
+	if _, ok := res.GetAnnotations()[konfig.PluginSourceAnnotation]; ok {
+		return l.loadRemoteExecPlugin(res)
+	}
+	if res.GetAnnotations()[konfig.PluginProtocolAnnotation] == konfig.PluginProtocolRPC {
+		return l.loadRpcPlugin(res.OrgId())
+	}
 	return l.loadExecOrGoPlugin(res.OrgId())
 }
 
+// loadRemoteExecPlugin downloads, or finds in the cache,
+// the exec plugin declared by the config's annotations.
+func (l *Loader) loadRemoteExecPlugin(
+	res *resource.Resource) (resmap.Configurable, error) {
+	annotations := res.GetAnnotations()
+	source := annotations[konfig.PluginSourceAnnotation]
+	checksum, ok := annotations[konfig.PluginChecksumAnnotation]
+	if !ok {
+		return nil, fmt.Errorf(
+			"plugin %s declares %s without %s",
+			res.OrgId(), konfig.PluginSourceAnnotation,
+			konfig.PluginChecksumAnnotation)
+	}
+	cacheDir, err := konfig.DefaultPluginCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	path, err := fetch.NewFetcher(cacheDir).Fetch(source, checksum)
+	if err != nil {
+		return nil, err
+	}
+	return execplugin.NewExecPlugin(path), nil
+}
+
+func (l *Loader) loadRpcPlugin(resId resid.ResId) (resmap.Configurable, error) {
+	absPluginPath, err := l.AbsolutePluginPath(resId)
+	if err != nil {
+		return nil, err
+	}
+	if err = execplugin.NewExecPlugin(absPluginPath).ErrIfNotExecutable(); err != nil {
+		return nil, err
+	}
+	return rpcplugin.NewRpcPlugin(absPluginPath), nil
+}
+
 func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, error) {
 	absPluginPath, err := l.AbsolutePluginPath(resId)
 	if err != nil {
@@ -267,10 +366,16 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 // but the loaded .so files are in shared memory, so one will get
 // "this plugin already loaded" errors if the registry is maintained
 // as a Loader instance variable.  So make it a package variable.
-var registry = make(map[string]resmap.Configurable)
+// registryMu guards it, as bases may be built concurrently.
+var (
+	registry   = make(map[string]resmap.Configurable)
+	registryMu sync.Mutex
+)
 
 func (l *Loader) loadGoPlugin(id resid.ResId, absPath string) (resmap.Configurable, error) {
 	regId := relativePluginPath(id)
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	if c, ok := registry[regId]; ok {
 		return copyPlugin(c), nil
 	}
@@ -278,9 +383,16 @@ func (l *Loader) loadGoPlugin(id resid.ResId, absPath string) (resmap.Configurab
 		return nil, fmt.Errorf(
 			"expected file with Go object code at: %s", absPath)
 	}
-	log.Printf("Attempting plugin load from '%s'", absPath)
+	logging.V(4).Info("opening Go plugin", "path", absPath)
 	p, err := plugin.Open(absPath)
 	if err != nil {
+		if isGoPluginVersionMismatch(err) {
+			return nil, errors.Wrapf(err,
+				"plugin %s was built against different versions of Go "+
+					"packages than this kustomize; rebuild it with "+
+					"'kustomize plugin build %s/%s/%s'",
+				absPath, id.Group, id.Version, id.Kind)
+		}
 		return nil, errors.Wrapf(err, "plugin %s fails to load", absPath)
 	}
 	symbol, err := p.Lookup(konfig.PluginSymbol)
@@ -297,6 +409,14 @@ func (l *Loader) loadGoPlugin(id resid.ResId, absPath string) (resmap.Configurab
 	return copyPlugin(c), nil
 }
 
+// isGoPluginVersionMismatch reports whether plugin.Open failed
+// because the plugin and the running program were built from
+// different versions of some package, e.g. kustomize/api, or
+// with different Go toolchains.
+func isGoPluginVersionMismatch(err error) bool {
+	return strings.Contains(err.Error(), "different version of package")
+}
+
 func copyPlugin(c resmap.Configurable) resmap.Configurable {
 	indirect := reflect.Indirect(reflect.ValueOf(c))
 	newIndirect := reflect.New(indirect.Type())
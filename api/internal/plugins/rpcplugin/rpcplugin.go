@@ -0,0 +1,355 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rpcplugin runs exec plugins as long lived servers.
+//
+// A plain exec plugin is started once per generate or transform
+// call, so plugins with an expensive start up (loading schemas,
+// warming registry caches, etc.) pay for it again and again.
+// A plugin config annotated with
+//
+//	config.kubernetes.io/plugin-protocol: rpc
+//
+// instead has its executable started once per build, however many
+// of its configs the build has, or once per watch session, if the
+// watch holds the servers across its builds (see Acquire).
+// The plugin is started with the environment variables
+//
+//	KUSTOMIZE_PLUGIN_PROTOCOL=1
+//	KUSTOMIZE_PLUGIN_SOCKET=/path/to/unix/socket
+//
+// and must listen on the given unix socket, serving Go net/rpc
+// requests encoded with the JSON-RPC codec (net/rpc/jsonrpc) for
+// the methods "Plugin.Generate" and "Plugin.Transform" (see
+// GenerateArgs, TransformArgs and Reply).  The plugin must exit
+// when its stdin is closed, which happens when the build or watch
+// session finishes, or when a call takes longer than the
+// plugin-timeout annotation of its config allows.  The plugin is
+// started under the same ExecPluginPolicy as other exec plugins.
+package rpcplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	// ProtocolVersion is the version of the protocol spoken
+	// between kustomize and the plugin.
+	ProtocolVersion = "1"
+
+	// ProtocolEnv names the environment variable holding
+	// the ProtocolVersion.
+	ProtocolEnv = "KUSTOMIZE_PLUGIN_PROTOCOL"
+
+	// SocketEnv names the environment variable holding the
+	// path of the unix socket the plugin must listen on.
+	SocketEnv = "KUSTOMIZE_PLUGIN_SOCKET"
+
+	// How long to wait for a started plugin to listen.
+	startTimeout = 10 * time.Second
+)
+
+// GenerateArgs are the arguments of Plugin.Generate.
+type GenerateArgs struct {
+	// Config is the plugin config YAML.
+	Config string
+	// Root is the kustomization root directory.
+	Root string
+}
+
+// TransformArgs are the arguments of Plugin.Transform.
+type TransformArgs struct {
+	// Config is the plugin config YAML.
+	Config string
+	// Root is the kustomization root directory.
+	Root string
+	// Resources is the multi-document YAML to transform.
+	Resources string
+}
+
+// Reply is the reply of both Plugin.Generate and Plugin.Transform.
+type Reply struct {
+	// Resources is the resulting multi-document YAML.
+	Resources string
+}
+
+// RpcPlugin is a generator and transformer whose
+// work is done by a long lived plugin server.
+type RpcPlugin struct {
+	// absolute path of the executable
+	path string
+
+	// Plugin configuration data.
+	cfg []byte
+
+	// Optional limit on how long one call of the
+	// server may take; zero means no limit.
+	timeout time.Duration
+
+	// PluginHelpers
+	h *resmap.PluginHelpers
+}
+
+func NewRpcPlugin(p string) *RpcPlugin {
+	return &RpcPlugin{path: p}
+}
+
+func (p *RpcPlugin) Path() string {
+	return p.path
+}
+
+func (p *RpcPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
+	p.h = h
+	p.cfg = config
+	var err error
+	p.timeout, err = execplugin.ParseTimeout(config)
+	return err
+}
+
+// policy returns the ExecPluginPolicy the server is started under.
+func (p *RpcPlugin) policy() types.ExecPluginPolicy {
+	if pc := p.h.GeneralConfig(); pc != nil {
+		return pc.ExecPluginPolicy
+	}
+	return types.ExecPluginPolicy{}
+}
+
+func (p *RpcPlugin) Generate() (resmap.ResMap, error) {
+	var reply Reply
+	err := call(p.path, p.policy(), p.timeout, "Plugin.Generate", &GenerateArgs{
+		Config: string(p.cfg),
+		Root:   p.h.Loader().Root(),
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	rm, err := p.h.ResmapFactory().NewResMapFromBytes([]byte(reply.Resources))
+	if err != nil {
+		return nil, err
+	}
+	return utils.UpdateResourceOptions(rm)
+}
+
+func (p *RpcPlugin) Transform(rm resmap.ResMap) error {
+	// add ResIds as annotations to all objects so that we can add them back
+	inputRM, err := utils.GetResMapWithIDAnnotation(rm)
+	if err != nil {
+		return err
+	}
+	resources, err := inputRM.AsYaml()
+	if err != nil {
+		return err
+	}
+	var reply Reply
+	err = call(p.path, p.policy(), p.timeout, "Plugin.Transform", &TransformArgs{
+		Config:    string(p.cfg),
+		Root:      p.h.Loader().Root(),
+		Resources: string(resources),
+	}, &reply)
+	if err != nil {
+		return err
+	}
+	return utils.UpdateResMapValues(p.path, p.h, []byte(reply.Resources), rm)
+}
+
+// server is a running plugin process and a client connected to it.
+type server struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	exited  chan struct{}
+	client  *rpc.Client
+	dir     string
+	cleanup func()
+}
+
+func (s *server) stop() {
+	s.client.Close()
+	// Closing stdin asks the plugin to exit.
+	s.stdin.Close()
+	select {
+	case <-s.exited:
+	case <-time.After(time.Second):
+		execplugin.KillProcessGroup(s.cmd)
+		<-s.exited
+	}
+	os.RemoveAll(s.dir)
+	s.cleanup()
+}
+
+// servers holds the running plugin servers, keyed by executable
+// path and policy, so that plugins stay warm across all the calls
+// of the builds in progress, which builds counts.
+var (
+	mu      sync.Mutex
+	servers = make(map[string]*server)
+	builds  int
+)
+
+// call calls the method of the plugin server, started if need
+// be, and kills the server if the call takes longer than timeout,
+// unless it's zero.
+func call(
+	path string, policy types.ExecPluginPolicy, timeout time.Duration,
+	method string, args interface{}, reply *Reply) error {
+	key := fmt.Sprintf("%s %+v", path, policy)
+	mu.Lock()
+	s, ok := servers[key]
+	if !ok {
+		var err error
+		s, err = start(path, policy)
+		if err != nil {
+			mu.Unlock()
+			return errors.Wrapf(err, "starting plugin server %s", path)
+		}
+		servers[key] = s
+	}
+	mu.Unlock()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var err error
+	select {
+	case c := <-s.client.Go(method, args, reply, nil).Done:
+		err = c.Error
+	case <-expired:
+		drop(key, s)
+		return fmt.Errorf(
+			"plugin %s timed out after %s and was killed", path, timeout)
+	}
+	if err != nil {
+		if _, isServerErr := err.(rpc.ServerError); !isServerErr {
+			// The connection is broken; start afresh next time.
+			drop(key, s)
+		}
+		return errors.Wrapf(err, "failure in plugin server %s", path)
+	}
+	return nil
+}
+
+// drop stops the server, so that the next call
+// of its plugin starts another.
+func drop(key string, s *server) {
+	mu.Lock()
+	if servers[key] == s {
+		delete(servers, key)
+	}
+	mu.Unlock()
+	s.stop()
+}
+
+func start(path string, policy types.ExecPluginPolicy) (*server, error) {
+	dir, err := ioutil.TempDir("", "kust-plugin-")
+	if err != nil {
+		return nil, err
+	}
+	socket := filepath.Join(dir, "plugin.sock")
+	// The server outlives the call starting it.
+	cmd, cleanup, err := execplugin.RestrictedCommand(
+		context.Background(), policy, path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	cmd.Env = append(cmd.Env,
+		ProtocolEnv+"="+ProtocolVersion,
+		SocketEnv+"="+socket)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	fail := func(err error) (*server, error) {
+		os.RemoveAll(dir)
+		cleanup()
+		return nil, err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fail(err)
+	}
+	if err = cmd.Start(); err != nil {
+		return fail(err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	deadline := time.Now().Add(startTimeout)
+	for {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			return &server{
+				cmd:     cmd,
+				stdin:   stdin,
+				exited:  exited,
+				client:  jsonrpc.NewClient(conn),
+				dir:     dir,
+				cleanup: cleanup,
+			}, nil
+		}
+		select {
+		case <-exited:
+			return fail(fmt.Errorf("plugin exited before listening on %s", socket))
+		case <-time.After(50 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			execplugin.KillProcessGroup(cmd)
+			<-exited
+			return fail(fmt.Errorf(
+				"plugin didn't listen on %s within %v", socket, startTimeout))
+		}
+	}
+}
+
+// Acquire notes that a build, which may call plugin servers, is
+// in progress, and returns the func to call when it finishes.
+// Once no build is in progress, the servers are stopped and their
+// sockets removed.
+func Acquire() (release func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	builds++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			builds--
+			if builds == 0 {
+				shutdown()
+			}
+		})
+	}
+}
+
+// Shutdown stops all running plugin servers.
+func Shutdown() {
+	mu.Lock()
+	defer mu.Unlock()
+	shutdown()
+}
+
+func shutdown() {
+	for path, s := range servers {
+		s.stop()
+		delete(servers, path)
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rpcplugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// testPlugin is served by the test binary when it's
+// started as a plugin, see TestHelperPluginServer.
+type testPlugin struct {
+	calls int
+}
+
+func (p *testPlugin) Generate(args *GenerateArgs, reply *Reply) error {
+	p.calls++
+	if strings.Contains(args.Config, "report") {
+		// Report the environment and working directory.
+		pwd, _ := os.Getwd()
+		dev, _ := ioutil.ReadFile("/proc/net/dev")
+		reply.Resources = fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: report
+data:
+  allowed: "%s"
+  other: "%s"
+  pwd: "%s"
+  interfaces: "%d"
+`, os.Getenv("ALLOWED_VAR"), os.Getenv("OTHER_VAR"), pwd,
+			strings.Count(strings.TrimSpace(string(dev)), "\n")-1)
+		return nil
+	}
+	reply.Resources = fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: generated
+data:
+  calls: "%d"
+`, p.calls)
+	return nil
+}
+
+func (p *testPlugin) Transform(args *TransformArgs, reply *Reply) error {
+	p.calls++
+	if strings.Contains(args.Config, "fail") {
+		return fmt.Errorf("asked to fail")
+	}
+	if strings.Contains(args.Config, "hang") {
+		select {}
+	}
+	reply.Resources = strings.ReplaceAll(
+		args.Resources, "name: cm", "name: cm-transformed")
+	return nil
+}
+
+func TestHelperPluginServer(t *testing.T) {
+	if os.Getenv(ProtocolEnv) == "" {
+		return
+	}
+	l, err := net.Listen("unix", os.Getenv(SocketEnv))
+	if err != nil {
+		os.Exit(1)
+	}
+	s := rpc.NewServer()
+	if err = s.RegisterName("Plugin", &testPlugin{}); err != nil {
+		os.Exit(1)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	// Exit when kustomize closes stdin.
+	_, _ = ioutil.ReadAll(os.Stdin)
+	os.Exit(0)
+}
+
+func makePlugin(t *testing.T, config string) *RpcPlugin {
+	t.Helper()
+	return makePluginWithPolicy(t, config, types.ExecPluginPolicy{})
+}
+
+func makePluginWithPolicy(
+	t *testing.T, config string, policy types.ExecPluginPolicy) *RpcPlugin {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin wrapper is a shell script")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin")
+	require.NoError(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(
+		"#!/bin/sh\nexec %s -test.run=TestHelperPluginServer\n", os.Args[0])), 0700))
+
+	fSys := filesys.MakeFsInMemory()
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, fSys)
+	require.NoError(t, err)
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(pvd.GetResourceFactory())
+	pc := types.DisabledPluginConfig()
+	pc.ExecPluginPolicy = policy
+	p := NewRpcPlugin(path)
+	require.NoError(t, p.Config(
+		resmap.NewPluginHelpers(ldr, pvd.GetFieldValidator(), rf, pc),
+		[]byte(config)))
+	return p
+}
+
+func TestRpcPluginStaysWarm(t *testing.T) {
+	defer Shutdown()
+	p := makePlugin(t, "kind: Generator\n")
+	for i := 1; i <= 3; i++ {
+		rm, err := p.Generate()
+		require.NoError(t, err)
+		yml, err := rm.AsYaml()
+		require.NoError(t, err)
+		// The same server answers every call.
+		assert.Contains(t, string(yml), fmt.Sprintf(`calls: "%d"`, i))
+	}
+}
+
+func TestRpcPluginTransform(t *testing.T) {
+	defer Shutdown()
+	p := makePlugin(t, "kind: Transformer\n")
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	rm, err := resmap.NewFactory(rf).NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	require.NoError(t, err)
+	require.NoError(t, p.Transform(rm))
+	assert.Equal(t, "cm-transformed", rm.Resources()[0].GetName())
+}
+
+func TestRpcPluginError(t *testing.T) {
+	defer Shutdown()
+	p := makePlugin(t, "kind: Transformer\nmode: fail\n")
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	rm, err := resmap.NewFactory(rf).NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	require.NoError(t, err)
+	err = p.Transform(rm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "asked to fail")
+	}
+}
+
+func TestRpcPluginTimeout(t *testing.T) {
+	defer Shutdown()
+	p := makePlugin(t, `kind: Transformer
+metadata:
+  annotations:
+    config.kubernetes.io/plugin-timeout: 200ms
+mode: hang
+`)
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	rm, err := resmap.NewFactory(rf).NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	require.NoError(t, err)
+	err = p.Transform(rm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out after 200ms and was killed")
+	}
+	// The stuck server is gone.
+	assert.Empty(t, servers)
+
+	err = NewRpcPlugin(p.path).Config(p.h, []byte(`metadata:
+  annotations:
+    config.kubernetes.io/plugin-timeout: soon
+`))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid config.kubernetes.io/plugin-timeout annotation "soon"`)
+	}
+}
+
+func TestRpcPluginPolicy(t *testing.T) {
+	defer Shutdown()
+	require.NoError(t, os.Setenv("ALLOWED_VAR", "yes"))
+	defer os.Unsetenv("ALLOWED_VAR")
+	require.NoError(t, os.Setenv("OTHER_VAR", "yes"))
+	defer os.Unsetenv("OTHER_VAR")
+
+	report := func(policy types.ExecPluginPolicy) map[string]string {
+		p := makePluginWithPolicy(t, "kind: Generator\nmode: report\n", policy)
+		rm, err := p.Generate()
+		require.NoError(t, err)
+		return rm.Resources()[0].GetDataMap()
+	}
+
+	data := report(types.ExecPluginPolicy{})
+	assert.Equal(t, "yes", data["allowed"])
+	assert.Equal(t, "yes", data["other"])
+
+	data = report(types.ExecPluginPolicy{
+		CleanEnv:          true,
+		EnvAllowlist:      []string{"ALLOWED_VAR"},
+		IsolateWorkingDir: true,
+	})
+	assert.Equal(t, "yes", data["allowed"])
+	assert.Equal(t, "", data["other"])
+	assert.Contains(t, data["pwd"], "kust-plugin-workdir-")
+	Shutdown()
+	assert.NoDirExists(t, data["pwd"])
+
+	if err := exec.Command("unshare", "--net", "--map-root-user", "true").Run(); err != nil {
+		t.Skipf("cannot create network namespaces: %v", err)
+	}
+	data = report(types.ExecPluginPolicy{DisableNetwork: true})
+	// Only loopback is left.
+	assert.Equal(t, "1", data["interfaces"])
+}
+
+func TestRpcPluginNotListening(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin is a shell script")
+	}
+	defer Shutdown()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0700))
+	var reply Reply
+	err := call(path, types.ExecPluginPolicy{}, 0, "Plugin.Generate", &GenerateArgs{}, &reply)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "plugin exited before listening")
+	}
+}
+
+func TestAcquireStopsServers(t *testing.T) {
+	release := Acquire()
+	inner := Acquire()
+	p := makePlugin(t, "kind: Generator\n")
+	_, err := p.Generate()
+	require.NoError(t, err)
+	var s *server
+	mu.Lock()
+	for _, running := range servers {
+		s = running
+	}
+	mu.Unlock()
+	require.NotNil(t, s)
+
+	inner()
+	inner()
+	// Another build is still in progress.
+	assert.DirExists(t, s.dir)
+	release()
+	assert.NoDirExists(t, s.dir)
+	select {
+	case <-s.exited:
+	default:
+		t.Fatal("plugin server is still running")
+	}
+	assert.Empty(t, servers)
+}
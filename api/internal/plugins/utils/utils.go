@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -26,8 +27,18 @@ const (
 	BehaviorAnnotation = "kustomize.config.k8s.io/behavior"
 )
 
+// GoBin returns the go command of the toolchain that built
+// this program, falling back to the go command on PATH if that
+// toolchain is gone, e.g. when running a released binary.
 func GoBin() string {
-	return filepath.Join(runtime.GOROOT(), "bin", "go")
+	goBin := filepath.Join(runtime.GOROOT(), "bin", "go")
+	if FileExists(goBin) {
+		return goBin
+	}
+	if p, err := exec.LookPath("go"); err == nil {
+		return p
+	}
+	return goBin
 }
 
 // DeterminePluginSrcRoot guesses where the user
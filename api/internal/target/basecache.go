@@ -0,0 +1,108 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/logging"
+)
+
+// BaseCache holds the bases built by the targets sharing it,
+// so that a base included several times, e.g. by the overlays
+// of a diamond, or by several kustomizations built in turn,
+// is read and built once, and copied thereafter.  Entries are
+// keyed by the root, or repository URL, of the base, and by
+// what its build takes from the including kustomization, i.e.
+// the origin and build metadata.  Targets sharing a cache must
+// otherwise be built with the same options, from files that
+// don't change.  With a file, see UseFile, bases are also kept
+// across builds whose files do change.
+type BaseCache struct {
+	mu      sync.Mutex
+	entries map[string]*baseCacheEntry
+	// file, if not nil, keeps the bases built in a file.
+	file *baseCacheFile
+}
+
+// baseCacheEntry is a base built, or being built; done is
+// closed once it's built.  record, if not nil, is what its
+// build read.
+type baseCacheEntry struct {
+	done   chan struct{}
+	ra     *accumulator.ResAccumulator
+	record *buildRecord
+	err    error
+}
+
+// NewBaseCache returns an empty BaseCache.
+func NewBaseCache() *BaseCache {
+	return &BaseCache{entries: make(map[string]*baseCacheEntry)}
+}
+
+// SetBaseCache makes the target, and its bases, take the bases
+// they include from the cache, building those it lacks.
+func (kt *KustTarget) SetBaseCache(c *BaseCache) {
+	kt.baseCache = c
+}
+
+// baseCacheKey returns the key of the base at the loader's
+// root, included by the target.
+func (kt *KustTarget) baseCacheKey(ldr ifc.Loader) (string, error) {
+	root := ldr.Root()
+	// Each clone of a repository has its own root.
+	if r, ok := ldr.(interface{ Repo() string }); ok && r.Repo() != "" {
+		root = r.Repo()
+	}
+	var origin string
+	if kt.origin != nil {
+		var err error
+		if origin, err = kt.origin.String(); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join([]string{
+		root, origin, strings.Join(kt.kustomization.BuildMetadata, ","),
+	}, "\x00"), nil
+}
+
+// get returns a copy of the base with the given key, and the
+// record of its build, calling build if it's not in the cache.
+// Callers asking for a base being built wait for it.  Failed
+// builds aren't kept.
+func (c *BaseCache) get(key string,
+	build func() (*accumulator.ResAccumulator, *buildRecord, error)) (
+	*accumulator.ResAccumulator, *buildRecord, error) {
+	c.mu.Lock()
+	e, found := c.entries[key]
+	if !found {
+		e = &baseCacheEntry{done: make(chan struct{})}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+	if found {
+		<-e.done
+		if e.err != nil {
+			return nil, nil, e.err
+		}
+		logging.V(2).Info("reusing base", "base", key[:strings.IndexByte(key, 0)])
+		return e.ra.DeepCopy(), e.record, nil
+	}
+	ra, record, err := build()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		e.err = err
+		close(e.done)
+		return nil, nil, err
+	}
+	e.ra = ra.DeepCopy()
+	e.record = record
+	close(e.done)
+	return ra, record, nil
+}
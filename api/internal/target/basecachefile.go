@@ -0,0 +1,306 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/logging"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// baseCacheFileVersion is the version of the format of base
+// cache files; files of other versions are ignored.
+const baseCacheFileVersion = 1
+
+// baseCacheFile keeps the bases of a BaseCache in a file,
+// with what their build read, so that later builds, e.g. by
+// other processes, reuse those whose inputs are unchanged.
+type baseCacheFile struct {
+	mu   sync.Mutex
+	path string
+	fSys filesys.FileSystem
+	// options identifies the options the bases are built with.
+	options string
+	bases   map[string]*savedBase
+	// checked holds the inputs found unchanged by the build.
+	checked map[string]bool
+	// changed is true if bases were added or dropped since
+	// the file was read.
+	changed bool
+}
+
+// baseCacheFileContent is the content of a base cache file.
+type baseCacheFileContent struct {
+	Version int                   `json:"version"`
+	Options string                `json:"options"`
+	Bases   map[string]*savedBase `json:"bases"`
+}
+
+// savedBase is a base kept in a base cache file.
+type savedBase struct {
+	// Inputs are those of the buildRecord of the base.
+	Inputs map[string]string `json:"inputs"`
+	// Resources are the YAML of the resources of the base.
+	Resources []string `json:"resources"`
+	// RefVarNames maps the index of resources to the names of
+	// the vars referring to them.
+	RefVarNames map[int][]string                 `json:"refVarNames,omitempty"`
+	Config      *builtinconfig.TransformerConfig `json:"config,omitempty"`
+	Vars        []types.Var                      `json:"vars,omitempty"`
+	Warnings    []types.Warning                  `json:"warnings,omitempty"`
+}
+
+// UseFile makes the cache keep the bases it holds in the file
+// at path, and take the bases its file holds, if they were
+// built with the same options, and their inputs are unchanged.
+// options identifies the options of the builds using the cache.
+// Called again with the same path and options, e.g. before
+// another build, it only forgets which inputs were checked.
+// The file is written by SaveFile.
+func (c *BaseCache) UseFile(fSys filesys.FileSystem, path, options string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f := c.file; f != nil && f.path == path && f.options == options {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.checked = make(map[string]bool)
+		return nil
+	}
+	f := &baseCacheFile{
+		path:    path,
+		fSys:    fSys,
+		options: options,
+		bases:   make(map[string]*savedBase),
+		checked: make(map[string]bool),
+	}
+	c.file = f
+	if !fSys.Exists(path) {
+		return nil
+	}
+	data, err := fSys.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "reading base cache file")
+	}
+	var content baseCacheFileContent
+	switch err = json.Unmarshal(data, &content); {
+	case err != nil:
+		logging.V(2).Info("ignoring base cache file", "file", path, "error", err)
+	case content.Version != baseCacheFileVersion || content.Options != options:
+		logging.V(2).Info("ignoring base cache file of other options", "file", path)
+	default:
+		if content.Bases != nil {
+			f.bases = content.Bases
+		}
+		return nil
+	}
+	// The file is replaced.
+	f.changed = true
+	return nil
+}
+
+// SaveFile writes the bases of the cache to its file, if it has
+// one, and they changed since it was read.
+func (c *BaseCache) SaveFile() error {
+	c.mu.Lock()
+	f := c.file
+	c.mu.Unlock()
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.changed {
+		return nil
+	}
+	data, err := json.Marshal(&baseCacheFileContent{
+		Version: baseCacheFileVersion,
+		Options: f.options,
+		Bases:   f.bases,
+	})
+	if err != nil {
+		return errors.Wrap(err, "writing base cache file")
+	}
+	if err = f.fSys.WriteFile(f.path, data); err != nil {
+		return errors.Wrap(err, "writing base cache file")
+	}
+	f.changed = false
+	return nil
+}
+
+// baseCacheFile returns the file of the target's base cache,
+// or nil if it has none, or the target can't use it, as its
+// build has overrides, whose use isn't recorded.
+func (kt *KustTarget) baseCacheFile() *baseCacheFile {
+	if kt.baseCache == nil || kt.overrides != nil {
+		return nil
+	}
+	kt.baseCache.mu.Lock()
+	defer kt.baseCache.mu.Unlock()
+	return kt.baseCache.file
+}
+
+// load returns the base with the given key, and its record,
+// if the file holds it and its inputs are unchanged, or else
+// nil.
+func (f *baseCacheFile) load(key string,
+	rf *resmap.Factory) (*accumulator.ResAccumulator, *buildRecord) {
+	if f == nil {
+		return nil, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.bases[key]
+	if !ok {
+		return nil, nil
+	}
+	if !f.unchanged(b.Inputs) {
+		delete(f.bases, key)
+		f.changed = true
+		return nil, nil
+	}
+	ra, err := b.accumulator(rf)
+	if err != nil {
+		logging.V(2).Info("ignoring saved base", "file", f.path, "error", err)
+		delete(f.bases, key)
+		f.changed = true
+		return nil, nil
+	}
+	record := newBuildRecord()
+	for k, v := range b.Inputs {
+		record.inputs[k] = v
+	}
+	record.warnings = b.Warnings
+	return ra, record
+}
+
+// save keeps the base with the given key, unless its build
+// is volatile.
+func (f *baseCacheFile) save(key string,
+	ra *accumulator.ResAccumulator, record *buildRecord) {
+	if f == nil || record == nil {
+		return
+	}
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	if record.volatile {
+		return
+	}
+	// Merging into an empty config copies it.
+	config, err := (&builtinconfig.TransformerConfig{}).Merge(ra.GetTransformerConfig())
+	if err != nil {
+		return
+	}
+	b := &savedBase{
+		Inputs:   record.inputs,
+		Config:   config,
+		Vars:     ra.Vars(),
+		Warnings: record.warnings,
+	}
+	for i, r := range ra.ResMap().Resources() {
+		s, err := r.RNode.String()
+		if err != nil {
+			return
+		}
+		b.Resources = append(b.Resources, s)
+		if names := r.GetRefVarNames(); len(names) > 0 {
+			if b.RefVarNames == nil {
+				b.RefVarNames = make(map[int][]string)
+			}
+			b.RefVarNames[i] = names
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bases[key] = b
+	f.changed = true
+}
+
+// unchanged returns true if the inputs read by a build are
+// the same now.
+func (f *baseCacheFile) unchanged(inputs map[string]string) bool {
+	for k, v := range inputs {
+		if f.checked[k] {
+			continue
+		}
+		if f.read(k) != v {
+			return false
+		}
+		f.checked[k] = true
+	}
+	return true
+}
+
+// read returns the value of the input with the given key,
+// as a recordingLoader would record it now.
+func (f *baseCacheFile) read(key string) string {
+	kind, path := key, ""
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		kind, path = key[:i], key[i+1:]
+	}
+	switch kind {
+	case inputFile:
+		content, err := f.fSys.ReadFile(path)
+		if err != nil {
+			return ""
+		}
+		return hashBytes(content)
+	case inputIsDir:
+		return strconv.FormatBool(f.fSys.IsDir(path))
+	case inputDir:
+		names, err := f.fSys.ReadDir(path)
+		if err != nil {
+			return ""
+		}
+		sort.Strings(names)
+		return hashNames(names)
+	case inputRoot:
+		dir, file, err := f.fSys.CleanedAbs(path)
+		if err != nil || file != "" {
+			return ""
+		}
+		return dir.String()
+	}
+	// An input of an unknown kind is never unchanged.
+	return "\x00"
+}
+
+// accumulator returns an accumulator holding the base.
+func (b *savedBase) accumulator(rf *resmap.Factory) (*accumulator.ResAccumulator, error) {
+	nodes := make([]*yaml.RNode, len(b.Resources))
+	for i, s := range b.Resources {
+		n, err := yaml.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = n
+	}
+	resources, err := rf.RF().ResourcesFromRNodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) != len(nodes) {
+		return nil, errors.New("resources were dropped")
+	}
+	m := resmap.New()
+	for i, r := range resources {
+		for _, name := range b.RefVarNames[i] {
+			r.AppendRefVarName(types.Var{Name: name})
+		}
+		if err = m.Append(r); err != nil {
+			return nil, err
+		}
+	}
+	return accumulator.MakeAccumulator(m, b.Config, b.Vars)
+}
@@ -0,0 +1,235 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/builtins"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Kinds of inputs recorded, prefixing the path in the keys
+// of buildRecord.inputs.
+const (
+	// inputFile is a file read; the value is the hash of its
+	// content, or empty if it couldn't be read.
+	inputFile = "file"
+	// inputIsDir is a path checked to be a directory; the
+	// value is "true" or "false".
+	inputIsDir = "isdir"
+	// inputDir is a directory listed; the value is the hash
+	// of the sorted names of its entries, or empty if it
+	// couldn't be listed.
+	inputDir = "dir"
+	// inputRoot is the directory of a base or component; the
+	// value is the root it resolves to, or empty if it
+	// doesn't.
+	inputRoot = "root"
+)
+
+// buildRecord records what the build of a base depends on,
+// for a base cache file to tell if the base is unchanged.
+// Its methods may be called concurrently, and on nil, which
+// records nothing.
+type buildRecord struct {
+	mu sync.Mutex
+	// inputs maps the kind and path of what the build read,
+	// e.g. "file:/app/base/kustomization.yaml", to what it
+	// read, see the input kinds.
+	inputs map[string]string
+	// volatile is true if the build depends on more than its
+	// inputs, e.g. on remote files or on exec plugins, or
+	// read decrypted files, which mustn't be saved.
+	volatile bool
+	// warnings are the warnings of the build.
+	warnings []types.Warning
+}
+
+func newBuildRecord() *buildRecord {
+	return &buildRecord{inputs: make(map[string]string)}
+}
+
+func (r *buildRecord) add(kind, path, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inputs[kind+":"+path] = value
+}
+
+func (r *buildRecord) markVolatile() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.volatile = true
+}
+
+func (r *buildRecord) addWarning(w types.Warning) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, w)
+}
+
+// merge adds what the build of a base depends on to the
+// record of the build including it.  A base built without
+// a record makes the including build volatile.
+func (r *buildRecord) merge(base *buildRecord) {
+	if r == nil {
+		return
+	}
+	if base == nil {
+		r.markVolatile()
+		return
+	}
+	base.mu.Lock()
+	defer base.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, v := range base.inputs {
+		r.inputs[k] = v
+	}
+	r.volatile = r.volatile || base.volatile
+	r.warnings = append(r.warnings, base.warnings...)
+}
+
+func hashBytes(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hashNames(names []string) string {
+	return hashBytes([]byte(strings.Join(names, "\n")))
+}
+
+// recordingLoader is a loader recording what it reads, and
+// what the loaders it makes read, in a buildRecord.
+type recordingLoader struct {
+	ifc.Loader
+	record *buildRecord
+}
+
+var _ ifc.DirLoader = &recordingLoader{}
+
+func (l *recordingLoader) path(location string) string {
+	if filepath.IsAbs(location) {
+		return location
+	}
+	return filepath.Join(l.Root(), location)
+}
+
+// New returns a recording loader at the new root.
+func (l *recordingLoader) New(newRoot string) (ifc.Loader, error) {
+	ldr, err := l.Loader.New(newRoot)
+	if err != nil {
+		l.record.add(inputRoot, l.path(newRoot), "")
+		return nil, err
+	}
+	if r, ok := ldr.(interface{ Repo() string }); ok && r.Repo() != "" {
+		l.record.markVolatile()
+	} else {
+		l.record.add(inputRoot, l.path(newRoot), ldr.Root())
+	}
+	return &recordingLoader{Loader: ldr, record: l.record}, nil
+}
+
+// Load returns the bytes read from the location.
+func (l *recordingLoader) Load(location string) ([]byte, error) {
+	content, err := l.Loader.Load(location)
+	switch {
+	case isRemote(location):
+		l.record.markVolatile()
+	case l.decrypts(location):
+		// Decrypted files are never saved.
+		l.record.markVolatile()
+	case err != nil:
+		l.record.add(inputFile, l.path(location), "")
+	default:
+		l.record.add(inputFile, l.path(location), hashBytes(content))
+	}
+	return content, err
+}
+
+// decrypts returns true if the loader decrypts the file at
+// the location, e.g. as a sops loader does.
+func (l *recordingLoader) decrypts(location string) bool {
+	d, ok := l.Loader.(interface{ Decrypts(string) bool })
+	return ok && d.Decrypts(location)
+}
+
+// IsDir returns true if the location is a directory.
+func (l *recordingLoader) IsDir(location string) bool {
+	dl, ok := l.Loader.(ifc.DirLoader)
+	if !ok {
+		return false
+	}
+	isDir := dl.IsDir(location)
+	l.record.add(inputIsDir, l.path(location), strconv.FormatBool(isDir))
+	return isDir
+}
+
+// ReadDir returns the sorted names of the entries of the
+// directory at the location.
+func (l *recordingLoader) ReadDir(location string) ([]string, error) {
+	dl, ok := l.Loader.(ifc.DirLoader)
+	if !ok {
+		return nil, fmt.Errorf("directories can't be listed in %s", l.Root())
+	}
+	names, err := dl.ReadDir(location)
+	if err != nil {
+		l.record.add(inputDir, l.path(location), "")
+	} else {
+		l.record.add(inputDir, l.path(location), hashNames(names))
+	}
+	return names, err
+}
+
+// Repo returns the URL of the repository the loader's root
+// was cloned from, if it was.
+func (l *recordingLoader) Repo() string {
+	if r, ok := l.Loader.(interface{ Repo() string }); ok {
+		return r.Repo()
+	}
+	return ""
+}
+
+// isRemote returns true if the location is a URL, rather
+// than a path.
+func isRemote(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// record returns the record of the base being built, or nil
+// if the reads of the target aren't recorded.
+func (kt *KustTarget) record() *buildRecord {
+	if l, ok := kt.ldr.(*recordingLoader); ok {
+		return l.record
+	}
+	return nil
+}
+
+// recordPlugin marks the base being built volatile if the
+// plugin reads more than the target's loader gives it, like
+// exec and function plugins, or helm chart inflation.
+func (kt *KustTarget) recordPlugin(p interface{}) {
+	_, helm := p.(*builtins.HelmChartInflationGeneratorPlugin)
+	if helm || !isBuiltin(p) {
+		kt.record().markVolatile()
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+)
+
+// includeConditionals adds the resources, components and
+// patches of the conditionals whose condition holds to those
+// the kustomization lists itself.
+func (kt *KustTarget) includeConditionals() error {
+	k := kt.kustomization
+	for _, c := range k.Conditionals {
+		holds, err := kt.conditionHolds(c.When)
+		if err != nil {
+			return err
+		}
+		if !holds {
+			continue
+		}
+		k.Resources = append(k.Resources, c.Resources...)
+		k.Components = append(k.Components, c.Components...)
+		k.Patches = append(k.Patches, c.Patches...)
+	}
+	// Included once, even if the target is accumulated again.
+	k.Conditionals = nil
+	return nil
+}
+
+// conditionHolds returns true if the parameter named in the
+// condition has, or for != hasn't, the value it gives.
+func (kt *KustTarget) conditionHolds(when string) (bool, error) {
+	negate := false
+	parts := strings.SplitN(when, "!=", 2)
+	if len(parts) == 2 {
+		negate = true
+	} else {
+		parts = strings.SplitN(when, "=", 2)
+	}
+	if len(parts) != 2 || parts[0] == "" {
+		return false, fmt.Errorf(
+			"illegal condition %q; expected NAME=VALUE or NAME!=VALUE", when)
+	}
+	var value string
+	if o := kt.overrides; o != nil {
+		value = o.vars[parts[0]]
+		o.mu.Lock()
+		o.params[parts[0]] = true
+		o.mu.Unlock()
+	}
+	return (value == parts[1]) != negate, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/validate"
+	"sigs.k8s.io/kustomize/api/kv"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// dataSourceGenerator generates the ConfigMap or Secret of a
+// data source.
+type dataSourceGenerator struct {
+	args      types.DataSourceArgs
+	ldr       ifc.Loader
+	validator ifc.Validator
+	rFactory  *resmap.Factory
+}
+
+// configureDataSources returns the generators of the data
+// sources of the kustomization.
+func (kt *KustTarget) configureDataSources() (
+	[]*resmap.GeneratorWithProperties, error) {
+	if len(kt.kustomization.DataSources) == 0 {
+		return nil, nil
+	}
+	if !kt.pLdr.Config().DataSourceConfig.Enabled {
+		return nil, fmt.Errorf(
+			"the data sources of %s require data sources to be enabled",
+			kt.ldr.Root())
+	}
+	var result []*resmap.GeneratorWithProperties
+	for _, args := range kt.kustomization.DataSources {
+		args.Options = types.MergeGlobalOptionsIntoLocal(
+			args.Options, kt.kustomization.GeneratorOptions)
+		var origin *resource.Origin
+		if kt.origin != nil {
+			origin = &resource.Origin{
+				Repo:         kt.origin.Repo,
+				Ref:          kt.origin.Ref,
+				ConfiguredIn: filepath.Join(kt.origin.Path, kt.kustFileName),
+				ConfiguredBy: yaml.ResourceIdentifier{
+					TypeMeta: yaml.TypeMeta{APIVersion: "builtin", Kind: "DataSource"},
+					NameMeta: yaml.NameMeta{Name: args.Name, Namespace: args.Namespace},
+				},
+			}
+		}
+		result = append(result, &resmap.GeneratorWithProperties{
+			Generator: &dataSourceGenerator{
+				args:      args,
+				ldr:       kt.ldr,
+				validator: kt.validator,
+				rFactory:  kt.rFactory,
+			},
+			Origin: origin,
+		})
+	}
+	return result, nil
+}
+
+// Generate runs the command, and returns the ConfigMap or
+// Secret holding its output, and the sources of the args.
+func (g *dataSourceGenerator) Generate() (resmap.ResMap, error) {
+	output, err := g.run()
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := g.pairs(output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "data source %s", g.args.Name)
+	}
+	kvLdr := &dataSourceKvLoader{
+		KvLoader: kv.NewLoader(g.ldr, g.validator), pairs: pairs}
+	if g.args.Kind == types.DataSourceKindSecret {
+		return g.rFactory.FromSecretArgs(kvLdr, types.SecretArgs{
+			GeneratorArgs: g.args.GeneratorArgs, Type: g.args.Type})
+	}
+	return g.rFactory.FromConfigMapArgs(kvLdr, types.ConfigMapArgs{
+		GeneratorArgs: g.args.GeneratorArgs})
+}
+
+func (g *dataSourceGenerator) run() ([]byte, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	//nolint:gosec
+	cmd := exec.Command(g.args.Command, g.args.Args...)
+	cmd.Dir = g.ldr.Root()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "data source %s: running '%s %s': %s",
+			g.args.Name, g.args.Command, strings.Join(g.args.Args, " "),
+			stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// pairs returns the keys and values of the output, in the
+// order of the keys, after checking it against the schema of
+// the data source, if any.
+func (g *dataSourceGenerator) pairs(output []byte) ([]types.Pair, error) {
+	if g.args.Schema != "" {
+		schema, err := g.ldr.Load(g.args.Schema)
+		if err != nil {
+			return nil, err
+		}
+		var data interface{}
+		if err = json.Unmarshal(output, &data); err != nil {
+			return nil, errors.Wrap(err, "reading output")
+		}
+		if err = validate.DataSource(schema, data); err != nil {
+			return nil, err
+		}
+	}
+	// Numbers are kept as written.
+	d := json.NewDecoder(bytes.NewReader(output))
+	d.UseNumber()
+	var values map[string]interface{}
+	if err := d.Decode(&values); err != nil {
+		return nil, errors.Wrap(err, "reading output, expected a JSON object")
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]types.Pair, len(keys))
+	for i, k := range keys {
+		pairs[i].Key = k
+		if s, ok := values[k].(string); ok {
+			pairs[i].Value = s
+			continue
+		}
+		v, err := json.Marshal(values[k])
+		if err != nil {
+			return nil, err
+		}
+		pairs[i].Value = string(v)
+	}
+	return pairs, nil
+}
+
+// dataSourceKvLoader adds the pairs of a data source to those
+// of the sources of its args.
+type dataSourceKvLoader struct {
+	ifc.KvLoader
+	pairs []types.Pair
+}
+
+func (l *dataSourceKvLoader) Load(
+	args types.KvPairSources) ([]types.Pair, error) {
+	all, err := l.KvLoader.Load(args)
+	if err != nil {
+		return nil, err
+	}
+	return append(l.pairs, all...), nil
+}
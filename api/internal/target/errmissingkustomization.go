@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 type errMissingKustomization struct {
@@ -22,6 +23,10 @@ func (e *errMissingKustomization) Error() string {
 		e.path)
 }
 
+func (e *errMissingKustomization) ErrorClass() types.ErrorClass {
+	return types.ErrorClassMissingFile
+}
+
 func IsMissingKustomizationFileError(err error) bool {
 	_, ok := err.(*errMissingKustomization)
 	if ok {
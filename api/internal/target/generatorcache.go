@@ -0,0 +1,212 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/builtins"
+	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// generatorCache keeps the output of expensive generators, i.e.
+// helm chart inflation and exec plugins, on local disk.  Entries
+// are keyed by the generator's config, and the content of the
+// files it declares as inputs: the local chart and values file
+// of helm chart inflation, and those listed by the plugin-inputs
+// annotation of exec plugins, which aren't cached without it.
+// Input paths are keyed relative to the kustomization root, so
+// that a kustomization checked out elsewhere hits the same
+// entries.  Data sources aren't cached, as their commands'
+// output goes stale, and may hold secrets.
+type generatorCache struct {
+	// dir holds one file per entry, named by its key.
+	dir string
+	// If refresh is true, existing entries are ignored
+	// and overwritten.
+	refresh bool
+	// fSys holds the kustomizations.
+	fSys filesys.FileSystem
+}
+
+// SetGeneratorCache makes the target, and its bases, cache
+// the output of expensive generators in the given directory.
+func (kt *KustTarget) SetGeneratorCache(
+	dir string, refresh bool, fSys filesys.FileSystem) {
+	kt.genCache = &generatorCache{dir: dir, refresh: refresh, fSys: fSys}
+}
+
+// generate runs the generator, or takes its output from the cache.
+func (kt *KustTarget) generate(g resmap.Generator) (resmap.ResMap, error) {
+	if kt.genCache == nil {
+		return g.Generate()
+	}
+	key, ok := kt.genCache.key(g, kt.ldr.Root())
+	if !ok {
+		return g.Generate()
+	}
+	if !kt.genCache.refresh {
+		if rm, err := kt.genCache.get(kt.rFactory, key); err == nil {
+			return rm, nil
+		}
+	}
+	rm, err := g.Generate()
+	if err != nil || rm == nil {
+		return rm, err
+	}
+	if err = kt.genCache.put(key, rm); err != nil {
+		return nil, errors.Wrap(err, "caching generator output")
+	}
+	return rm, nil
+}
+
+// key returns the cache key of the generator, and false
+// if its output shouldn't be cached.
+func (c *generatorCache) key(g resmap.Generator, root string) (string, bool) {
+	h := sha256.New()
+	var inputs []string
+	switch p := g.(type) {
+	case *builtins.HelmChartInflationGeneratorPlugin:
+		config, err := json.Marshal(p)
+		if err != nil || isRemote(p.ValuesFile) {
+			return "", false
+		}
+		h.Write([]byte("helm\n"))
+		h.Write(config)
+		if p.ValuesFile != "" {
+			inputs = append(inputs, p.ValuesFile)
+		}
+		if p.Repo == "" {
+			// A chart pulled from a repo is pinned by its
+			// version, in the config.
+			inputs = append(inputs, filepath.Join(p.ChartHome, p.Name))
+		}
+	case *execplugin.ExecPlugin:
+		var ok bool
+		if inputs, ok = p.Inputs(); !ok {
+			return "", false
+		}
+		f, err := os.Open(p.Path())
+		if err != nil {
+			return "", false
+		}
+		defer f.Close()
+		h.Write([]byte("exec\n"))
+		if _, err = io.Copy(h, f); err != nil {
+			return "", false
+		}
+		h.Write(p.Cfg())
+		h.Write([]byte("\n" + strings.Join(p.Args(), "\n")))
+	default:
+		return "", false
+	}
+	if err := c.hashInputs(h, root, inputs); err != nil {
+		return "", false
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// hashInputs adds the content of the files at the paths, and
+// below the directories at them, to the hash, with their paths
+// relative to the root.  Paths missing are hashed as such.
+func (c *generatorCache) hashInputs(h hash.Hash, root string, paths []string) error {
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(root, p)
+		}
+		if !c.fSys.Exists(p) {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte("\nmissing " + filepath.ToSlash(rel) + "\n"))
+			continue
+		}
+		err := c.fSys.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			content, err := c.fSys.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte("\n" + filepath.ToSlash(rel) + "\n"))
+			h.Write(content)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *generatorCache) path(key string) string {
+	return filepath.Join(c.dir, key+".yaml")
+}
+
+func (c *generatorCache) get(
+	rf *resmap.Factory, key string) (resmap.ResMap, error) {
+	content, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, err
+	}
+	rm, err := rf.NewResMapFromBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	return utils.UpdateResourceOptions(rm)
+}
+
+// put writes the generator output, with the resource options
+// kept in the annotations that utils.UpdateResourceOptions reads.
+func (c *generatorCache) put(key string, rm resmap.ResMap) error {
+	rm = rm.DeepCopy()
+	for _, r := range rm.Resources() {
+		annotations := r.GetAnnotations()
+		annotations[utils.BehaviorAnnotation] = r.Behavior().String()
+		annotations[utils.HashAnnotation] = strconv.FormatBool(r.NeedHashSuffix())
+		if err := r.SetAnnotations(annotations); err != nil {
+			return err
+		}
+	}
+	content, err := rm.AsYaml()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	// Write atomically, as bases may be built concurrently.
+	f, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
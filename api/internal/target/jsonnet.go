@@ -0,0 +1,124 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/yaml"
+)
+
+// loadJsonnet evaluates the jsonnet file at path with the
+// jsonnet command, and reads the resources of its output.
+// The file is read through the loader, so its restrictions
+// apply, and evaluated from stdin, importing from its own
+// directory, then from the import paths of the kustomization.
+func (kt *KustTarget) loadJsonnet(path string) (resmap.ResMap, error) {
+	config := kt.pLdr.Config().JsonnetConfig
+	if !config.Enabled {
+		return nil, fmt.Errorf(
+			"evaluating '%s' requires jsonnet to be enabled", path)
+	}
+	content, err := kt.ldr.Load(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "accumulating resources from '%s'", path)
+	}
+	args := []string{"-J", filepath.Join(kt.ldr.Root(), filepath.Dir(path))}
+	if kt.kustomization.Jsonnet != nil {
+		for _, p := range kt.kustomization.Jsonnet.ImportPaths {
+			args = append(args, "-J", filepath.Join(kt.ldr.Root(), p))
+		}
+	}
+	args = append(args, "-")
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	//nolint:gosec
+	cmd := exec.Command(config.Command, args...)
+	cmd.Dir = kt.ldr.Root()
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err = cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err,
+			"evaluating '%s' with '%s %s' (is '%s' installed?): %s",
+			path, config.Command, strings.Join(args, " "),
+			config.Command, stderr.String())
+	}
+	docs, err := jsonnetResources(stdout.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "evaluating '%s'", path)
+	}
+	resources, err := kt.rFactory.NewResMapFromBytes(docs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "accumulating resources from '%s'", path)
+	}
+	return kt.annotateOrigin(resources, path)
+}
+
+// jsonnetResources returns, as a YAML stream, the resources of
+// the JSON a jsonnet file evaluates to.  An object with an
+// apiVersion and a kind is a resource; the values of other
+// objects, in the order of their keys, and the elements of
+// arrays, hold resources, as with kubecfg.
+func jsonnetResources(output []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(output, &value); err != nil {
+		return nil, err
+	}
+	var objects []interface{}
+	if err := appendJsonnetObjects(value, &objects); err != nil {
+		return nil, err
+	}
+	docs := make([][]byte, 0, len(objects))
+	for _, obj := range objects {
+		doc, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return bytes.Join(docs, []byte("---\n")), nil
+}
+
+func appendJsonnetObjects(value interface{}, objects *[]interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, e := range v {
+			if err := appendJsonnetObjects(e, objects); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		_, hasApiVersion := v["apiVersion"]
+		_, hasKind := v["kind"]
+		if hasApiVersion && hasKind {
+			*objects = append(*objects, v)
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := appendJsonnetObjects(v[k], objects); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("expected objects or arrays of them, got %v", v)
+	}
+}
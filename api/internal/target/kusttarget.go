@@ -4,22 +4,28 @@
 package target
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/accumulator"
 	"sigs.k8s.io/kustomize/api/internal/builtins"
+	"sigs.k8s.io/kustomize/api/internal/git"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/internal/validate"
 	"sigs.k8s.io/kustomize/api/konfig"
 	load "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -36,6 +42,42 @@ type KustTarget struct {
 	rFactory      *resmap.Factory
 	pLdr          *loader.Loader
 	origin        *resource.Origin
+	// parallelism bounds how many bases are built concurrently;
+	// values below 2 mean bases are built one after another.
+	parallelism int
+	// genCache, if not nil, holds the output of expensive generators.
+	genCache *generatorCache
+	// overrides, if not nil, holds values given at build time.
+	overrides *overrides
+	// namespace, if not empty, is set on the output, as
+	// if by a kustomization with it including this one.
+	namespace string
+	// fetchSchema, if not nil, returns the openapi schema
+	// of the cluster.
+	fetchSchema func() ([]byte, error)
+	// isBuilt is true for the kustomization being built, rather
+	// than one of its bases or components.
+	isBuilt bool
+	// finalTransformers run after all the other steps.
+	finalTransformers []*resmap.TransformerWithProperties
+	// lenient, if true, lets kustomization files have unknown
+	// fields, which are ignored.
+	lenient bool
+	// trackOrigins, if true, annotates resources with their
+	// origin even if the kustomization has no buildMetadata.
+	trackOrigins bool
+	// warnings collects the warnings of the build.
+	warnings *warnings
+	// checkReferences, if true, fails the build if resources
+	// refer by name to resources it lacks.
+	checkReferences bool
+	// baseCache, if not nil, holds the bases already built.
+	baseCache *BaseCache
+	// timings, if not nil, adds up the time the build spends.
+	timings *Timings
+	// input, if not nil, holds resources the kustomization
+	// being built takes before its own.
+	input resmap.ResMap
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -46,12 +88,74 @@ func NewKustTarget(
 	pLdr *loader.Loader) *KustTarget {
 	pLdrCopy := *pLdr
 	pLdrCopy.SetWorkDir(ldr.Root())
-	return &KustTarget{
+	kt := &KustTarget{
 		ldr:       ldr,
 		validator: validator,
 		rFactory:  rFactory,
 		pLdr:      &pLdrCopy,
+		warnings:  &warnings{},
 	}
+	pLdrCopy.SetWarn(func(msg string) {
+		kt.warn(types.WarningUnknownPluginField, "%s", msg)
+	})
+	return kt
+}
+
+// SetParallelism lets up to n of the target's resource files
+// be read, and up to n of its bases, and of their bases in
+// turn, be built concurrently.
+// Callers must make sure that the global openapi schema is
+// initialized, see openapi.SetSchema; bases setting their
+// own schema fail the build.
+func (kt *KustTarget) SetParallelism(n int) {
+	kt.parallelism = n
+}
+
+// SetLenient lets the kustomization files have unknown fields,
+// which are ignored, rather than failing the build.
+// It must be called before Load.
+func (kt *KustTarget) SetLenient(lenient bool) {
+	kt.lenient = lenient
+}
+
+// SetTrackOrigins annotates the resources with their origin,
+// as the originAnnotations buildMetadata option does, e.g. to
+// name their files in errors.  Callers not asking for origin
+// annotations should remove them from the output.
+func (kt *KustTarget) SetTrackOrigins(track bool) {
+	kt.trackOrigins = track
+}
+
+// SetOpenAPIFetcher sets the function returning the openapi
+// schema of the cluster, for kustomizations whose openapi
+// field has source cluster.
+func (kt *KustTarget) SetOpenAPIFetcher(f func() ([]byte, error)) {
+	kt.fetchSchema = f
+}
+
+// OpenAPISchema returns the custom schema the openapi field
+// of the kustomization points to, or nil if it has none.
+func (kt *KustTarget) OpenAPISchema() ([]byte, error) {
+	field := kt.kustomization.OpenAPI
+	source, fromSource := field["source"]
+	openApiPath, fromPath := field["path"]
+	switch {
+	case fromSource && fromPath:
+		return nil, fmt.Errorf(
+			"openapi source and path provided, cannot use both")
+	case fromPath:
+		return kt.ldr.Load(filepath.Join(kt.ldr.Root(), openApiPath))
+	case !fromSource:
+		return nil, nil
+	case source != "cluster":
+		return nil, fmt.Errorf(
+			"unknown openapi source %q; expected cluster", source)
+	case kt.fetchSchema == nil:
+		return nil, fmt.Errorf(
+			"the openapi schema of the cluster cannot be fetched in this build")
+	}
+	kt.record().markVolatile()
+	return kt.fetchSchema()
 }
 
 // Load attempts to load the target's kustomization file.
@@ -60,24 +164,37 @@ func (kt *KustTarget) Load() error {
 	if err != nil {
 		return err
 	}
+	kt.kustFileName = kustFileName
 	content, err = types.FixKustomizationPreUnmarshalling(content)
 	if err != nil {
-		return err
+		return types.WithErrorClass(err, types.ErrorClassConfig)
 	}
 	var k types.Kustomization
-	err = k.Unmarshal(content)
+	err = kt.unmarshal(&k, content)
 	if err != nil {
-		return err
+		return types.WithErrorClass(err, types.ErrorClassConfig)
+	}
+	err = kt.mergeFragments(&k)
+	if err != nil {
+		return types.WithErrorClass(err, types.ErrorClassConfig)
+	}
+	for _, msg := range k.DeprecatedFields() {
+		kt.warn(types.WarningDeprecatedField, "%s", msg)
 	}
 	k.FixKustomizationPostUnmarshalling()
+	if oldVersion := k.MigrateAPIVersion(); oldVersion != "" {
+		kt.warn(types.WarningDeprecatedField,
+			"apiVersion %s is deprecated, and read as %s; "+
+				"run '%s edit fix --to-version %s' to migrate it",
+			oldVersion, k.APIVersion, konfig.ProgramName, k.APIVersion)
+	}
 	errs := k.EnforceFields()
 	if len(errs) > 0 {
-		return fmt.Errorf(
+		return types.WithErrorClass(fmt.Errorf(
 			"Failed to read kustomization file under %s:\n"+
-				strings.Join(errs, "\n"), kt.ldr.Root())
+				strings.Join(errs, "\n"), kt.ldr.Root()), types.ErrorClassConfig)
 	}
 	kt.kustomization = &k
-	kt.kustFileName = kustFileName
 	return nil
 }
 
@@ -112,6 +229,51 @@ func loadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	}
 }
 
+// unmarshal reads the content of a kustomization file into k,
+// first validating it against the kustomization schema unless
+// the target is lenient.
+func (kt *KustTarget) unmarshal(k *types.Kustomization, content []byte) error {
+	if kt.lenient {
+		return k.UnmarshalLenient(content)
+	}
+	if err := validate.KustomizationFile(content); err != nil {
+		return err
+	}
+	return k.Unmarshal(content)
+}
+
+// mergeFragments merges the YAML files of the fragments
+// directory, if there is one, into the kustomization, in
+// the order of their names.
+func (kt *KustTarget) mergeFragments(k *types.Kustomization) error {
+	dl, ok := kt.ldr.(ifc.DirLoader)
+	if !ok || !dl.IsDir(konfig.KustomizationFragmentsDir) {
+		return nil
+	}
+	names, err := dl.ReadDir(konfig.KustomizationFragmentsDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		path := filepath.Join(konfig.KustomizationFragmentsDir, name)
+		content, err := kt.ldr.Load(path)
+		if err != nil {
+			return err
+		}
+		var f types.Kustomization
+		if err = kt.unmarshal(&f, content); err != nil {
+			return errors.Wrapf(err, "reading kustomization fragment %s", path)
+		}
+		if err = k.MergeFragment(&f); err != nil {
+			return errors.Wrapf(err, "merging kustomization fragment %s", path)
+		}
+	}
+	return nil
+}
+
 // MakeCustomizedResMap creates a fully customized ResMap
 // per the instructions contained in its kustomization instance.
 func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
@@ -120,10 +282,11 @@ func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
 
 func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 	var origin *resource.Origin
-	if len(kt.kustomization.BuildMetadata) != 0 {
+	if len(kt.kustomization.BuildMetadata) != 0 || kt.trackOrigins {
 		origin = &resource.Origin{}
 	}
 	kt.origin = origin
+	kt.isBuilt = true
 	ra, err := kt.AccumulateTarget()
 	if err != nil {
 		return nil, err
@@ -132,6 +295,11 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 	// The following steps must be done last, not as part of
 	// the recursion implicit in AccumulateTarget.
 
+	err = kt.setNamespace(ra)
+	if err != nil {
+		return nil, err
+	}
+
 	err = kt.addHashesToNames(ra)
 	if err != nil {
 		return nil, err
@@ -139,20 +307,115 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 
 	// Given that names have changed (prefixs/suffixes added),
 	// fix all the back references to those names.
+	stop := kt.timings.start(timingTransformer, "NameReferenceTransformer")
 	err = ra.FixBackReferences()
+	stop()
 	if err != nil {
 		return nil, err
 	}
 
+	err = kt.checkOverridesUsed(ra)
+	if err != nil {
+		return nil, err
+	}
+	if kt.overrides != nil {
+		ra.SetVarOverrides(kt.overrides.vars)
+	}
+
 	// With all the back references fixed, it's OK to resolve Vars.
 	err = ra.ResolveVars()
 	if err != nil {
 		return nil, err
 	}
+	for _, v := range ra.UnusedVars() {
+		kt.warn(types.WarningUnusedVar, "var %s is never referenced", v)
+	}
+
+	err = ra.Transform(newMultiTransformer(kt.ldr.Root(), kt.timings, kt.finalTransformers))
+	if err != nil {
+		return nil, err
+	}
 
+	if kt.checkReferences {
+		err = kt.errorIfDanglingReferences(
+			ra.ResMap(), ra.GetTransformerConfig().NameReference)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = kt.runValidators(ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+
+	kt.warnClusterScopedNamespaces(ra.ResMap())
 	return ra.ResMap(), nil
 }
 
+// SetImages sets the given images in the kustomization, as
+// 'kustomize edit set image' would; an image replaces the one
+// of the same name, if any, keeping its new name, new tag or
+// digest where the given image has "*".  Call after Load.
+func (kt *KustTarget) SetImages(images []types.Image) {
+	for _, im := range images {
+		var old types.Image
+		i := 0
+		for ; i < len(kt.kustomization.Images); i++ {
+			if kt.kustomization.Images[i].Name == im.Name {
+				old = kt.kustomization.Images[i]
+				break
+			}
+		}
+		if im.NewName == "*" {
+			im.NewName = old.NewName
+		}
+		if im.NewTag == "*" {
+			im.NewTag = old.NewTag
+		}
+		if im.Digest == "*" {
+			im.Digest = old.Digest
+		}
+		if i < len(kt.kustomization.Images) {
+			kt.kustomization.Images[i] = im
+		} else {
+			kt.kustomization.Images = append(kt.kustomization.Images, im)
+		}
+	}
+}
+
+// SetInput makes the target take the given resources, e.g.
+// read from stdin, before those of its kustomization, so that
+// its generators, transformers and patches apply to them.
+func (kt *KustTarget) SetInput(m resmap.ResMap) {
+	kt.input = m
+}
+
+// SetNamespace makes the target put all its namespaced
+// resources in the given namespace, after its kustomization,
+// and those of its bases, are done.
+func (kt *KustTarget) SetNamespace(namespace string) {
+	kt.namespace = namespace
+}
+
+func (kt *KustTarget) setNamespace(ra *accumulator.ResAccumulator) error {
+	if kt.namespace == "" {
+		return nil
+	}
+	var c struct {
+		types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+		FieldSpecs       []types.FieldSpec
+	}
+	c.Namespace = kt.namespace
+	c.FieldSpecs = ra.GetTransformerConfig().NameSpace
+	p := builtins.NewNamespaceTransformerPlugin()
+	err := kt.configureBuiltinPlugin(p, c, builtinhelpers.NamespaceTransformer)
+	if err != nil {
+		return err
+	}
+	return ra.Transform(p)
+}
+
 func (kt *KustTarget) addHashesToNames(
 	ra *accumulator.ResAccumulator) error {
 	p := builtins.NewHashTransformerPlugin()
@@ -185,6 +448,16 @@ func (kt *KustTarget) AccumulateTarget() (
 // (or empty if the Component does not have a parent).
 func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	resRa *accumulator.ResAccumulator, err error) {
+	logging.V(2).Info("accumulating kustomization", "root", kt.ldr.Root())
+	err = kt.includeConditionals()
+	if err != nil {
+		return nil, err
+	}
+	if kt.isBuilt && kt.input != nil {
+		if err = ra.AppendAll(kt.input); err != nil {
+			return nil, errors.Wrap(err, "accumulating input")
+		}
+	}
 	ra, err = kt.accumulateResources(ra, kt.kustomization.Resources)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating resources")
@@ -217,14 +490,34 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, err
 	}
+	err = kt.applyFieldOverrides(ra)
+	if err != nil {
+		return nil, err
+	}
 	err = kt.runTransformers(ra)
 	if err != nil {
 		return nil, err
 	}
-	err = kt.runValidators(ra)
+	err = kt.warnUnmatchedSelectors(ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+	err = kt.warnUnusedConfigurations(ra.ResMap())
 	if err != nil {
 		return nil, err
 	}
+	if !kt.isBuilt {
+		err = ra.Transform(newMultiTransformer(kt.ldr.Root(), kt.timings, kt.finalTransformers))
+		if err != nil {
+			return nil, err
+		}
+		// The validators of the kustomization being built run
+		// once its output is final.
+		err = kt.runValidators(ra.ResMap())
+		if err != nil {
+			return nil, err
+		}
+	}
 	err = ra.MergeVars(kt.kustomization.Vars)
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -259,15 +552,26 @@ func (kt *KustTarget) runGenerators(
 	}
 	generators = append(generators, gs...)
 
+	gs, err = kt.configureDataSources()
+	if err != nil {
+		return err
+	}
+	generators = append(generators, gs...)
+
 	gs, err = kt.configureExternalGenerators()
 	if err != nil {
 		return errors.Wrap(err, "loading generator plugins")
 	}
 	generators = append(generators, gs...)
 	for i, g := range generators {
-		resMap, err := g.Generate()
+		kt.recordPlugin(g.Generator)
+		logging.V(3).Info("running generator",
+			"root", kt.ldr.Root(), "generator", pluginName(g.Generator, g.Origin))
+		stop := kt.timings.start(timingGenerator, pluginName(g.Generator, g.Origin))
+		resMap, err := kt.generate(g.Generator)
+		stop()
 		if err != nil {
-			return err
+			return pluginFailure(err, "generator", g.Generator, g.Origin, kt.ldr.Root())
 		}
 		if resMap != nil {
 			err = resMap.AddOriginAnnotation(generators[i].Origin)
@@ -313,25 +617,102 @@ func (kt *KustTarget) configureExternalGenerators() (
 }
 
 func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
-	var r []*resmap.TransformerWithProperties
 	tConfig := ra.GetTransformerConfig()
-	lts, err := kt.configureBuiltinTransformers(tConfig)
+	builtins, err := kt.configureBuiltinTransformers(tConfig)
 	if err != nil {
 		return err
 	}
-	r = append(r, lts...)
-	lts, err = kt.configureExternalTransformers(kt.kustomization.Transformers)
+	configs, err := kt.loadExternalConfigs(kt.kustomization.Transformers)
 	if err != nil {
 		return err
 	}
-	r = append(r, lts...)
-	return ra.Transform(newMultiTransformer(r))
+	phases, err := splitTransformerPhases(configs)
+	if err != nil {
+		return err
+	}
+	pre, err := kt.pLdr.LoadTransformers(
+		kt.ldr, kt.validator, phases[konfig.TransformerPhasePreBuiltin])
+	if err != nil {
+		return err
+	}
+	post, err := kt.pLdr.LoadTransformers(
+		kt.ldr, kt.validator, phases[konfig.TransformerPhasePostBuiltin])
+	if err != nil {
+		return err
+	}
+	kt.finalTransformers, err = kt.pLdr.LoadTransformers(
+		kt.ldr, kt.validator, phases[konfig.TransformerPhaseFinal])
+	if err != nil {
+		return err
+	}
+	var mutators []*resmap.TransformerWithProperties
+	if kt.kustomization.Pipeline != nil {
+		mutators, err = kt.configurePipelineFunctions(
+			kt.kustomization.Pipeline.Mutators)
+		if err != nil {
+			return err
+		}
+	}
+	var r []*resmap.TransformerWithProperties
+	r = append(r, pre...)
+	r = append(r, builtins...)
+	r = append(r, post...)
+	r = append(r, mutators...)
+	for _, t := range append(r, kt.finalTransformers...) {
+		kt.recordPlugin(t.Transformer)
+	}
+	if err = ra.Transform(newMultiTransformer(kt.ldr.Root(), kt.timings, r)); err != nil {
+		return err
+	}
+	if kt.kustomization.Kind == types.ComponentKind {
+		// The resources entries marked are those of the
+		// kustomization including the component.
+		return nil
+	}
+	return dropNotInherited(ra.ResMap())
+}
+
+// splitTransformerPhases returns the transformer configs of
+// each phase, per their konfig.TransformerPhaseAnnotation.
+func splitTransformerPhases(configs resmap.ResMap) (map[string]resmap.ResMap, error) {
+	result := map[string]resmap.ResMap{
+		konfig.TransformerPhasePreBuiltin:  resmap.New(),
+		konfig.TransformerPhasePostBuiltin: resmap.New(),
+		konfig.TransformerPhaseFinal:       resmap.New(),
+	}
+	for _, r := range configs.Resources() {
+		phase, ok := r.GetAnnotations()[konfig.TransformerPhaseAnnotation]
+		if !ok {
+			phase = konfig.TransformerPhasePostBuiltin
+		}
+		m, ok := result[phase]
+		if !ok {
+			return nil, fmt.Errorf(
+				"unknown transformer phase %q in %s; expected %s, %s or %s",
+				phase, r.CurId(), konfig.TransformerPhasePreBuiltin,
+				konfig.TransformerPhasePostBuiltin, konfig.TransformerPhaseFinal)
+		}
+		if err := m.Append(r); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]*resmap.TransformerWithProperties, error) {
+	configs, err := kt.loadExternalConfigs(transformers)
+	if err != nil {
+		return nil, err
+	}
+	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, configs)
+}
+
+// loadExternalConfigs returns the plugin configs listed, as
+// paths or inline, by the kustomization.
+func (kt *KustTarget) loadExternalConfigs(configs []string) (resmap.ResMap, error) {
 	ra := accumulator.MakeEmptyAccumulator()
 	var transformerPaths []string
-	for _, p := range transformers {
+	for _, p := range configs {
 		// handle inline transformers
 		rm, err := kt.rFactory.NewResMapFromBytes([]byte(p))
 		if err != nil {
@@ -353,32 +734,78 @@ func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]*r
 	if err != nil {
 		return nil, err
 	}
-	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	return ra.ResMap(), nil
 }
 
-func (kt *KustTarget) runValidators(ra *accumulator.ResAccumulator) error {
+// runValidators runs the validators of the kustomization on
+// copies of its resources, so that they can only fail the build
+// or log warnings.  They may label resources as validated by
+// them, but any other change is an error.
+func (kt *KustTarget) runValidators(m resmap.ResMap) error {
 	validators, err := kt.configureExternalTransformers(kt.kustomization.Validators)
 	if err != nil {
 		return err
 	}
-	for _, v := range validators {
-		// Validators shouldn't modify the resource map
-		orignal := ra.ResMap().DeepCopy()
-		err = v.Transform(ra.ResMap())
+	if kt.kustomization.Pipeline != nil {
+		fns, err := kt.configurePipelineFunctions(
+			kt.kustomization.Pipeline.Validators)
 		if err != nil {
 			return err
 		}
-		newMap := ra.ResMap().DeepCopy()
-		if err = kt.removeValidatedByLabel(newMap); err != nil {
+		validators = append(validators, fns...)
+	}
+	for _, v := range validators {
+		kt.recordPlugin(v.Transformer)
+		logging.V(3).Info("running validator",
+			"root", kt.ldr.Root(), "validator", pluginName(v.Transformer, v.Origin))
+		validated := m.DeepCopy()
+		stop := kt.timings.start(timingValidator, pluginName(v.Transformer, v.Origin))
+		err = v.Transform(validated)
+		stop()
+		if err != nil {
+			return pluginFailure(err, "validator", v.Transformer, v.Origin, kt.ldr.Root())
+		}
+		if err = kt.removeValidatedByLabel(validated); err != nil {
 			return err
 		}
-		if err = orignal.ErrorIfNotEqualSets(newMap); err != nil {
+		if err = errorIfChanged(m, validated); err != nil {
 			return fmt.Errorf("validator shouldn't modify the resource map: %v", err)
 		}
 	}
 	return nil
 }
 
+// errorIfChanged errors if the resources a validator returned
+// differ from those it was given, but for build annotations,
+// e.g. those that function plugins add.  The resources given
+// are copied one at a time, to drop their build annotations,
+// rather than all at once, which fleet-sized builds can't
+// afford.
+func errorIfChanged(given, returned resmap.ResMap) error {
+	if given.Size() != returned.Size() {
+		return fmt.Errorf("got %d resources, returned %d",
+			given.Size(), returned.Size())
+	}
+	returned.RemoveBuildAnnotations()
+	returnedResources := returned.Resources()
+	for i, r := range given.Resources() {
+		r = r.DeepCopy()
+		r.RemoveBuildAnnotations()
+		before, err := r.AsYAML()
+		if err != nil {
+			return err
+		}
+		after, err := returnedResources[i].AsYAML()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(before, after) {
+			return fmt.Errorf("changed %s", r.CurId())
+		}
+	}
+	return nil
+}
+
 func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) error {
 	resources := rm.Resources()
 	for _, r := range resources {
@@ -398,16 +825,29 @@ func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) error {
 // with resources read from the given list of paths.
 func (kt *KustTarget) accumulateResources(
 	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
-	for _, path := range paths {
+	paths, err := kt.expandResourcePatterns(paths)
+	if err != nil {
+		return nil, err
+	}
+	if kt.parallelism > 1 {
+		return kt.accumulateResourcesConcurrently(ra, paths)
+	}
+	// fetch remote bases concurrently, rather than in turn
+	fetched := kt.fetchRemoteEntries(paths)
+	defer fetched.cleanup()
+	for i, entry := range paths {
+		path, opts := resourceEntryPath(entry)
+		before := ra.ResMap().Size()
 		// try loading resource as file then as base (directory or git repository)
-		if errF := kt.accumulateFile(ra, path); errF != nil {
-			// not much we can do if the error is an HTTP error so we bail out
-			if errors.Is(errF, load.ErrorHTTP) {
+		if errF := fetched.accumulateFile(kt, ra, i, path); errF != nil {
+			// not much we can do if the error is an HTTP error,
+			// or the resource is inline, so we bail out
+			if errors.Is(errF, load.ErrorHTTP) || types.IsInlineResource(path) {
 				return nil, errF
 			}
-			ldr, err := kt.ldr.New(path)
+			ldr, err := fetched.newLoader(kt, i, path)
 			if err != nil {
-				return nil, errors.Wrapf(
+				return nil, wrapUnlessCycle(
 					err, "accumulation err='%s'", errF.Error())
 			}
 			// store the origin, we'll need it later
@@ -421,23 +861,149 @@ func (kt *KustTarget) accumulateResources(
 				ra, err = kt.accumulateDirectory(ra, ldr, false)
 			}
 			if err != nil {
-				return nil, errors.Wrapf(
+				return nil, wrapUnlessCycle(
 					err, "accumulation err='%s'", errF.Error())
 			}
 		}
+		if err := markNotInherited(ra, before, opts); err != nil {
+			return nil, err
+		}
+	}
+	return ra, nil
+}
+
+// accumulateResourcesConcurrently is like accumulateResources,
+// but reads files and builds bases concurrently. Up to
+// kt.parallelism files are read and parsed at a time, then
+// loaders for the entries that aren't files are made, up to
+// git.MaxConcurrentClones at a time as some may clone remote
+// bases, then up to kt.parallelism bases are built at a time. All
+// results are merged in the order of paths, so that the
+// outcome doesn't depend on scheduling.
+func (kt *KustTarget) accumulateResourcesConcurrently(
+	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
+	type entry struct {
+		path      string
+		opts      *types.ResourceOptions
+		resources resmap.ResMap
+		ldr       ifc.Loader
+		errF      error
+		errL      error
+		subRa     *accumulator.ResAccumulator
+		err       error
+	}
+	entries := make([]entry, len(paths))
+	for i := range paths {
+		entries[i].path, entries[i].opts = resourceEntryPath(paths[i])
+	}
+	// try loading resources as files then as bases (directories or git repositories)
+	forEachConcurrently(kt.parallelism, len(entries), func(i int) {
+		e := &entries[i]
+		e.resources, e.errF = kt.loadFile(e.path)
+	})
+	// not much we can do if the error is an HTTP error,
+	// or the resource is inline, so we bail out
+	bailOut := func(e *entry) bool {
+		return errors.Is(e.errF, load.ErrorHTTP) || types.IsInlineResource(e.path)
+	}
+	forEachConcurrently(git.MaxConcurrentClones, len(entries), func(i int) {
+		e := &entries[i]
+		if e.errF != nil && !bailOut(e) {
+			e.ldr, e.errL = kt.newLoader(e.path)
+		}
+	})
+	cleanupLoaders := func() {
+		for _, e := range entries {
+			if e.ldr != nil {
+				e.ldr.Cleanup()
+			}
+		}
+	}
+	for i := range entries {
+		e := &entries[i]
+		if e.errF == nil {
+			continue
+		}
+		if bailOut(e) {
+			cleanupLoaders()
+			return nil, e.errF
+		}
+		if e.errL != nil {
+			cleanupLoaders()
+			return nil, wrapUnlessCycle(
+				e.errL, "accumulation err='%s'", e.errF.Error())
+		}
+	}
+	forEachConcurrently(kt.parallelism, len(entries), func(i int) {
+		e := &entries[i]
+		if e.ldr == nil {
+			return
+		}
+		// Each base gets its own copy of the target,
+		// so that its origin can be tracked separately.
+		sub := *kt
+		if kt.origin != nil {
+			sub.origin = kt.origin.Append(e.path)
+		}
+		e.subRa, e.err = sub.accumulateDirectory(
+			accumulator.MakeEmptyAccumulator(), e.ldr, false)
+	})
+	for _, e := range entries {
+		before := ra.ResMap().Size()
+		if e.ldr == nil {
+			if err := ra.AppendAll(e.resources); err != nil {
+				return nil, errors.Wrapf(err, "merging resources from '%s'", e.path)
+			}
+		} else {
+			if e.err != nil {
+				return nil, wrapUnlessCycle(
+					e.err, "accumulation err='%s'", e.errF.Error())
+			}
+			if err := ra.MergeAccumulator(e.subRa); err != nil {
+				return nil, errors.Wrapf(
+					err, "recursed merging from path '%s'", e.ldr.Root())
+			}
+		}
+		if err := markNotInherited(ra, before, e.opts); err != nil {
+			return nil, err
+		}
 	}
 	return ra, nil
 }
 
+// forEachConcurrently calls f for each index below count,
+// with up to n workers calling it at a time.
+func forEachConcurrently(n, count int, f func(i int)) {
+	if n > count {
+		n = count
+	}
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for w := 0; w < n; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
 // accumulateResources fills the given resourceAccumulator
 // with resources read from the given list of paths.
 func (kt *KustTarget) accumulateComponents(
 	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
 	for _, path := range paths {
 		// Components always refer to directories
-		ldr, errL := kt.ldr.New(path)
+		ldr, errL := kt.newLoader(path)
 		if errL != nil {
-			return nil, fmt.Errorf("loader.New %q", errL)
+			return nil, wrapUnlessCycle(errL, "loader.New")
 		}
 		var errD error
 		// store the origin, we'll need it later
@@ -451,16 +1017,88 @@ func (kt *KustTarget) accumulateComponents(
 			ra, errD = kt.accumulateDirectory(ra, ldr, true)
 		}
 		if errD != nil {
-			return nil, fmt.Errorf("accumulateDirectory: %q", errD)
+			return nil, wrapUnlessCycle(errD, "accumulateDirectory")
 		}
 	}
 	return ra, nil
 }
 
+// wrapUnlessCycle wraps err, unless it's from a cycle of
+// kustomizations: that's returned as is, since its inclusion
+// path tells more than the errors of each kustomization
+// in the cycle.
+func wrapUnlessCycle(err error, format string, args ...interface{}) error {
+	var cycle *load.CycleError
+	if errors.As(err, &cycle) {
+		return cycle
+	}
+	return errors.Wrapf(err, format, args...)
+}
+
+// newLoader returns a loader at the path, a directory below
+// the target's root or a repository clone, timing clones.
+func (kt *KustTarget) newLoader(path string) (ifc.Loader, error) {
+	start := time.Now()
+	ldr, err := kt.ldr.New(path)
+	if r, ok := ldr.(interface{ Repo() string }); ok && r.Repo() != "" {
+		kt.timings.add(timingRemote, r.Repo(), time.Since(start))
+	}
+	return ldr, err
+}
+
 func (kt *KustTarget) accumulateDirectory(
-	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
+	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (
+	result *accumulator.ResAccumulator, err error) {
 	defer ldr.Cleanup()
+	entry := "base"
+	if isComponent {
+		entry = "component"
+	}
+	logging.V(2).Info("accumulating "+entry, "root", kt.ldr.Root(), entry, ldr.Root())
+	defer kt.timings.start(entry, ldr.Root())()
+	defer func() {
+		if err != nil {
+			logging.V(2).Info(entry+" failed",
+				"root", kt.ldr.Root(), entry, ldr.Root(), "error", err)
+		}
+	}()
+	var subRa *accumulator.ResAccumulator
+	if isComponent {
+		// Components don't create a new accumulator: the kustomization directives are added to the current accumulator
+		subKt, err := kt.makeSubTarget(ldr, isComponent)
+		if err != nil {
+			return nil, err
+		}
+		subRa, err = subKt.accumulateTarget(ra)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "recursed accumulation of path '%s'", ldr.Root())
+		}
+		ra = accumulator.MakeEmptyAccumulator()
+	} else {
+		// Child Kustomizations create a new accumulator which resolves their kustomization directives, which will later
+		// be merged into the current accumulator.
+		subRa, err = kt.accumulateBase(ldr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = ra.MergeAccumulator(subRa)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "recursed merging from path '%s'", ldr.Root())
+	}
+	return ra, nil
+}
+
+// makeSubTarget loads the kustomization at the loader's root,
+// a base or component of the target, into a target inheriting
+// the target's settings.
+func (kt *KustTarget) makeSubTarget(
+	ldr ifc.Loader, isComponent bool) (*KustTarget, error) {
 	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
+	subKt.lenient = kt.lenient
+	subKt.warnings = kt.warnings
 	err := subKt.Load()
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -468,69 +1106,161 @@ func (kt *KustTarget) accumulateDirectory(
 	}
 	subKt.kustomization.BuildMetadata = kt.kustomization.BuildMetadata
 	subKt.origin = kt.origin
-	var bytes []byte
-	path := ldr.Root()
-	if openApiPath, exists := subKt.Kustomization().OpenAPI["path"]; exists {
-		bytes, err = ldr.Load(filepath.Join(path, openApiPath))
-		if err != nil {
-			return nil, err
-		}
+	subKt.parallelism = kt.parallelism
+	subKt.genCache = kt.genCache
+	subKt.overrides = kt.overrides
+	subKt.fetchSchema = kt.fetchSchema
+	subKt.baseCache = kt.baseCache
+	subKt.timings = kt.timings
+	if kt.parallelism > 0 && len(subKt.Kustomization().OpenAPI) > 0 {
+		// Bases built concurrently share the global schema,
+		// which is fixed before they're built.  The build fails
+		// even if only one base is built at a time, so that it
+		// doesn't depend on the number of CPUs.
+		return nil, types.WithErrorClass(fmt.Errorf(
+			"'%s' sets openapi, which bases built in parallel can't; "+
+				"set it in the kustomization being built, or build without parallelism",
+			ldr.Root()), types.ErrorClassConfig)
+	}
+	bytes, err := subKt.OpenAPISchema()
+	if err != nil {
+		return nil, err
 	}
 	err = openapi.SetSchema(subKt.Kustomization().OpenAPI, bytes, false)
 	if err != nil {
 		return nil, err
 	}
 	if isComponent && subKt.kustomization.Kind != types.ComponentKind {
-		return nil, fmt.Errorf(
-			"expected kind '%s' for path '%s' but got '%s'", types.ComponentKind, ldr.Root(), subKt.kustomization.Kind)
+		return nil, types.WithErrorClass(fmt.Errorf(
+			"expected kind '%s' for path '%s' but got '%s'; "+
+				"list kustomizations under 'resources'",
+			types.ComponentKind, ldr.Root(), subKt.kustomization.Kind), types.ErrorClassConfig)
 	} else if !isComponent && subKt.kustomization.Kind == types.ComponentKind {
-		return nil, fmt.Errorf(
-			"expected kind != '%s' for path '%s'", types.ComponentKind, ldr.Root())
+		return nil, types.WithErrorClass(fmt.Errorf(
+			"expected kind != '%s' for path '%s'; "+
+				"list components under 'components'",
+			types.ComponentKind, ldr.Root()), types.ErrorClassConfig)
 	}
+	return subKt, nil
+}
 
-	var subRa *accumulator.ResAccumulator
-	if isComponent {
-		// Components don't create a new accumulator: the kustomization directives are added to the current accumulator
-		subRa, err = subKt.accumulateTarget(ra)
-		ra = accumulator.MakeEmptyAccumulator()
-	} else {
-		// Child Kustomizations create a new accumulator which resolves their kustomization directives, which will later
-		// be merged into the current accumulator.
-		subRa, err = subKt.AccumulateTarget()
+// accumulateBase builds the base at the loader's root, or
+// takes a copy of it from the base cache, or its file.
+func (kt *KustTarget) accumulateBase(
+	ldr ifc.Loader) (*accumulator.ResAccumulator, error) {
+	file := kt.baseCacheFile()
+	var record *buildRecord
+	if file != nil {
+		// The base records its reads apart from the target,
+		// which takes them from its record.
+		if l, ok := ldr.(*recordingLoader); ok {
+			ldr = l.Loader
+		}
+		record = newBuildRecord()
+		ldr = &recordingLoader{Loader: ldr, record: record}
+	}
+	build := func() (*accumulator.ResAccumulator, *buildRecord, error) {
+		subKt, err := kt.makeSubTarget(ldr, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		subRa, err := subKt.AccumulateTarget()
+		if err != nil {
+			return nil, nil, errors.Wrapf(
+				err, "recursed accumulation of path '%s'", ldr.Root())
+		}
+		return subRa, record, nil
+	}
+	if kt.baseCache == nil {
+		ra, _, err := build()
+		return ra, err
 	}
+	key, err := kt.baseCacheKey(ldr)
 	if err != nil {
-		return nil, errors.Wrapf(
-			err, "recursed accumulation of path '%s'", ldr.Root())
+		return nil, err
 	}
-	err = ra.MergeAccumulator(subRa)
+	ra, record, err := kt.baseCache.get(key, func() (
+		*accumulator.ResAccumulator, *buildRecord, error) {
+		if ra, record := file.load(key, kt.rFactory); ra != nil {
+			logging.V(2).Info("reusing saved base", "base", ldr.Root(), "file", file.path)
+			return ra, record, nil
+		}
+		ra, record, err := build()
+		if err == nil {
+			file.save(key, ra, record)
+		}
+		return ra, record, err
+	})
 	if err != nil {
-		return nil, errors.Wrapf(
-			err, "recursed merging from path '%s'", ldr.Root())
+		return nil, err
 	}
+	if record != nil {
+		// The warnings of bases taken from the cache.
+		for _, w := range record.warnings {
+			kt.warnings.add(w)
+		}
+	}
+	kt.record().merge(record)
 	return ra, nil
 }
 
 func (kt *KustTarget) accumulateFile(
 	ra *accumulator.ResAccumulator, path string) error {
+	resources, err := kt.loadFile(path)
+	if err != nil {
+		return err
+	}
+	err = ra.AppendAll(resources)
+	if err != nil {
+		return errors.Wrapf(err, "merging resources from '%s'", path)
+	}
+	return nil
+}
+
+// loadFile reads the resources in the file at path,
+// annotating them with their origin if it's tracked.
+func (kt *KustTarget) loadFile(path string) (resmap.ResMap, error) {
+	if types.IsInlineResource(path) {
+		return kt.loadInline(path)
+	}
+	if isRemote(path) {
+		defer kt.timings.start(timingRemote, path)()
+	}
+	if types.IsJsonnetFile(path) {
+		return kt.loadJsonnet(path)
+	}
 	resources, err := kt.rFactory.FromFile(kt.ldr, path)
 	if err != nil {
-		return errors.Wrapf(err, "accumulating resources from '%s'", path)
+		return nil, errors.Wrapf(err, "accumulating resources from '%s'", path)
 	}
+	return kt.annotateOrigin(resources, path)
+}
+
+// loadInline reads the resources held by a resources entry,
+// with the kustomization file as their origin.
+func (kt *KustTarget) loadInline(content string) (resmap.ResMap, error) {
+	resources, err := kt.rFactory.NewResMapFromBytes([]byte(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "accumulating inline resource")
+	}
+	return kt.annotateOrigin(resources, kt.kustFileName)
+}
+
+// annotateOrigin annotates the resources, read from the file
+// at path, with their origin if it's tracked.
+func (kt *KustTarget) annotateOrigin(
+	resources resmap.ResMap, path string) (resmap.ResMap, error) {
 	if kt.origin != nil {
 		originAnno, err := kt.origin.Append(path).String()
 		if err != nil {
-			return errors.Wrapf(err, "cannot add path annotation for '%s'", path)
+			return nil, errors.Wrapf(err, "cannot add path annotation for '%s'", path)
 		}
 		err = resources.AnnotateAll(utils.OriginAnnotationKey, originAnno)
 		if err != nil || originAnno == "" {
-			return errors.Wrapf(err, "cannot add path annotation for '%s'", path)
+			return nil, errors.Wrapf(err, "cannot add path annotation for '%s'", path)
 		}
 	}
-	err = ra.AppendAll(resources)
-	if err != nil {
-		return errors.Wrapf(err, "merging resources from '%s'", path)
-	}
-	return nil
+	return resources, nil
 }
 
 func (kt *KustTarget) configureBuiltinPlugin(
@@ -6,12 +6,14 @@ package target
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -101,7 +103,11 @@ func (kt *KustTarget) configureBuiltinTransformers(
 			}
 		}
 		for i := range r {
-			result = append(result, &resmap.TransformerWithProperties{Transformer: r[i], Origin: transformerOrigin})
+			t := r[i]
+			if field, ok := inheritableFields[bpt]; ok {
+				t = &inheritedOnly{Transformer: t, field: field}
+			}
+			result = append(result, &resmap.TransformerWithProperties{Transformer: t, Origin: transformerOrigin})
 		}
 	}
 	return result, nil
@@ -287,6 +293,9 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			} else {
 				// only add to metadata by default
 				fss, err = fss.MergeOne(types.FieldSpec{Path: "metadata/labels", CreateIfNotPresent: true})
+				if err == nil && label.IncludeTemplates {
+					fss, err = fss.MergeAll(templateLabelFieldSpecs(tc.CommonLabels))
+				}
 			}
 			if err != nil {
 				return nil, err
@@ -336,41 +345,51 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 	builtinhelpers.PrefixTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
-		if kt.kustomization.NamePrefix == "" {
-			return
-		}
 		var c struct {
-			Prefix     string            `json:"prefix,omitempty" yaml:"prefix,omitempty"`
-			FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
-		}
-		c.Prefix = kt.kustomization.NamePrefix
-		c.FieldSpecs = tc.NamePrefix
-		p := f()
-		err = kt.configureBuiltinPlugin(p, c, bpt)
-		if err != nil {
-			return nil, err
+			Prefix           string            `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+			FieldSpecs       []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+			FieldSpecsToSkip []types.FieldSpec `json:"fieldSpecsToSkip,omitempty" yaml:"fieldSpecsToSkip,omitempty"`
+		}
+		for _, a := range nameAffixes(
+			kt.kustomization.NamePrefix, kt.kustomization.NamePrefixes) {
+			c.Prefix = a.Value
+			c.FieldSpecs = affixFieldSpecs(tc.NamePrefix, a.Gvks)
+			if len(c.FieldSpecs) == 0 {
+				continue
+			}
+			c.FieldSpecsToSkip = gvkFieldSpecs(a.Skip)
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
 		}
-		result = append(result, p)
 		return
 	},
 	builtinhelpers.SuffixTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
-		if kt.kustomization.NameSuffix == "" {
-			return
-		}
 		var c struct {
-			Suffix     string            `json:"suffix,omitempty" yaml:"suffix,omitempty"`
-			FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
-		}
-		c.Suffix = kt.kustomization.NameSuffix
-		c.FieldSpecs = tc.NameSuffix
-		p := f()
-		err = kt.configureBuiltinPlugin(p, c, bpt)
-		if err != nil {
-			return nil, err
+			Suffix           string            `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+			FieldSpecs       []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+			FieldSpecsToSkip []types.FieldSpec `json:"fieldSpecsToSkip,omitempty" yaml:"fieldSpecsToSkip,omitempty"`
+		}
+		for _, a := range nameAffixes(
+			kt.kustomization.NameSuffix, kt.kustomization.NameSuffixes) {
+			c.Suffix = a.Value
+			c.FieldSpecs = affixFieldSpecs(tc.NameSuffix, a.Gvks)
+			if len(c.FieldSpecs) == 0 {
+				continue
+			}
+			c.FieldSpecsToSkip = gvkFieldSpecs(a.Skip)
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
 		}
-		result = append(result, p)
 		return
 	},
 	builtinhelpers.ImageTagTransformer: func(
@@ -436,3 +455,83 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return nil, fmt.Errorf("valueadd keyword not yet defined")
 	},
 }
+
+// templateLabelFieldSpecs returns the fieldSpecs, of those
+// for commonLabels, that are for the labels of templates,
+// e.g. the pod template of a Deployment.
+func templateLabelFieldSpecs(fss types.FsSlice) types.FsSlice {
+	var result types.FsSlice
+	for _, fs := range fss {
+		if strings.HasSuffix(fs.Path, "template/metadata/labels") {
+			result = append(result, fs)
+		}
+	}
+	return result
+}
+
+// nameAffixes returns the affix of the kustomization, for all
+// kinds, followed by those for some kinds only.
+func nameAffixes(affix string, affixes []types.NameAffix) []types.NameAffix {
+	var result []types.NameAffix
+	if affix != "" {
+		result = append(result, types.NameAffix{Value: affix})
+	}
+	for _, a := range affixes {
+		if a.Value != "" {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// affixFieldSpecs returns the fieldSpecs, of those for name
+// affixes, restricted to the given Gvks, if any.
+func affixFieldSpecs(fss types.FsSlice, gvks []resid.Gvk) types.FsSlice {
+	if len(gvks) == 0 {
+		return fss
+	}
+	var result types.FsSlice
+	for _, gvk := range gvks {
+		for _, fs := range fss {
+			if g, ok := intersectGvks(fs.Gvk, gvk); ok {
+				fs.Gvk = g
+				result = append(result, fs)
+			}
+		}
+	}
+	return result
+}
+
+// intersectGvks returns the Gvk selecting the resources that
+// both x and y select, if any.
+func intersectGvks(x, y resid.Gvk) (resid.Gvk, bool) {
+	var ok bool
+	result := resid.Gvk{}
+	if result.Group, ok = intersectField(x.Group, y.Group); !ok {
+		return result, false
+	}
+	if result.Version, ok = intersectField(x.Version, y.Version); !ok {
+		return result, false
+	}
+	result.Kind, ok = intersectField(x.Kind, y.Kind)
+	return result, ok
+}
+
+func intersectField(x, y string) (string, bool) {
+	switch {
+	case x == "":
+		return y, true
+	case y == "" || x == y:
+		return x, true
+	default:
+		return "", false
+	}
+}
+
+func gvkFieldSpecs(gvks []resid.Gvk) types.FsSlice {
+	var result types.FsSlice
+	for _, gvk := range gvks {
+		result = append(result, types.FieldSpec{Gvk: gvk})
+	}
+	return result
+}
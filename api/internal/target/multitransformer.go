@@ -4,19 +4,35 @@
 package target
 
 import (
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/builtins"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 )
 
 // multiTransformer contains a list of transformers.
 type multiTransformer struct {
+	// root is the root of the kustomization running them.
+	root string
+	// timings, if not nil, adds up the time they take.
+	timings      *Timings
 	transformers []*resmap.TransformerWithProperties
 }
 
 var _ resmap.Transformer = &multiTransformer{}
 
 // newMultiTransformer constructs a multiTransformer.
-func newMultiTransformer(t []*resmap.TransformerWithProperties) resmap.Transformer {
+func newMultiTransformer(root string, timings *Timings,
+	t []*resmap.TransformerWithProperties) resmap.Transformer {
 	r := &multiTransformer{
+		root:         root,
+		timings:      timings,
 		transformers: make([]*resmap.TransformerWithProperties, len(t)),
 	}
 	copy(r.transformers, t)
@@ -27,8 +43,13 @@ func newMultiTransformer(t []*resmap.TransformerWithProperties) resmap.Transform
 // optionally detecting and erroring on commutation conflict.
 func (o *multiTransformer) Transform(m resmap.ResMap) error {
 	for _, t := range o.transformers {
-		if err := t.Transform(m); err != nil {
-			return err
+		logging.V(3).Info("running transformer",
+			"root", o.root, "transformer", pluginName(t.Transformer, t.Origin))
+		stop := o.timings.start(timingTransformer, pluginName(t.Transformer, t.Origin))
+		err := t.Transform(m)
+		stop()
+		if err != nil {
+			return pluginFailure(err, "transformer", t.Transformer, t.Origin, o.root)
 		}
 		if t.Origin != nil {
 			if err := m.AddTransformerAnnotation(t.Origin); err != nil {
@@ -39,3 +60,55 @@ func (o *multiTransformer) Transform(m resmap.ResMap) error {
 	}
 	return nil
 }
+
+// pluginFailure annotates the error of a generator, transformer
+// or validator with the plugin and kustomization it failed in.
+// Builtins fail on their config, other plugins are classified
+// as plugin failures.
+func pluginFailure(
+	err error, role string, p interface{}, origin *resource.Origin, root string) error {
+	err = errors.WithContext(err, "%s %s of kustomization %s",
+		role, pluginName(p, origin), root)
+	if isBuiltin(p) {
+		return types.WithErrorClass(err, types.ErrorClassConfig)
+	}
+	return types.WithErrorClass(err, types.ErrorClassPlugin)
+}
+
+// builtinPkgPaths are the packages of builtin plugins.
+var builtinPkgPaths = map[string]bool{
+	reflect.TypeOf(builtins.PatchTransformerPlugin{}).PkgPath(): true,
+	reflect.TypeOf(builtinhelpers.MultiTransformer{}).PkgPath(): true,
+}
+
+// isBuiltin returns true if p is a builtin plugin.
+func isBuiltin(p interface{}) bool {
+	if w, ok := p.(*inheritedOnly); ok {
+		p = w.Transformer
+	}
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return builtinPkgPaths[t.PkgPath()]
+}
+
+// pluginName names a generator or transformer in logs, by
+// the kind and name of its config if its origin is tracked,
+// or else by its type, e.g. PatchTransformer for builtins.
+func pluginName(p interface{}, origin *resource.Origin) string {
+	if origin != nil && origin.ConfiguredBy.Kind != "" {
+		return origin.ConfiguredBy.Kind + "/" + origin.ConfiguredBy.Name
+	}
+	if w, ok := p.(*inheritedOnly); ok {
+		p = w.Transformer
+	}
+	t := reflect.TypeOf(p)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name := strings.TrimSuffix(t.Name(), "Plugin"); name != "" {
+		return name
+	}
+	return t.String()
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// overrides holds values given at build time, that replace
+// the values of vars, and of the resource fields that
+// replacements take as their source.
+type overrides struct {
+	// vars maps var names, and the names of the parameters
+	// of conditionals, to their values.
+	vars   map[string]string
+	fields []*fieldOverride
+	// params holds the names used by the conditions of
+	// conditionals.
+	params map[string]bool
+	// mu guards the fields' used flags, and params, as bases
+	// may be built concurrently.
+	mu sync.Mutex
+}
+
+// fieldOverride sets a field of the resources with the
+// given kind and name.
+type fieldOverride struct {
+	key       string
+	kind      string
+	name      string
+	fieldPath []string
+	value     string
+	used      bool
+}
+
+// SetOverrides makes the target, and its bases, use the given
+// values.  A key is either the name of a var, or of a parameter
+// of the conditions of conditionals, or has the form
+// KIND/NAME/FIELDPATH, e.g. ConfigMap/cluster/data.name, naming
+// a resource field.  The field is set in every kustomization,
+// before its replacements run, so replacements that take the
+// field as their source use the value.
+func (kt *KustTarget) SetOverrides(values map[string]string) error {
+	o := &overrides{
+		vars:   make(map[string]string),
+		params: make(map[string]bool),
+	}
+	for key, value := range values {
+		parts := strings.SplitN(key, "/", 3)
+		switch len(parts) {
+		case 1:
+			o.vars[key] = value
+		case 3:
+			if parts[0] == "" || parts[1] == "" || parts[2] == "" {
+				return fmt.Errorf("illegal override %q", key)
+			}
+			o.fields = append(o.fields, &fieldOverride{
+				key:       key,
+				kind:      parts[0],
+				name:      parts[1],
+				fieldPath: utils.SmarterPathSplitter(parts[2], "."),
+				value:     value,
+			})
+		default:
+			return fmt.Errorf(
+				"illegal override %q; expected a var name, or KIND/NAME/FIELDPATH", key)
+		}
+	}
+	kt.overrides = o
+	return nil
+}
+
+// applyFieldOverrides sets the overridden fields of the
+// resources accumulated so far.
+func (kt *KustTarget) applyFieldOverrides(ra *accumulator.ResAccumulator) error {
+	if kt.overrides == nil {
+		return nil
+	}
+	for _, f := range kt.overrides.fields {
+		for _, r := range ra.ResMap().Resources() {
+			if !f.matches(r) {
+				continue
+			}
+			node, err := r.Pipe(yaml.Lookup(f.fieldPath...))
+			if err != nil {
+				return err
+			}
+			if node == nil {
+				return fmt.Errorf(
+					"override %q: %s %s has no such field", f.key, f.kind, f.name)
+			}
+			node.YNode().Value = f.value
+			kt.overrides.mu.Lock()
+			f.used = true
+			kt.overrides.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// matches returns true if the resource has the override's
+// kind, and its name, now or earlier in the build.
+func (f *fieldOverride) matches(r *resource.Resource) bool {
+	if r.GetKind() != f.kind {
+		return false
+	}
+	for _, id := range append(r.PrevIds(), r.CurId()) {
+		if id.Name == f.name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOverridesUsed returns an error naming the overrides
+// that match neither a var, a parameter nor a resource.
+func (kt *KustTarget) checkOverridesUsed(ra *accumulator.ResAccumulator) error {
+	if kt.overrides == nil {
+		return nil
+	}
+	var unused []string
+	for _, f := range kt.overrides.fields {
+		if !f.used {
+			unused = append(unused, f.key)
+		}
+	}
+	declared := make(map[string]bool)
+	for _, v := range ra.Vars() {
+		declared[v.Name] = true
+	}
+	for name := range kt.overrides.vars {
+		if !declared[name] && !kt.overrides.params[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return fmt.Errorf(
+		"overrides match no var, parameter or resource: %s", strings.Join(unused, ", "))
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// pipelineFunctionConfigName names the ConfigMap given as
+// config to functions with a configMap, as kpt names it.
+const pipelineFunctionConfigName = "function-input"
+
+// configurePipelineFunctions returns the functions, of the
+// pipeline of the kustomization, as transformers.
+func (kt *KustTarget) configurePipelineFunctions(
+	fns []types.Function) ([]*resmap.TransformerWithProperties, error) {
+	var result []*resmap.TransformerWithProperties
+	for _, fn := range fns {
+		// Each function is loaded on its own, as their configs
+		// may have the same ids.
+		config, err := kt.pipelineFunctionConfig(fn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "function %s", pipelineFunctionName(fn))
+		}
+		if kt.origin != nil {
+			config.SetOrigin(kt.origin.Append(kt.kustFileName))
+		}
+		ts, err := kt.pLdr.LoadTransformers(
+			kt.ldr, kt.validator, kt.rFactory.FromResource(config))
+		if err != nil {
+			return nil, errors.Wrapf(err, "function %s", pipelineFunctionName(fn))
+		}
+		result = append(result, ts...)
+	}
+	return result, nil
+}
+
+// pipelineFunctionConfig returns the config of the function,
+// that of its configPath, or a ConfigMap holding its
+// configMap, annotated as the config of a function plugin.
+// Unlike resources, configs marked as local config are kept,
+// as kpt packages mark them so.
+func (kt *KustTarget) pipelineFunctionConfig(
+	fn types.Function) (*resource.Resource, error) {
+	var obj map[string]interface{}
+	if fn.ConfigPath != "" {
+		content, err := kt.ldr.Load(fn.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := kt.rFactory.RF().RNodesFromBytes(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(nodes) != 1 {
+			return nil, fmt.Errorf(
+				"expected one config in %s, found %d", fn.ConfigPath, len(nodes))
+		}
+		if obj, err = nodes[0].Map(); err != nil {
+			return nil, err
+		}
+	} else {
+		data := make(map[string]interface{}, len(fn.ConfigMap))
+		for k, v := range fn.ConfigMap {
+			data[k] = v
+		}
+		obj = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": pipelineFunctionConfigName},
+			"data":       data,
+		}
+	}
+	config := kt.rFactory.RF().FromMap(obj)
+	var spec runtimeutil.FunctionSpec
+	if fn.Exec != "" {
+		spec.Exec.Path = fn.Exec
+	} else {
+		spec.Container.Image = fn.Image
+	}
+	specYaml, err := yaml.Marshal(&spec)
+	if err != nil {
+		return nil, err
+	}
+	annotations := config.GetAnnotations()
+	annotations[runtimeutil.FunctionAnnotationKey] = string(specYaml)
+	if err := config.SetAnnotations(annotations); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// pipelineFunctionName returns the name of the function, or
+// its image or executable.
+func pipelineFunctionName(fn types.Function) string {
+	switch {
+	case fn.Name != "":
+		return fn.Name
+	case fn.Image != "":
+		return fn.Image
+	default:
+		return fn.Exec
+	}
+}
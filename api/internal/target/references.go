@@ -0,0 +1,154 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/fieldspec"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// SetCheckReferences fails the build if resources refer by
+// name, in the fields of the nameReference configuration, to
+// resources that are neither in the build nor selected by the
+// externalReferences of the kustomization.
+func (kt *KustTarget) SetCheckReferences(check bool) {
+	kt.checkReferences = check
+}
+
+// reference is a name found in a field of a resource, that
+// refers to a resource of one of several kinds, e.g. both
+// roles and cluster roles for the roleRef of a RoleBinding.
+type reference struct {
+	referrer *resource.Resource
+	path     string
+	name     string
+	gvks     []resid.Gvk
+}
+
+// errorIfDanglingReferences errors if resources refer to
+// others that are neither in the build nor external.
+func (kt *KustTarget) errorIfDanglingReferences(
+	m resmap.ResMap, nbrs []builtinconfig.NameBackReferences) error {
+	var refs []*reference
+	byKey := map[string]*reference{}
+	for _, nbr := range nbrs {
+		for _, fs := range nbr.Referrers {
+			for _, r := range m.Resources() {
+				for _, name := range referencedNames(r, fs, nbr.Gvk) {
+					key := fmt.Sprintf("%p|%s|%s", r, fs.Path, name)
+					ref, ok := byKey[key]
+					if !ok {
+						ref = &reference{referrer: r, path: fs.Path, name: name}
+						byKey[key] = ref
+						refs = append(refs, ref)
+					}
+					ref.gvks = append(ref.gvks, nbr.Gvk)
+				}
+			}
+		}
+	}
+	var msgs []string
+	for _, ref := range refs {
+		if kt.isReferenceResolved(m, ref) {
+			continue
+		}
+		var kinds []string
+		for _, gvk := range ref.gvks {
+			kinds = append(kinds, gvk.Kind)
+		}
+		msgs = append(msgs, fmt.Sprintf("%s %s: %s: %s %s is not in the build",
+			ref.referrer.GetKind(), ref.referrer.GetName(), ref.path,
+			strings.Join(kinds, " or "), ref.name))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("dangling references, "+
+		"which may be declared in externalReferences:\n%s", strings.Join(msgs, "\n"))
+}
+
+// isReferenceResolved returns true if the reference names a
+// resource in the build, or external to it.
+func (kt *KustTarget) isReferenceResolved(m resmap.ResMap, ref *reference) bool {
+	for _, gvk := range ref.gvks {
+		if gvk.Kind == "ServiceAccount" && ref.name == "default" {
+			// Every namespace has one.
+			return true
+		}
+		for _, r := range m.Resources() {
+			rGvk := r.GetGvk()
+			if r.GetName() == ref.name && rGvk.IsSelected(&gvk) {
+				return true
+			}
+		}
+		for _, s := range kt.kustomization.ExternalReferences {
+			sr, err := types.NewSelectorRegex(&s)
+			if err != nil {
+				// Invalid selectors select nothing.
+				continue
+			}
+			if sr.MatchGvk(gvk) && sr.MatchName(ref.name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// referencedNames returns the names that the resource refers
+// to, in the field of the fieldSpec, as resources of the kind
+// of gvk.  The field holds a name, a list of names, or objects
+// with a name and possibly a kind, e.g. the subjects of a
+// RoleBinding.  Names with unresolved vars are left out.
+func referencedNames(r *resource.Resource, fs types.FieldSpec, gvk resid.Gvk) []string {
+	var names []string
+	addName := func(node *yaml.Node) {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if node.Value != "" && !strings.Contains(node.Value, "$(") {
+				names = append(names, node.Value)
+			}
+		case yaml.MappingNode:
+			obj := yaml.NewRNode(node)
+			if kind := obj.Field("kind"); kind != nil &&
+				yaml.GetValue(kind.Value) != gvk.Kind {
+				return
+			}
+			if name := obj.Field("name"); name != nil {
+				value := yaml.GetValue(name.Value)
+				if value != "" && !strings.Contains(value, "$(") {
+					names = append(names, value)
+				}
+			}
+		}
+	}
+	lookup := fs
+	lookup.CreateIfNotPresent = false
+	// Errors are about fields of unexpected types, which
+	// aren't references.
+	_, _ = r.Pipe(fieldspec.Filter{
+		FieldSpec: lookup,
+		SetValue: func(node *yaml.RNode) error {
+			if node.YNode().Kind == yaml.SequenceNode {
+				for _, e := range node.YNode().Content {
+					addName(e)
+				}
+				return nil
+			}
+			addName(node.YNode())
+			return nil
+		},
+	})
+	return names
+}
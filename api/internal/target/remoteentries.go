@@ -0,0 +1,99 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	load "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// fetchedEntry is a resources entry that may be a remote base,
+// fetched before its turn: read as a file, or else cloned.
+type fetchedEntry struct {
+	resources resmap.ResMap
+	errF      error
+	ldr       ifc.Loader
+	err       error
+}
+
+// fetchedEntries are the entries fetched, by position.
+type fetchedEntries map[int]*fetchedEntry
+
+// fetchRemoteEntries fetches the resources entries that may
+// be remote bases, i.e. that look like git repository URLs,
+// as accumulateResources would one after another, but up to
+// git.MaxConcurrentClones at a time.  Nothing is fetched
+// unless there are several of them.
+func (kt *KustTarget) fetchRemoteEntries(paths []string) fetchedEntries {
+	var remote []int
+	for i, entry := range paths {
+		path, _ := resourceEntryPath(entry)
+		if types.IsInlineResource(path) {
+			continue
+		}
+		if _, err := git.NewRepoSpecFromUrl(path); err == nil {
+			remote = append(remote, i)
+		}
+	}
+	if len(remote) < 2 {
+		return nil
+	}
+	result := make(fetchedEntries, len(remote))
+	for _, i := range remote {
+		result[i] = &fetchedEntry{}
+	}
+	forEachConcurrently(git.MaxConcurrentClones, len(remote), func(j int) {
+		e := result[remote[j]]
+		path, _ := resourceEntryPath(paths[remote[j]])
+		e.resources, e.errF = kt.loadFile(path)
+		if e.errF != nil && !errors.Is(e.errF, load.ErrorHTTP) {
+			e.ldr, e.err = kt.newLoader(path)
+		}
+	})
+	return result
+}
+
+// accumulateFile is kt.accumulateFile, with the resources
+// of the i-th entry if they were fetched.
+func (f fetchedEntries) accumulateFile(kt *KustTarget,
+	ra *accumulator.ResAccumulator, i int, path string) error {
+	e, ok := f[i]
+	if !ok {
+		return kt.accumulateFile(ra, path)
+	}
+	if e.errF != nil {
+		return e.errF
+	}
+	if err := ra.AppendAll(e.resources); err != nil {
+		return errors.Wrapf(err, "merging resources from '%s'", path)
+	}
+	return nil
+}
+
+// newLoader is kt.newLoader, with the loader of the i-th
+// entry if it was fetched, which is no longer cleaned up
+// by cleanup.
+func (f fetchedEntries) newLoader(
+	kt *KustTarget, i int, path string) (ifc.Loader, error) {
+	e, ok := f[i]
+	if !ok {
+		return kt.newLoader(path)
+	}
+	delete(f, i)
+	return e.ldr, e.err
+}
+
+// cleanup cleans up the loaders fetched but not used.
+func (f fetchedEntries) cleanup() {
+	for _, e := range f {
+		if e.ldr != nil {
+			e.ldr.Cleanup()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/ifc"
+	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// cloningLoader "clones" the repositories of github.com from
+// /repos, ignoring refs, taking a while, and counts the clones
+// under way.
+type cloningLoader struct {
+	ifc.Loader
+	fSys filesys.FileSystem
+
+	mu      sync.Mutex
+	running int
+	most    int
+}
+
+func (l *cloningLoader) New(path string) (ifc.Loader, error) {
+	repo := strings.TrimPrefix(path, "github.com/")
+	if repo == path {
+		return l.Loader.New(path)
+	}
+	repo = strings.Split(repo, "?")[0]
+	l.mu.Lock()
+	l.running++
+	if l.running > l.most {
+		l.most = l.running
+	}
+	l.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	l.mu.Lock()
+	l.running--
+	l.mu.Unlock()
+	return fLdr.NewLoader(fLdr.RestrictionRootOnly, "/repos/"+repo, l.fSys)
+}
+
+func TestRemoteBasesClonedConcurrently(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- github.com/org/a
+- github.com/org/b
+- service.yaml
+- github.com/org/c
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: local
+`)
+	for _, repo := range []string{"a", "b", "c"} {
+		th.WriteK("/repos/org/"+repo, `
+resources:
+- service.yaml
+`)
+		th.WriteF("/repos/org/"+repo+"/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: `+repo+`
+`)
+	}
+	for name, parallelism := range map[string]int{"serial": 1, "parallel": 4} {
+		t.Run(name, func(t *testing.T) {
+			ldr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, "/app", th.GetFSys())
+			require.NoError(t, err)
+			cloner := &cloningLoader{Loader: ldr, fSys: th.GetFSys()}
+			rf := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory())
+			kt := target.NewKustTarget(
+				cloner,
+				valtest_test.MakeFakeValidator(),
+				rf,
+				pLdr.NewLoader(types.DisabledPluginConfig(), rf, th.GetFSys()))
+			kt.SetParallelism(parallelism)
+			require.NoError(t, kt.Load())
+			m, err := kt.MakeCustomizedResMap()
+			require.NoError(t, err)
+			assert.Equal(t, 3, cloner.most)
+			var names []string
+			for _, r := range m.Resources() {
+				names = append(names, r.GetName())
+			}
+			assert.Equal(t, []string{"a", "b", "local", "c"}, names)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+// isResourcePattern returns true if the resources entry is a
+// glob pattern, rather than a file, directory or URL, or an
+// entry written as an object.  Remote bases may look like
+// patterns, e.g. github.com/org/repo//app?ref=v1.0.6, so
+// entries the loader would clone are never patterns.
+func isResourcePattern(entry string) bool {
+	if !strings.ContainsAny(entry, "*?[") || strings.Contains(entry, "://") ||
+		strings.Contains(entry, "\n") {
+		return false
+	}
+	_, err := git.NewRepoSpecFromUrl(entry)
+	return err != nil
+}
+
+// expandResourcePatterns replaces the glob patterns among the
+// resources entries with the files they match, in the order of
+// their paths.  A "**" element of a pattern matches any number
+// of directories.  Hidden files, kustomization files and
+// fragments, and files listed before, aren't matched.
+func (kt *KustTarget) expandResourcePatterns(entries []string) ([]string, error) {
+	listed := make(map[string]bool)
+	hasPatterns := false
+	for _, entry := range entries {
+		if isResourcePattern(entry) {
+			hasPatterns = true
+		} else {
+			p, _ := resourceEntryPath(entry)
+			listed[path.Clean(p)] = true
+		}
+	}
+	if !hasPatterns {
+		return entries, nil
+	}
+	dl, ok := kt.ldr.(ifc.DirLoader)
+	if !ok {
+		return nil, fmt.Errorf(
+			"resources patterns can't be matched in %s", kt.ldr.Root())
+	}
+	var result []string
+	for _, entry := range entries {
+		if !isResourcePattern(entry) {
+			result = append(result, entry)
+			continue
+		}
+		if _, err := path.Match(entry, ""); err != nil {
+			return nil, fmt.Errorf("resources pattern %q: %w", entry, err)
+		}
+		dir, pattern := "", path.Clean(entry)
+		if path.IsAbs(pattern) {
+			dir, pattern = "/", pattern[1:]
+		}
+		matches, err := matchFiles(dl, dir, strings.Split(pattern, "/"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("resources pattern %q matches no files", entry)
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			if !listed[m] {
+				result = append(result, m)
+				listed[m] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// matchFiles returns the files below dir matching the pattern
+// elements.
+func matchFiles(dl ifc.DirLoader, dir string, elems []string) ([]string, error) {
+	elem, rest := elems[0], elems[1:]
+	if !isResourcePattern(elem) {
+		p := path.Join(dir, elem)
+		if len(rest) == 0 {
+			if dl.IsDir(p) || !isMatchable(elem) {
+				return nil, nil
+			}
+			return []string{p}, nil
+		}
+		if !dl.IsDir(p) {
+			return nil, nil
+		}
+		return matchFiles(dl, p, rest)
+	}
+	names, err := dl.ReadDir(dirOrSelf(dir))
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	if elem == "**" {
+		if len(rest) == 0 {
+			rest = []string{"*"}
+		}
+		// "**" matching no directory.
+		matches, err := matchFiles(dl, dir, rest)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, matches...)
+	}
+	for _, name := range names {
+		if !isMatchable(name) {
+			continue
+		}
+		p := path.Join(dir, name)
+		if elem == "**" {
+			if dl.IsDir(p) {
+				matches, err := matchFiles(dl, p, elems)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, matches...)
+			}
+			continue
+		}
+		if ok, _ := path.Match(elem, name); !ok {
+			continue
+		}
+		switch {
+		case len(rest) > 0 && dl.IsDir(p):
+			matches, err := matchFiles(dl, p, rest)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, matches...)
+		case len(rest) == 0 && !dl.IsDir(p):
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// isMatchable returns true if patterns may match the file or
+// directory name.
+func isMatchable(name string) bool {
+	if strings.HasPrefix(name, ".") || name == konfig.KustomizationFragmentsDir {
+		return false
+	}
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if name == n {
+			return false
+		}
+	}
+	return true
+}
+
+func dirOrSelf(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
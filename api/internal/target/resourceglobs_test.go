@@ -0,0 +1,62 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestRemoteBasesWithRefAreNotPatterns(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- github.com/org/a//prod?ref=v1.0.6
+- service.yaml
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: local
+`)
+	th.WriteK("/repos/org/a/prod", `
+resources:
+- service.yaml
+`)
+	th.WriteF("/repos/org/a/prod/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: remote
+`)
+	ldr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, "/app", th.GetFSys())
+	require.NoError(t, err)
+	cloner := &cloningLoader{Loader: ldr, fSys: th.GetFSys()}
+	rf := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory())
+	kt := target.NewKustTarget(
+		cloner,
+		valtest_test.MakeFakeValidator(),
+		rf,
+		pLdr.NewLoader(types.DisabledPluginConfig(), rf, th.GetFSys()))
+	require.NoError(t, kt.Load())
+	m, err := kt.MakeCustomizedResMap()
+	require.NoError(t, err)
+	assert.Equal(t, 1, cloner.most)
+	var names []string
+	for _, r := range m.Resources() {
+		names = append(names, r.GetName())
+	}
+	assert.Equal(t, []string{"remote", "local"}, names)
+}
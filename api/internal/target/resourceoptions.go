@@ -0,0 +1,115 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// inheritableFields maps the builtin transformers that the
+// resources of a resources entry may be kept from, per its
+// options, to the kustomization field configuring them.
+var inheritableFields = map[builtinhelpers.BuiltinPluginType]string{
+	builtinhelpers.LabelTransformer:       "commonLabels",
+	builtinhelpers.AnnotationsTransformer: "commonAnnotations",
+	builtinhelpers.PrefixTransformer:      "namePrefix",
+	builtinhelpers.SuffixTransformer:      "nameSuffix",
+}
+
+// resourceEntryPath returns the path of a resources entry,
+// and the options of those written as objects.
+func resourceEntryPath(entry string) (string, *types.ResourceOptions) {
+	if e, ok := types.ParseResourceEntry(entry); ok {
+		return e.Path, &e.Options
+	}
+	return entry, nil
+}
+
+// markNotInherited records, on the resources of a resources
+// entry, i.e. those accumulated from index from on, the
+// kustomization fields its options keep off them.
+func markNotInherited(
+	ra *accumulator.ResAccumulator, from int, opts *types.ResourceOptions) error {
+	if opts == nil {
+		return nil
+	}
+	fields := opts.NotInherited()
+	if len(fields) == 0 {
+		return nil
+	}
+	// Resources() would copy the list of all the resources.
+	m := ra.ResMap()
+	for i := from; i < m.Size(); i++ {
+		r := m.GetByIndex(i)
+		annotations := r.GetAnnotations()
+		annotations[utils.BuildAnnotationNotInherited] = strings.Join(fields, ",")
+		if err := r.SetAnnotations(annotations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropNotInherited removes the marks of markNotInherited,
+// once the transformers of the kustomization have run.
+func dropNotInherited(m resmap.ResMap) error {
+	for _, r := range m.Resources() {
+		annotations := r.GetAnnotations()
+		if _, ok := annotations[utils.BuildAnnotationNotInherited]; !ok {
+			continue
+		}
+		delete(annotations, utils.BuildAnnotationNotInherited)
+		if err := r.SetAnnotations(annotations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritedOnly wraps a transformer configured by a field of
+// the kustomization, to skip the resources that don't inherit
+// that field.
+type inheritedOnly struct {
+	resmap.Transformer
+	field string
+}
+
+func (t *inheritedOnly) Transform(m resmap.ResMap) error {
+	inheriting := resmap.New()
+	skipped := false
+	for _, r := range m.Resources() {
+		if isNotInherited(r, t.field) {
+			skipped = true
+			continue
+		}
+		if err := inheriting.Append(r); err != nil {
+			return err
+		}
+	}
+	if !skipped {
+		return t.Transformer.Transform(m)
+	}
+	// The resources are shared, so the changes apply to m.
+	return t.Transformer.Transform(inheriting)
+}
+
+func isNotInherited(r *resource.Resource, field string) bool {
+	fields, ok := r.GetAnnotations()[utils.BuildAnnotationNotInherited]
+	if !ok {
+		return false
+	}
+	for _, f := range strings.Split(fields, ",") {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
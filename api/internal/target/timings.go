@@ -0,0 +1,104 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of timings.
+const (
+	timingBase        = "base"
+	timingComponent   = "component"
+	timingGenerator   = "generator"
+	timingTransformer = "transformer"
+	timingValidator   = "validator"
+	timingRemote      = "remote"
+)
+
+// Timings adds up the time builds spend on each base and
+// component, generator, transformer and validator, and on
+// fetching remote bases and files, to tell what makes them
+// slow.  The time of a base includes that of its own bases,
+// generators and transformers.  Its methods may be called
+// concurrently, and on nil, which times nothing.
+type Timings struct {
+	mu      sync.Mutex
+	entries map[[2]string]*Timing
+}
+
+// Timing is the time spent on something in builds.
+type Timing struct {
+	// Kind is what took the time: a base, component, generator,
+	// transformer, validator, or remote base or file.
+	Kind string
+	// Name is the root of the base or component, the plugin,
+	// named as in logs, or the URL of the remote base or file.
+	Name string
+	// Count is how many times it ran.
+	Count int
+	// Duration is the time it took in all.  Bases built
+	// concurrently add up to more than the time of the build.
+	Duration time.Duration
+}
+
+// NewTimings returns empty Timings.
+func NewTimings() *Timings {
+	return &Timings{entries: make(map[[2]string]*Timing)}
+}
+
+// SetTimings makes the target, and its bases, add the time
+// they spend to the timings.
+func (kt *KustTarget) SetTimings(t *Timings) {
+	kt.timings = t
+}
+
+// start starts timing, and returns the function stopping it.
+func (t *Timings) start(kind, name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		t.add(kind, name, time.Since(start))
+	}
+}
+
+func (t *Timings) add(kind, name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := [2]string{kind, name}
+	e, ok := t.entries[key]
+	if !ok {
+		e = &Timing{Kind: kind, Name: name}
+		t.entries[key] = e
+	}
+	e.Count++
+	e.Duration += d
+}
+
+// List returns the timings, longest first.
+func (t *Timings) List() []Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]Timing, 0, len(t.entries))
+	for _, e := range t.entries {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Duration != result[j].Duration {
+			return result[i].Duration > result[j].Duration
+		}
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
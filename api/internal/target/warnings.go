@@ -0,0 +1,256 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/filters/fieldspec"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// warnings collects the warnings of a build, from the targets
+// of all its kustomizations, which may be built concurrently.
+type warnings struct {
+	mu   sync.Mutex
+	list []types.Warning
+}
+
+func (w *warnings) add(warning types.Warning) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, seen := range w.list {
+		if seen == warning {
+			// e.g. about a base used twice.
+			return
+		}
+	}
+	w.list = append(w.list, warning)
+}
+
+// Warnings returns the warnings of the build so far, ordered
+// by the kustomization file they're about.
+func (kt *KustTarget) Warnings() []types.Warning {
+	kt.warnings.mu.Lock()
+	defer kt.warnings.mu.Unlock()
+	result := append([]types.Warning(nil), kt.warnings.list...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// warn records a warning about the kustomization of the target.
+func (kt *KustTarget) warn(code, format string, args ...interface{}) {
+	w := types.Warning{
+		Code:    code,
+		Path:    filepath.Join(kt.ldr.Root(), kt.kustFileName),
+		Message: fmt.Sprintf(format, args...),
+	}
+	kt.warnings.add(w)
+	kt.record().addWarning(w)
+}
+
+// warnUnmatchedSelectors warns about the patches and replacement
+// targets of the kustomization that select none of its resources.
+// Patches without a target name the resource they patch, and
+// fail the build if it doesn't exist.
+func (kt *KustTarget) warnUnmatchedSelectors(m resmap.ResMap) error {
+	for _, p := range append(kt.kustomization.Patches, kt.kustomization.PatchesJson6902...) {
+		if p.Target == nil || strings.Contains(p.Patch, "$patch: delete") {
+			// Deleted resources are gone.
+			continue
+		}
+		ok, err := selects(m, p.Target)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			name := p.Path
+			if name == "" {
+				name = "inline patch"
+			}
+			kt.warn(types.WarningUnusedPatch,
+				"%s targets %s, which matches no resource", name, describeSelector(p.Target))
+		}
+	}
+	for _, r := range kt.kustomization.Replacements {
+		for _, t := range r.Targets {
+			if t.Select == nil {
+				continue
+			}
+			ok, err := selects(m, t.Select)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				kt.warn(types.WarningUnmatchedSelector,
+					"replacement target %s matches no resource", describeSelector(t.Select))
+			}
+		}
+	}
+	return nil
+}
+
+// warnClusterScopedNamespaces warns about the cluster-scoped
+// resources that have a namespace.
+func (kt *KustTarget) warnClusterScopedNamespaces(m resmap.ResMap) {
+	for _, r := range m.Resources() {
+		if ns := r.GetNamespace(); ns != "" && r.GetGvk().IsClusterScoped() {
+			kt.warn(types.WarningClusterScopedNamespace,
+				"%s %s is cluster-scoped, but has namespace %s",
+				r.GetKind(), r.GetName(), ns)
+		}
+	}
+}
+
+// warnUnusedConfigurations warns about the fieldSpecs of the
+// configurations of the kustomization that match no field of
+// its resources.  FieldSpecs that create their field match any
+// resource of their kind.
+func (kt *KustTarget) warnUnusedConfigurations(m resmap.ResMap) error {
+	for _, path := range kt.kustomization.Configurations {
+		data, err := kt.ldr.Load(path)
+		if err != nil {
+			return err
+		}
+		var c builtinconfig.TransformerConfig
+		if err = k8syaml.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		var nameReferrers types.FsSlice
+		for _, nbr := range c.NameReference {
+			nameReferrers = append(nameReferrers, nbr.Referrers...)
+		}
+		for _, f := range []struct {
+			name  string
+			specs types.FsSlice
+		}{
+			{"namePrefix", c.NamePrefix},
+			{"nameSuffix", c.NameSuffix},
+			{"namespace", c.NameSpace},
+			{"commonLabels", c.CommonLabels},
+			{"commonAnnotations", c.CommonAnnotations},
+			{"nameReference", nameReferrers},
+			{"varReference", c.VarReference},
+			{"images", c.Images},
+			{"replicas", c.Replicas},
+		} {
+			for _, fs := range f.specs {
+				if !hitsField(m, fs) {
+					kt.warn(types.WarningUnusedConfiguration,
+						"%s fieldSpec %s in %s matches no field",
+						f.name, describeFieldSpec(fs), path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hitsField returns true if the fieldSpec matches a field of
+// any resource.
+func hitsField(m resmap.ResMap, fs types.FieldSpec) bool {
+	hit := false
+	for _, r := range m.Resources() {
+		if fs.CreateIfNotPresent && r.GetGvk().IsSelected(&fs.Gvk) {
+			return true
+		}
+		lookup := fs
+		lookup.CreateIfNotPresent = false
+		// Errors are about fields of unexpected types, which
+		// don't count as hits.
+		_, _ = r.Pipe(fieldspec.Filter{
+			FieldSpec: lookup,
+			SetValue: func(*yaml.RNode) error {
+				hit = true
+				return nil
+			},
+		})
+		if hit {
+			return true
+		}
+	}
+	return false
+}
+
+// describeFieldSpec returns e.g. "kind=Deployment path=spec/replicas".
+func describeFieldSpec(fs types.FieldSpec) string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{
+		{"group", fs.Group},
+		{"version", fs.Version},
+		{"kind", fs.Kind},
+		{"path", fs.Path},
+	} {
+		if f.value != "" {
+			parts = append(parts, f.key+"="+f.value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// selects returns true if the selector matches any resource,
+// by its current id or by any id it had earlier in the build.
+func selects(m resmap.ResMap, s *types.Selector) (bool, error) {
+	sr, err := types.NewSelectorRegex(s)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range m.Resources() {
+		idMatched := false
+		for _, id := range append(r.PrevIds(), r.CurId()) {
+			if sr.MatchGvk(id.Gvk) && sr.MatchName(id.Name) &&
+				sr.MatchNamespace(id.EffectiveNamespace()) {
+				idMatched = true
+				break
+			}
+		}
+		if !idMatched {
+			continue
+		}
+		matched, err := r.MatchesLabelSelector(s.LabelSelector)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			continue
+		}
+		matched, err = r.MatchesAnnotationSelector(s.AnnotationSelector)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// describeSelector returns e.g. "kind=Deployment name=app".
+func describeSelector(s *types.Selector) string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{
+		{"group", s.Group},
+		{"version", s.Version},
+		{"kind", s.Kind},
+		{"name", s.Name},
+		{"namespace", s.Namespace},
+		{"labelSelector", s.LabelSelector},
+		{"annotationSelector", s.AnnotationSelector},
+	} {
+		if f.value != "" {
+			parts = append(parts, f.key+"="+f.value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -18,6 +18,15 @@ const (
 	BuildAnnotationAllowNameChange = konfig.ConfigAnnoDomain + "/allowNameChange"
 	BuildAnnotationAllowKindChange = konfig.ConfigAnnoDomain + "/allowKindChange"
 
+	// the following are only for strategic merge patches, to specify
+	// how they merge lists and whether they replace fields of another type
+	BuildAnnotationMergeLists = konfig.ConfigAnnoDomain + "/mergeLists"
+	BuildAnnotationForcePatch = konfig.ConfigAnnoDomain + "/forcePatch"
+
+	// the kustomization fields, e.g. commonLabels, that the resources
+	// of a resources entry don't inherit, per its options
+	BuildAnnotationNotInherited = konfig.ConfigAnnoDomain + "/notInherited"
+
 	// for keeping track of origin and transformer data
 	OriginAnnotationKey      = "config.kubernetes.io/origin"
 	TransformerAnnotationKey = "alpha.config.kubernetes.io/transformations"
@@ -0,0 +1,40 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	oaerrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	oavalidate "k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+)
+
+// DataSource errors if the output of a data source, decoded
+// from JSON, doesn't match the JSON schema, written in JSON
+// or YAML.
+func DataSource(schema []byte, data interface{}) error {
+	var s spec.Schema
+	if err := yaml.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("reading schema: %w", err)
+	}
+	result := oavalidate.NewSchemaValidator(&s, nil, "", strfmt.Default).Validate(data)
+	if result.IsValid() {
+		return nil
+	}
+	var msgs []string
+	for _, err := range result.Errors {
+		msg := err.Error()
+		if v, ok := err.(*oaerrors.Validation); ok {
+			msg = strings.TrimPrefix(strings.Replace(msg, " in "+v.In, "", 1), ".")
+		}
+		msgs = append(msgs, msg)
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("output doesn't match the schema:\n%s", strings.Join(msgs, "\n"))
+}
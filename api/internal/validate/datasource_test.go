@@ -0,0 +1,31 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	schema := []byte(`
+type: object
+required: [host, port]
+properties:
+  host:
+    type: string
+  port:
+    type: integer
+additionalProperties: false
+`)
+	assert.NoError(t, DataSource(schema, map[string]interface{}{
+		"host": "db.example.com", "port": int64(5432)}))
+	assert.EqualError(t, DataSource(schema, map[string]interface{}{
+		"host": "db.example.com", "port": "5432", "user": "web"}),
+		`output doesn't match the schema:
+port must be of type integer: "string"
+user is a forbidden property`)
+	assert.Error(t, DataSource([]byte(`type: [`), nil))
+}
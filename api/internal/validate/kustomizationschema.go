@@ -0,0 +1,213 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	oaerrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	oavalidate "k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// objectEntryFields are the list fields of a kustomization
+// whose entries may be written as objects, rather than as
+// the strings they're read into.
+var objectEntryFields = map[string]bool{
+	"resources":             true,
+	"patchesStrategicMerge": true,
+}
+
+var (
+	schemasOnce sync.Once
+	schemas     map[string]*spec.Schema
+)
+
+// KustomizationSchema returns the schema of the kustomization
+// files of the given apiVersion, generated from the fields of
+// types.Kustomization, or nil if the apiVersion is unknown.
+func KustomizationSchema(apiVersion string) *spec.Schema {
+	schemasOnce.Do(func() {
+		s := schemaOf(reflect.TypeOf(types.Kustomization{}), map[reflect.Type]bool{})
+		for name := range objectEntryFields {
+			p := s.Properties[name]
+			p.Items.Schema.Type = spec.StringOrArray{"string", "object"}
+			s.Properties[name] = p
+		}
+		// Kustomizations and components have the same fields.
+		schemas = map[string]*spec.Schema{
+			types.KustomizationVersion: &s,
+			types.ComponentVersion:     &s,
+		}
+	})
+	return schemas[apiVersion]
+}
+
+// KustomizationFile errors if the content of a kustomization
+// file has fields that are unknown, e.g. misspelled, or whose
+// values are of the wrong type, for its apiVersion.
+// Files of versions that are migrated are checked as files of
+// the current version; files of other groups aren't checked.
+func KustomizationFile(content []byte) error {
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		// Left to the unmarshalling to report.
+		return nil
+	}
+	var k types.Kustomization
+	k.APIVersion, _ = data["apiVersion"].(string)
+	k.Kind, _ = data["kind"].(string)
+	k.FixKustomizationPostUnmarshalling()
+	k.MigrateAPIVersion()
+	s := KustomizationSchema(k.APIVersion)
+	if s == nil {
+		return nil
+	}
+	matchPropertyNames(s, data)
+	result := oavalidate.NewSchemaValidator(s, nil, "", strfmt.Default).Validate(data)
+	if result.IsValid() {
+		return nil
+	}
+	var msgs []string
+	for _, err := range result.Errors {
+		msgs = append(msgs, validationMessage(err))
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("invalid kustomization:\n%s", strings.Join(msgs, "\n"))
+}
+
+// validationMessage returns the message of a schema validation
+// error, naming unknown fields as the decoding of
+// kustomizations does.
+func validationMessage(err error) string {
+	v, ok := err.(*oaerrors.Validation)
+	if !ok {
+		return err.Error()
+	}
+	if v.Code() == oaerrors.UnallowedPropertyCode {
+		return fmt.Sprintf("unknown field %q", strings.TrimPrefix(v.Name+"."+fmt.Sprint(v.Value), "."))
+	}
+	return strings.Replace(v.Error(), " in "+v.In, "", 1)
+}
+
+// matchPropertyNames renames the fields of v, a value decoded
+// from YAML, that match a property of s but for their case, as
+// the decoding of kustomizations ignores the case of fields.
+func matchPropertyNames(s *spec.Schema, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			fv := v[k]
+			if _, ok := s.Properties[k]; !ok {
+				for p := range s.Properties {
+					if strings.EqualFold(p, k) {
+						delete(v, k)
+						k = p
+						v[k] = fv
+						break
+					}
+				}
+			}
+			if ps, ok := s.Properties[k]; ok {
+				matchPropertyNames(&ps, fv)
+			} else if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+				matchPropertyNames(s.AdditionalProperties.Schema, fv)
+			}
+		}
+	case []interface{}:
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for _, ev := range v {
+			matchPropertyNames(s.Items.Schema, ev)
+		}
+	}
+}
+
+// schemaOf returns the schema of the JSON encoding of values of
+// the type t.  Structs don't allow properties they lack.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) spec.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return *spec.StringProperty()
+	case reflect.Bool:
+		return *spec.BoolProperty()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return *spec.Int64Property()
+	case reflect.Float32, reflect.Float64:
+		return *spec.Float64Property()
+	case reflect.Slice, reflect.Array:
+		items := schemaOf(t.Elem(), seen)
+		return *spec.ArrayProperty(&items)
+	case reflect.Map:
+		values := schemaOf(t.Elem(), seen)
+		return *spec.MapProperty(&values)
+	case reflect.Struct:
+		if seen[t] {
+			// Recursive types aren't checked below their first level.
+			return spec.Schema{}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s := spec.Schema{}
+		s.Type = spec.StringOrArray{"object"}
+		s.Properties = map[string]spec.Schema{}
+		s.AdditionalProperties = &spec.SchemaOrBool{Allows: false}
+		addFieldSchemas(&s, t, seen)
+		return s
+	}
+	// e.g. interface{}, allowing any value.
+	return spec.Schema{}
+}
+
+// addFieldSchemas adds the schemas of the fields of the struct
+// type t, including those of inlined structs, to s.
+func addFieldSchemas(s *spec.Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && (f.Anonymous || strings.Contains(tag, ",inline")) {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFieldSchemas(s, ft, seen)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fs := schemaOf(f.Type, seen)
+		// Fields left out when empty may also be written as null.
+		if len(fs.Type) > 0 {
+			fs.Nullable = true
+		}
+		s.Properties[name] = fs
+	}
+}
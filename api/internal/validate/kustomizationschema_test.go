@@ -0,0 +1,112 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/api/internal/validate"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestKustomizationSchema(t *testing.T) {
+	s := KustomizationSchema(types.KustomizationVersion)
+	require.NotNil(t, s)
+	assert.Contains(t, s.Properties, "patchesStrategicMerge")
+	assert.Contains(t, s.Properties, "apiVersion")
+	assert.Equal(t, s, KustomizationSchema(types.ComponentVersion))
+	assert.Nil(t, KustomizationSchema("kustomize.config.k8s.io/v9"))
+}
+
+func TestKustomizationFile(t *testing.T) {
+	testCases := map[string]struct {
+		content  string
+		expected string
+	}{
+		"valid": {
+			content: `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: dev-
+resources:
+- deployment.yaml
+- inline: {apiVersion: v1, kind: ConfigMap, metadata: {name: flags}}
+patchesStrategicMerge:
+- path: patch.yaml
+  mergeLists: append
+configMapGenerator:
+- name: flags
+  literals: [a=b]
+replicas:
+- name: web
+  count: 3
+vars:
+- name: PORT
+  objref: {kind: Service, name: web, apiVersion: v1}
+  fieldref:
+    fieldpath: spec.ports[0].port
+helmCharts:
+- name: minecraft
+  valuesInline:
+    minecraftServer: {eula: true}
+`,
+		},
+		"legacy apiVersion": {
+			content: `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+patchesStrategicMerges: []
+`,
+			expected: "invalid kustomization:\n" +
+				`unknown field "patchesStrategicMerges"`,
+		},
+		"other group": {
+			content: `
+apiVersion: kustomize.io/v1beta1
+patchesStrategicMerges: []
+`,
+		},
+		"unknown field": {
+			content: `
+patchesStrategicMerges:
+- patch.yaml
+`,
+			expected: "invalid kustomization:\n" +
+				`unknown field "patchesStrategicMerges"`,
+		},
+		"unknown nested field": {
+			content: `
+kind: Component
+replicas:
+- name: web
+  cuont: 3
+`,
+			expected: "invalid kustomization:\n" +
+				`unknown field "replicas.cuont"`,
+		},
+		"wrong types": {
+			content: `
+namePrefix: {dev: true}
+replicas:
+- name: web
+  count: three
+`,
+			expected: "invalid kustomization:\n" +
+				"namePrefix must be of type string: \"object\"\n" +
+				"replicas.count must be of type integer: \"string\"",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := KustomizationFile([]byte(tc.content))
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, err.Error())
+		})
+	}
+}
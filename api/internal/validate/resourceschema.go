@@ -0,0 +1,170 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// quantityRef is the definition of resource quantities,
+// which are strings but are often written as numbers.
+const quantityRef = "#/definitions/io.k8s.apimachinery.pkg.api.resource.Quantity"
+
+// Resources errors if resources have fields unknown to the
+// openapi schema of their type in the global schema, e.g.
+// misspelled ones, or values of the wrong type.  Resources
+// of types without a schema, e.g. custom resources, aren't
+// checked.  Each error names the file the resource was read
+// from if it has an origin annotation.
+func Resources(m resmap.ResMap) error {
+	var msgs []string
+	for _, r := range m.Resources() {
+		rs := openapi.SchemaForResourceType(yaml.TypeMeta{
+			APIVersion: r.GetApiVersion(),
+			Kind:       r.GetKind(),
+		})
+		if rs.IsMissingOrNull() {
+			continue
+		}
+		prefix := r.CurId().String()
+		if origin, err := r.GetOrigin(); err == nil && origin != nil && origin.Path != "" {
+			prefix = origin.Path + ": " + prefix
+		}
+		c := &schemaChecker{root: openapi.Schema()}
+		c.check(rs.Schema, r.YNode(), "")
+		for _, e := range c.errs {
+			msgs = append(msgs, prefix+": "+e)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("invalid resources:\n%s", strings.Join(msgs, "\n"))
+}
+
+// schemaChecker collects the errors of a value against a schema.
+type schemaChecker struct {
+	root *spec.Schema
+	errs []string
+}
+
+func (c *schemaChecker) errorf(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "."
+	}
+	c.errs = append(c.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *schemaChecker) check(s *spec.Schema, n *yaml.Node, path string) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	isQuantity := false
+	for s.Ref.String() != "" {
+		isQuantity = s.Ref.String() == quantityRef
+		resolved, err := openapi.Resolve(&s.Ref, c.root)
+		if err != nil {
+			// Left unchecked.
+			return
+		}
+		s = resolved
+	}
+	// Nodes set by transformers may have no tag.
+	tag := n.ShortTag()
+	if tag == yaml.NodeTagNull {
+		return
+	}
+	switch {
+	case s.Type.Contains("object") || len(s.Properties) > 0:
+		c.checkObject(s, n, path)
+	case s.Type.Contains("array"):
+		if n.Kind != yaml.SequenceNode {
+			c.errorf(path, "expected a list, got %s", describe(n))
+			return
+		}
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for i, e := range n.Content {
+			c.check(s.Items.Schema, e, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case s.Type.Contains("string"):
+		switch {
+		case n.Kind != yaml.ScalarNode:
+			c.errorf(path, "expected a string, got %s", describe(n))
+		case tag == yaml.NodeTagString:
+		case tag == yaml.NodeTagInt && s.Format == "int-or-string":
+		case (tag == yaml.NodeTagInt || tag == yaml.NodeTagFloat) && isQuantity:
+		default:
+			c.errorf(path, "expected a string, got %s", describe(n))
+		}
+	case s.Type.Contains("integer"):
+		if tag != yaml.NodeTagInt {
+			c.errorf(path, "expected an integer, got %s", describe(n))
+		}
+	case s.Type.Contains("number"):
+		if tag != yaml.NodeTagInt && tag != yaml.NodeTagFloat {
+			c.errorf(path, "expected a number, got %s", describe(n))
+		}
+	case s.Type.Contains("boolean"):
+		if tag != yaml.NodeTagBool {
+			c.errorf(path, "expected a boolean, got %s", describe(n))
+		}
+	}
+}
+
+func (c *schemaChecker) checkObject(s *spec.Schema, n *yaml.Node, path string) {
+	if n.Kind != yaml.MappingNode {
+		c.errorf(path, "expected an object, got %s", describe(n))
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i].Value, n.Content[i+1]
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if p, ok := s.Properties[key]; ok {
+			c.check(&p, value, fieldPath)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties == nil:
+			if len(s.Properties) > 0 {
+				c.errorf(fieldPath, "unknown field")
+			}
+		case s.AdditionalProperties.Schema != nil:
+			c.check(s.AdditionalProperties.Schema, value, fieldPath)
+		case !s.AdditionalProperties.Allows:
+			c.errorf(fieldPath, "unknown field")
+		}
+	}
+}
+
+// describe returns the kind of value a node holds, for errors.
+func describe(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch n.ShortTag() {
+	case yaml.NodeTagString:
+		return fmt.Sprintf("the string %q", n.Value)
+	case yaml.NodeTagInt, yaml.NodeTagFloat:
+		return "the number " + n.Value
+	case yaml.NodeTagBool:
+		return "the boolean " + n.Value
+	}
+	return n.Value
+}
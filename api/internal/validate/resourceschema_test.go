@@ -0,0 +1,97 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/api/internal/validate"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+func TestResources(t *testing.T) {
+	testCases := map[string]struct {
+		resources string
+		expected  string
+	}{
+		"valid": {
+			resources: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels: null
+spec:
+  ports:
+  - port: 80
+    targetPort: http
+  - port: 443
+    targetPort: 8443
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: quota
+spec:
+  hard:
+    cpu: 2
+    memory: 1.5Gi
+`,
+		},
+		"custom resource": {
+			resources: `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: web
+spec:
+  anything: goes
+`,
+		},
+		"errors": {
+			resources: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: [web]
+spec:
+  ports: 80
+  selectr:
+    app: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  debug: true
+immutable: "yes"
+`,
+			expected: `invalid resources:
+ConfigMap.v1.[noGrp]/config.[noNs]: data.debug: expected a string, got the boolean true
+ConfigMap.v1.[noGrp]/config.[noNs]: immutable: expected a boolean, got the string "yes"
+Service.v1.[noGrp]/web.[noNs]: metadata.labels.app: expected a string, got a list
+Service.v1.[noGrp]/web.[noNs]: spec.ports: expected a list, got the number 80
+Service.v1.[noGrp]/web.[noNs]: spec.selectr: unknown field`,
+		},
+	}
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m, err := resmap.NewFactory(rf).NewResMapFromBytes([]byte(tc.resources))
+			require.NoError(t, err)
+			err = Resources(m)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
@@ -19,6 +19,11 @@ func DefaultKustomizationFileName() string {
 	return RecognizedKustomizationFileNames()[0]
 }
 
+// KustomizationFragmentsDir is the directory, next to a
+// kustomization file, holding fragments of the kustomization
+// that are merged into it.
+const KustomizationFragmentsDir = "kustomization.d"
+
 const (
 	// An environment variable to consult for kustomization
 	// configuration data.  See:
@@ -38,6 +43,11 @@ const (
 	// If a resource has this annotation, kustomize will drop it.
 	IgnoredByKustomizeAnnotation = "config.kubernetes.io/local-config"
 
+	// If a ConfigMap or Secret has this annotation, $(VAR) style
+	// variables are replaced in the values of its data keys listed,
+	// comma separated, in the annotation.  Kustomize drops it.
+	SubstituteKeysAnnotation = "kustomize.config.k8s.io/substitute-keys"
+
 	// Label key that indicates the resources are built from Kustomize
 	ManagedbyLabelKey = "app.kubernetes.io/managed-by"
 
@@ -46,4 +56,46 @@ const (
 
 	// Label key that indicates the resources are validated by a validator
 	ValidatedByLabelKey = "validated-by"
+
+	// If a plugin config has this annotation with the value
+	// PluginProtocolRPC, its exec plugin is run as a long lived
+	// server instead of once per invocation.
+	PluginProtocolAnnotation = "config.kubernetes.io/plugin-protocol"
+
+	// See PluginProtocolAnnotation.
+	PluginProtocolRPC = "rpc"
+
+	// If a plugin config has this annotation, its exec plugin
+	// is killed if a run, or a call of its rpc server, takes
+	// longer than the given duration, e.g. "30s".
+	PluginTimeoutAnnotation = "config.kubernetes.io/plugin-timeout"
+
+	// If a generator config has this annotation, the output of
+	// its exec plugin may be cached, keyed by the config, the
+	// plugin, and the files at the given comma separated paths,
+	// relative to the kustomization root, and below the
+	// directories among them, e.g. "values.yaml,templates".
+	PluginInputsAnnotation = "config.kubernetes.io/plugin-inputs"
+
+	// If a plugin config has this annotation, its exec plugin is
+	// downloaded from the given http(s) URL or oci:// reference
+	// rather than looked up below the plugin home. The download
+	// must match PluginChecksumAnnotation.
+	PluginSourceAnnotation = "config.kubernetes.io/plugin-source"
+
+	// The hex encoded sha256 checksum of a plugin declared with
+	// PluginSourceAnnotation.
+	PluginChecksumAnnotation = "config.kubernetes.io/plugin-sha256"
+
+	// If a transformer config listed in a kustomization has this
+	// annotation, the transformer runs in the given phase: before
+	// the builtin transformers, after them, the default, or last,
+	// once validators ran and, in the kustomization being built,
+	// once names are hashed and vars resolved.
+	TransformerPhaseAnnotation = "config.kubernetes.io/transformer-phase"
+
+	// See TransformerPhaseAnnotation.
+	TransformerPhasePreBuiltin  = "pre-builtin"
+	TransformerPhasePostBuiltin = "post-builtin"
+	TransformerPhaseFinal       = "final"
 )
@@ -16,10 +16,19 @@ const (
 	// Symbol that must be used inside Go plugins.
 	PluginSymbol = "KustomizePlugin"
 
+	// Name of the optional file, in the directory of an exec
+	// or Go plugin, holding the JSON Schema (in JSON or YAML)
+	// that the plugin's config must satisfy.
+	PluginSchemaFileName = "schema.json"
+
 	// Name of environment variable used to set AbsPluginHome.
 	// See that variable for an explanation.
 	KustomizePluginHomeEnv = "KUSTOMIZE_PLUGIN_HOME"
 
+	// Name of environment variable used to set the directory
+	// caching downloaded plugins. See DefaultPluginCacheDir.
+	KustomizePluginCacheEnv = "KUSTOMIZE_PLUGIN_CACHE"
+
 	// Relative path below XDG_CONFIG_HOME/kustomize to find plugins.
 	// e.g. AbsPluginHome = XDG_CONFIG_HOME/kustomize/plugin
 	RelPluginHome = "plugin"
@@ -86,6 +95,20 @@ func DefaultAbsPluginHome(fSys filesys.FileSystem) (string, error) {
 		})
 }
 
+// DefaultPluginCacheDir returns the directory on local disk
+// holding downloaded plugins, $KUSTOMIZE_PLUGIN_CACHE if set,
+// else kustomize/plugin below the user's cache directory.
+func DefaultPluginCacheDir() (string, error) {
+	if dir := os.Getenv(KustomizePluginCacheEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ProgramName, RelPluginHome), nil
+}
+
 // FirstDirThatExistsElseError tests different path functions for
 // existence, returning the first that works, else error if all fail.
 func FirstDirThatExistsElseError(
@@ -0,0 +1,59 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestLegacyAPIVersion(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Kustomization
+namePrefix: dev-
+resources:
+- service.yaml
+components:
+- component
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteF("component/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1
+kind: Component
+commonLabels:
+  app: web
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: web
+  name: dev-web
+spec:
+  selector:
+    app: web
+`)
+}
+
+func TestLegacyAPIVersionOtherGroup(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("kustomization.yaml", `
+apiVersion: kustomize.io/v1beta1
+kind: Kustomization
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	assert.Contains(t, err.Error(),
+		"apiVersion for Kustomization should be kustomize.config.k8s.io/v1beta1")
+}
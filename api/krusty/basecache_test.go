@@ -0,0 +1,142 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/logging"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// writeDiamond writes an app including two overlays of the
+// same base, each of which changes the resources of the base.
+func writeDiamond(th kusttest_test.Harness) {
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteK("dev", `
+namePrefix: dev-
+commonLabels:
+  env: dev
+resources:
+- ../base
+`)
+	th.WriteK("prod", `
+namePrefix: prod-
+commonLabels:
+  env: prod
+resources:
+- ../base
+`)
+	th.WriteK("app", `
+resources:
+- ../dev
+- ../prod
+`)
+}
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	logging.SetOutput(&out)
+	logging.SetVerbosity(2)
+	t.Cleanup(func() {
+		logging.SetOutput(os.Stderr)
+		logging.SetVerbosity(0)
+	})
+	return &out
+}
+
+func TestBaseCacheDiamond(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	out := captureLogs(t)
+	m := th.Run("app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: dev
+  name: dev-web
+spec:
+  selector:
+    matchLabels:
+      env: dev
+  template:
+    metadata:
+      labels:
+        env: dev
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: prod-web
+spec:
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+`)
+	assert.Equal(t, 1,
+		strings.Count(out.String(), "accumulating kustomization root=/base\n"))
+	assert.Contains(t, out.String(), "reusing base base=/base\n")
+}
+
+func TestBaseCacheSharedByBuilds(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	out := captureLogs(t)
+	opts := th.MakeDefaultOptions()
+	opts.BaseCache = krusty.NewBaseCache()
+	k := krusty.MakeKustomizer(&opts)
+	_, err := k.Run(th.GetFSys(), "dev")
+	assert.NoError(t, err)
+	m, err := k.Run(th.GetFSys(), "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, 1,
+		strings.Count(out.String(), "accumulating kustomization root=/base\n"))
+	assert.Equal(t, "prod-web", m.Resources()[0].GetName())
+}
+
+func TestBaseCacheKeyedByBuildMetadata(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	th.WriteK("app", `
+buildMetadata: [originAnnotations]
+resources:
+- ../dev
+`)
+	out := captureLogs(t)
+	opts := th.MakeDefaultOptions()
+	opts.BaseCache = krusty.NewBaseCache()
+	k := krusty.MakeKustomizer(&opts)
+	_, err := k.Run(th.GetFSys(), "dev")
+	assert.NoError(t, err)
+	m, err := k.Run(th.GetFSys(), "app")
+	assert.NoError(t, err)
+	assert.Equal(t, 2,
+		strings.Count(out.String(), "accumulating kustomization root=/base\n"))
+	yml, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Contains(t, string(yml), "path: ../base/deployment.yaml")
+}
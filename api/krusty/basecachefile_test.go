@@ -0,0 +1,184 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// runWithCacheFile builds the path as a new kustomize process
+// would, with a cache file, and returns the output.
+func runWithCacheFile(t *testing.T, th kusttest_test.Harness, path string) string {
+	t.Helper()
+	opts := th.MakeDefaultOptions()
+	opts.CacheFile = "/cache.json"
+	m, err := krusty.MakeKustomizer(&opts).Run(th.GetFSys(), path)
+	require.NoError(t, err)
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	return string(yml)
+}
+
+func TestCacheFileReusesUnchangedBases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	out := captureLogs(t)
+	built := runWithCacheFile(t, th, "app")
+	assert.True(t, th.GetFSys().Exists("/cache.json"))
+	assert.Contains(t, out.String(), "accumulating kustomization root=/base\n")
+
+	out.Reset()
+	assert.Equal(t, built, runWithCacheFile(t, th, "app"))
+	assert.NotContains(t, out.String(), "accumulating kustomization root=/base\n")
+	assert.NotContains(t, out.String(), "accumulating kustomization root=/dev\n")
+	assert.Contains(t, out.String(), "reusing saved base base=/dev file=/cache.json\n")
+	assert.Contains(t, out.String(), "reusing saved base base=/prod file=/cache.json\n")
+}
+
+func TestCacheFileRebuildsChangedBases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	runWithCacheFile(t, th, "app")
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+`)
+	out := captureLogs(t)
+	built := runWithCacheFile(t, th, "app")
+	assert.Equal(t, 1,
+		strings.Count(out.String(), "accumulating kustomization root=/base\n"))
+	assert.Contains(t, out.String(), "accumulating kustomization root=/dev\n")
+	assert.NotContains(t, out.String(), "reusing saved base")
+	assert.Contains(t, built, "name: dev-api")
+	assert.Contains(t, built, "name: prod-api")
+}
+
+func TestCacheFileNewFileInDirectoryListed(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- '*.yaml'
+`)
+	th.WriteF("base/a.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+`)
+	th.WriteK("app", `
+resources:
+- ../base
+`)
+	assert.NotContains(t, runWithCacheFile(t, th, "app"), "name: b")
+	th.WriteF("base/b.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`)
+	assert.Contains(t, runWithCacheFile(t, th, "app"), "name: b")
+}
+
+func TestCacheFileKeepsVarsOfBases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- service.yaml
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: backend
+`)
+	th.WriteF("base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+`)
+	th.WriteK("app", `
+namePrefix: app-
+resources:
+- ../base
+- deployment.yaml
+`)
+	th.WriteF("app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        env:
+        - name: URL
+          value: http://$(SERVICE)
+`)
+	built := runWithCacheFile(t, th, "app")
+	assert.Contains(t, built, "value: http://app-backend")
+	out := captureLogs(t)
+	assert.Equal(t, built, runWithCacheFile(t, th, "app"))
+	assert.Contains(t, out.String(), "reusing saved base base=/base")
+}
+
+func TestCacheFileUnusedWithOverrides(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	opts := th.MakeDefaultOptions()
+	opts.CacheFile = "/cache.json"
+	opts.Overrides = map[string]string{"Deployment/web/metadata.name": "api"}
+	_, err := krusty.MakeKustomizer(&opts).Run(th.GetFSys(), "app")
+	require.NoError(t, err)
+	assert.False(t, th.GetFSys().Exists("/cache.json"))
+}
+
+func TestCacheFileSkipsBasesWithSopsFiles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- secret.sops.yaml
+`)
+	th.WriteF("base/secret.sops.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  password: ENC[hunter2]
+`)
+	th.WriteK("app", `
+resources:
+- ../base
+`)
+	// The harness's file system is in memory, so sops is
+	// written to disk.
+	sops := filepath.Join(t.TempDir(), "sops")
+	require.NoError(t, ioutil.WriteFile(sops, []byte(fakeSopsDotSh), 0700))
+	opts := th.MakeDefaultOptions()
+	opts.CacheFile = "/cache.json"
+	opts.SopsCommand = sops
+	m, err := krusty.MakeKustomizer(&opts).Run(th.GetFSys(), "app")
+	require.NoError(t, err)
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	assert.Contains(t, string(yml), "password: hunter2")
+	if th.GetFSys().Exists("/cache.json") {
+		content, err := th.GetFSys().ReadFile("/cache.json")
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "hunter2")
+	}
+}
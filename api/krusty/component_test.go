@@ -520,7 +520,8 @@ resources:
 `),
 			},
 			runPath:       "compinres",
-			expectedError: "expected kind != 'Component' for path '/comp'",
+			expectedError: "expected kind != 'Component' for path '/comp'; " +
+				"list components under 'components'",
 		},
 		"kustomizations-cannot-be-added-to-components": {
 			input: []FileGen{writeTestBase, writeTestComponent,
@@ -531,8 +532,8 @@ components:
 `),
 			},
 			runPath: "kustincomponents",
-			expectedError: "accumulating components: accumulateDirectory: \"expected kind 'Component' for path " +
-				"'/base' but got 'Kustomization'",
+			expectedError: "accumulating components: accumulateDirectory: expected kind 'Component' for path " +
+				"'/base' but got 'Kustomization'; list kustomizations under 'resources'",
 		},
 		"files-cannot-be-added-to-components-list": {
 			input: []FileGen{writeTestBase,
@@ -0,0 +1,118 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConditionals(th kusttest_test.Harness) {
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`)
+	th.WriteC("monitoring", `
+resources:
+- monitor.yaml
+`)
+	th.WriteF("monitoring/monitor.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: monitor
+`)
+	th.WriteF("overlay/cache.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: cache
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+conditionals:
+- when: cache=true
+  resources:
+  - cache.yaml
+- when: monitoring!=false
+  components:
+  - ../monitoring
+- when: env=prod
+  patches:
+  - patch: |-
+      - op: replace
+        path: /spec/replicas
+        value: 3
+    target:
+      kind: Deployment
+`)
+}
+
+func TestConditionalsDefault(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionals(th)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: monitor
+`)
+}
+
+func TestConditionalsWithParameters(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConditionals(th)
+	opts := th.MakeDefaultOptions()
+	opts.Overrides = map[string]string{
+		"cache":      "true",
+		"monitoring": "false",
+		"env":        "prod",
+	}
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: cache
+`)
+}
+
+func TestConditionalsIllegalCondition(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+conditionals:
+- when: cache
+  resources:
+  - cache.yaml
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	assert.EqualError(t, err,
+		`illegal condition "cache"; expected NAME=VALUE or NAME!=VALUE`)
+}
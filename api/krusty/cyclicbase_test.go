@@ -0,0 +1,61 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	load "sigs.k8s.io/kustomize/api/loader"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestCyclicBases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("a", `
+resources:
+- ../b
+`)
+	th.WriteK("b", `
+resources:
+- ../c
+`)
+	th.WriteK("c", `
+resources:
+- ../a
+`)
+	err := th.RunWithErr("a", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Equal(t,
+		"accumulating resources: cycle detected: "+
+			"candidate root '/a' contains visited root '/a'; "+
+			"inclusion path: /a -> /b -> /c -> /a",
+		err.Error())
+	assert.Equal(t, types.ErrorClassConfig, types.ClassOfError(err))
+}
+
+func TestCyclicBasesThroughComponent(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("app", `
+resources:
+- ../base
+`)
+	th.WriteK("base", `
+components:
+- ../comp
+`)
+	th.WriteC("comp", `
+resources:
+- ../app
+`)
+	options := th.MakeDefaultOptions()
+	options.ParallelBuild = true
+	err := th.RunWithErr("app", options)
+	require.Error(t, err)
+	var cycle *load.CycleError
+	require.ErrorAs(t, err, &cycle)
+	assert.Equal(t, []string{"/app", "/base", "/comp", "/app"}, cycle.Path)
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeReferringResources(th kusttest_test.Harness) {
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      serviceAccountName: default
+      imagePullSecrets:
+      - name: registry
+      containers:
+      - name: web
+        image: nginx
+        env:
+        - name: MODE
+          valueFrom:
+            configMapKeyRef:
+              name: settingz
+              key: mode
+        - name: PORT
+          valueFrom:
+            configMapKeyRef:
+              name: settings
+              key: port
+`)
+	th.WriteF("rbac.yaml", `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: web
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: web
+- kind: User
+  name: jane
+`)
+}
+
+func TestDanglingReferences(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReferringResources(th)
+	th.WriteK(".", `
+namePrefix: dev-
+resources:
+- deployment.yaml
+- rbac.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - port=80
+`)
+	opts := th.MakeDefaultOptions()
+	opts.CheckReferences = true
+	err := th.RunWithErr(".", opts)
+	assert.EqualError(t, err, "dangling references, "+
+		"which may be declared in externalReferences:\n"+
+		"Deployment dev-web: spec/template/spec/containers/env/valueFrom/configMapKeyRef/name: "+
+		"ConfigMap settingz is not in the build\n"+
+		"Deployment dev-web: spec/template/spec/imagePullSecrets/name: "+
+		"Secret registry is not in the build\n"+
+		"RoleBinding dev-web: roleRef/name: Role or ClusterRole view is not in the build\n"+
+		"RoleBinding dev-web: subjects: ServiceAccount web is not in the build")
+}
+
+func TestDanglingReferencesExternal(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReferringResources(th)
+	th.WriteF("serviceaccount.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+`)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+- rbac.yaml
+- serviceaccount.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - port=80
+- name: settingz
+  literals:
+  - mode=prod
+externalReferences:
+- kind: Secret
+  name: registry
+- kind: ClusterRole
+`)
+	opts := th.MakeDefaultOptions()
+	opts.CheckReferences = true
+	th.Run(".", opts)
+
+	// Without the check, references aren't looked at.
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+- rbac.yaml
+`)
+	opts.CheckReferences = false
+	th.Run(".", opts)
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// outputsDotSh prints outputs as terraform output would, once
+// flattened.
+const outputsDotSh = `#!/bin/sh
+echo '{"db_host": "db.example.com", "db_port": 5432, "replicas": 3, "tags": ["a", "b"]}'
+`
+
+func writeDataSourceKustomization(
+	t *testing.T, th kusttest_test.Harness, dir, portType string) {
+	t.Helper()
+	th.WriteK(dir, `
+resources:
+- deployment.yaml
+dataSources:
+- name: outputs
+  command: ./outputs.sh
+  schema: outputs.schema.yaml
+  literals:
+  - env=prod
+- name: credentials
+  kind: Secret
+  command: ./outputs.sh
+  options:
+    disableNameSuffixHash: true
+replacements:
+- source:
+    kind: ConfigMap
+    name: outputs
+    fieldPath: data.db_host
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.template.spec.containers.0.env.0.value
+`)
+	th.WriteF(filepath.Join(dir, "deployment.yaml"), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        env:
+        - name: DB_HOST
+          value: localhost
+`)
+	th.WriteF(filepath.Join(dir, "outputs.schema.yaml"), `
+type: object
+required: [db_host, db_port]
+properties:
+  db_host:
+    type: string
+  db_port:
+    type: `+portType+`
+`)
+	th.WriteF(filepath.Join(dir, "outputs.sh"), outputsDotSh)
+	require.NoError(t, os.Chmod(filepath.Join(dir, "outputs.sh"), 0777))
+}
+
+func TestDataSources(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	writeDataSourceKustomization(t, th, tmpDir.String(), "integer")
+	o := th.MakeDefaultOptions()
+	o.PluginConfig.DataSourceConfig.Enabled = true
+	m := th.Run(tmpDir.String(), o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: DB_HOST
+          value: db.example.com
+        image: web
+        name: web
+---
+apiVersion: v1
+data:
+  db_host: db.example.com
+  db_port: "5432"
+  env: prod
+  replicas: "3"
+  tags: '["a","b"]'
+kind: ConfigMap
+metadata:
+  name: outputs-t45k664g8m
+---
+apiVersion: v1
+data:
+  db_host: ZGIuZXhhbXBsZS5jb20=
+  db_port: NTQzMg==
+  replicas: Mw==
+  tags: WyJhIiwiYiJd
+kind: Secret
+metadata:
+  name: credentials
+type: Opaque
+`)
+}
+
+func TestDataSourcesSchema(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	writeDataSourceKustomization(t, th, tmpDir.String(), "string")
+	o := th.MakeDefaultOptions()
+	o.PluginConfig.DataSourceConfig.Enabled = true
+	err = th.RunWithErr(tmpDir.String(), o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `data source outputs: output doesn't match the schema:
+db_port must be of type string: "number"`)
+}
+
+func TestDataSourcesDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+dataSources:
+- name: outputs
+  command: ./outputs.sh
+`)
+	for _, o := range []krusty.Options{
+		th.MakeDefaultOptions(),
+		// Enabling plugins doesn't enable data sources.
+		th.MakeOptionsPluginsEnabled(),
+	} {
+		err := th.RunWithErr(".", o)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "require data sources to be enabled")
+	}
+}
+
+func TestDataSourcesNotCached(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	dir := tmpDir.String()
+	th.WriteK(dir, `
+dataSources:
+- name: credentials
+  kind: Secret
+  command: ./token.sh
+  options:
+    disableNameSuffixHash: true
+`)
+	// The command's output changes from run to run, as its
+	// file outside the kustomization changes.
+	token := filepath.Join(t.TempDir(), "token")
+	th.WriteF(filepath.Join(dir, "token.sh"), `#!/bin/sh
+printf '{"token": "%s"}' "$(cat `+token+`)"
+`)
+	require.NoError(t, os.Chmod(filepath.Join(dir, "token.sh"), 0777))
+	cacheDir := t.TempDir()
+	o := th.MakeDefaultOptions()
+	o.PluginConfig.DataSourceConfig.Enabled = true
+	o.GeneratorCacheDir = cacheDir
+	for _, value := range []string{"first", "second"} {
+		require.NoError(t, ioutil.WriteFile(token, []byte(value), 0600))
+		m := th.Run(dir, o)
+		data := m.Resources()[0].GetDataMap()
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(value)), data["token"])
+	}
+	entries, err := ioutil.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// apiResourceList is a discovery document, as served at
+// e.g. /apis/apps/v1, or a List of them.
+type apiResourceList struct {
+	Kind         string `json:"kind"`
+	GroupVersion string `json:"groupVersion"`
+	Resources    []struct {
+		Name       string `json:"name"`
+		Kind       string `json:"kind"`
+		Namespaced bool   `json:"namespaced"`
+	} `json:"resources"`
+	Items []apiResourceList `json:"items"`
+}
+
+// discoverScopes returns whether the resource types the
+// discovery option names are namespace scoped, or nil if
+// there's no discovery.
+func (b *Kustomizer) discoverScopes(
+	fSys filesys.FileSystem) (map[yaml.TypeMeta]bool, error) {
+	var data []byte
+	var err error
+	switch b.options.Discovery {
+	case "":
+		return nil, nil
+	case DiscoveryFromCluster:
+		if b.options.FetchAPIResources == nil {
+			return nil, fmt.Errorf(
+				"the API resources of the cluster cannot be fetched in this build")
+		}
+		data, err = b.options.FetchAPIResources()
+	default:
+		data, err = fSys.ReadFile(b.options.Discovery)
+	}
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := parseAPIResources(data)
+	return scopes, errors.Wrapf(err, "discovery %s", b.options.Discovery)
+}
+
+// parseAPIResources parses the output of kubectl api-resources,
+// or discovery documents, e.g. saved with kubectl get --raw.
+func parseAPIResources(data []byte) (map[yaml.TypeMeta]bool, error) {
+	if isAPIResourcesTable(data) {
+		return parseAPIResourcesTable(data)
+	}
+	scopes := make(map[yaml.TypeMeta]bool)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		// Round trip through JSON, to use the json tags.
+		content, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		var list apiResourceList
+		if err = k8syaml.Unmarshal(content, &list); err != nil {
+			return nil, err
+		}
+		if err = addAPIResourceList(scopes, &list); err != nil {
+			return nil, err
+		}
+	}
+	return scopes, nil
+}
+
+func addAPIResourceList(
+	scopes map[yaml.TypeMeta]bool, list *apiResourceList) error {
+	switch list.Kind {
+	case "List":
+		for i := range list.Items {
+			if err := addAPIResourceList(scopes, &list.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "APIResourceList":
+	default:
+		return fmt.Errorf(
+			"expected APIResourceList or List documents, got kind %q", list.Kind)
+	}
+	for _, r := range list.Resources {
+		// Skip subresources, e.g. deployments/scale.
+		if strings.Contains(r.Name, "/") {
+			continue
+		}
+		scopes[yaml.TypeMeta{
+			APIVersion: list.GroupVersion, Kind: r.Kind}] = r.Namespaced
+	}
+	return nil
+}
+
+// isAPIResourcesTable returns true if the data is a table
+// whose last columns are APIVERSION NAMESPACED KIND.
+func isAPIResourcesTable(data []byte) bool {
+	fields := strings.Fields(strings.SplitN(
+		strings.TrimSpace(string(data)), "\n", 2)[0])
+	if len(fields) < 3 {
+		return false
+	}
+	namespaced := fields[len(fields)-2]
+	_, err := strconv.ParseBool(namespaced)
+	return err == nil || namespaced == "NAMESPACED"
+}
+
+func parseAPIResourcesTable(data []byte) (map[yaml.TypeMeta]bool, error) {
+	scopes := make(map[yaml.TypeMeta]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// The SHORTNAMES column may be empty, so
+		// fields are counted from the end.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("unexpected line %q", scanner.Text())
+		}
+		n := len(fields)
+		if fields[n-2] == "NAMESPACED" {
+			continue
+		}
+		namespaced, err := strconv.ParseBool(fields[n-2])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected line %q", scanner.Text())
+		}
+		scopes[yaml.TypeMeta{
+			APIVersion: fields[n-3], Kind: fields[n-1]}] = namespaced
+	}
+	return scopes, scanner.Err()
+}
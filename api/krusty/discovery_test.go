@@ -0,0 +1,139 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const discoveryTable = `NAME                 SHORTNAMES   APIVERSION                 NAMESPACED   KIND
+configmaps           cm           v1                         true         ConfigMap
+certificates         cert,certs   cert-manager.io/v1         true         Certificate
+clusterissuers                    cert-manager.io/v1         false        ClusterIssuer
+`
+
+const discoveryDocuments = `
+kind: APIResourceList
+apiVersion: v1
+groupVersion: cert-manager.io/v1
+resources:
+- name: certificates
+  kind: Certificate
+  namespaced: true
+- name: certificates/status
+  kind: Certificate
+  namespaced: true
+- name: clusterissuers
+  kind: ClusterIssuer
+  namespaced: false
+`
+
+func writeDiscoveryKustomization(th kusttest_test.Harness) {
+	th.WriteK(".", `
+namespace: prod
+resources:
+- resources.yaml
+`)
+	th.WriteF("resources.yaml", `
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: letsencrypt
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+`)
+}
+
+func TestDiscovery(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiscoveryKustomization(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: letsencrypt
+  namespace: prod
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+  namespace: prod
+`)
+
+	for name, dump := range map[string]string{
+		"table":     discoveryTable,
+		"documents": discoveryDocuments,
+	} {
+		t.Run(name, func(t *testing.T) {
+			th.WriteF("discovery", dump)
+			o := th.MakeDefaultOptions()
+			o.Discovery = "discovery"
+			m := th.Run(".", o)
+			th.AssertActualEqualsExpected(m, `
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: letsencrypt
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+  namespace: prod
+`)
+		})
+	}
+}
+
+func TestDiscoveryFromCluster(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiscoveryKustomization(th)
+	o := th.MakeDefaultOptions()
+	o.Discovery = krusty.DiscoveryFromCluster
+	err := th.RunWithErr(".", o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be fetched in this build")
+
+	o.FetchAPIResources = func() ([]byte, error) {
+		return []byte(discoveryTable), nil
+	}
+	m := th.Run(".", o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: letsencrypt
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+  namespace: prod
+`)
+}
+
+func TestDiscoveryErrors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiscoveryKustomization(th)
+	th.WriteF("discovery", `
+kind: ConfigMap
+apiVersion: v1
+`)
+	o := th.MakeDefaultOptions()
+	o.Discovery = "discovery"
+	err := th.RunWithErr(".", o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		`discovery discovery: expected APIResourceList or List documents, got kind "ConfigMap"`)
+}
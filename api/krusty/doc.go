@@ -8,4 +8,8 @@
 // To use, follow the example of the kustomize CLI's 'build'
 // command.  Also, see the high level tests in this package,
 // which serve a dual purpose as examples.
+//
+// The options of this package follow the flags of the CLI, so
+// change from release to release.  Programs that need a stable
+// API should use package sigs.k8s.io/kustomize/api/kustomize.
 package krusty
@@ -0,0 +1,47 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+func TestErrorChain(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+`)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	assert.Error(t, err)
+	chain := errors.Chain(err)
+	assert.Contains(t, chain, "recursed accumulation of path '/base'")
+	assert.Contains(t, chain, "in transformer PatchTransformer of kustomization /base")
+	assert.Contains(t, chain, "in resource Deployment.v1.apps/web.[noNs]")
+	assert.Equal(t, "missing value", chain[len(chain)-1])
+	// The message is still that of the whole chain.
+	assert.Contains(t, err.Error(),
+		"replace operation does not apply: doc is missing path: /spec/replicas: missing value")
+}
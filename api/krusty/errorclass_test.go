@@ -0,0 +1,82 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestErrorClass(t *testing.T) {
+	testCases := map[string]struct {
+		files    map[string]string
+		expected types.ErrorClass
+	}{
+		"missing base": {
+			files: map[string]string{
+				"overlay/kustomization.yaml": `
+resources:
+- ../base
+`,
+			},
+			expected: types.ErrorClassMissingFile,
+		},
+		"missing kustomization": {
+			files: map[string]string{
+				"overlay/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`,
+			},
+			expected: types.ErrorClassMissingFile,
+		},
+		"unknown field": {
+			files: map[string]string{
+				"overlay/kustomization.yaml": `
+resourcez:
+- deployment.yaml
+`,
+			},
+			expected: types.ErrorClassConfig,
+		},
+		"failing builtin transformer": {
+			files: map[string]string{
+				"overlay/kustomization.yaml": `
+resources:
+- deployment.yaml
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+`,
+				"overlay/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`,
+			},
+			expected: types.ErrorClassConfig,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			for path, content := range tc.files {
+				th.WriteF(path, content)
+			}
+			err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+			assert.Error(t, err)
+			assert.Equal(t, tc.expected, types.ClassOfError(err), err.Error())
+		})
+	}
+}
@@ -213,6 +213,67 @@ spec:
 	assert.NoError(t, fSys.RemoveAll(tmpDir.String()))
 }
 
+func TestFnStarlarkTransformerInBase(t *testing.T) {
+	fSys := filesys.MakeFsOnDisk()
+
+	th := kusttest_test.MakeHarnessWithFs(t, fSys)
+	o := th.MakeOptionsPluginsEnabled()
+
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	assert.NoError(t, err)
+	base := filepath.Join(tmpDir.String(), "base")
+	overlay := filepath.Join(tmpDir.String(), "overlay")
+	assert.NoError(t, fSys.MkdirAll(base))
+	assert.NoError(t, fSys.MkdirAll(overlay))
+	th.WriteK(base, `
+resources:
+- configmap.yaml
+transformers:
+- annotate.yaml
+`)
+	th.WriteF(filepath.Join(base, "configmap.yaml"), `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  a: b
+`)
+	th.WriteF(filepath.Join(base, "annotate.yaml"), `
+apiVersion: example.com/v1
+kind: Annotator
+metadata:
+  name: annotate
+starlark:
+  path: annotate.star
+`)
+	th.WriteF(filepath.Join(base, "annotate.star"), `
+def run(items):
+  for item in items:
+    item["metadata"]["annotations"]["owner"] = "base"
+
+run(ctx.resource_list["items"])
+`)
+	th.WriteK(overlay, `
+resources:
+- ../base
+namePrefix: dev-
+`)
+
+	m := th.Run(overlay, o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  a: b
+kind: ConfigMap
+metadata:
+  annotations:
+    owner: base
+  name: dev-cm
+`)
+	assert.NoError(t, fSys.RemoveAll(tmpDir.String()))
+}
+
 func skipIfNoDocker(t *testing.T) {
 	if _, err := exec.LookPath("docker"); err != nil {
 		t.Skip("skipping because docker binary wasn't found in PATH")
@@ -585,6 +646,46 @@ metadata:
 `)
 }
 
+func TestFnContainerTransformerWithContainerField(t *testing.T) {
+	skipIfNoDocker(t)
+
+	th := kusttest_test.MakeHarness(t)
+
+	th.WriteK(".", `
+resources:
+- data1.yaml
+transformers:
+- label_namespace.yaml
+`)
+
+	th.WriteF("data1.yaml", `apiVersion: v1
+kind: Namespace
+metadata:
+  name: my-namespace
+`)
+
+	th.WriteF("label_namespace.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: label_namespace
+container:
+  image: gcr.io/kpt-functions/label-namespace@sha256:4f030738d6d25a207641ca517916431517578bd0eb8d98a8bde04e3bb9315dcd
+data:
+  label_name: my-ns-name
+  label_value: function-test
+`)
+
+	m := th.Run(".", th.MakeOptionsPluginsEnabled())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    my-ns-name: function-test
+  name: my-namespace
+`)
+}
+
 func TestFnContainerEnvVars(t *testing.T) {
 	skipIfNoDocker(t)
 
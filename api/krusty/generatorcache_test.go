@@ -0,0 +1,123 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/konfig"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestGeneratorCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script plugin")
+	}
+	// The generator counts its runs in a file.
+	runs := filepath.Join(t.TempDir(), "runs")
+	generator := "#!/bin/sh\necho run >> " + runs + "\n" +
+		strings.TrimPrefix(remoteGenerator, "#!/bin/sh\n")
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(generator))
+		}))
+	defer srv.Close()
+	os.Setenv(konfig.KustomizePluginCacheEnv, t.TempDir())
+	defer os.Unsetenv(konfig.KustomizePluginCacheEnv)
+	sum := sha256.Sum256([]byte(generator))
+	countRuns := func() int {
+		content, err := ioutil.ReadFile(runs)
+		if os.IsNotExist(err) {
+			return 0
+		}
+		require.NoError(t, err)
+		return strings.Count(string(content), "run")
+	}
+
+	th := kusttest_test.MakeHarness(t)
+	// writeApp writes a kustomization using the generator, which
+	// declares the inputs directory, to dir.
+	writeApp := func(dir, inputs string) {
+		th.WriteK(dir, `
+generators:
+- config.yaml
+`)
+		th.WriteF(filepath.Join(dir, "config.yaml"), `
+apiVersion: someteam.example.com/v1
+kind: Greeter
+metadata:
+  name: greeter
+  annotations:
+    config.kubernetes.io/plugin-source: `+srv.URL+`/greeter
+    config.kubernetes.io/plugin-sha256: `+hex.EncodeToString(sum[:])+`
+`+inputs)
+		th.WriteF(filepath.Join(dir, "inputs", "greeting.txt"), "hello")
+	}
+	const declared = "    config.kubernetes.io/plugin-inputs: inputs\n"
+	writeApp("a", declared)
+	opts := th.MakeOptionsPluginsEnabled()
+	opts.GeneratorCacheDir = t.TempDir()
+	expected := `
+apiVersion: v1
+data:
+  greeting: hello
+kind: ConfigMap
+metadata:
+  name: from-remote-plugin
+`
+	m := th.Run("a", opts)
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 1, countRuns())
+
+	m = th.Run("a", opts)
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 1, countRuns(), "expected a cache hit")
+
+	// Files that aren't declared inputs don't matter.
+	th.WriteF("a/notes.txt", "changed")
+	m = th.Run("a", opts)
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 1, countRuns(), "expected a cache hit")
+
+	// Changing a declared input invalidates the entry.
+	th.WriteF("a/inputs/greeting.txt", "changed")
+	m = th.Run("a", opts)
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 2, countRuns())
+
+	// The same kustomization elsewhere hits the same entry.
+	writeApp("b", declared)
+	th.WriteF("b/inputs/greeting.txt", "changed")
+	m = th.Run("b", opts)
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 2, countRuns(), "expected a cache hit")
+
+	opts.RefreshGeneratorCache = true
+	m = th.Run("a", opts)
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 3, countRuns())
+
+	// Without a cache directory, the generator always runs.
+	m = th.Run("a", th.MakeOptionsPluginsEnabled())
+	th.AssertActualEqualsExpected(m, expected)
+	assert.Equal(t, 4, countRuns())
+
+	// Nor is it cached without declared inputs.
+	opts.RefreshGeneratorCache = false
+	writeApp("c", "")
+	th.Run("c", opts)
+	th.Run("c", opts)
+	assert.Equal(t, 6, countRuns())
+}
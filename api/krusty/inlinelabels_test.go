@@ -91,3 +91,45 @@ spec:
     c: d
 `)
 }
+
+func TestKustomizationLabelsIncludeTemplates(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+
+labels:
+- pairs:
+    foo: bar
+  includeTemplates: true
+`)
+	th.WriteF("/app/deployment.yaml", resources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    foo: bar
+  name: my-deployment
+spec:
+  template:
+    metadata:
+      labels:
+        foo: bar
+    spec:
+      containers:
+      - livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 8080
+        name: my-deployment
+---
+apiVersion: example.dev/v1
+kind: MyCRD
+metadata:
+  labels:
+    foo: bar
+  name: crd
+`)
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestInlineResource(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namePrefix: dev-
+resources:
+- inline:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: flags
+    data:
+      debug: "true"
+- service.yaml
+- inline: {apiVersion: v1, kind: Namespace, metadata: {name: web}}
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  debug: "true"
+kind: ConfigMap
+metadata:
+  name: dev-flags
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: dev-web
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`)
+}
+
+func TestInlineResourceWithOrigin(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+buildMetadata: [originAnnotations]
+resources:
+- inline:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: flags
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    config.kubernetes.io/origin: |
+      path: kustomization.yaml
+  name: flags
+`)
+}
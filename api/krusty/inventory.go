@@ -0,0 +1,122 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// addInventory appends to the resources an inventory object
+// recording them, in the formats of cli-utils.
+func (b *Kustomizer) addInventory(m resmap.ResMap, inv *types.Inventory) error {
+	var objects []inventoryObject
+	for _, r := range m.Resources() {
+		objects = append(objects, inventoryObject{
+			Group:     r.GetGvk().Group,
+			Kind:      r.GetKind(),
+			Name:      r.GetName(),
+			Namespace: r.GetNamespace(),
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].key() < objects[j].key()
+	})
+	n := inv.NameArgs()
+	metadata := map[string]interface{}{
+		"name": n.Name,
+		"labels": map[string]interface{}{
+			types.InventoryIdLabel: inventoryId(inv),
+		},
+	}
+	if n.Namespace != "" {
+		metadata["namespace"] = n.Namespace
+	}
+	var obj map[string]interface{}
+	switch inv.Type {
+	case "", types.InventoryTypeConfigMap:
+		data := make(map[string]interface{}, len(objects))
+		for _, o := range objects {
+			data[o.key()] = ""
+		}
+		obj = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   metadata,
+			"data":       data,
+		}
+	case types.InventoryTypeResourceGroup:
+		resources := make([]interface{}, len(objects))
+		for i, o := range objects {
+			resources[i] = o.asMap()
+		}
+		obj = map[string]interface{}{
+			"apiVersion": "kpt.dev/v1alpha1",
+			"kind":       "ResourceGroup",
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"resources": resources,
+			},
+		}
+	default:
+		return fmt.Errorf(
+			"unknown inventory type %q; expected %s or %s",
+			inv.Type, types.InventoryTypeConfigMap, types.InventoryTypeResourceGroup)
+	}
+	return m.Append(b.depProvider.GetResourceFactory().FromMap(obj))
+}
+
+// inventoryId returns the id of the inventory, or one derived
+// from the namespace and name of its object, so that it's the
+// same in all builds.
+func inventoryId(inv *types.Inventory) string {
+	if inv.Id != "" {
+		return inv.Id
+	}
+	n := inv.NameArgs()
+	sum := sha256.Sum256([]byte(n.Namespace + "/" + n.Name))
+	return hex.EncodeToString(sum[:16])
+}
+
+// inventoryObject is an object recorded by an inventory.
+type inventoryObject struct {
+	Group     string
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// key returns the object as cli-utils names it in ConfigMap
+// inventories, i.e. namespace_name_group_kind, with the colons
+// of the name, e.g. of RBAC objects, written as "__".
+func (o inventoryObject) key() string {
+	return strings.Join([]string{
+		o.Namespace, strings.ReplaceAll(o.Name, ":", "__"), o.Group, o.Kind,
+	}, "_")
+}
+
+// asMap returns the object as a ResourceGroup lists it.
+func (o inventoryObject) asMap() map[string]interface{} {
+	return map[string]interface{}{
+		"group":     o.Group,
+		"kind":      o.Kind,
+		"name":      o.Name,
+		"namespace": o.Namespace,
+	}
+}
+
+// inventory returns the inventory of the build, that of the
+// options if any, else that of the kustomization.
+func (b *Kustomizer) inventory(k types.Kustomization) *types.Inventory {
+	if b.options.Inventory != nil {
+		return b.options.Inventory
+	}
+	return k.Inventory
+}
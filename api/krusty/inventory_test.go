@@ -0,0 +1,137 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeInventoryResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:web
+`)
+}
+
+func TestInventoryConfigMap(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+inventory:
+  configMap:
+    name: inventory
+    namespace: apps
+`)
+	writeInventoryResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:web
+---
+apiVersion: v1
+data:
+  _system__web_rbac.authorization.k8s.io_ClusterRole: ""
+  apps_web_apps_Deployment: ""
+kind: ConfigMap
+metadata:
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: 07da415b5a805c60937ac084dbe45206
+  name: inventory
+  namespace: apps
+`)
+}
+
+func TestInventoryResourceGroupOption(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+inventory:
+  configMap:
+    name: inventory
+`)
+	writeInventoryResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.Inventory = &types.Inventory{
+		Type: types.InventoryTypeResourceGroup,
+		ResourceGroup: types.NameArgs{
+			Name:      "web",
+			Namespace: "apps",
+		},
+		Id: "web-app",
+	}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:web
+---
+apiVersion: kpt.dev/v1alpha1
+kind: ResourceGroup
+metadata:
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: web-app
+  name: web
+  namespace: apps
+spec:
+  resources:
+  - group: rbac.authorization.k8s.io
+    kind: ClusterRole
+    name: system:web
+    namespace: ""
+  - group: apps
+    kind: Deployment
+    name: web
+    namespace: apps
+`)
+}
+
+func TestInventoryUnknownType(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+inventory:
+  type: Secret
+  configMap:
+    name: inventory
+`)
+	writeInventoryResources(th)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		`unknown inventory.type "Secret"; expected ConfigMap or ResourceGroup`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
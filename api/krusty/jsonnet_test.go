@@ -0,0 +1,115 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// fakeJsonnet returns a command standing in for jsonnet,
+// evaluating files of plain JSON, which are valid jsonnet.
+func fakeJsonnet(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake jsonnet command is a shell script")
+	}
+	command := filepath.Join(t.TempDir(), "jsonnet")
+	require.NoError(t, os.WriteFile(
+		command, []byte("#!/bin/sh\nexec cat\n"), 0700))
+	return command
+}
+
+func TestJsonnetResources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namePrefix: prod-
+resources:
+- service.yaml
+- web.jsonnet
+jsonnet:
+  importPaths:
+  - lib
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteF("web.jsonnet", `{
+  "service": [],
+  "deployment": {
+    "apiVersion": "apps/v1",
+    "kind": "Deployment",
+    "metadata": {"name": "web"}
+  },
+  "config": [
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "web"}}
+  ]
+}`)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.JsonnetConfig.Enabled = true
+	opts.PluginConfig.JsonnetConfig.Command = fakeJsonnet(t)
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: prod-web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod-web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+`)
+}
+
+func TestJsonnetResourcesDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- web.jsonnet
+`)
+	th.WriteF("web.jsonnet", `{}`)
+	for _, opts := range []krusty.Options{
+		th.MakeDefaultOptions(),
+		// Enabling plugins doesn't enable jsonnet.
+		th.MakeOptionsPluginsEnabled(),
+	} {
+		err := th.RunWithErr(".", opts)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(
+			err.Error(), "evaluating 'web.jsonnet' requires jsonnet to be enabled"))
+	}
+}
+
+func TestJsonnetResourcesNotObjects(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- web.jsonnet
+`)
+	th.WriteF("web.jsonnet", `{"replicas": 3}`)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.JsonnetConfig.Enabled = true
+	opts.PluginConfig.JsonnetConfig.Command = fakeJsonnet(t)
+	err := th.RunWithErr(".", opts)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(
+		err.Error(), "expected objects or arrays of them, got 3"))
+}
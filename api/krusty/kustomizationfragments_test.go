@@ -0,0 +1,104 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestKustomizationFragments(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("app", `
+namePrefix: dev-
+resources:
+- deployment.yaml
+`)
+	th.WriteF("app/kustomization.d/20-service.yaml", `
+resources:
+- service.yaml
+commonLabels:
+  team: web
+`)
+	th.WriteF("app/kustomization.d/10-config.yaml", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+configMapGenerator:
+- name: config
+  literals:
+  - mode=dev
+`)
+	th.WriteF("app/kustomization.d/README.md", `
+Fragments of the kustomization, one per team.
+`)
+	th.WriteF("app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteF("app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	m := th.Run("app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: web
+  name: dev-web
+spec:
+  selector:
+    matchLabels:
+      team: web
+  template:
+    metadata:
+      labels:
+        team: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: web
+  name: dev-web
+spec:
+  selector:
+    team: web
+---
+apiVersion: v1
+data:
+  mode: dev
+kind: ConfigMap
+metadata:
+  labels:
+    team: web
+  name: dev-config-t2hmhtdth5
+`)
+}
+
+func TestKustomizationFragmentsConflict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("app", `
+namePrefix: dev-
+`)
+	th.WriteF("app/kustomization.d/prefix.yaml", `
+namePrefix: prod-
+`)
+	err := th.RunWithErr("app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"merging kustomization fragment kustomization.d/prefix.yaml: "+
+			"namePrefix is set in more than one file") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
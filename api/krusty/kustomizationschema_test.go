@@ -0,0 +1,61 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeMisspelledKustomization(th kusttest_test.Harness) {
+	th.WriteF("base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("overlay", `
+namePrefix: dev-
+resources:
+- ../base
+`)
+	th.WriteK("base", `
+resources:
+- service.yaml
+patchesStrategicMerges:
+- patch.yaml
+commonLabels:
+  app: web
+`)
+}
+
+func TestKustomizationUnknownField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMisspelledKustomization(th)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	assert.Contains(t, err.Error(),
+		"couldn't make target for path '/base': invalid kustomization:\n"+
+			`unknown field "patchesStrategicMerges"`)
+}
+
+func TestKustomizationUnknownFieldLenient(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMisspelledKustomization(th)
+	opts := th.MakeDefaultOptions()
+	opts.Lenient = true
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: web
+  name: dev-web
+spec:
+  selector:
+    app: web
+`)
+}
@@ -5,20 +5,28 @@ package krusty
 
 import (
 	"fmt"
-	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
 	"sigs.k8s.io/kustomize/api/internal/builtins"
 	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/plugins/rpcplugin"
 	"sigs.k8s.io/kustomize/api/internal/target"
 	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/internal/validate"
 	"sigs.k8s.io/kustomize/api/konfig"
 	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/api/provenance"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/openapi/kubernetesapi"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 // Kustomizer performs kustomizations.
@@ -52,6 +60,29 @@ func MakeKustomizer(o *Options) *Kustomizer {
 // of internal paths (e.g. the filesystem may contain multiple overlays,
 // and Run can be called on each of them).
 func (b *Kustomizer) Run(
+	fSys filesys.FileSystem, path string) (resmap.ResMap, error) {
+	logging.V(1).Info("building kustomization", "path", path)
+	// Plugin servers started by the build are stopped after it.
+	release := rpcplugin.Acquire()
+	defer release()
+	m, err := b.run(fSys, path)
+	if err != nil {
+		logging.V(1).Info("build failed", "path", path, "error", err)
+		return nil, err
+	}
+	logging.V(1).Info("built kustomization", "path", path, "resources", m.Size())
+	return m, nil
+}
+
+// KeepPluginServers keeps the servers of rpc plugins, which are
+// otherwise stopped as each Run finishes, running across Runs until
+// the returned func is called, e.g. by a watch rebuilding the same
+// kustomization.
+func KeepPluginServers() (release func()) {
+	return rpcplugin.Acquire()
+}
+
+func (b *Kustomizer) run(
 	fSys filesys.FileSystem, path string) (resmap.ResMap, error) {
 	resmapFactory := resmap.NewFactory(b.depProvider.GetResourceFactory())
 	lr := fLdr.RestrictionNone
@@ -63,6 +94,9 @@ func (b *Kustomizer) Run(
 		return nil, err
 	}
 	defer ldr.Cleanup()
+	if b.options.SopsCommand != "" {
+		ldr = fLdr.NewSopsLoader(ldr, b.options.SopsCommand)
+	}
 	kt := target.NewKustTarget(
 		ldr,
 		b.depProvider.GetFieldValidator(),
@@ -70,14 +104,27 @@ func (b *Kustomizer) Run(
 		// The plugin configs are always located on disk, regardless of the fSys passed in
 		pLdr.NewLoader(b.options.PluginConfig, resmapFactory, filesys.MakeFsOnDisk()),
 	)
+	kt.SetLenient(b.options.Lenient)
+	kt.SetTrackOrigins(b.options.trackOrigins())
 	err = kt.Load()
 	if err != nil {
 		return nil, err
 	}
-	var bytes []byte
-	if openApiPath, exists := kt.Kustomization().OpenAPI["path"]; exists {
-		bytes, err = ldr.Load(filepath.Join(ldr.Root(), openApiPath))
-		if err != nil {
+	var fetchSchema func() ([]byte, error)
+	if b.options.FetchOpenAPISchema != nil {
+		fetchSchema = fetchOnce(b.options.FetchOpenAPISchema)
+		kt.SetOpenAPIFetcher(fetchSchema)
+	}
+	bytes, err := kt.OpenAPISchema()
+	if err != nil {
+		return nil, err
+	}
+	if b.options.SchemaFromCluster && len(kt.Kustomization().OpenAPI) == 0 {
+		if fetchSchema == nil {
+			return nil, fmt.Errorf(
+				"the openapi schema of the cluster cannot be fetched in this build")
+		}
+		if bytes, err = fetchSchema(); err != nil {
 			return nil, err
 		}
 	}
@@ -85,14 +132,66 @@ func (b *Kustomizer) Run(
 	if err != nil {
 		return nil, err
 	}
+	scopes, err := b.discoverScopes(fSys)
+	if err != nil {
+		return nil, err
+	}
+	openapi.SetDiscoveredScopes(scopes)
+	if b.options.ParallelBuild {
+		if err = pinSchema(); err != nil {
+			return nil, err
+		}
+		kt.SetParallelism(runtime.GOMAXPROCS(0))
+	}
+	if b.options.GeneratorCacheDir != "" {
+		kt.SetGeneratorCache(
+			b.options.GeneratorCacheDir, b.options.RefreshGeneratorCache, fSys)
+	}
+	cache := b.options.BaseCache
+	if cache == nil {
+		cache = NewBaseCache()
+	}
+	if b.options.CacheFile != "" {
+		err = cache.UseFile(fSys, b.options.CacheFile, b.options.cacheFileOptions())
+		if err != nil {
+			return nil, err
+		}
+	}
+	kt.SetBaseCache(cache)
+	kt.SetTimings(b.options.Timings)
+	kt.SetNamespace(b.options.Namespace)
+	if len(b.options.Input) > 0 {
+		input, err := resmapFactory.NewResMapFromBytes(b.options.Input)
+		if err != nil {
+			return nil, err
+		}
+		kt.SetInput(input)
+	}
+	kt.SetCheckReferences(b.options.CheckReferences)
+	kt.SetImages(b.options.Images)
+	if len(b.options.Overrides) > 0 {
+		if err = kt.SetOverrides(b.options.Overrides); err != nil {
+			return nil, err
+		}
+	}
 	var m resmap.ResMap
 	m, err = kt.MakeCustomizedResMap()
 	if err != nil {
 		return nil, err
 	}
-	if b.options.DoLegacyResourceSort {
-		err = builtins.NewLegacyOrderTransformerPlugin().Transform(m)
-		if err != nil {
+	if err = cache.SaveFile(); err != nil {
+		return nil, err
+	}
+	sortOptions := kt.Kustomization().SortOptions
+	if b.options.SortOptions != nil {
+		sortOptions = b.options.SortOptions
+	}
+	err = b.sort(m, sortOptions)
+	if err != nil {
+		return nil, err
+	}
+	if inv := b.inventory(kt.Kustomization()); inv != nil {
+		if err = b.addInventory(m, inv); err != nil {
 			return nil, err
 		}
 	}
@@ -111,7 +210,26 @@ func (b *Kustomizer) Run(
 			return nil, err
 		}
 	}
-	m.RemoveBuildAnnotations()
+	if b.options.Validate != "" {
+		if err = b.validate(m, fetchSchema); err != nil {
+			return nil, err
+		}
+	}
+	if err = b.warn(kt.Warnings()); err != nil {
+		return nil, err
+	}
+	if !b.options.KeepBuildAnnotations {
+		m.RemoveBuildAnnotations()
+	}
+	if b.options.Origins != nil {
+		for _, r := range m.Resources() {
+			origin, err := r.GetOrigin()
+			if err != nil {
+				return nil, err
+			}
+			b.options.Origins[r.CurId()] = origin
+		}
+	}
 	if !utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.OriginAnnotations) {
 		m.RemoveOriginAnnotations()
 	}
@@ -120,3 +238,182 @@ func (b *Kustomizer) Run(
 	}
 	return m, nil
 }
+
+// fetchOnce returns a function calling fetch the first time
+// it's called, and returning the same result after that.
+func fetchOnce(fetch func() ([]byte, error)) func() ([]byte, error) {
+	var once sync.Once
+	var schema []byte
+	var err error
+	return func() ([]byte, error) {
+		once.Do(func() {
+			schema, err = fetch()
+		})
+		return schema, err
+	}
+}
+
+// sort orders the resources per the sortOptions of the
+// kustomization, if any, or else per DoLegacyResourceSort.
+func (b *Kustomizer) sort(m resmap.ResMap, opts *types.SortOptions) error {
+	if opts == nil {
+		if !b.options.DoLegacyResourceSort {
+			return nil
+		}
+		return builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+	}
+	switch opts.Order {
+	case types.LegacySortOrder:
+		t := builtins.LegacyOrderTransformerPlugin{}
+		if lo := opts.LegacySortOptions; lo != nil {
+			t.OrderFirst = lo.OrderFirst
+			t.OrderLast = lo.OrderLast
+		}
+		return t.Transform(m)
+	case types.NameSortOrder:
+		return sortResources(m, func(a, b resid.ResId) bool {
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.Gvk.IsLessThan(b.Gvk)
+		})
+	case types.NamespaceSortOrder:
+		return sortResources(m, func(a, b resid.ResId) bool {
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			if !a.Gvk.Equals(b.Gvk) {
+				return a.Gvk.IsLessThan(b.Gvk)
+			}
+			return a.Name < b.Name
+		})
+	default:
+		return nil
+	}
+}
+
+// sortResources orders the resources of the ResMap by their
+// current ids, keeping the order of those with equal ids.
+func sortResources(m resmap.ResMap, less func(a, b resid.ResId) bool) error {
+	resources := m.Resources()
+	sort.SliceStable(resources, func(i, j int) bool {
+		return less(resources[i].CurId(), resources[j].CurId())
+	})
+	sorted, err := resmap.NewFromResources(resources)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	return m.AppendAll(sorted)
+}
+
+// warn reports the warnings of a build, or fails it with
+// them if it's strict.
+func (b *Kustomizer) warn(warnings []types.Warning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	if b.options.Strict {
+		var msgs []string
+		for _, w := range warnings {
+			msgs = append(msgs, w.String())
+		}
+		return fmt.Errorf("build has %d warnings, and is strict:\n%s",
+			len(warnings), strings.Join(msgs, "\n"))
+	}
+	for _, w := range warnings {
+		if b.options.Warn != nil {
+			b.options.Warn(w)
+		} else {
+			logging.Warning(w.Message, "path", w.Path, "code", w.Code)
+		}
+	}
+	return nil
+}
+
+// validate checks the resources against the openapi schema
+// that the Validate option names.
+func (b *Kustomizer) validate(
+	m resmap.ResMap, fetchSchema func() ([]byte, error)) error {
+	v := b.options.Validate
+	switch {
+	case v == ValidateWithBuildSchema:
+	case v == ValidateWithClusterSchema:
+		if fetchSchema == nil {
+			return fmt.Errorf(
+				"the openapi schema of the cluster cannot be fetched in this build")
+		}
+		schema, err := fetchSchema()
+		if err != nil {
+			return err
+		}
+		if err = openapi.SetSchema(nil, schema, true); err != nil {
+			return err
+		}
+		defer restoreDefaultSchema()
+	case strings.HasPrefix(v, validateK8sPrefix):
+		version, err := bundledVersion(strings.TrimPrefix(v, validateK8sPrefix))
+		if err != nil {
+			return err
+		}
+		err = openapi.SetSchema(map[string]string{"version": version}, nil, true)
+		if err != nil {
+			return err
+		}
+		defer restoreDefaultSchema()
+	default:
+		return fmt.Errorf(
+			"unknown validate option %q; expected %s, %s or %sVERSION",
+			v, ValidateWithBuildSchema, ValidateWithClusterSchema, validateK8sPrefix)
+	}
+	return validate.Resources(m)
+}
+
+// bundledVersion returns the bundled openapi schema version
+// of the given kubernetes version, which may leave out its
+// patch version, e.g. 1.21 for v1212.
+func bundledVersion(k8sVersion string) (string, error) {
+	prefix := "v" + strings.ReplaceAll(k8sVersion, ".", "")
+	var matches, versions []string
+	for version := range kubernetesapi.OpenAPIMustAsset {
+		versions = append(versions, version)
+		if strings.HasPrefix(version, prefix) {
+			matches = append(matches, version)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	sort.Strings(versions)
+	return "", fmt.Errorf(
+		"kubernetes %s matches no single bundled openapi schema; bundled versions are %s",
+		k8sVersion, strings.Join(versions, ", "))
+}
+
+// restoreDefaultSchema sets the global openapi schema back to
+// the default one, after validating against another one, as
+// builds only set the schema their kustomization asks for.
+func restoreDefaultSchema() {
+	_ = openapi.SetSchema(
+		map[string]string{"version": kubernetesapi.DefaultOpenAPI}, nil, true)
+}
+
+// pinSchema fixes the global openapi schema, so that bases
+// built concurrently can neither change it nor race to
+// initialize it.
+func pinSchema() error {
+	if openapi.GetSchemaVersion() == kubernetesapi.DefaultOpenAPI {
+		// Nothing was set; make the default explicit,
+		// so that bases can't override it.
+		err := openapi.SetSchema(
+			map[string]string{"version": kubernetesapi.DefaultOpenAPI}, nil, true)
+		if err != nil {
+			return err
+		}
+	}
+	openapi.Schema()
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/logging"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestBuildLogging(t *testing.T) {
+	var out bytes.Buffer
+	logging.SetOutput(&out)
+	logging.SetVerbosity(3)
+	defer func() {
+		logging.SetOutput(os.Stderr)
+		logging.SetVerbosity(0)
+	}()
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- missing.yaml
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+namePrefix: dev-
+`)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	assert.Error(t, err)
+	assert.Contains(t, out.String(), `building kustomization path=overlay
+accumulating kustomization root=/overlay
+accumulating base root=/overlay base=/base
+accumulating kustomization root=/base
+base failed root=/overlay base=/base error=`)
+
+	out.Reset()
+	th.WriteF("base/missing.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+	th.Run("overlay", th.MakeDefaultOptions())
+	assert.Contains(t, out.String(), `accumulating base root=/overlay base=/base
+accumulating kustomization root=/base
+running transformer root=/overlay transformer=PrefixTransformer
+`)
+	assert.Contains(t, out.String(), "built kustomization path=overlay resources=1\n")
+}
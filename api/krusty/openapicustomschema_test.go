@@ -257,6 +257,95 @@ openapi:
 		err.Error())
 }
 
+func TestCustomOpenApiFieldFromCluster(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- mycrd.yaml
+openapi:
+  source: cluster
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+openapi:
+  source: cluster
+`+customSchemaPatch)
+	writeCustomResource(th, "base/mycrd.yaml")
+	openapi.ResetOpenAPI()
+	fetches := 0
+	opts := th.MakeDefaultOptions()
+	opts.FetchOpenAPISchema = func() ([]byte, error) {
+		fetches++
+		return ioutil.ReadFile("testdata/customschema.json")
+	}
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, patchedCustomResource)
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, "using custom schema from file provided",
+		openapi.GetSchemaVersion())
+}
+
+func TestCustomOpenApiFieldFromClusterErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		openapi string
+		err     string
+	}{
+		"unknown source": {
+			openapi: "source: server",
+			err:     `unknown openapi source "server"; expected cluster`,
+		},
+		"source and path": {
+			openapi: "source: cluster\n  path: mycrd_schema.json",
+			err:     "openapi source and path provided, cannot use both",
+		},
+		"no fetcher": {
+			openapi: "source: cluster",
+			err:     "the openapi schema of the cluster cannot be fetched in this build",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			th.WriteK(".", `
+resources:
+- mycrd.yaml
+openapi:
+  `+tc.openapi+`
+`)
+			writeCustomResource(th, "mycrd.yaml")
+			openapi.ResetOpenAPI()
+			err := th.RunWithErr(".", th.MakeDefaultOptions())
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestSchemaFromCluster(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- mycrd.yaml
+`+customSchemaPatch)
+	writeCustomResource(th, "mycrd.yaml")
+	openapi.ResetOpenAPI()
+	opts := th.MakeDefaultOptions()
+	opts.SchemaFromCluster = true
+	err := th.RunWithErr(".", opts)
+	assert.EqualError(t, err,
+		"the openapi schema of the cluster cannot be fetched in this build")
+
+	fetches := 0
+	opts.FetchOpenAPISchema = func() ([]byte, error) {
+		fetches++
+		return ioutil.ReadFile("testdata/customschema.json")
+	}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, patchedCustomResource)
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, "using custom schema from file provided",
+		openapi.GetSchemaVersion())
+}
+
 func TestCustomOpenApiFieldFromBase(t *testing.T) {
 	th := kusttest_test.MakeHarness(t)
 	th.WriteK("base", `
@@ -453,3 +542,144 @@ spec:
         name: cm
 `)
 }
+
+// Lists of custom resources are merged as their structural
+// schema's list type says, as are those of builtin types with
+// a patch strategy.
+func TestCustomOpenApiFieldListTypes(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- gateway.yaml
+openapi:
+  path: gateway_schema.json
+patches:
+- patch: |-
+    apiVersion: example.com/v1
+    kind: Gateway
+    metadata:
+      name: gw
+    spec:
+      listeners:
+      - port: 443
+        protocol: HTTPS
+      - port: 8080
+        protocol: HTTP
+      hosts:
+      - b.example.com
+      - c.example.com
+      rules:
+      - path: /new
+`)
+	th.WriteF("gateway.yaml", `
+apiVersion: example.com/v1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  listeners:
+  - port: 80
+    protocol: HTTP
+  - port: 443
+    protocol: TLS
+  hosts:
+  - a.example.com
+  - b.example.com
+  rules:
+  - path: /old
+`)
+	th.WriteF("gateway_schema.json", `{
+  "definitions": {
+    "com.example.v1.Gateway": {
+      "properties": {
+        "spec": {
+          "properties": {
+            "listeners": {
+              "items": {"type": "object"},
+              "type": "array",
+              "x-kubernetes-list-map-keys": ["port"],
+              "x-kubernetes-list-type": "map"
+            },
+            "hosts": {
+              "items": {"type": "string"},
+              "type": "array",
+              "x-kubernetes-list-type": "set"
+            },
+            "rules": {
+              "items": {"type": "object"},
+              "type": "array",
+              "x-kubernetes-list-type": "atomic"
+            }
+          },
+          "type": "object"
+        }
+      },
+      "type": "object",
+      "x-kubernetes-group-version-kind": [
+        {"group": "example.com", "kind": "Gateway", "version": "v1"}
+      ]
+    }
+  }
+}`)
+	openapi.ResetOpenAPI()
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: example.com/v1
+kind: Gateway
+metadata:
+  name: gw
+spec:
+  hosts:
+  - b.example.com
+  - c.example.com
+  - a.example.com
+  listeners:
+  - port: 443
+    protocol: HTTPS
+  - port: 8080
+    protocol: HTTP
+  - port: 80
+    protocol: HTTP
+  rules:
+  - path: /new
+`)
+}
+
+// Lists of builtin types without a patch strategy are replaced,
+// as with kubectl, whatever their list type.
+func TestBuiltinOpenApiFieldListTypes(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- csidriver.yaml
+patches:
+- patch: |-
+    apiVersion: storage.k8s.io/v1
+    kind: CSIDriver
+    metadata:
+      name: driver
+    spec:
+      volumeLifecycleModes:
+      - Ephemeral
+`)
+	th.WriteF("csidriver.yaml", `
+apiVersion: storage.k8s.io/v1
+kind: CSIDriver
+metadata:
+  name: driver
+spec:
+  volumeLifecycleModes:
+  - Persistent
+`)
+	openapi.ResetOpenAPI()
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: storage.k8s.io/v1
+kind: CSIDriver
+metadata:
+  name: driver
+spec:
+  volumeLifecycleModes:
+  - Ephemeral
+`)
+}
@@ -4,8 +4,15 @@
 package krusty
 
 import (
+	"encoding/json"
+
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	"sigs.k8s.io/kustomize/api/provenance"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 // Options holds high-level kustomize configuration options,
@@ -16,8 +23,14 @@ type Options struct {
 	// per a particular sort order.  When false, don't do the
 	// sort, and instead respect the depth-first resource input
 	// order as specified by the kustomization file(s).
+	// Ignored if the kustomization has sortOptions.
 	DoLegacyResourceSort bool
 
+	// If not nil, the resources are sorted per these options,
+	// rather than per the sortOptions of the kustomization, or
+	// DoLegacyResourceSort.
+	SortOptions *types.SortOptions
+
 	// When true, a label
 	//     app.kubernetes.io/managed-by: kustomize-<version>
 	// is added to all the resources in the build out.
@@ -27,11 +40,196 @@ type Options struct {
 	// See type definition.
 	LoadRestrictions types.LoadRestrictions
 
+	// If not empty, the sops command that decrypts files named
+	// e.g. secrets.sops.yaml as they're loaded, so that they can
+	// be resources, patches or generator inputs.
+	SopsCommand string
+
 	// Create an inventory object for pruning.
 	DoPrune bool
 
+	// If not nil, an inventory object recording the resources
+	// of the build is appended to them, per this rather than
+	// the inventory of the kustomization.
+	Inventory *types.Inventory
+
 	// Options related to kustomize plugins.
 	PluginConfig *types.PluginConfig
+
+	// When true, the files and bases of a kustomization's
+	// resources are read and built concurrently, up to
+	// GOMAXPROCS at a time.  The output is the same as that
+	// of a serial build, but bases may not set the openapi
+	// field, only the top kustomization.
+	ParallelBuild bool
+
+	// If not nil, the bases built are kept in this cache, and
+	// bases found in it are copied rather than built again, so
+	// that kustomizers building several kustomizations in turn
+	// share them.  Otherwise each build has a cache of its own,
+	// for bases included several times, e.g. in a diamond.
+	// Kustomizers sharing a cache must have the same options,
+	// and build from files that don't change meanwhile.
+	BaseCache *BaseCache
+
+	// If not empty, the bases built are also kept in this file,
+	// with hashes of the files their build read, so that later
+	// builds, e.g. by other kustomize processes, take the bases
+	// whose files are unchanged from it, rather than building
+	// them again.  Bases that read remote files, or run exec,
+	// function or helm plugins, aren't kept, and builds with
+	// Overrides don't use the file.
+	CacheFile string
+
+	// When true, the fields of kustomization files that are
+	// unknown, e.g. misspelled, are ignored, rather than
+	// failing the build.
+	Lenient bool
+
+	// If not empty, the output of helm chart inflation and exec
+	// generators is cached in this directory, keyed by the
+	// generator config and the input files it declares; see
+	// konfig.PluginInputsAnnotation.
+	GeneratorCacheDir string
+
+	// When true, cached generator output is ignored and replaced.
+	RefreshGeneratorCache bool
+
+	// When true, the build annotations recording the previous
+	// names, namespaces and kinds of resources are left in the
+	// output, for tools like linters that need them.
+	KeepBuildAnnotations bool
+
+	// Values that override those of vars, keyed by var name,
+	// and of the resource fields replacements take as their
+	// source, keyed by KIND/NAME/FIELDPATH.
+	Overrides map[string]string
+
+	// If not empty, all namespaced resources are put in this
+	// namespace, as if by a kustomization including the one
+	// being built, and setting its namespace field.
+	Namespace string
+
+	// Images set in the kustomization being built, replacing
+	// those of the same name, as if by 'kustomize edit set
+	// image'.  A new name, new tag or digest of "*" keeps
+	// that of the image replaced.
+	Images []types.Image
+
+	// If not nil, returns the openapi schema of the cluster,
+	// for kustomizations whose openapi field has source
+	// cluster.  It's called at most once per build.
+	FetchOpenAPISchema func() ([]byte, error)
+
+	// If true, the schema FetchOpenAPISchema returns, i.e. that
+	// of the cluster, with its custom resources, is used to merge
+	// patches and such, unless the openapi field of the
+	// kustomization names another schema.
+	SchemaFromCluster bool
+
+	// If not empty, whether resource types, e.g. custom
+	// resources, are namespace scoped is taken from discovery
+	// rather than the openapi data, so that the namespace of the
+	// build isn't set on cluster scoped custom resources:
+	// DiscoveryFromCluster for the API resources that
+	// FetchAPIResources returns, or the path of a file holding
+	// them, as kubectl api-resources prints them or as
+	// APIResourceList documents.
+	Discovery string
+
+	// If not nil, returns the API resources of the cluster,
+	// for the DiscoveryFromCluster discovery.
+	FetchAPIResources func() ([]byte, error)
+
+	// If not nil, the time the build spends on each base and
+	// component, generator, transformer and validator, and on
+	// fetching remote bases and files, is added to Timings.
+	Timings *Timings
+
+	// If not nil, called with each warning of the build, e.g.
+	// about deprecated fields, rather than logging it.
+	Warn func(types.Warning)
+
+	// When true, a build with warnings fails, with an error
+	// listing them.
+	Strict bool
+
+	// When true, the build fails if resources refer by name,
+	// e.g. in a configMapKeyRef, to resources that are neither
+	// in the build nor in the externalReferences of the
+	// kustomization.
+	CheckReferences bool
+
+	// If not empty, the output is checked against an openapi
+	// schema, failing the build if resources have unknown
+	// fields or values of the wrong type: ValidateWithBuildSchema
+	// for the schema of the build, ValidateWithClusterSchema for
+	// the one FetchOpenAPISchema returns, or "k8s-" followed by
+	// a bundled kubernetes version, e.g. k8s-1.21.2.
+	Validate string
+
+	// If not empty, YAML resources, e.g. read from stdin, that
+	// the kustomization being built takes before its own, as if
+	// they were the first of its resources, e.g. to run kustomize
+	// as a helm post-renderer.
+	Input []byte
+
+	// If not nil, filled with the origin of each resource of the
+	// output, by id, as if the kustomization asked for origin
+	// annotations, whether or not it does.
+	Origins map[resid.ResId]*resource.Origin
+}
+
+const (
+	// ValidateWithBuildSchema checks the output against the
+	// schema the build uses, per the openapi field.
+	ValidateWithBuildSchema = "build"
+
+	// ValidateWithClusterSchema checks the output against the
+	// schema of the cluster.
+	ValidateWithClusterSchema = "cluster"
+
+	// DiscoveryFromCluster takes the scopes of resource types
+	// from the cluster.
+	DiscoveryFromCluster = "cluster"
+
+	// validateK8sPrefix prefixes the bundled kubernetes versions
+	// whose schema the output can be checked against.
+	validateK8sPrefix = "k8s-"
+)
+
+// cacheFileOptions identifies the options bases are built
+// with, and the kustomize version, so that bases kept in a
+// cache file by builds with other options aren't reused.
+func (o *Options) cacheFileOptions() string {
+	data, _ := json.Marshal(struct {
+		Version          string
+		LoadRestrictions types.LoadRestrictions
+		DecryptSops      bool
+		Discovery        string
+		ClusterSchema    bool
+		PluginConfig     *types.PluginConfig
+		ParallelBuild    bool
+		Lenient          bool
+		TrackOrigins     bool
+	}{
+		Version:          provenance.GetProvenance().Full(),
+		LoadRestrictions: o.LoadRestrictions,
+		DecryptSops:      o.SopsCommand != "",
+		Discovery:        o.Discovery,
+		ClusterSchema:    o.SchemaFromCluster,
+		PluginConfig:     o.PluginConfig,
+		ParallelBuild:    o.ParallelBuild,
+		Lenient:          o.Lenient,
+		TrackOrigins:     o.trackOrigins(),
+	})
+	return string(data)
+}
+
+// trackOrigins returns true if the build must track origins,
+// whether or not the kustomization asks for origin annotations.
+func (o *Options) trackOrigins() bool {
+	return o.Validate != "" || o.Origins != nil
 }
 
 // MakeDefaultOptions returns a default instance of Options.
@@ -56,3 +254,39 @@ func GetBuiltinPluginNames() []string {
 	}
 	return ret
 }
+
+// NewBuiltinPlugin returns a new instance of the builtin
+// generator or transformer with the given name, e.g.
+// PatchTransformer, or nil if there's no such builtin.
+// Its exported fields hold its config.
+func NewBuiltinPlugin(name string) resmap.Configurable {
+	t := builtinhelpers.GetBuiltinPluginType(name)
+	if f, ok := builtinhelpers.GeneratorFactories[t]; ok {
+		return f()
+	}
+	if f, ok := builtinhelpers.TransformerFactories[t]; ok {
+		return f()
+	}
+	return nil
+}
+
+// BaseCache holds the bases built by kustomizers sharing it,
+// see Options.BaseCache.
+type BaseCache = target.BaseCache
+
+// NewBaseCache returns an empty BaseCache.
+func NewBaseCache() *BaseCache {
+	return target.NewBaseCache()
+}
+
+// Timings adds up the time spent by the builds of kustomizers
+// sharing it, see Options.Timings.
+type Timings = target.Timings
+
+// Timing is the time spent on something in builds.
+type Timing = target.Timing
+
+// NewTimings returns empty Timings.
+func NewTimings() *Timings {
+	return target.NewTimings()
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/api/internal/utils"
 	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func TestAnnoOriginLocalFiles(t *testing.T) {
@@ -1143,3 +1146,54 @@ spec:
 `, string(yml))
 	assert.NoError(t, fSys.RemoveAll(tmpDir.String()))
 }
+
+func TestOriginsOption(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteK("app", `
+resources:
+- ../base
+configMapGenerator:
+- name: settings
+  literals:
+  - mode=dev
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Origins = make(map[resid.ResId]*resource.Origin)
+	m := th.Run("app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+data:
+  mode: dev
+kind: ConfigMap
+metadata:
+  name: settings-t2hmhtdth5
+`)
+	resources := m.Resources()
+	assert.Equal(t, map[resid.ResId]*resource.Origin{
+		resources[0].CurId(): {Path: "../base/deployment.yaml"},
+		resources[1].CurId(): {
+			ConfiguredIn: "kustomization.yaml",
+			ConfiguredBy: yaml.ResourceIdentifier{
+				TypeMeta: yaml.TypeMeta{
+					APIVersion: "builtin",
+					Kind:       "ConfigMapGenerator",
+				},
+			},
+		},
+	}, opts.Origins)
+}
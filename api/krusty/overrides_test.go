@@ -0,0 +1,114 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestOverrides(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: cluster
+  literals:
+  - name=dev
+vars:
+- name: IMAGE_TAG
+  objref:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: app
+  fieldref:
+    fieldpath: metadata.annotations.tag
+replacements:
+- source:
+    kind: ConfigMap
+    name: cluster
+    fieldPath: data.name
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - metadata.labels.cluster
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    tag: v1
+  labels:
+    cluster: unknown
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        env:
+        - name: TAG
+          value: $(IMAGE_TAG)
+`)
+	th.WriteK("overlay", `
+namePrefix: prod-
+resources:
+- ../base
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Overrides = map[string]string{
+		"IMAGE_TAG":                   "v2",
+		"ConfigMap/cluster/data.name": "prod-eu",
+	}
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    tag: v1
+  labels:
+    cluster: prod-eu
+  name: prod-app
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: TAG
+          value: v2
+        image: app
+        name: app
+---
+apiVersion: v1
+data:
+  name: prod-eu
+kind: ConfigMap
+metadata:
+  name: prod-cluster-887k5h8gb9
+`)
+
+	for erMsg, overrides := range map[string]map[string]string{
+		"overrides match no var, parameter or resource: NO_SUCH_VAR": {
+			"NO_SUCH_VAR": "x"},
+		"overrides match no var, parameter or resource: Secret/cluster/data.name": {
+			"Secret/cluster/data.name": "x"},
+		"ConfigMap cluster has no such field": {
+			"ConfigMap/cluster/data.region": "x"},
+		"illegal override": {
+			"ConfigMap/data.name": "x"},
+	} {
+		opts.Overrides = overrides
+		err := th.RunWithErr("overlay", opts)
+		if err == nil || !strings.Contains(err.Error(), erMsg) {
+			t.Fatalf("expected error %q, but got %v", erMsg, err)
+		}
+	}
+}
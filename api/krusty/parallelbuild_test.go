@@ -0,0 +1,197 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+// writeParallelBuildTree writes an overlay with several bases,
+// each of which has a base of its own, mixed with plain files.
+func writeParallelBuildTree(th kusttest_test.Harness) {
+	th.WriteK("overlay", `
+namePrefix: o-
+buildMetadata: [originAnnotations]
+resources:
+- first.yaml
+- ../base0
+- middle.yaml
+- ../base1
+- ../base2
+- ../base3
+`)
+	th.WriteF("overlay/first.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+`)
+	th.WriteF("overlay/middle.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: middle
+`)
+	th.WriteK("common", `
+resources:
+- service.yaml
+`)
+	th.WriteF("common/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+	for i := 0; i < 4; i++ {
+		th.WriteK(fmt.Sprintf("base%d", i), fmt.Sprintf(`
+nameSuffix: "-%d"
+commonLabels:
+  base: b%d
+resources:
+- deployment.yaml
+- ../common
+configMapGenerator:
+- name: cm
+  literals:
+  - index=%d
+`, i, i, i))
+		th.WriteF(fmt.Sprintf("base%d/deployment.yaml", i), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: cm
+`)
+	}
+}
+
+func TestParallelBuild(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeParallelBuildTree(th)
+
+	serial := th.Run("overlay", th.MakeDefaultOptions())
+	expected, err := serial.AsYaml()
+	require.NoError(t, err)
+
+	opts := th.MakeDefaultOptions()
+	opts.ParallelBuild = true
+	for i := 0; i < 5; i++ {
+		m := th.Run("overlay", opts)
+		actual, err := m.AsYaml()
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+	}
+	assert.Contains(t, string(expected), `
+    config.kubernetes.io/origin: |
+      path: ../base2/deployment.yaml
+`)
+}
+
+func TestParallelBuildError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeParallelBuildTree(th)
+	th.WriteK("base2", `
+resources:
+- missing.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.ParallelBuild = true
+	err := th.RunWithErr("overlay", opts)
+	assert.Contains(t, err.Error(), "base2")
+	assert.Contains(t, err.Error(), "missing.yaml")
+}
+
+func TestParallelBuildManyFiles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	resources := ""
+	for i := 0; i < 200; i++ {
+		resources += fmt.Sprintf("- cm%d.yaml\n", i)
+		th.WriteF(fmt.Sprintf("app/cm%d.yaml", i), fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm%d
+data:
+  index: "%d"
+`, i, i))
+	}
+	th.WriteK("app", "namePrefix: p-\nresources:\n"+resources)
+
+	serial := th.Run("app", th.MakeDefaultOptions())
+	expected, err := serial.AsYaml()
+	require.NoError(t, err)
+
+	opts := th.MakeDefaultOptions()
+	opts.ParallelBuild = true
+	for i := 0; i < 5; i++ {
+		m := th.Run("app", opts)
+		actual, err := m.AsYaml()
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+	}
+}
+
+func TestParallelBuildFirstFileError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeParallelBuildTree(th)
+	th.WriteF("overlay/first.yaml", "kind: [\n")
+	th.WriteF("overlay/middle.yaml", "kind: {\n")
+	opts := th.MakeDefaultOptions()
+	opts.ParallelBuild = true
+	for i := 0; i < 5; i++ {
+		err := th.RunWithErr("overlay", opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "first.yaml")
+		assert.NotContains(t, err.Error(), "middle.yaml")
+	}
+}
+
+func TestParallelBuildBaseOpenAPI(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeParallelBuildTree(th)
+	th.WriteK("base3", `
+resources:
+- deployment.yaml
+openapi:
+  version: v1.21.2
+`)
+	defer openapi.ResetOpenAPI()
+	opts := th.MakeDefaultOptions()
+	th.Run("overlay", opts)
+
+	// The schema bases set would depend on their build order.
+	opts.ParallelBuild = true
+	err := th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"'/base3' sets openapi, which bases built in parallel can't")
+
+	// The kustomization being built may set it.
+	th.WriteK("base3", `
+resources:
+- deployment.yaml
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base0
+- ../base3
+openapi:
+  version: v1.21.2
+`)
+	th.Run("overlay", opts)
+}
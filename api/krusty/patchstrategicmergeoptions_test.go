@@ -0,0 +1,109 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestPatchesStrategicMergeOptions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- path: args.yaml
+  mergeLists: append
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: web
+    spec:
+      template:
+        spec:
+          volumes:
+          - name: cache
+            emptyDir: {}
+  mergeLists: replace
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: web
+    spec:
+      strategy:
+      - Recreate
+  force: true
+`)
+	th.WriteF("args.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        args:
+        - --verbose
+`)
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  strategy:
+    type: RollingUpdate
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        args:
+        - --port=80
+      volumes:
+      - name: data
+        emptyDir: {}
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  strategy:
+  - Recreate
+  template:
+    spec:
+      containers:
+      - args:
+        - --port=80
+        - --verbose
+        image: nginx
+        name: web
+      volumes:
+      - emptyDir: {}
+        name: cache
+`)
+}
+
+func TestPatchesStrategicMergeOptionsInvalid(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+patchesStrategicMerge:
+- path: patch.yaml
+  mergeLists: prepend
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(), `unknown mergeLists "prepend"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
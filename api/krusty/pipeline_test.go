@@ -0,0 +1,140 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// scaleDotSh scales the Deployments to 3 replicas.
+const scaleDotSh = `#!/bin/sh
+sed 's/replicas: 1$/replicas: 3/'
+`
+
+// denyDotSh fails if its config denies a kind of the input.
+const denyDotSh = `#!/bin/sh
+input=$(cat)
+kind=$(echo "$input" | sed -n 's/^ *deny: //p')
+if echo "$input" | grep -q "^  kind: $kind\$"; then
+  echo "$kind denied" >&2
+  exit 1
+fi
+echo "$input"
+`
+
+func writePipelineKustomization(
+	t *testing.T, th kusttest_test.Harness, dir, deny string) {
+	t.Helper()
+	th.WriteK(dir, `
+namePrefix: prod-
+resources:
+- deployment.yaml
+pipeline:
+  mutators:
+  - exec: ./scale.sh
+  validators:
+  - name: deny
+    exec: ./deny.sh
+    configPath: deny.yaml
+`)
+	th.WriteF(filepath.Join(dir, "deployment.yaml"), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteF(filepath.Join(dir, "deny.yaml"), `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: deny
+  annotations:
+    config.kubernetes.io/local-config: "true"
+data:
+  deny: `+deny+`
+`)
+	for name, script := range map[string]string{
+		"scale.sh": scaleDotSh, "deny.sh": denyDotSh} {
+		th.WriteF(filepath.Join(dir, name), script)
+		require.NoError(t, os.Chmod(filepath.Join(dir, name), 0777))
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	writePipelineKustomization(t, th, tmpDir.String(), "Service")
+	m := th.Run(tmpDir.String(), o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+spec:
+  replicas: 3
+`)
+}
+
+func TestPipelineValidatorFails(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	writePipelineKustomization(t, th, tmpDir.String(), "Deployment")
+	err = th.RunWithErr(tmpDir.String(), o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "couldn't execute function: exit status 1")
+}
+
+func TestPipelineExecPathQuoted(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	dir := tmpDir.String()
+	// Unquoted in YAML, the path would end before the comment.
+	th.WriteK(dir, `
+resources:
+- deployment.yaml
+pipeline:
+  mutators:
+  - exec: "./scale #1.sh"
+`)
+	th.WriteF(filepath.Join(dir, "deployment.yaml"), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteF(filepath.Join(dir, "scale #1.sh"), scaleDotSh)
+	require.NoError(t, os.Chmod(filepath.Join(dir, "scale #1.sh"), 0777))
+	m := th.Run(dir, o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fakeOpaDotSh records its input, and the policies in the
+// directory of its --data flag, then prints result.json.
+const fakeOpaDotSh = `#!/bin/sh
+dir=$(dirname "$0")
+cat > "$dir/input.json"
+cp -r "$6" "$dir/data"
+cat "$dir/result.json"
+`
+
+func writePolicyKustomization(
+	t *testing.T, th kusttest_test.Harness, dir string) {
+	t.Helper()
+	th.WriteK(dir, `
+namespace: prod
+resources:
+- resources.yaml
+validators:
+- policy.yaml
+`)
+	th.WriteF(filepath.Join(dir, "resources.yaml"), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: NodePort
+`)
+	th.WriteF(filepath.Join(dir, "policy.yaml"), `
+apiVersion: builtin
+kind: PolicyTransformer
+metadata:
+  name: policies
+policies:
+- policies
+`)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "policies"), 0755))
+	th.WriteF(filepath.Join(dir, "policies", "template.yaml"), `
+apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sRequiredLabels
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    rego: |
+      package k8srequiredlabels
+
+      violation[{"msg": msg}] {
+        provided := {label | input.review.object.metadata.labels[label]}
+        required := {label | label := input.parameters.labels[_]}
+        missing := required - provided
+        count(missing) > 0
+        msg := sprintf("you must provide labels: %v", [missing])
+      }
+`)
+	th.WriteF(filepath.Join(dir, "policies", "constraints.yaml"), `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-team
+spec:
+  match:
+    kinds:
+    - apiGroups: ["apps"]
+      kinds: ["Deployment"]
+    namespaces: ["prod"]
+  parameters:
+    labels: ["team"]
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: should-have-owner
+spec:
+  enforcementAction: warn
+  parameters:
+    labels: ["owner"]
+`)
+	th.WriteF(filepath.Join(dir, "policies", "services.rego"), `
+package main
+
+deny[msg] {
+  input.kind == "Service"
+  input.spec.type == "NodePort"
+  msg := "services must not be NodePort"
+}
+`)
+	th.WriteF(filepath.Join(dir, "policies", "README.md"), `
+Skipped.
+`)
+	th.WriteF(filepath.Join(dir, "opa"), fakeOpaDotSh)
+	require.NoError(t, os.Chmod(filepath.Join(dir, "opa"), 0777))
+}
+
+func TestPolicyValidator(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	dir := tmpDir.String()
+	writePolicyKustomization(t, th, dir)
+	th.WriteF(filepath.Join(dir, "result.json"), `
+{"result": [{"expressions": [{"value": [
+  [0, 0, {"msg": "you must provide labels: {\"team\"}"}],
+  [-1, 1, "services must not be NodePort"]
+]}]}]}
+`)
+	o := th.MakeDefaultOptions()
+	o.PluginConfig.OpaConfig.Enabled = true
+	o.PluginConfig.OpaConfig.Command = filepath.Join(dir, "opa")
+	err = th.RunWithErr(dir, o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `resources violate policies:
+Deployment web: [K8sRequiredLabels must-have-team] you must provide labels: {"team"}
+Service web: services must not be NodePort`)
+
+	// The warning constraint isn't enforced.
+	content, err := ioutil.ReadFile(filepath.Join(dir, "input.json"))
+	require.NoError(t, err)
+	var input struct {
+		Constraints []map[string]interface{} `json:"constraints"`
+		Reviews     []struct {
+			Namespace string `json:"namespace"`
+		} `json:"reviews"`
+	}
+	require.NoError(t, json.Unmarshal(content, &input))
+	assert.Equal(t, []map[string]interface{}{{
+		"kind":       "K8sRequiredLabels",
+		"name":       "must-have-team",
+		"parameters": map[string]interface{}{"labels": []interface{}{"team"}},
+		"matches":    []interface{}{float64(0)},
+	}}, input.Constraints)
+	require.Len(t, input.Reviews, 2)
+	assert.Equal(t, "prod", input.Reviews[0].Namespace)
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "data"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	query, err := ioutil.ReadFile(filepath.Join(dir, "data", "policy0.rego"))
+	require.NoError(t, err)
+	assert.Contains(t, string(query), `constraint.kind == "K8sRequiredLabels"`)
+	assert.Contains(t, string(query), "data.k8srequiredlabels.violation[_]")
+
+	th.WriteF(filepath.Join(dir, "result.json"), `
+{"result": [{"expressions": [{"value": []}]}]}
+`)
+	m := th.Run(dir, o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  type: NodePort
+`)
+}
+
+func TestPolicyValidatorDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+validators:
+- policy.yaml
+`)
+	th.WriteF("policy.yaml", `
+apiVersion: builtin
+kind: PolicyTransformer
+metadata:
+  name: policies
+policies:
+- policies
+`)
+	// Enabling plugins doesn't enable opa.
+	enabled := th.MakeDefaultOptions()
+	enabled.PluginConfig = types.EnabledPluginConfig(types.BploUseStaticallyLinked)
+	for _, o := range []krusty.Options{th.MakeDefaultOptions(), enabled} {
+		err := th.RunWithErr(".", o)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must specify --enable-opa")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const remoteGenerator = `#!/bin/sh
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: from-remote-plugin
+data:
+  greeting: hello
+EOF
+`
+
+func writeRemoteGeneratorConfig(th kusttest_test.Harness, url, checksum string) {
+	th.WriteF("config.yaml", `
+apiVersion: someteam.example.com/v1
+kind: Greeter
+metadata:
+  name: greeter
+  annotations:
+    config.kubernetes.io/plugin-source: `+url+`
+    config.kubernetes.io/plugin-sha256: `+checksum+`
+`)
+}
+
+func TestRemoteExecPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script plugin")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(remoteGenerator))
+		}))
+	defer srv.Close()
+	os.Setenv(konfig.KustomizePluginCacheEnv, t.TempDir())
+	defer os.Unsetenv(konfig.KustomizePluginCacheEnv)
+	sum := sha256.Sum256([]byte(remoteGenerator))
+
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+generators:
+- config.yaml
+`)
+	writeRemoteGeneratorConfig(th, srv.URL+"/greeter", hex.EncodeToString(sum[:]))
+	m := th.Run(".", th.MakeOptionsPluginsEnabled())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  greeting: hello
+kind: ConfigMap
+metadata:
+  name: from-remote-plugin
+`)
+
+	// Remote plugins are subject to the usual plugin restrictions.
+	err := th.RunWithErr(".", th.MakeOptionsPluginsDisabled())
+	if !types.IsErrOnlyBuiltinPluginsAllowed(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bad := strings.Repeat("0", 64)
+	writeRemoteGeneratorConfig(th, srv.URL+"/greeter", bad)
+	err = th.RunWithErr(".", th.MakeOptionsPluginsEnabled())
+	if !strings.Contains(err.Error(), "but "+bad+" was declared") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestRequiredMetadataValidator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("policy.yaml", `
+apiVersion: builtin
+kind: RequiredMetadataTransformer
+metadata:
+  name: policy
+target:
+  kind: Deployment
+labels:
+- team
+- app.kubernetes.io/*
+annotations:
+- cost-center
+`)
+	th.WriteK(".", `
+resources:
+- base
+validators:
+- policy.yaml
+commonLabels:
+  team: storefront
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	assert.EqualError(t, err, `resources lack required metadata:
+Deployment web: labels app.kubernetes.io/*; annotations cost-center`)
+
+	th.WriteK(".", `
+resources:
+- base
+validators:
+- policy.yaml
+commonLabels:
+  team: storefront
+  app.kubernetes.io/name: web
+commonAnnotations:
+  cost-center: "42"
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    cost-center: "42"
+  labels:
+    app.kubernetes.io/name: web
+    team: storefront
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: web
+      team: storefront
+  template:
+    metadata:
+      annotations:
+        cost-center: "42"
+      labels:
+        app.kubernetes.io/name: web
+        team: storefront
+`)
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConfigMap(th kusttest_test.Harness, path, name string) {
+	th.WriteF(path, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: `+name+`
+`)
+}
+
+func TestResourcePatterns(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("app", `
+resources:
+- manifests/b.yaml
+- manifests/*.yaml
+- crds/**
+`)
+	writeConfigMap(th, "app/manifests/c.yaml", "c")
+	writeConfigMap(th, "app/manifests/a.yaml", "a")
+	writeConfigMap(th, "app/manifests/b.yaml", "b")
+	writeConfigMap(th, "app/manifests/.hidden.yaml", "hidden")
+	writeConfigMap(th, "app/manifests/nested/d.yaml", "d")
+	writeConfigMap(th, "app/crds/v2/f.yaml", "f")
+	writeConfigMap(th, "app/crds/e.yaml", "e")
+	m := th.Run("app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: e
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: f
+`)
+}
+
+func TestResourcePatternsSkipKustomizationFiles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("app", `
+resources:
+- "*.yaml"
+`)
+	th.WriteF("app/kustomization.d/prefix.yaml", `
+namePrefix: dev-
+`)
+	writeConfigMap(th, "app/config.yaml", "config")
+	m := th.Run("app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dev-config
+`)
+}
+
+func TestResourcePatternsNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("app", `
+resources:
+- manifests/*.yml
+`)
+	writeConfigMap(th, "app/manifests/a.yaml", "a")
+	err := th.RunWithErr("app", th.MakeDefaultOptions())
+	assert.EqualError(t, err,
+		`accumulating resources: resources pattern "manifests/*.yml" matches no files`)
+}
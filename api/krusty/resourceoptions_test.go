@@ -0,0 +1,162 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeResourceOptionsBases(th kusttest_test.Harness) {
+	th.WriteK("vendor", `
+resources:
+- deployment.yaml
+- service.yaml
+`)
+	th.WriteF("vendor/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: vendor
+spec:
+  template:
+    spec:
+      containers:
+      - name: vendor
+        image: vendor
+        envFrom:
+        - configMapRef:
+            name: settings
+`)
+	th.WriteF("vendor/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: vendor
+`)
+	th.WriteK("app", `
+resources:
+- configmap.yaml
+`)
+	th.WriteF("app/configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+}
+
+func TestResourceOptions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeResourceOptionsBases(th)
+	th.WriteK("overlay", `
+namePrefix: dev-
+commonLabels:
+  team: web
+commonAnnotations:
+  owner: web
+resources:
+- ../app
+- path: ../vendor
+  options:
+    inheritCommonLabels: false
+    inheritNamePrefix: false
+`)
+	opts := th.MakeDefaultOptions()
+	for _, parallel := range []bool{false, true} {
+		opts.ParallelBuild = parallel
+		m := th.Run("overlay", opts)
+		th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    owner: web
+  labels:
+    team: web
+  name: dev-settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    owner: web
+  name: vendor
+spec:
+  template:
+    metadata:
+      annotations:
+        owner: web
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: dev-settings
+        image: vendor
+        name: vendor
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    owner: web
+  name: vendor
+`)
+	}
+}
+
+func TestResourceOptionsOnlyInTheirKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeResourceOptionsBases(th)
+	th.WriteK("overlay", `
+commonLabels:
+  team: web
+resources:
+- path: ../vendor
+  options:
+    inheritCommonLabels: false
+`)
+	th.WriteK("prod", `
+nameSuffix: -prod
+commonLabels:
+  env: prod
+resources:
+- ../overlay
+`)
+	m := th.Run("prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: vendor-prod
+spec:
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: settings
+        image: vendor
+        name: vendor
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    env: prod
+  name: vendor-prod
+spec:
+  selector:
+    env: prod
+`)
+}
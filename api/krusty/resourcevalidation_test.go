@@ -0,0 +1,150 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeValidatedBase(th kusttest_test.Harness) {
+	th.WriteF("base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+    targetPort: 8080
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        resources:
+          limits:
+            cpu: 1
+            memory: 1Gi
+`)
+	th.WriteF("base/widget.yaml", `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: web
+spec:
+  anything: goes
+`)
+	th.WriteK("base", `
+resources:
+- service.yaml
+- deployment.yaml
+- widget.yaml
+`)
+}
+
+func TestValidateOutput(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedBase(th)
+	opts := th.MakeDefaultOptions()
+	opts.Validate = krusty.ValidateWithBuildSchema
+	m := th.Run("base", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+    targetPort: 8080
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - image: nginx
+        name: web
+        resources:
+          limits:
+            cpu: 1
+            memory: 1Gi
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: web
+spec:
+  anything: goes
+`)
+}
+
+func TestValidateOutputErrors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedBase(th)
+	th.WriteF("overlay/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: two
+  template:
+    spec:
+      containers:
+      - name: web
+        imagePullPolice: Always
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Validate = "k8s-1.21"
+	err := th.RunWithErr("overlay", opts)
+	assert.EqualError(t, err, "invalid resources:\n"+
+		"../base/deployment.yaml: Deployment.v1.apps/web.[noNs]: "+
+		"spec.replicas: expected an integer, got the string \"two\"\n"+
+		"../base/deployment.yaml: Deployment.v1.apps/web.[noNs]: "+
+		"spec.template.spec.containers[0].imagePullPolice: unknown field")
+}
+
+func TestValidateOutputUnknownVersion(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedBase(th)
+	opts := th.MakeDefaultOptions()
+	opts.Validate = "k8s-1.9"
+	err := th.RunWithErr("base", opts)
+	assert.EqualError(t, err,
+		"kubernetes 1.9 matches no single bundled openapi schema; "+
+			"bundled versions are v1212")
+}
+
+func TestValidateOutputClusterSchemaNotFetched(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidatedBase(th)
+	opts := th.MakeDefaultOptions()
+	opts.Validate = krusty.ValidateWithClusterSchema
+	err := th.RunWithErr("base", opts)
+	assert.EqualError(t, err,
+		"the openapi schema of the cluster cannot be fetched in this build")
+}
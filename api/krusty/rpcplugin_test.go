@@ -0,0 +1,182 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+// +build !windows
+
+package krusty_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/plugins/rpcplugin"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// rpcRecordEnv names the file the plugin server
+// records its pid and socket directory in.
+const rpcRecordEnv = "KRUSTY_TEST_RPC_RECORD"
+
+type rpcGenerator struct{}
+
+func (g *rpcGenerator) Generate(
+	args *rpcplugin.GenerateArgs, reply *rpcplugin.Reply) error {
+	if strings.Contains(args.Config, "fail") {
+		return fmt.Errorf("asked to fail")
+	}
+	reply.Resources = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: from-rpc-plugin
+`
+	return nil
+}
+
+// TestHelperRpcPluginServer is the plugin server when the
+// test binary is started as the RpcGenerator plugin.
+func TestHelperRpcPluginServer(t *testing.T) {
+	if os.Getenv(rpcplugin.ProtocolEnv) == "" {
+		return
+	}
+	socket := os.Getenv(rpcplugin.SocketEnv)
+	err := ioutil.WriteFile(os.Getenv(rpcRecordEnv), []byte(fmt.Sprintf(
+		"%d %s", os.Getpid(), filepath.Dir(socket))), 0644)
+	if err != nil {
+		os.Exit(1)
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		os.Exit(1)
+	}
+	s := rpc.NewServer()
+	if err = s.RegisterName("Plugin", &rpcGenerator{}); err != nil {
+		os.Exit(1)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	_, _ = ioutil.ReadAll(os.Stdin)
+	os.Exit(0)
+}
+
+// writeRpcGenerator writes the RpcGenerator plugin, served by
+// the test binary, and a kustomization using it, and returns
+// the file the plugin server records its pid and socket
+// directory in.
+func writeRpcGenerator(t *testing.T, th kusttest_test.Harness) string {
+	t.Helper()
+	home := t.TempDir()
+	dir := filepath.Join(home, "someteam.example.com", "v1", "rpcgenerator")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "RpcGenerator"), []byte(fmt.Sprintf(
+			"#!/bin/sh\nexec %s -test.run=^TestHelperRpcPluginServer$\n",
+			os.Args[0])), 0700))
+	th.WriteK(".", `
+generators:
+- config.yaml
+`)
+	th.WriteF("config.yaml", `
+apiVersion: someteam.example.com/v1
+kind: RpcGenerator
+metadata:
+  name: generator
+  annotations:
+    config.kubernetes.io/plugin-protocol: rpc
+`)
+	return home
+}
+
+// readRpcRecord returns the pid and socket directory the plugin
+// server recorded, and removes the record.
+func readRpcRecord(t *testing.T, record string) (int, string) {
+	t.Helper()
+	content, err := ioutil.ReadFile(record)
+	require.NoError(t, err)
+	fields := strings.Fields(string(content))
+	require.Len(t, fields, 2)
+	pid, err := strconv.Atoi(fields[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(record))
+	return pid, fields[1]
+}
+
+func TestRpcPluginStoppedAfterBuild(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	home := writeRpcGenerator(t, th)
+	record := filepath.Join(home, "record")
+	os.Setenv(konfig.KustomizePluginHomeEnv, home)
+	defer os.Unsetenv(konfig.KustomizePluginHomeEnv)
+	os.Setenv(rpcRecordEnv, record)
+	defer os.Unsetenv(rpcRecordEnv)
+
+	assertStopped := func() {
+		t.Helper()
+		pid, dir := readRpcRecord(t, record)
+		assert.NoDirExists(t, dir)
+		assert.Equal(t, syscall.ESRCH, syscall.Kill(pid, 0))
+	}
+
+	m := th.Run(".", th.MakeOptionsPluginsEnabled())
+	assert.Equal(t, "from-rpc-plugin", m.Resources()[0].GetName())
+	assertStopped()
+
+	th.WriteF("config.yaml", `
+apiVersion: someteam.example.com/v1
+kind: RpcGenerator
+metadata:
+  name: generator
+  annotations:
+    config.kubernetes.io/plugin-protocol: rpc
+mode: fail
+`)
+	err := th.RunWithErr(".", th.MakeOptionsPluginsEnabled())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "asked to fail")
+	assertStopped()
+}
+
+func TestRpcPluginKeptAcrossBuilds(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	home := writeRpcGenerator(t, th)
+	record := filepath.Join(home, "record")
+	os.Setenv(konfig.KustomizePluginHomeEnv, home)
+	defer os.Unsetenv(konfig.KustomizePluginHomeEnv)
+	os.Setenv(rpcRecordEnv, record)
+	defer os.Unsetenv(rpcRecordEnv)
+
+	release := krusty.KeepPluginServers()
+	th.Run(".", th.MakeOptionsPluginsEnabled())
+	pid, dir := readRpcRecord(t, record)
+	assert.DirExists(t, dir)
+	assert.NoError(t, syscall.Kill(pid, 0))
+
+	m := th.Run(".", th.MakeOptionsPluginsEnabled())
+	assert.Equal(t, "from-rpc-plugin", m.Resources()[0].GetName())
+	// The server wasn't started again.
+	assert.NoFileExists(t, record)
+
+	release()
+	assert.NoDirExists(t, dir)
+	assert.Equal(t, syscall.ESRCH, syscall.Kill(pid, 0))
+}
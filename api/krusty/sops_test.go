@@ -0,0 +1,81 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fakeSopsDotSh "decrypts" ENC[...] values.
+const fakeSopsDotSh = `#!/bin/sh
+sed 's/ENC\[\([^]]*\)\]/\1/g' "$6"
+`
+
+func TestSopsFiles(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	dir := tmpDir.String()
+	th.WriteK(dir, `
+resources:
+- secret.sops.yaml
+patchesStrategicMerge:
+- patch.sops.yaml
+secretGenerator:
+- name: app
+  envs:
+  - app.sops.env
+  options:
+    disableNameSuffixHash: true
+`)
+	th.WriteF(filepath.Join(dir, "secret.sops.yaml"), `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  user: ENC[admin]
+`)
+	th.WriteF(filepath.Join(dir, "patch.sops.yaml"), `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  password: ENC[hunter2]
+`)
+	th.WriteF(filepath.Join(dir, "app.sops.env"), `
+TOKEN=ENC[abc]
+`)
+	th.WriteF(filepath.Join(dir, "sops"), fakeSopsDotSh)
+	require.NoError(t, os.Chmod(filepath.Join(dir, "sops"), 0777))
+
+	o := th.MakeDefaultOptions()
+	o.SopsCommand = filepath.Join(dir, "sops")
+	m := th.Run(dir, o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+stringData:
+  password: hunter2
+  user: admin
+---
+apiVersion: v1
+data:
+  TOKEN: YWJj
+kind: Secret
+metadata:
+  name: app
+type: Opaque
+`)
+}
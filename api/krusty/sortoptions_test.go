@@ -0,0 +1,258 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeSortOptionsResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`)
+}
+
+func TestSortOptionsFIFO(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: fifo
+`)
+	writeSortOptionsResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.DoLegacyResourceSort = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`)
+}
+
+func TestSortOptionsLegacy(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: legacy
+`)
+	writeSortOptionsResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+`)
+}
+
+func TestSortOptionsLegacyOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: legacy
+  legacySortOptions:
+    orderFirst:
+    - Deployment
+    orderLast:
+    - Namespace
+`)
+	writeSortOptionsResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`)
+}
+
+func TestSortOptionsUnknownOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: alphabetical
+`)
+	writeSortOptionsResources(th)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		`unknown sortOptions.order "alphabetical"; expected legacy, fifo, name or namespace`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSortOptionsName(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: name
+`)
+	writeSortOptionsResources(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+}
+
+func TestSortOptionsNamespace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: namespace
+`)
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+  namespace: two
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+  namespace: two
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+  namespace: one
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: one
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: one
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+  namespace: one
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+  namespace: two
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+  namespace: two
+`)
+}
+
+func TestSortOptionsOption(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  order: legacy
+`)
+	writeSortOptionsResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.SortOptions = &types.SortOptions{Order: types.FIFOSortOrder}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`)
+}
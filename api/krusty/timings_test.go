@@ -0,0 +1,30 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestTimings(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDiamond(th)
+	opts := th.MakeDefaultOptions()
+	opts.Timings = krusty.NewTimings()
+	th.Run("app", opts)
+	counts := make(map[string]int)
+	for _, timing := range opts.Timings.List() {
+		counts[timing.Kind+" "+timing.Name] = timing.Count
+	}
+	// The base is built once, and taken from the cache once.
+	assert.Equal(t, 2, counts["base /base"])
+	assert.Equal(t, 1, counts["base /dev"])
+	assert.Equal(t, 1, counts["transformer NameReferenceTransformer"])
+	assert.Equal(t, 2, counts["transformer PrefixTransformer"])
+	assert.Equal(t, 2, counts["transformer LabelTransformer"])
+}
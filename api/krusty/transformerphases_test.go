@@ -0,0 +1,85 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestTransformerPhases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namePrefix: dev-
+configMapGenerator:
+- name: settings
+  literals:
+  - mode=dev
+transformers:
+- |-
+  apiVersion: builtin
+  kind: PrefixTransformer
+  metadata:
+    name: pre
+    annotations:
+      config.kubernetes.io/transformer-phase: pre-builtin
+  prefix: pre-
+  fieldSpecs:
+  - path: metadata/name
+- |-
+  apiVersion: builtin
+  kind: PrefixTransformer
+  metadata:
+    name: post
+  prefix: post-
+  fieldSpecs:
+  - path: metadata/name
+- |-
+  apiVersion: builtin
+  kind: AnnotationsTransformer
+  metadata:
+    name: final
+    annotations:
+      config.kubernetes.io/transformer-phase: final
+  annotations:
+    owner: web
+  fieldSpecs:
+  - path: metadata/annotations
+    create: true
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: dev
+kind: ConfigMap
+metadata:
+  annotations:
+    owner: web
+  name: post-dev-pre-settings-t2hmhtdth5
+`)
+}
+
+func TestTransformerPhaseUnknown(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+transformers:
+- |-
+  apiVersion: builtin
+  kind: PrefixTransformer
+  metadata:
+    name: pre
+    annotations:
+      config.kubernetes.io/transformer-phase: first
+  prefix: pre-
+  fieldSpecs:
+  - path: metadata/name
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(), `unknown transformer phase "first"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 func makeTransfomersImageBase(th kusttest_test.Harness) {
@@ -400,3 +401,49 @@ spec:
             image: solsa-echo:foo
 `)
 }
+
+func TestImagesOption(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+images:
+- name: app
+  newName: registry.example.com/app
+  newTag: v1
+`)
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+      - name: proxy
+        image: nginx:1.21
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Images = []types.Image{
+		{Name: "app", NewName: "*", Digest: "sha256:abc"},
+		{Name: "nginx", NewTag: "1.25.3"},
+	}
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.example.com/app@sha256:abc
+        name: app
+      - image: nginx:1.25.3
+        name: proxy
+`)
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// checkHashedDotSh fails unless the configmap it's given has
+// a hashed name, i.e. unless it's given the final output.
+const checkHashedDotSh = `#!/bin/sh
+input=$(cat)
+if ! echo "$input" | grep -q "name: settings-[a-z0-9]\{10\}$"; then
+  echo "the settings configmap isn't hashed" >&2
+  exit 1
+fi
+echo "$input"
+`
+
+// scaleUpDotSh changes the replicas of deployments.
+const scaleUpDotSh = `#!/bin/sh
+sed 's/replicas: 1$/replicas: 3/'
+`
+
+func writeValidatedKustomization(
+	t *testing.T, th kusttest_test.Harness, dir, validator, script string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	th.WriteF(filepath.Join(dir, "deployment.yaml"), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteK(dir, `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - debug=false
+validators:
+- |-
+  kind: Validator
+  metadata:
+    name: check
+    annotations:
+      config.kubernetes.io/function: |
+        exec:
+          path: ./`+validator+`
+`)
+	th.WriteF(filepath.Join(dir, validator), script)
+	require.NoError(t, os.Chmod(filepath.Join(dir, validator), 0777))
+}
+
+func TestValidatorGetsFinalOutput(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	writeValidatedKustomization(t, th, tmpDir.String(), "checkHashed.sh", checkHashedDotSh)
+	m := th.Run(tmpDir.String(), o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+---
+apiVersion: v1
+data:
+  debug: "false"
+kind: ConfigMap
+metadata:
+  name: settings-dm8hck6684
+`)
+}
+
+func TestValidatorOfBaseFails(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	base := filepath.Join(tmpDir.String(), "base")
+	writeValidatedKustomization(t, th, base, "checkHashed.sh", checkHashedDotSh)
+	overlay := filepath.Join(tmpDir.String(), "overlay")
+	require.NoError(t, os.MkdirAll(overlay, 0700))
+	th.WriteK(overlay, `
+resources:
+- ../base
+`)
+	// The base doesn't hash its configmap, although the
+	// overlay would.
+	th.WriteK(base, `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - debug=false
+  options:
+    disableNameSuffixHash: true
+validators:
+- |-
+  kind: Validator
+  metadata:
+    name: check
+    annotations:
+      config.kubernetes.io/function: |
+        exec:
+          path: ./checkHashed.sh
+`)
+	// The message of the validator is logged.
+	err = th.RunWithErr(overlay, o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recursed accumulation of path '"+base+
+		"': couldn't execute function: exit status 1")
+}
+
+func TestValidatorCannotModify(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	writeValidatedKustomization(t, th, tmpDir.String(), "scaleUp.sh", scaleUpDotSh)
+	err = th.RunWithErr(tmpDir.String(), o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"validator shouldn't modify the resource map: changed Deployment.v1.apps/web.[noNs]")
+}
@@ -2272,3 +2272,184 @@ metadata:
   name: theConfigMap-hdd8h8cgdt
 `)
 }
+
+func TestVariableRefDefaultAndLabelSelector(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namePrefix: base-
+resources:
+- pod.yaml
+- service.yaml
+vars:
+- name: SERVICE_NAME
+  objref:
+    apiVersion: v1
+    kind: Service
+    labelSelector: tier=frontend
+- name: DB_NAME
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: db
+  default: localhost
+- name: DEBUG
+  objref:
+    apiVersion: v1
+    kind: Service
+    labelSelector: tier=frontend
+  fieldref:
+    fieldpath: metadata.annotations.debug
+  default: "false"
+`)
+	th.WriteF("pod.yaml", `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: clown
+spec:
+  containers:
+  - name: frown
+    image: frown
+    command:
+    - echo
+    - "$(SERVICE_NAME)"
+    - "$(DB_NAME)"
+    - "$(DEBUG)"
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+  labels:
+    tier: backend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    tier: frontend
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: base-clown
+spec:
+  containers:
+  - command:
+    - echo
+    - base-web
+    - localhost
+    - "false"
+    image: frown
+    name: frown
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    tier: backend
+  name: base-backend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    tier: frontend
+  name: base-web
+`)
+}
+
+func TestVariableRefInData(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namePrefix: base-
+resources:
+- service.yaml
+- secret.yaml
+configMapGenerator:
+- name: app
+  literals:
+  - app.properties=backend=$(SERVICE_NAME):8080
+  - raw=$(SERVICE_NAME)
+  options:
+    disableNameSuffixHash: true
+    annotations:
+      kustomize.config.k8s.io/substitute-keys: app.properties
+vars:
+- name: SERVICE_NAME
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: backend
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+`)
+	// "url" is "http://$(SERVICE_NAME)", base64 encoded.
+	th.WriteF("secret.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+  annotations:
+    kustomize.config.k8s.io/substitute-keys: url, host
+data:
+  url: aHR0cDovLyQoU0VSVklDRV9OQU1FKQ==
+stringData:
+  host: $(SERVICE_NAME)
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: base-backend
+---
+apiVersion: v1
+data:
+  url: aHR0cDovL2Jhc2UtYmFja2VuZA==
+kind: Secret
+metadata:
+  name: base-creds
+stringData:
+  host: base-backend
+---
+apiVersion: v1
+data:
+  app.properties: backend=base-backend:8080
+  raw: $(SERVICE_NAME)
+kind: ConfigMap
+metadata:
+  name: base-app
+`)
+}
+
+func TestVariableRefInDataMissingKey(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+configMapGenerator:
+- name: app
+  literals:
+  - a=b
+  options:
+    annotations:
+      kustomize.config.k8s.io/substitute-keys: c
+vars:
+- name: APP
+  objref:
+    apiVersion: v1
+    kind: ConfigMap
+    name: app
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(), `has no data key "c"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeWarnedKustomizations(th kusttest_test.Harness) {
+	th.WriteF("base/resources.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+  namespace: default
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteK("base", `
+resources:
+- resources.yaml
+vars:
+- name: WEB
+  objref:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+`)
+	th.WriteK("overlay", `
+bases:
+- ../base
+patches:
+- target:
+    kind: StatefulSet
+  patch: |-
+    - op: add
+      path: /spec/replicas
+      value: 2
+replacements:
+- source:
+    kind: Deployment
+    fieldPath: metadata.name
+  targets:
+  - select:
+      kind: ConfigMap
+    fieldPaths:
+    - data.name
+`)
+}
+
+func TestWarnings(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeWarnedKustomizations(th)
+	opts := th.MakeDefaultOptions()
+	var warnings []types.Warning
+	opts.Warn = func(w types.Warning) {
+		warnings = append(warnings, w)
+	}
+	th.Run("overlay", opts)
+	assert.Equal(t, []types.Warning{
+		{
+			Code:    types.WarningDeprecatedField,
+			Path:    "/base/kustomization.yaml",
+			Message: "'vars' is deprecated; use 'replacements'",
+		},
+		{
+			Code:    types.WarningDeprecatedField,
+			Path:    "/overlay/kustomization.yaml",
+			Message: "'bases' is deprecated; list bases under 'resources'",
+		},
+		{
+			Code:    types.WarningUnusedPatch,
+			Path:    "/overlay/kustomization.yaml",
+			Message: "inline patch targets kind=StatefulSet, which matches no resource",
+		},
+		{
+			Code:    types.WarningUnmatchedSelector,
+			Path:    "/overlay/kustomization.yaml",
+			Message: "replacement target kind=ConfigMap matches no resource",
+		},
+		{
+			Code:    types.WarningUnusedVar,
+			Path:    "/overlay/kustomization.yaml",
+			Message: "var WEB is never referenced",
+		},
+		{
+			Code:    types.WarningClusterScopedNamespace,
+			Path:    "/overlay/kustomization.yaml",
+			Message: "Namespace web is cluster-scoped, but has namespace default",
+		},
+	}, warnings)
+}
+
+func TestWarningsStrict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeWarnedKustomizations(th)
+	opts := th.MakeDefaultOptions()
+	opts.Strict = true
+	err := th.RunWithErr("base", opts)
+	assert.EqualError(t, err, `build has 3 warnings, and is strict:
+/base/kustomization.yaml: 'vars' is deprecated; use 'replacements' [deprecated-field]
+/base/kustomization.yaml: var WEB is never referenced [unused-var]
+/base/kustomization.yaml: Namespace web is cluster-scoped, but has namespace default [cluster-scoped-namespace]`)
+}
+
+func TestWarningsUnusedConfiguration(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("crd.yaml", `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  configRef: settings
+`)
+	th.WriteF("config.yaml", `
+commonLabels:
+- kind: Widget
+  path: spec/selector
+- kind: Widget
+  path: spec/template/labels
+- kind: Gadget
+  path: spec/labels
+  create: true
+nameReference:
+- kind: ConfigMap
+  fieldSpecs:
+  - kind: Widget
+    path: spec/configRef
+  - kind: Widget
+    path: spec/secretRef
+`)
+	th.WriteK(".", `
+resources:
+- crd.yaml
+configurations:
+- config.yaml
+commonLabels:
+  app: web
+`)
+	opts := th.MakeDefaultOptions()
+	var warnings []string
+	opts.Warn = func(w types.Warning) {
+		warnings = append(warnings, w.String())
+	}
+	th.Run(".", opts)
+	assert.Equal(t, []string{
+		"/kustomization.yaml: commonLabels fieldSpec kind=Widget path=spec/template/labels " +
+			"in config.yaml matches no field [unused-configuration]",
+		"/kustomization.yaml: commonLabels fieldSpec kind=Gadget path=spec/labels " +
+			"in config.yaml matches no field [unused-configuration]",
+		"/kustomization.yaml: nameReference fieldSpec kind=Widget path=spec/secretRef " +
+			"in config.yaml matches no field [unused-configuration]",
+	}, warnings)
+}
+
+func TestWarningsUnknownPluginField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+generators:
+- generator.yaml
+`)
+	th.WriteF("generator.yaml", `
+apiVersion: builtin
+kind: ConfigMapGenerator
+metadata:
+  name: web
+labels:
+  app: web
+literals:
+- mode=prod
+`)
+	opts := th.MakeDefaultOptions()
+	var warnings []string
+	opts.Warn = func(w types.Warning) {
+		warnings = append(warnings, w.String())
+	}
+	m := th.Run(".", opts)
+	assert.Equal(t, 1, m.Size())
+	assert.Equal(t, []string{
+		`/kustomization.yaml: ConfigMapGenerator web has unknown field ` +
+			`"labels", which is ignored [unknown-plugin-field]`,
+	}, warnings)
+
+	opts.Strict = true
+	err := th.RunWithErr(".", opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown field "labels"`)
+	}
+}
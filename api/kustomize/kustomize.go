@@ -0,0 +1,100 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kustomize is the supported entry point for programs
+// embedding kustomize builds, e.g. deployment tools.
+//
+// Unlike krusty, whose options follow the flags of the kustomize
+// CLI from release to release, this package follows the semantic
+// versioning of the api module: within a major version, its
+// functions and types keep their signatures, and fields are only
+// added to Options, with zero values that keep the behavior of
+// earlier versions.
+//
+//	k := kustomize.MakeKustomizer(filesys.MakeFsOnDisk(), nil)
+//	m, err := k.Run("overlays/production")
+//	if err != nil {
+//		return err
+//	}
+//	yml, err := m.AsYaml()
+package kustomize
+
+import (
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Options configure builds.  The zero value builds as the
+// kustomize CLI does without flags.
+type Options struct {
+	// If true, kustomizations may load files from outside
+	// their root directory, as with the CLI's
+	// --load-restrictor LoadRestrictionsNone.
+	AllowLoadOutsideRoot bool
+
+	// If true, kustomizations may inflate helm charts, with
+	// HelmCommand, as with the CLI's --enable-helm.
+	EnableHelm bool
+
+	// HelmCommand is the helm command; if empty, helm.
+	HelmCommand string
+
+	// If true, kustomizations may use exec and function
+	// plugins, as with the CLI's --enable-alpha-plugins.
+	EnableAlphaPlugins bool
+
+	// If true, the app.kubernetes.io/managed-by label is set
+	// on all resources, to kustomize and its version.
+	AddManagedByLabel bool
+}
+
+// Kustomizer builds kustomizations from a file system.
+// A Kustomizer may run any number of builds, but not
+// concurrently.
+type Kustomizer struct {
+	fSys    filesys.FileSystem
+	options Options
+}
+
+// MakeKustomizer returns a Kustomizer building the kustomizations
+// of the file system with the options, or the default options if
+// the options are nil.
+func MakeKustomizer(fSys filesys.FileSystem, o *Options) *Kustomizer {
+	k := &Kustomizer{fSys: fSys}
+	if o != nil {
+		k.options = *o
+	}
+	return k
+}
+
+// Run builds the kustomization in the directory, or at the
+// URL of a git repository, at the path, and returns its
+// resources, in the order the CLI prints them.
+func (k *Kustomizer) Run(path string) (resmap.ResMap, error) {
+	return krusty.MakeKustomizer(k.krustyOptions()).Run(k.fSys, path)
+}
+
+// krustyOptions translates the options to those of krusty,
+// which may change from release to release.
+func (k *Kustomizer) krustyOptions() *krusty.Options {
+	o := krusty.MakeDefaultOptions()
+	// The CLI sorts resources so by default.
+	o.DoLegacyResourceSort = true
+	if k.options.AllowLoadOutsideRoot {
+		o.LoadRestrictions = types.LoadRestrictionsNone
+	}
+	if k.options.EnableAlphaPlugins {
+		o.PluginConfig = types.EnabledPluginConfig(types.BploUseStaticallyLinked)
+	}
+	if k.options.EnableHelm {
+		o.PluginConfig.HelmConfig.Enabled = true
+	}
+	o.PluginConfig.HelmConfig.Command = k.options.HelmCommand
+	if o.PluginConfig.HelmConfig.Command == "" {
+		o.PluginConfig.HelmConfig.Command = "helm"
+	}
+	o.AddManagedbyLabel = k.options.AddManagedByLabel
+	return o
+}
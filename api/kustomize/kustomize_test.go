@@ -0,0 +1,147 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kustomize_test
+
+import (
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/kustomize"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeFiles(t *testing.T, files map[string]string) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range files {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return fSys
+}
+
+func TestKustomizer(t *testing.T) {
+	fSys := writeFiles(t, map[string]string{
+		"app/kustomization.yaml": `
+namePrefix: prod-
+resources:
+- deployment.yaml
+- namespace.yaml
+`,
+		"app/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`,
+		"app/namespace.yaml": `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`,
+	})
+	m, err := kustomize.MakeKustomizer(fSys, nil).Run("app")
+	require.NoError(t, err)
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	// Namespaces come first, as the CLI prints them.
+	assert.Equal(t, `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+`, string(yml))
+
+	m, err = kustomize.MakeKustomizer(fSys, &kustomize.Options{
+		AddManagedByLabel: true,
+	}).Run("app")
+	require.NoError(t, err)
+	for _, r := range m.Resources() {
+		assert.Contains(t, r.GetLabels(), "app.kubernetes.io/managed-by")
+	}
+}
+
+func TestKustomizerLoadOutsideRoot(t *testing.T) {
+	fSys := writeFiles(t, map[string]string{
+		"app/kustomization.yaml": `
+resources:
+- ../shared/configmap.yaml
+`,
+		"shared/configmap.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+`,
+	})
+	_, err := kustomize.MakeKustomizer(fSys, nil).Run("app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not in or below")
+
+	m, err := kustomize.MakeKustomizer(fSys, &kustomize.Options{
+		AllowLoadOutsideRoot: true,
+	}).Run("app")
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.Size())
+}
+
+func TestKustomizerHelmDisabled(t *testing.T) {
+	fSys := writeFiles(t, map[string]string{
+		"app/kustomization.yaml": `
+helmCharts:
+- name: web
+`,
+	})
+	_, err := kustomize.MakeKustomizer(fSys, nil).Run("app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must specify --enable-helm")
+}
+
+func ExampleKustomizer_Run() {
+	fSys := filesys.MakeFsInMemory()
+	err := fSys.WriteFile("app/kustomization.yaml", []byte(`
+commonLabels:
+  app: web
+resources:
+- service.yaml
+`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = fSys.WriteFile("app/service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	m, err := kustomize.MakeKustomizer(fSys, nil).Run("app")
+	if err != nil {
+		log.Fatal(err)
+	}
+	yml, err := m.AsYaml()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(yml))
+	// Output:
+	// apiVersion: v1
+	// kind: Service
+	// metadata:
+	//   labels:
+	//     app: web
+	//   name: web
+	// spec:
+	//   selector:
+	//     app: web
+}
@@ -1,5 +1,29 @@
 package loader
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
 
 var ErrorHTTP = fmt.Errorf("HTTP Error")
+
+// CycleError is a kustomization including itself, directly
+// or through other kustomizations, e.g. as a base.
+type CycleError struct {
+	reason string
+
+	// Path holds the roots of the kustomizations included,
+	// outermost first, up to the one included again.
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle detected: %s; inclusion path: %s",
+		e.reason, strings.Join(e.Path, " -> "))
+}
+
+func (e *CycleError) ErrorClass() types.ErrorClass {
+	return types.ErrorClassConfig
+}
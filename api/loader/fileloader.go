@@ -11,10 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -100,6 +102,8 @@ type fileLoader struct {
 	cleaner func() error
 }
 
+var _ ifc.DirLoader = &fileLoader{}
+
 // NewFileLoaderAtCwd returns a loader that loads from PWD.
 // A convenience for kustomize edit commands.
 func NewFileLoaderAtCwd(fSys filesys.FileSystem) *fileLoader {
@@ -120,6 +124,15 @@ func (fl *fileLoader) Root() string {
 	return fl.root.String()
 }
 
+// Repo returns the URL of the repository the loader's root
+// was cloned from, or "" if it wasn't cloned.
+func (fl *fileLoader) Repo() string {
+	if fl.repoSpec == nil {
+		return ""
+	}
+	return fl.repoSpec.Raw()
+}
+
 func newLoaderOrDie(
 	lr LoadRestrictorFunc,
 	fSys filesys.FileSystem, path string) *fileLoader {
@@ -208,7 +221,7 @@ func newLoaderAtGitClone(
 	err := cloner(repoSpec)
 	if err != nil {
 		cleaner()
-		return nil, err
+		return nil, types.WithErrorClass(err, types.ErrorClassRemote)
 	}
 	root, f, err := fSys.CleanedAbs(repoSpec.AbsPath())
 	if err != nil {
@@ -269,15 +282,17 @@ func (fl *fileLoader) containingRepo() *git.RepoSpec {
 // is equal to or above the root of any ancestor.
 func (fl *fileLoader) errIfArgEqualOrHigher(
 	candidateRoot filesys.ConfirmedDir) error {
-	if fl.root.HasPrefix(candidateRoot) {
-		return fmt.Errorf(
-			"cycle detected: candidate root '%s' contains visited root '%s'",
-			candidateRoot, fl.root)
-	}
-	if fl.referrer == nil {
-		return nil
+	for l := fl; l != nil; l = l.referrer {
+		if l.root.HasPrefix(candidateRoot) {
+			return &CycleError{
+				reason: fmt.Sprintf(
+					"candidate root '%s' contains visited root '%s'",
+					candidateRoot, l.root),
+				Path: append(fl.inclusionPath(), candidateRoot.String()),
+			}
+		}
 	}
-	return fl.referrer.errIfArgEqualOrHigher(candidateRoot)
+	return nil
 }
 
 // TODO(monopole): Distinguish branches?
@@ -285,17 +300,33 @@ func (fl *fileLoader) errIfArgEqualOrHigher(
 // path foo and tag bar and a git URI with the same
 // path but a different tag?
 func (fl *fileLoader) errIfRepoCycle(newRepoSpec *git.RepoSpec) error {
-	// TODO(monopole): Use parsed data instead of Raw().
-	if fl.repoSpec != nil &&
-		strings.HasPrefix(fl.repoSpec.Raw(), newRepoSpec.Raw()) {
-		return fmt.Errorf(
-			"cycle detected: URI '%s' referenced by previous URI '%s'",
-			newRepoSpec.Raw(), fl.repoSpec.Raw())
+	for l := fl; l != nil; l = l.referrer {
+		// TODO(monopole): Use parsed data instead of Raw().
+		if l.repoSpec != nil &&
+			strings.HasPrefix(l.repoSpec.Raw(), newRepoSpec.Raw()) {
+			return &CycleError{
+				reason: fmt.Sprintf(
+					"URI '%s' referenced by previous URI '%s'",
+					newRepoSpec.Raw(), l.repoSpec.Raw()),
+				Path: append(fl.inclusionPath(), newRepoSpec.Raw()),
+			}
+		}
 	}
-	if fl.referrer == nil {
-		return nil
+	return nil
+}
+
+// inclusionPath returns the roots of the loaders that
+// led to this one, and of this one, outermost first.
+// Clones are named by their URI, not their temp directory.
+func (fl *fileLoader) inclusionPath() []string {
+	var path []string
+	if fl.referrer != nil {
+		path = fl.referrer.inclusionPath()
 	}
-	return fl.referrer.errIfRepoCycle(newRepoSpec)
+	if fl.repoSpec != nil {
+		return append(path, fl.repoSpec.Raw())
+	}
+	return append(path, fl.root.String())
 }
 
 // Load returns the content of file at the given path,
@@ -311,7 +342,7 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 		}
 		resp, err := hc.Get(path)
 		if err != nil {
-			return nil, err
+			return nil, types.WithErrorClass(err, types.ErrorClassRemote)
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode < 200 || resp.StatusCode > 299 {
@@ -319,7 +350,9 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 			if err == nil {
 				return nil, errors.New("URL is a git repository")
 			}
-			return nil, fmt.Errorf("%w: status code %d (%s)", ErrorHTTP, resp.StatusCode, http.StatusText(resp.StatusCode))
+			return nil, types.WithErrorClass(
+				fmt.Errorf("%w: status code %d (%s)", ErrorHTTP, resp.StatusCode, http.StatusText(resp.StatusCode)),
+				types.ErrorClassRemote)
 		}
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
@@ -337,6 +370,31 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 	return fl.fSys.ReadFile(path)
 }
 
+// IsDir returns true if the path is a directory.  Relative
+// paths are taken relative to the root.
+func (fl *fileLoader) IsDir(path string) bool {
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	return fl.fSys.IsDir(path)
+}
+
+// ReadDir returns the sorted names of the entries of the
+// directory at the given path.  Relative paths are taken
+// relative to the root.  The load restrictions apply when
+// the entries are loaded, not when they're listed.
+func (fl *fileLoader) ReadDir(path string) ([]string, error) {
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	names, err := fl.fSys.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // Cleanup runs the cleaner.
 func (fl *fileLoader) Cleanup() error {
 	return fl.cleaner()
@@ -5,6 +5,7 @@ package loader
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -580,6 +581,38 @@ func TestRepoIndirectCycleDetection(t *testing.T) {
 	if !strings.Contains(err.Error(), "cycle detected") {
 		t.Fatalf("unexpected err: %v", err)
 	}
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected a CycleError, got %T", err)
+	}
+	if expected := []string{topDir, p1, p2, p1}; !reflect.DeepEqual(cycle.Path, expected) {
+		t.Fatalf("expected inclusion path %v, got %v", expected, cycle.Path)
+	}
+}
+
+func TestDirectoryCycleDetection(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.MkdirAll("/a")
+	fSys.MkdirAll("/b")
+	root, err := demandDirectoryRoot(fSys, "/a")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	l1 := newLoaderAtConfirmedDir(
+		RestrictionRootOnly, root, fSys, nil, git.ClonerUsingGitExec)
+	l2, err := l1.New("../b")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	_, err = l2.New("../a")
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	expected := "cycle detected: candidate root '/a' contains visited root '/a'; " +
+		"inclusion path: /a -> /b -> /a"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
 }
 
 // Inspired by https://hassansin.github.io/Unit-Testing-http-client-in-Go
@@ -0,0 +1,137 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+)
+
+// sopsTypes maps the extensions of sops-encrypted files,
+// e.g. secrets.sops.yaml, to the sops type of their content.
+var sopsTypes = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".json": "json",
+	".env":  "dotenv",
+}
+
+// sopsLoader is a loader decrypting the sops-encrypted
+// files it loads, and those the loaders it makes load.
+type sopsLoader struct {
+	ifc.Loader
+	command string
+}
+
+var _ ifc.DirLoader = &sopsLoader{}
+
+// NewSopsLoader wraps the loader so that files named e.g.
+// secrets.sops.yaml, secrets.sops.json or app.sops.env are
+// decrypted, with the sops command, as they're loaded.  The
+// command finds the age or KMS keys in its environment, e.g.
+// in SOPS_AGE_KEY_FILE, as it does when run by hand.
+func NewSopsLoader(ldr ifc.Loader, command string) ifc.Loader {
+	return &sopsLoader{Loader: ldr, command: command}
+}
+
+// sopsType returns the sops type of the file, or "" if
+// it isn't named as sops encrypted.
+func sopsType(location string) string {
+	base := filepath.Base(location)
+	ext := filepath.Ext(base)
+	t, ok := sopsTypes[ext]
+	stem := strings.TrimSuffix(base, ext)
+	// .sops.yaml itself configures sops.
+	if !ok || !strings.HasSuffix(stem, ".sops") || stem == ".sops" {
+		return ""
+	}
+	return t
+}
+
+// New returns a sops loader at the new root.
+func (l *sopsLoader) New(newRoot string) (ifc.Loader, error) {
+	ldr, err := l.Loader.New(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &sopsLoader{Loader: ldr, command: l.command}, nil
+}
+
+// Load returns the bytes read from the location, decrypted
+// if the location is a sops file.
+func (l *sopsLoader) Load(location string) ([]byte, error) {
+	content, err := l.Loader.Load(location)
+	t := sopsType(location)
+	if err != nil || t == "" {
+		return content, err
+	}
+	// The ciphertext is passed in a file of its own, as the
+	// location needn't be on disk.
+	f, err := ioutil.TempFile("", "kustomize-sops-")
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decrypt %s", location)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(content)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decrypt %s", location)
+	}
+	args := []string{
+		"--decrypt", "--input-type", t, "--output-type", t, f.Name()}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	//nolint:gosec
+	cmd := exec.Command(l.command, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err = cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err,
+			"unable to decrypt %s with '%s %s' (is '%s' installed?): %s",
+			location, l.command, strings.Join(args, " "),
+			l.command, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// Decrypts returns true if the loader decrypts the file at
+// the location as it loads it.
+func (l *sopsLoader) Decrypts(location string) bool {
+	return sopsType(location) != ""
+}
+
+// IsDir returns true if the location is a directory.
+func (l *sopsLoader) IsDir(location string) bool {
+	dl, ok := l.Loader.(ifc.DirLoader)
+	return ok && dl.IsDir(location)
+}
+
+// ReadDir returns the sorted names of the entries of the
+// directory at the location.
+func (l *sopsLoader) ReadDir(location string) ([]string, error) {
+	dl, ok := l.Loader.(ifc.DirLoader)
+	if !ok {
+		return nil, fmt.Errorf("directories can't be listed in %s", l.Root())
+	}
+	return dl.ReadDir(location)
+}
+
+// Repo returns the URL of the repository the loader's root
+// was cloned from, if it was.
+func (l *sopsLoader) Repo() string {
+	if r, ok := l.Loader.(interface{ Repo() string }); ok {
+		return r.Repo()
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fakeSops "decrypts" ENC[...] values, and notes the
+// type of the content.
+const fakeSops = `#!/bin/sh
+sed 's/ENC\[\([^]]*\)\]/\1/g' "$6"
+echo "# $3"
+`
+
+func TestSopsLoader(t *testing.T) {
+	fSys := filesys.MakeFsOnDisk()
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir.String())
+	dir := tmpDir.String()
+	sops := filepath.Join(dir, "sops")
+	require.NoError(t, fSys.WriteFile(sops, []byte(fakeSops)))
+	require.NoError(t, os.Chmod(sops, 0777))
+	require.NoError(t, fSys.MkdirAll(filepath.Join(dir, "base")))
+	for path, content := range map[string]string{
+		"secret.sops.yaml":      "password: ENC[hunter2]\n",
+		"plain.yaml":            "password: ENC[hunter2]\n",
+		".sops.yaml":            "creation_rules: ENC[none]\n",
+		"base/app.sops.env":     "TOKEN=ENC[abc]\n",
+		"base/config.sops.json": "{\"token\": \"ENC[abc]\"}\n",
+	} {
+		require.NoError(t, fSys.WriteFile(
+			filepath.Join(dir, path), []byte(content)))
+	}
+
+	fLdr, err := NewLoader(RestrictionRootOnly, dir, fSys)
+	require.NoError(t, err)
+	ldr := NewSopsLoader(fLdr, sops)
+	for path, expected := range map[string]string{
+		"secret.sops.yaml": "password: hunter2\n# yaml\n",
+		"plain.yaml":       "password: ENC[hunter2]\n",
+		".sops.yaml":       "creation_rules: ENC[none]\n",
+	} {
+		content, err := ldr.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), path)
+	}
+
+	base, err := ldr.New("base")
+	require.NoError(t, err)
+	for path, expected := range map[string]string{
+		"app.sops.env":     "TOKEN=abc\n# dotenv\n",
+		"config.sops.json": "{\"token\": \"abc\"}\n# json\n",
+	} {
+		content, err := base.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), path)
+	}
+
+	ldr = NewSopsLoader(fLdr, filepath.Join(dir, "missing"))
+	_, err = ldr.Load("secret.sops.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to decrypt secret.sops.yaml")
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logging is the leveled logger of kustomize.
+//
+// Messages are logged at a verbosity level, and written if
+// the level is at most the verbosity set, which is 0 unless
+// set otherwise.  Level 0 is for messages always shown, e.g.
+// warnings; higher levels trace a build in more detail:
+//
+//	1: the kustomization built, and its outcome
+//	2: the kustomizations, bases and components accumulated
+//	3: the generators, transformers and validators run
+//	4: the plugins loaded
+//
+// Messages carry key/value pairs, e.g. the root of the
+// kustomization being accumulated, and are written as text
+// or as JSON objects, one per line.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format is the format of logged messages.
+type Format string
+
+const (
+	// FormatText writes messages as text, e.g.
+	//   accumulating base root=overlay base=base
+	FormatText Format = "text"
+
+	// FormatJSON writes messages as JSON objects, e.g.
+	//   {"time":"...","level":"info","v":2,"msg":"accumulating base","root":"overlay","base":"base"}
+	FormatJSON Format = "json"
+)
+
+// Formats are the supported formats.
+var Formats = []Format{FormatText, FormatJSON}
+
+type logger struct {
+	mu        sync.Mutex
+	w         io.Writer
+	verbosity int
+	format    Format
+}
+
+var std = &logger{w: os.Stderr, format: FormatText}
+
+// now is replaced in tests.
+var now = time.Now
+
+// SetOutput sets where messages are written, by default
+// to stderr.
+func SetOutput(w io.Writer) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.w = w
+}
+
+// SetVerbosity sets the highest level of messages written.
+func SetVerbosity(v int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.verbosity = v
+}
+
+// Verbosity returns the highest level of messages written.
+func Verbosity() int {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return std.verbosity
+}
+
+// SetFormat sets the format of messages.
+func SetFormat(f Format) error {
+	for _, known := range Formats {
+		if f == known {
+			std.mu.Lock()
+			defer std.mu.Unlock()
+			std.format = f
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log format %q; expected one of %v", f, Formats)
+}
+
+// Verbose logs messages if its level is enabled.
+type Verbose struct {
+	level   int
+	enabled bool
+}
+
+// V returns a Verbose logging at the given level, e.g.
+//
+//	logging.V(2).Info("accumulating base", "root", root, "base", base)
+func V(level int) Verbose {
+	return Verbose{level: level, enabled: level <= Verbosity()}
+}
+
+// Enabled returns true if messages of the level are written,
+// to skip computing values only logged.
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Info logs the message with the given key/value pairs.
+func (v Verbose) Info(msg string, keysAndValues ...interface{}) {
+	if v.enabled {
+		std.write("info", v.level, msg, keysAndValues)
+	}
+}
+
+// Warning logs the message, a likely mistake that doesn't
+// fail the command, with the given key/value pairs.
+func Warning(msg string, keysAndValues ...interface{}) {
+	std.write("warning", 0, msg, keysAndValues)
+}
+
+func (l *logger) write(severity string, level int, msg string, kvs []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var line string
+	if l.format == FormatJSON {
+		line = jsonLine(severity, level, msg, kvs)
+	} else {
+		line = textLine(severity, msg, kvs)
+	}
+	_, _ = io.WriteString(l.w, line+"\n")
+}
+
+func textLine(severity, msg string, kvs []interface{}) string {
+	var b strings.Builder
+	if severity == "warning" {
+		b.WriteString("Warning: ")
+	}
+	b.WriteString(msg)
+	for i := 0; i < len(kvs); i += 2 {
+		v := fmt.Sprint(value(kvs, i))
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			v = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, " %s=%s", key(kvs, i), v)
+	}
+	return b.String()
+}
+
+func jsonLine(severity string, level int, msg string, kvs []interface{}) string {
+	// Fields are written in order, which a map wouldn't keep.
+	var b strings.Builder
+	b.WriteString("{")
+	writeField := func(k string, v interface{}) {
+		if b.Len() > 1 {
+			b.WriteString(",")
+		}
+		kb, _ := json.Marshal(k)
+		vb, err := json.Marshal(v)
+		if err != nil {
+			vb, _ = json.Marshal(fmt.Sprint(v))
+		}
+		b.Write(kb)
+		b.WriteString(":")
+		b.Write(vb)
+	}
+	writeField("time", now().UTC().Format(time.RFC3339))
+	writeField("level", severity)
+	writeField("v", level)
+	writeField("msg", msg)
+	for i := 0; i < len(kvs); i += 2 {
+		v := value(kvs, i)
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		writeField(key(kvs, i), v)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func key(kvs []interface{}, i int) string {
+	return fmt.Sprint(kvs[i])
+}
+
+// value returns the value of the key at i, if any.
+func value(kvs []interface{}, i int) interface{} {
+	if i+1 < len(kvs) {
+		return kvs[i+1]
+	}
+	return "(missing)"
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setUp(t *testing.T, verbosity int, format Format) *bytes.Buffer {
+	t.Helper()
+	var out bytes.Buffer
+	SetOutput(&out)
+	SetVerbosity(verbosity)
+	if err := SetFormat(format); err != nil {
+		t.Fatal(err)
+	}
+	now = func() time.Time {
+		return time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	}
+	t.Cleanup(func() {
+		SetOutput(os.Stderr)
+		SetVerbosity(0)
+		_ = SetFormat(FormatText)
+		now = time.Now
+	})
+	return &out
+}
+
+func TestText(t *testing.T) {
+	out := setUp(t, 2, FormatText)
+	V(1).Info("building kustomization", "path", "overlay")
+	V(2).Info("accumulating base", "root", "overlay", "base", "my base")
+	V(3).Info("running transformer", "transformer", "PatchTransformer")
+	V(2).Info("base failed", "error", fmt.Errorf("no kustomization"), "odd")
+	Warning("var X is never referenced", "code", "unused-var")
+	assert.Equal(t, `building kustomization path=overlay
+accumulating base root=overlay base="my base"
+base failed error="no kustomization" odd=(missing)
+Warning: var X is never referenced code=unused-var
+`, out.String())
+}
+
+func TestJSON(t *testing.T) {
+	out := setUp(t, 2, FormatJSON)
+	V(2).Info("accumulating base", "root", "overlay", "resources", 3)
+	V(2).Info("base failed", "error", fmt.Errorf("no kustomization"))
+	V(3).Info("running transformer", "transformer", "PatchTransformer")
+	Warning("var X is never referenced", "code", "unused-var")
+	assert.Equal(t,
+		`{"time":"2022-03-01T12:00:00Z","level":"info","v":2,"msg":"accumulating base","root":"overlay","resources":3}
+{"time":"2022-03-01T12:00:00Z","level":"info","v":2,"msg":"base failed","error":"no kustomization"}
+{"time":"2022-03-01T12:00:00Z","level":"warning","v":0,"msg":"var X is never referenced","code":"unused-var"}
+`, out.String())
+}
+
+func TestSetFormat(t *testing.T) {
+	setUp(t, 0, FormatText)
+	assert.EqualError(t, SetFormat("xml"),
+		`unknown log format "xml"; expected one of [text json]`)
+	assert.False(t, V(1).Enabled())
+	assert.True(t, V(0).Enabled())
+}
@@ -32,6 +32,12 @@ func New() ResMap {
 	return newOne()
 }
 
+// NewFromResources returns a ResMap holding the resources,
+// in order, or an error if two have the same current id.
+func NewFromResources(resources []*resource.Resource) (ResMap, error) {
+	return newResMapFromResourceSlice(resources)
+}
+
 // FromResource returns a ResMap with one entry.
 func (rmF *Factory) FromResource(res *resource.Resource) ResMap {
 	m, err := newResMapFromResourceSlice([]*resource.Resource{res})
@@ -125,12 +131,9 @@ func (rmF *Factory) FromSecretArgs(
 
 func newResMapFromResourceSlice(
 	resources []*resource.Resource) (ResMap, error) {
-	result := New()
-	for _, res := range resources {
-		err := result.Append(res)
-		if err != nil {
-			return nil, err
-		}
+	result := newOne()
+	if err := result.appendAll(resources); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
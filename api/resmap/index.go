@@ -0,0 +1,305 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+// Prefixes of the keys of the buckets of an Index.
+const (
+	// keyName prefixes current names.
+	keyName = "n:"
+	// keyAnyName prefixes the names of any id, current or
+	// previous.
+	keyAnyName = "a:"
+	// keyKind prefixes current kinds.
+	keyKind = "k:"
+	// keyLabel prefixes labels, as key=value.
+	keyLabel = "l:"
+	// keyOrgNamespace prefixes original effective namespaces.
+	keyOrgNamespace = "o:"
+)
+
+// Index indexes the resources of a ResMap by name, kind, label
+// and original namespace, for lookups that needn't scan them
+// all.  It's a snapshot: it mustn't be used once resources are
+// renamed, relabeled or moved, as transformers do, or the
+// ResMap changes.  Lookups return resources in ResMap order.
+type Index struct {
+	// resources are the resources indexed, in order.
+	resources *[]*resource.Resource
+	// pos maps the resources to their position, or is nil if
+	// they aren't indexed, e.g. as one is there twice, which
+	// makes lookups scan them.
+	pos map[*resource.Resource]int
+	// keys maps the resources to the keys they're indexed by.
+	keys map[*resource.Resource][]string
+	// buckets maps keys to the resources with them, in order.
+	buckets map[string][]*resource.Resource
+}
+
+// NewIndex returns an index of the resources of the ResMap.
+func NewIndex(m ResMap) *Index {
+	resources := m.Resources()
+	return newIndex(&resources)
+}
+
+func newIndex(resources *[]*resource.Resource) *Index {
+	x := &Index{
+		resources: resources,
+		pos:       make(map[*resource.Resource]int, len(*resources)),
+		keys:      make(map[*resource.Resource][]string, len(*resources)),
+		buckets:   make(map[string][]*resource.Resource),
+	}
+	for i := range *resources {
+		x.added(i)
+	}
+	return x
+}
+
+// unindexed returns an Index of the resources that scans them,
+// for single lookups, not worth indexing them.
+func unindexed(resources *[]*resource.Resource) *Index {
+	return &Index{resources: resources}
+}
+
+// indexKeys returns the keys the resource is indexed by.
+func indexKeys(r *resource.Resource) []string {
+	cur := r.CurId()
+	prev := r.PrevIds()
+	org := cur
+	if len(prev) > 0 {
+		org = prev[0]
+	}
+	keys := []string{
+		keyName + cur.Name,
+		keyAnyName + cur.Name,
+		keyKind + cur.Kind,
+		keyOrgNamespace + org.EffectiveNamespace(),
+	}
+	for _, id := range prev {
+		keys = append(keys, keyAnyName+id.Name)
+	}
+	for k, v := range r.GetLabels() {
+		keys = append(keys, keyLabel+k+"="+v)
+	}
+	// Drop duplicates, e.g. names kept by renames.
+	sort.Strings(keys)
+	result := keys[:0]
+	for i, k := range keys {
+		if i == 0 || k != keys[i-1] {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// added indexes the i-th resource, added after the others.
+func (x *Index) added(i int) {
+	if x.pos == nil {
+		return
+	}
+	r := (*x.resources)[i]
+	if _, ok := x.pos[r]; ok {
+		x.pos, x.keys, x.buckets = nil, nil, nil
+		return
+	}
+	x.pos[r] = i
+	x.keys[r] = indexKeys(r)
+	for _, k := range x.keys[r] {
+		x.buckets[k] = append(x.buckets[k], r)
+	}
+}
+
+// replaced indexes the i-th resource in place of the old one.
+func (x *Index) replaced(i int, old *resource.Resource) {
+	if x.pos == nil {
+		return
+	}
+	for _, k := range x.keys[old] {
+		b := x.buckets[k]
+		for j := range b {
+			if b[j] == old {
+				b = append(b[:j], b[j+1:]...)
+				break
+			}
+		}
+		x.buckets[k] = b
+	}
+	delete(x.keys, old)
+	delete(x.pos, old)
+	r := (*x.resources)[i]
+	if _, ok := x.pos[r]; ok {
+		x.pos, x.keys, x.buckets = nil, nil, nil
+		return
+	}
+	x.pos[r] = i
+	x.keys[r] = indexKeys(r)
+	for _, k := range x.keys[r] {
+		b := x.buckets[k]
+		j := sort.Search(len(b), func(j int) bool { return x.pos[b[j]] > i })
+		b = append(b, nil)
+		copy(b[j+1:], b[j:])
+		b[j] = r
+		x.buckets[k] = b
+	}
+}
+
+// lookup returns the resources indexed with the key, or all of
+// them if they aren't indexed, that match.
+func (x *Index) lookup(
+	key string, match func(*resource.Resource) bool) []*resource.Resource {
+	candidates := *x.resources
+	if x.pos != nil {
+		candidates = x.buckets[key]
+	}
+	var result []*resource.Resource
+	for _, r := range candidates {
+		if match(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// indexOf returns the position of the resource, or -1.
+func (x *Index) indexOf(r *resource.Resource) int {
+	if x.pos != nil {
+		if i, ok := x.pos[r]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, o := range *x.resources {
+		if o == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func (x *Index) withCurrentId(id resid.ResId) []*resource.Resource {
+	return x.lookup(keyName+id.Name, func(r *resource.Resource) bool {
+		return id.Equals(r.CurId())
+	})
+}
+
+func (x *Index) withAnyId(id resid.ResId) []*resource.Resource {
+	return x.lookup(keyAnyName+id.Name, func(r *resource.Resource) bool {
+		return hasAnyId(r, id.Equals)
+	})
+}
+
+// indexOfCurrentId is ResMap.GetIndexOfCurrentId.
+func (x *Index) indexOfCurrentId(id resid.ResId) (int, error) {
+	matches := x.withCurrentId(id)
+	if len(matches) > 1 {
+		return -1, fmt.Errorf("id matched %d resources", len(matches))
+	}
+	if len(matches) == 0 {
+		return -1, nil
+	}
+	return x.indexOf(matches[0]), nil
+}
+
+// Named returns the resources whose current or previous name
+// is the given one.
+func (x *Index) Named(name string) []*resource.Resource {
+	return x.lookup(keyAnyName+name, func(r *resource.Resource) bool {
+		return hasAnyId(r, func(id resid.ResId) bool {
+			return id.Name == name
+		})
+	})
+}
+
+// HasOriginalNamespace returns true if the namespace is a key
+// of the GroupedByOriginalNamespace of the ResMap.
+func (x *Index) HasOriginalNamespace(namespace string) bool {
+	if namespace == resid.TotallyNotANamespace {
+		return false
+	}
+	return len(x.lookup(keyOrgNamespace+namespace, func(r *resource.Resource) bool {
+		return r.OrgId().EffectiveNamespace() == namespace
+	})) > 0
+}
+
+// GetByCurrentId is ResMap.GetByCurrentId.
+func (x *Index) GetByCurrentId(id resid.ResId) (*resource.Resource, error) {
+	return demandOneMatch(x.withCurrentId(id), id, "Current")
+}
+
+// GetById is ResMap.GetById.
+func (x *Index) GetById(id resid.ResId) (*resource.Resource, error) {
+	r, err := demandOneMatch(x.withAnyId(id), id, "Id")
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%s; failed to find unique target for patch %s",
+			err.Error(), id.String())
+	}
+	return r, nil
+}
+
+// Select is ResMap.Select.
+func (x *Index) Select(s types.Selector) ([]*resource.Resource, error) {
+	return selectResources(x.selectCandidates(s), s)
+}
+
+// literal matches the selector names and kinds that match only
+// themselves as regular expressions.
+var literal = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// labelEquality matches the label selector requirements of a
+// label value.
+var labelEquality = regexp.MustCompile(
+	`^\s*([A-Za-z0-9._/-]+)\s*==?\s*([A-Za-z0-9._-]*)\s*$`)
+
+// selectCandidates returns the resources the selector may
+// select, narrowed down by the name, kind or label values it
+// requires, if any.
+func (x *Index) selectCandidates(s types.Selector) []*resource.Resource {
+	result := *x.resources
+	if x.pos == nil {
+		return result
+	}
+	var keys []string
+	if literal.MatchString(s.Name) {
+		keys = append(keys, keyAnyName+s.Name)
+	}
+	if literal.MatchString(s.Kind) {
+		keys = append(keys, keyKind+s.Kind)
+	}
+	keys = append(keys, labelKeys(s.LabelSelector)...)
+	for _, k := range keys {
+		if b := x.buckets[k]; len(b) < len(result) {
+			result = b
+		}
+	}
+	return result
+}
+
+// labelKeys returns the keys of the label values the selector
+// requires, unless it has other requirements, whose checks,
+// and errors, are left to the selector.
+func labelKeys(selector string) []string {
+	if strings.TrimSpace(selector) == "" {
+		return nil
+	}
+	var keys []string
+	for _, term := range strings.Split(selector, ",") {
+		m := labelEquality.FindStringSubmatch(term)
+		if m == nil {
+			return nil
+		}
+		keys = append(keys, keyLabel+m[1]+"="+m[2])
+	}
+	return keys
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+func TestIndexSelect(t *testing.T) {
+	m := setupRMForPatchTargets(t)
+	x := NewIndex(m)
+	for name, s := range map[string]types.Selector{
+		"name":           {ResId: resid.ResId{Name: "name1"}},
+		"name regex":     {ResId: resid.ResId{Name: "name.*"}},
+		"name dot":       {ResId: resid.ResId{Name: "x.name1"}},
+		"kind":           {ResId: resid.ResId{Gvk: resid.Gvk{Kind: "Kind2"}}},
+		"kind and name":  {ResId: resid.ResId{Gvk: resid.Gvk{Kind: "Kind1"}, Name: "name2"}},
+		"namespace":      {ResId: resid.ResId{Namespace: "default"}},
+		"label":          {LabelSelector: "app=name1"},
+		"label ==":       {LabelSelector: "app == name3"},
+		"labels":         {LabelSelector: "app=name1,app=name2"},
+		"label set":      {LabelSelector: "app in (name1, name2)"},
+		"label exists":   {LabelSelector: "app"},
+		"label and kind": {ResId: resid.ResId{Gvk: resid.Gvk{Kind: "Kind2"}}, LabelSelector: "app=name1"},
+		"annotation":     {AnnotationSelector: "foo=bar"},
+		"no match":       {ResId: resid.ResId{Name: "NotMatched"}},
+		"everything":     {},
+		"bad label":      {LabelSelector: "app=name1,=="},
+	} {
+		t.Run(name, func(t *testing.T) {
+			expected, expectedErr := m.Select(s)
+			actual, err := x.Select(s)
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestIndexGetById(t *testing.T) {
+	m := setupRMForPatchTargets(t)
+	r := m.Resources()[0]
+	r.StorePreviousId()
+	require.NoError(t, r.SetName("renamed"))
+	x := NewIndex(m)
+
+	got, err := x.GetById(r.OrgId())
+	require.NoError(t, err)
+	assert.Same(t, r, got)
+	got, err = x.GetById(r.CurId())
+	require.NoError(t, err)
+	assert.Same(t, r, got)
+	_, err = x.GetByCurrentId(r.OrgId())
+	assert.Error(t, err)
+	got, err = x.GetByCurrentId(r.CurId())
+	require.NoError(t, err)
+	assert.Same(t, r, got)
+
+	_, err = x.GetById(resid.NewResId(resid.Gvk{Kind: "Kind1"}, "missing"))
+	assert.EqualError(t, err,
+		"no matches for Id Kind1.[noVer].[noGrp]/missing.[noNs]; "+
+			"failed to find unique target for patch Kind1.[noVer].[noGrp]/missing.[noNs]")
+}
+
+func TestIndexNamed(t *testing.T) {
+	m := setupRMForPatchTargets(t)
+	r := m.Resources()[0]
+	r.StorePreviousId()
+	require.NoError(t, r.SetName("renamed"))
+	x := NewIndex(m)
+
+	assert.Equal(t, m.Resources()[:1], x.Named("name1"))
+	assert.Equal(t, m.Resources()[:1], x.Named("renamed"))
+	assert.Equal(t, m.Resources()[1:2], x.Named("name2"))
+	assert.Empty(t, x.Named("name"))
+}
+
+func TestIndexHasOriginalNamespace(t *testing.T) {
+	m := setupRMForPatchTargets(t)
+	r := m.Resources()[0]
+	r.StorePreviousId()
+	require.NoError(t, r.SetNamespace("moved"))
+	x := NewIndex(m)
+
+	for ns := range m.GroupedByOriginalNamespace() {
+		assert.True(t, x.HasOriginalNamespace(ns), ns)
+	}
+	assert.True(t, x.HasOriginalNamespace("ns1"))
+	assert.False(t, x.HasOriginalNamespace("moved"))
+	assert.False(t, x.HasOriginalNamespace(resid.TotallyNotANamespace))
+}
@@ -74,12 +74,19 @@ func (m *resWrangler) Resources() []*resource.Resource {
 
 // Append implements ResMap.
 func (m *resWrangler) Append(res *resource.Resource) error {
+	return m.appendIndexed(res, unindexed(&m.rList))
+}
+
+// appendIndexed appends with an Id check looking up the
+// index of the resources, which it updates.
+func (m *resWrangler) appendIndexed(res *resource.Resource, x *Index) error {
 	id := res.CurId()
-	if r := m.GetMatchingResourcesByCurrentId(id.Equals); len(r) > 0 {
+	if r := x.withCurrentId(id); len(r) > 0 {
 		return fmt.Errorf(
 			"may not add resource with an already registered id: %s", id)
 	}
 	m.append(res)
+	x.added(len(m.rList) - 1)
 	return nil
 }
 
@@ -105,15 +112,23 @@ func (m *resWrangler) Remove(adios resid.ResId) error {
 
 // Replace implements ResMap.
 func (m *resWrangler) Replace(res *resource.Resource) (int, error) {
+	return m.replaceIndexed(res, unindexed(&m.rList))
+}
+
+// replaceIndexed replaces looking up the index of the
+// resources, which it updates.
+func (m *resWrangler) replaceIndexed(res *resource.Resource, x *Index) (int, error) {
 	id := res.CurId()
-	i, err := m.GetIndexOfCurrentId(id)
+	i, err := x.indexOfCurrentId(id)
 	if err != nil {
 		return -1, errors.Wrap(err, "in Replace")
 	}
 	if i < 0 {
 		return -1, fmt.Errorf("cannot find resource with id %s to replace", id)
 	}
+	old := m.rList[i]
 	m.rList[i] = res
+	x.replaced(i, old)
 	return i, nil
 }
 
@@ -152,18 +167,7 @@ func (m *resWrangler) GetByIndex(i int) *resource.Resource {
 
 // GetIndexOfCurrentId implements ResMap.
 func (m *resWrangler) GetIndexOfCurrentId(id resid.ResId) (int, error) {
-	count := 0
-	result := -1
-	for i, r := range m.rList {
-		if id.Equals(r.CurId()) {
-			count++
-			result = i
-		}
-	}
-	if count > 1 {
-		return -1, fmt.Errorf("id matched %d resources", count)
-	}
-	return result, nil
+	return unindexed(&m.rList).indexOfCurrentId(id)
 }
 
 type IdFromResource func(r *resource.Resource) resid.ResId
@@ -181,16 +185,24 @@ func (m *resWrangler) GetMatchingResourcesByAnyId(
 	matches IdMatcher) []*resource.Resource {
 	var result []*resource.Resource
 	for _, r := range m.rList {
-		for _, id := range append(r.PrevIds(), r.CurId()) {
-			if matches(id) {
-				result = append(result, r)
-				break
-			}
+		if hasAnyId(r, matches) {
+			result = append(result, r)
 		}
 	}
 	return result
 }
 
+// hasAnyId returns true if any id of the resource, previous
+// or current, matches.
+func hasAnyId(r *resource.Resource, matches IdMatcher) bool {
+	for _, id := range append(r.PrevIds(), r.CurId()) {
+		if matches(id) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *resWrangler) filteredById(
 	matches IdMatcher, idGetter IdFromResource) []*resource.Resource {
 	var result []*resource.Resource
@@ -205,26 +217,17 @@ func (m *resWrangler) filteredById(
 // GetByCurrentId implements ResMap.
 func (m *resWrangler) GetByCurrentId(
 	id resid.ResId) (*resource.Resource, error) {
-	return demandOneMatch(m.GetMatchingResourcesByCurrentId, id, "Current")
+	return unindexed(&m.rList).GetByCurrentId(id)
 }
 
 // GetById implements ResMap.
 func (m *resWrangler) GetById(
 	id resid.ResId) (*resource.Resource, error) {
-	r, err := demandOneMatch(m.GetMatchingResourcesByAnyId, id, "Id")
-	if err != nil {
-		return nil, fmt.Errorf(
-			"%s; failed to find unique target for patch %s",
-			err.Error(), id.String())
-	}
-	return r, nil
+	return unindexed(&m.rList).GetById(id)
 }
 
-type resFinder func(IdMatcher) []*resource.Resource
-
 func demandOneMatch(
-	f resFinder, id resid.ResId, s string) (*resource.Resource, error) {
-	r := f(id.Equals)
+	r []*resource.Resource, id resid.ResId, s string) (*resource.Resource, error) {
 	if len(r) == 1 {
 		return r[0], nil
 	}
@@ -465,8 +468,9 @@ func (m *resWrangler) AppendAll(other ResMap) error {
 
 // appendAll appends all the resources, error on Id collision.
 func (m *resWrangler) appendAll(list []*resource.Resource) error {
+	x := newIndex(&m.rList)
 	for _, res := range list {
-		if err := m.Append(res); err != nil {
+		if err := m.appendIndexed(res, x); err != nil {
 			return err
 		}
 	}
@@ -482,8 +486,9 @@ func (m *resWrangler) AbsorbAll(other ResMap) error {
 	if !ok {
 		return fmt.Errorf("bad cast to resWrangler 4")
 	}
+	x := newIndex(&m.rList)
 	for _, r := range m2.rList {
-		err := m.appendReplaceOrMerge(r)
+		err := m.appendReplaceOrMerge(r, x)
 		if err != nil {
 			return err
 		}
@@ -554,9 +559,10 @@ func (m *resWrangler) RemoveTransformerAnnotations() error {
 	return nil
 }
 
-func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
+func (m *resWrangler) appendReplaceOrMerge(
+	res *resource.Resource, x *Index) error {
 	id := res.CurId()
-	matches := m.GetMatchingResourcesByAnyId(id.Equals)
+	matches := x.withAnyId(id)
 	switch len(matches) {
 	case 0:
 		switch res.Behavior() {
@@ -565,14 +571,14 @@ func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
 				"id %#v does not exist; cannot merge or replace", id)
 		default:
 			// presumably types.BehaviorCreate
-			return m.Append(res)
+			return m.appendIndexed(res, x)
 		}
 	case 1:
 		old := matches[0]
 		if old == nil {
 			return fmt.Errorf("id lookup failure")
 		}
-		index := m.indexOfResource(old)
+		index := x.indexOf(old)
 		if index < 0 {
 			return fmt.Errorf("indexing problem")
 		}
@@ -596,7 +602,7 @@ func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
 			return fmt.Errorf(
 				"id %#v exists; behavior must be merge or replace", id)
 		}
-		i, err := m.Replace(res)
+		i, err := m.replaceIndexed(res, x)
 		if err != nil {
 			return err
 		}
@@ -627,12 +633,19 @@ func (m *resWrangler) AnnotateAll(key string, value string) error {
 // Select returns a list of resources that
 // are selected by a Selector
 func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
+	return unindexed(&m.rList).Select(s)
+}
+
+// selectResources returns the resources of the list
+// selected by the Selector.
+func selectResources(
+	list []*resource.Resource, s types.Selector) ([]*resource.Resource, error) {
 	var result []*resource.Resource
 	sr, err := types.NewSelectorRegex(&s)
 	if err != nil {
 		return nil, err
 	}
-	for _, r := range m.rList {
+	for _, r := range list {
 		curId := r.CurId()
 		orgId := r.OrgId()
 
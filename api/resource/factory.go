@@ -72,6 +72,7 @@ func (rf *Factory) makeOne(rn *yaml.RNode, o *types.GeneratorArgs) *Resource {
 	if rn == nil {
 		log.Fatal("RNode must not be null")
 	}
+	internStrings(rn.YNode())
 	resource := &Resource{RNode: *rn}
 	if o != nil {
 		if o.Options == nil || !o.Options.DisableNameSuffixHash {
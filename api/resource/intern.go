@@ -0,0 +1,61 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// maxInterned bounds the number of strings interned, so
+	// that builds of resources with many distinct values, e.g.
+	// the data of ConfigMaps, don't keep them all.
+	maxInterned = 1 << 14
+	// maxInternedLen is the length of the longest string
+	// interned; longer ones are rarely repeated.
+	maxInternedLen = 64
+)
+
+// interned holds strings found in many resources, e.g. field
+// names, kinds and label values, so that the resources share
+// them rather than each holding a copy, as parsing makes.
+var interned = struct {
+	sync.Mutex
+	strings map[string]string
+}{strings: make(map[string]string)}
+
+// internStrings replaces the strings of the scalars of the
+// tree, e.g. field names, with interned copies.
+func internStrings(n *yaml.Node) {
+	interned.Lock()
+	defer interned.Unlock()
+	internNode(n)
+}
+
+func internNode(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode {
+		n.Value = intern(n.Value)
+		return
+	}
+	for _, c := range n.Content {
+		internNode(c)
+	}
+}
+
+// intern returns the interned copy of s, interning s if there's
+// room.  The caller must hold the lock of interned.
+func intern(s string) string {
+	if len(s) > maxInternedLen {
+		return s
+	}
+	if is, ok := interned.strings[s]; ok {
+		return is
+	}
+	if len(interned.strings) < maxInterned {
+		interned.strings[s] = s
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestInternStrings(t *testing.T) {
+	long := strings.Repeat("x", maxInternedLen+1)
+	resources, err := NewFactory(nil).SliceFromBytes([]byte(fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+data:
+  key: %s
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+data:
+  key: %s
+`, long, long)))
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	first := resources[0].YNode()
+	second := resources[1].YNode()
+	// Field names, and short values, are shared.
+	assert.Equal(t, "metadata", second.Content[4].Value)
+	assert.Equal(t,
+		stringData(first.Content[4].Value), stringData(second.Content[4].Value))
+	assert.Equal(t, "ConfigMap", second.Content[3].Value)
+	assert.Equal(t,
+		stringData(first.Content[3].Value), stringData(second.Content[3].Value))
+	// Long values aren't.
+	assert.NotEqual(t,
+		stringData(first.Content[7].Content[1].Value),
+		stringData(second.Content[7].Content[1].Value))
+	assert.Equal(t, long, second.Content[7].Content[1].Value)
+}
+
+func TestInternBounded(t *testing.T) {
+	interned.Lock()
+	defer interned.Unlock()
+	saved := interned.strings
+	defer func() { interned.strings = saved }()
+	interned.strings = make(map[string]string)
+	for i := 0; i < maxInterned+10; i++ {
+		intern(fmt.Sprintf("value%d", i))
+	}
+	assert.Len(t, interned.strings, maxInterned)
+}
@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/utils"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/resid"
@@ -35,6 +36,9 @@ var BuildAnnotations = []string{
 	utils.BuildAnnotationPreviousNamespaces,
 	utils.BuildAnnotationAllowNameChange,
 	utils.BuildAnnotationAllowKindChange,
+	utils.BuildAnnotationMergeLists,
+	utils.BuildAnnotationForcePatch,
+	utils.BuildAnnotationNotInherited,
 	utils.BuildAnnotationsRefBy,
 	utils.BuildAnnotationsGenBehavior,
 	utils.BuildAnnotationsGenAddHashSuffix,
@@ -336,6 +340,33 @@ func (r *Resource) KindChangeAllowed() bool {
 	return r.isEnabled(utils.BuildAnnotationAllowKindChange)
 }
 
+// SetMergeLists sets the way a patch resource merges lists,
+// one of the types.MergeLists* values.
+func (r *Resource) SetMergeLists(mergeLists string) {
+	annotations := r.GetAnnotations()
+	annotations[utils.BuildAnnotationMergeLists] = mergeLists
+	if err := r.SetAnnotations(annotations); err != nil {
+		panic(err)
+	}
+}
+
+// MergeLists returns the way a patch resource merges lists.
+func (r *Resource) MergeLists() string {
+	return r.GetAnnotations()[utils.BuildAnnotationMergeLists]
+}
+
+// ForcePatch makes a patch resource replace the fields of
+// another resource that hold another type of value.
+func (r *Resource) ForcePatch() {
+	r.enable(utils.BuildAnnotationForcePatch)
+}
+
+// PatchForced checks if a patch resource replaces the fields of
+// another resource that hold another type of value.
+func (r *Resource) PatchForced() bool {
+	return r.isEnabled(utils.BuildAnnotationForcePatch)
+}
+
 func (r *Resource) isEnabled(annoKey string) bool {
 	annotations := r.GetAnnotations()
 	v, ok := annotations[annoKey]
@@ -479,8 +510,21 @@ func (r *Resource) ApplySmPatch(patch *Resource) error {
 	if patch.NameChangeAllowed() || patch.KindChangeAllowed() {
 		r.StorePreviousId()
 	}
+	if patch.PatchForced() {
+		if err := dropMismatchedFields(&r.RNode, &patch.RNode); err != nil {
+			return err
+		}
+	}
+	listStrategy := kyaml.MergeOptionsListMergeByKey
+	switch patch.MergeLists() {
+	case types.MergeListsAppend:
+		listStrategy = kyaml.MergeOptionsListAppendAll
+	case types.MergeListsReplace:
+		listStrategy = kyaml.MergeOptionsListReplaceAll
+	}
 	if err := r.ApplyFilter(patchstrategicmerge.Filter{
-		Patch: &patch.RNode,
+		Patch:        &patch.RNode,
+		ListStrategy: listStrategy,
 	}); err != nil {
 		return err
 	}
@@ -497,8 +541,32 @@ func (r *Resource) ApplySmPatch(patch *Resource) error {
 	return nil
 }
 
+// dropMismatchedFields removes, from the fields of node, those
+// that the patch sets to another type of value, e.g. a list
+// rather than a map, so that the patch replaces them.
+func dropMismatchedFields(node, patch *kyaml.RNode) error {
+	if node.YNode().Kind != kyaml.MappingNode ||
+		patch.YNode().Kind != kyaml.MappingNode {
+		return nil
+	}
+	return patch.VisitFields(func(field *kyaml.MapNode) error {
+		target := node.Field(field.Key.YNode().Value)
+		if target == nil || kyaml.IsMissingOrNull(field.Value) ||
+			kyaml.IsMissingOrNull(target.Value) {
+			return nil
+		}
+		if target.Value.YNode().Kind != field.Value.YNode().Kind {
+			return node.PipeE(kyaml.Clear(field.Key.YNode().Value))
+		}
+		return dropMismatchedFields(target.Value, field.Value)
+	})
+}
+
 func (r *Resource) ApplyFilter(f kio.Filter) error {
 	l, err := f.Filter([]*kyaml.RNode{&r.RNode})
+	if err != nil {
+		err = errors.WithContext(err, "resource %s", r.CurId())
+	}
 	if len(l) == 0 {
 		// The node was deleted, which means the entire resource
 		// must be deleted.  Signal that via the following:
@@ -0,0 +1,24 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kusttest_test holds test harnesses for
+// kustomizations and plugins.
+//
+// Harness runs kustomizations held in a (usually in-memory)
+// file system and compares the output to expected YAML.
+//
+// HarnessEnhanced loads and runs individual plugins. Plugin
+// authors outside the kustomize repo can use it to unit test
+// their exec or Go plugins, e.g.
+//
+//	th := kusttest_test.MakeEnhancedHarnessWithPluginRoot(t, "../..")
+//	defer th.Reset()
+//	th.PrepExecPlugin("someteam.example.com", "v1", "Greeter")
+//	m := th.LoadAndRunGenerator(`
+//	apiVersion: someteam.example.com/v1
+//	kind: Greeter
+//	metadata:
+//	  name: greeter
+//	`)
+//	th.AssertActualEqualsExpected(m, expected)
+package kusttest_test
@@ -55,6 +55,21 @@ func MakeEnhancedHarness(t *testing.T) *HarnessEnhanced {
 	return r
 }
 
+// MakeEnhancedHarnessWithPluginRoot is like MakeEnhancedHarness,
+// but loads plugins from below the given directory rather than from
+// the kustomize repo, so that plugins maintained elsewhere can be
+// tested. Plugins must be laid out as they are in a plugin home, i.e.
+//
+//	${pluginRoot}/${group}/${version}/LOWERCASE(${kind})/${kind}
+func MakeEnhancedHarnessWithPluginRoot(
+	t *testing.T, pluginRoot string) *HarnessEnhanced {
+	r := makeBaseEnhancedHarnessWithEnv(
+		t, newPluginTestEnv(t).setWithRoot(pluginRoot))
+	r.Harness = MakeHarnessWithFs(t, filesys.MakeFsInMemory())
+	r.ResetLoaderRoot(filesys.Separator)
+	return r
+}
+
 func MakeEnhancedHarnessWithTmpRoot(t *testing.T) *HarnessEnhanced {
 	r := makeBaseEnhancedHarness(t)
 	fSys := filesys.MakeFsOnDisk()
@@ -72,10 +87,15 @@ func MakeEnhancedHarnessWithTmpRoot(t *testing.T) *HarnessEnhanced {
 }
 
 func makeBaseEnhancedHarness(t *testing.T) *HarnessEnhanced {
+	return makeBaseEnhancedHarnessWithEnv(t, newPluginTestEnv(t).set())
+}
+
+func makeBaseEnhancedHarnessWithEnv(
+	t *testing.T, pte *pluginTestEnv) *HarnessEnhanced {
 	rf := resmap.NewFactory(
 		provider.NewDefaultDepProvider().GetResourceFactory())
 	return &HarnessEnhanced{
-		pte: newPluginTestEnv(t).set(),
+		pte: pte,
 		rf:  rf,
 		pl: pLdr.NewLoader(
 			types.EnabledPluginConfig(types.BploLoadFromFileSys),
@@ -114,6 +134,12 @@ func (th *HarnessEnhanced) Reset() {
 	th.pte.reset()
 }
 
+// GetResMapFactory returns the factory the harness uses to
+// make ResMaps from YAML, e.g. to prepare transformer input.
+func (th *HarnessEnhanced) GetResMapFactory() *resmap.Factory {
+	return th.rf
+}
+
 func (th *HarnessEnhanced) GetPluginConfig() *types.PluginConfig {
 	return th.pl.Config()
 }
@@ -0,0 +1,59 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusttest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+func TestEnhancedHarnessWithPluginRoot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script plugin")
+	}
+	root := t.TempDir()
+	dir := filepath.Join(root, "someteam.example.com", "v1", "greeter")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Greeter"), []byte(`#!/bin/sh
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: greeting
+data:
+  greeting: hello
+EOF
+`), 0700))
+
+	old, wasSet := os.LookupEnv(konfig.KustomizePluginHomeEnv)
+	th := MakeEnhancedHarnessWithPluginRoot(t, root).
+		PrepExecPlugin("someteam.example.com", "v1", "Greeter")
+	require.Equal(t, root, os.Getenv(konfig.KustomizePluginHomeEnv))
+
+	m := th.LoadAndRunGenerator(`
+apiVersion: someteam.example.com/v1
+kind: Greeter
+metadata:
+  name: greeter
+`)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  greeting: hello
+kind: ConfigMap
+metadata:
+  name: greeting
+`)
+
+	th.Reset()
+	now, isSet := os.LookupEnv(konfig.KustomizePluginHomeEnv)
+	require.Equal(t, wasSet, isSet)
+	require.Equal(t, old, now)
+}
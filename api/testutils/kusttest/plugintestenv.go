@@ -33,11 +33,18 @@ func newPluginTestEnv(t *testing.T) *pluginTestEnv {
 // plugin code - this FileSystem has nothing to do with
 // the FileSystem used for loading config yaml in the tests.
 func (x *pluginTestEnv) set() *pluginTestEnv {
-	var err error
-	x.pluginRoot, err = utils.DeterminePluginSrcRoot(filesys.MakeFsOnDisk())
+	pluginRoot, err := utils.DeterminePluginSrcRoot(filesys.MakeFsOnDisk())
 	if err != nil {
 		x.t.Error(err)
 	}
+	return x.setWithRoot(pluginRoot)
+}
+
+// setWithRoot creates a test environment using plugins
+// below the given root, e.g. a directory outside the
+// kustomize repo holding third party plugins.
+func (x *pluginTestEnv) setWithRoot(pluginRoot string) *pluginTestEnv {
+	x.pluginRoot = pluginRoot
 	x.compiler = compiler.NewCompiler(x.pluginRoot)
 	x.setEnv()
 	return x
@@ -0,0 +1,17 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Conditional holds resources, components and patches that
+// are part of a kustomization only when its condition holds.
+type Conditional struct {
+	// When is a condition on a parameter given at build time,
+	// with the --set flag, of the form NAME=VALUE or NAME!=VALUE.
+	// A parameter that isn't given has the empty value.
+	When string `json:"when" yaml:"when"`
+
+	Resources  []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+	Patches    []Patch  `json:"patches,omitempty" yaml:"patches,omitempty"`
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+const (
+	// DataSourceKindConfigMap makes data sources generate
+	// ConfigMaps, the default.
+	DataSourceKindConfigMap = "ConfigMap"
+
+	// DataSourceKindSecret makes data sources generate Secrets.
+	DataSourceKindSecret = "Secret"
+)
+
+// DataSourceArgs declares a data source, a command printing a
+// JSON object whose keys and values become the data of a
+// generated ConfigMap or Secret, e.g. the outputs of terraform.
+// Values that aren't strings are written as JSON.
+type DataSourceArgs struct {
+	// GeneratorArgs for the ConfigMap or Secret.  Its sources
+	// are added to the output of the command.
+	GeneratorArgs `json:",inline,omitempty" yaml:",inline,omitempty"`
+
+	// Kind is ConfigMap, the default, or Secret.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Type of the Secret, if the kind is Secret.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Command prints the JSON object.  It runs in the
+	// directory of the kustomization, relative to which a path
+	// is resolved, else it's looked up in the PATH.
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+
+	// Args are the arguments of the command.
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+
+	// Schema is a file holding the JSON schema, in JSON or
+	// YAML, that the JSON object must match.
+	Schema string `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// enforceFields returns the errors of the data source, the
+// i-th one of the kustomization.
+func (ds *DataSourceArgs) enforceFields(i int) []string {
+	var errs []string
+	if ds.Name == "" {
+		errs = append(errs, fmt.Sprintf("dataSources[%d] needs a name", i))
+	}
+	if ds.Command == "" {
+		errs = append(errs, fmt.Sprintf("dataSources[%d] needs a command", i))
+	}
+	switch ds.Kind {
+	case "", DataSourceKindConfigMap:
+		if ds.Type != "" {
+			errs = append(errs, fmt.Sprintf(
+				"dataSources[%d] can only have a type with kind %s",
+				i, DataSourceKindSecret))
+		}
+	case DataSourceKindSecret:
+	default:
+		errs = append(errs, fmt.Sprintf(
+			"unknown dataSources[%d].kind %q; expected %s or %s",
+			i, ds.Kind, DataSourceKindConfigMap, DataSourceKindSecret))
+	}
+	return errs
+}
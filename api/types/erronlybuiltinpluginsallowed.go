@@ -19,6 +19,10 @@ func (e *errOnlyBuiltinPluginsAllowed) Error() string {
 		e.name)
 }
 
+func (e *errOnlyBuiltinPluginsAllowed) ErrorClass() ErrorClass {
+	return ErrorClassPlugin
+}
+
 func NewErrOnlyBuiltinPluginsAllowed(n string) *errOnlyBuiltinPluginsAllowed {
 	return &errOnlyBuiltinPluginsAllowed{name: n}
 }
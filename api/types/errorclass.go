@@ -0,0 +1,78 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrorClass is a class of failure, e.g. a missing file,
+// that callers such as CI pipelines may react to differently;
+// the kustomize CLI exits with a distinct code for each.
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is a failure of no known class.
+	ErrorClassUnknown ErrorClass = iota
+
+	// ErrorClassConfig is an invalid kustomization, plugin
+	// config or command line.
+	ErrorClassConfig
+
+	// ErrorClassMissingFile is a file or directory, e.g. a
+	// resource or base, that doesn't exist.
+	ErrorClassMissingFile
+
+	// ErrorClassRemote is a failure to fetch a remote base,
+	// resource or plugin, which may be worth retrying.
+	ErrorClassRemote
+
+	// ErrorClassPlugin is an external plugin failing to load
+	// or to run.
+	ErrorClassPlugin
+
+	// ErrorClassInternal is a bug of kustomize.
+	ErrorClassInternal
+)
+
+// errWithClass classifies an error, without changing its
+// message.
+type errWithClass struct {
+	class ErrorClass
+	err   error
+}
+
+func (e *errWithClass) Error() string { return e.err.Error() }
+
+func (e *errWithClass) ErrorClass() ErrorClass { return e.class }
+
+// Unwrap lets errors.Is and errors.As see the wrapped error.
+func (e *errWithClass) Unwrap() error { return e.err }
+
+// Cause lets github.com/pkg/errors.Cause see the wrapped error.
+func (e *errWithClass) Cause() error { return e.err }
+
+// WithErrorClass returns err classified as c, unless it's
+// nil.  An error already classified keeps its class.
+func WithErrorClass(err error, c ErrorClass) error {
+	if err == nil || ClassOfError(err) != ErrorClassUnknown {
+		return err
+	}
+	return &errWithClass{class: c, err: err}
+}
+
+// ClassOfError returns the class of the first error in the
+// chain of err that has one, i.e. that has an ErrorClass
+// method.  Files that don't exist are missing files.
+func ClassOfError(err error) ErrorClass {
+	var classified interface{ ErrorClass() ErrorClass }
+	if errors.As(err, &classified) {
+		return classified.ErrorClass()
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrorClassMissingFile
+	}
+	return ErrorClassUnknown
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/types"
+)
+
+func TestClassOfError(t *testing.T) {
+	plain := fmt.Errorf("boom")
+	config := WithErrorClass(plain, ErrorClassConfig)
+	testCases := map[string]struct {
+		err      error
+		expected ErrorClass
+	}{
+		"nil": {
+			err:      nil,
+			expected: ErrorClassUnknown,
+		},
+		"unclassified": {
+			err:      plain,
+			expected: ErrorClassUnknown,
+		},
+		"classified": {
+			err:      config,
+			expected: ErrorClassConfig,
+		},
+		"wrapped": {
+			err:      errors.Wrap(fmt.Errorf("in base: %w", config), "accumulating"),
+			expected: ErrorClassConfig,
+		},
+		"first class kept": {
+			err:      WithErrorClass(config, ErrorClassRemote),
+			expected: ErrorClassConfig,
+		},
+		"not exist": {
+			err:      fmt.Errorf("reading: %w", os.ErrNotExist),
+			expected: ErrorClassMissingFile,
+		},
+		"unable to find plugin": {
+			err:      NewErrUnableToFind("plugin", nil),
+			expected: ErrorClassPlugin,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ClassOfError(tc.err))
+		})
+	}
+}
+
+func TestWithErrorClass(t *testing.T) {
+	assert.NoError(t, WithErrorClass(nil, ErrorClassConfig))
+	err := WithErrorClass(fmt.Errorf("boom"), ErrorClassPlugin)
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, "boom", errors.Cause(err).Error())
+}
@@ -26,6 +26,12 @@ func (e *errUnableToFind) Error() string {
 		"unable to find %s - tried: %s", e.what, strings.Join(m, ", "))
 }
 
+// ErrorClass is that of plugin failures, as the plugin home
+// is what's looked for.
+func (e *errUnableToFind) ErrorClass() ErrorClass {
+	return ErrorClassPlugin
+}
+
 func NewErrUnableToFind(w string, a []Pair) *errUnableToFind {
 	return &errUnableToFind{what: w, attempts: a}
 }
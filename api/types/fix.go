@@ -5,6 +5,7 @@ package types
 
 import (
 	"regexp"
+	"strings"
 
 	"sigs.k8s.io/yaml"
 )
@@ -52,3 +53,41 @@ func useLegacyPatch(data []byte) (bool, error) {
 	}
 	return found, nil
 }
+
+// CurrentAPIVersion returns the apiVersion of the kustomization
+// files of the given kind read by this version of kustomize.
+func CurrentAPIVersion(kind string) string {
+	if kind == ComponentKind {
+		return ComponentVersion
+	}
+	return KustomizationVersion
+}
+
+// MigrateAPIVersion converts a kustomization of another version
+// of the kustomize.config.k8s.io group, e.g. a legacy or future
+// one, to the current version for its kind, whose fields are
+// read in its place.  It returns the apiVersion the kustomization
+// had, or an empty string if it didn't need converting.
+// Other kinds and groups, and components of the kustomization
+// version, which rather hints at a wrong kind, are left for
+// EnforceFields to report.
+func (k *Kustomization) MigrateAPIVersion() string {
+	if k.Kind != KustomizationKind && k.Kind != ComponentKind {
+		return ""
+	}
+	current := CurrentAPIVersion(k.Kind)
+	old := k.APIVersion
+	if old == current || !IsKustomizationAPIVersion(old) ||
+		(k.Kind == ComponentKind && old == KustomizationVersion) {
+		return ""
+	}
+	k.APIVersion = current
+	return old
+}
+
+// IsKustomizationAPIVersion returns true if the apiVersion is a
+// version of the kustomize.config.k8s.io group.
+func IsKustomizationAPIVersion(apiVersion string) bool {
+	group := strings.Split(KustomizationVersion, "/")[0]
+	return strings.HasPrefix(apiVersion, group+"/")
+}
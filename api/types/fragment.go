@@ -0,0 +1,58 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MergeFragment merges a fragment of the kustomization, read
+// from a file of its own, into k.  The lists of the fragment
+// are appended to those of k, and its maps merged with those
+// of k.  Any other field may be set in only one of them.
+// Call before FixKustomizationPostUnmarshalling.
+func (k *Kustomization) MergeFragment(f *Kustomization) error {
+	if f.Kind != "" && f.Kind != k.Kind &&
+		!(f.Kind == KustomizationKind && k.Kind == "") {
+		return fmt.Errorf("kind %s differs from that of the kustomization", f.Kind)
+	}
+	kv := reflect.ValueOf(k).Elem()
+	fv := reflect.ValueOf(f).Elem()
+	t := kv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Anonymous {
+			// TypeMeta, checked above.
+			continue
+		}
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		dst, src := kv.Field(i), fv.Field(i)
+		if src.IsZero() {
+			continue
+		}
+		switch src.Kind() {
+		case reflect.Slice:
+			dst.Set(reflect.AppendSlice(dst, src))
+		case reflect.Map:
+			if dst.IsNil() {
+				dst.Set(reflect.MakeMap(src.Type()))
+			}
+			iter := src.MapRange()
+			for iter.Next() {
+				old := dst.MapIndex(iter.Key())
+				if old.IsValid() && !reflect.DeepEqual(old.Interface(), iter.Value().Interface()) {
+					return fmt.Errorf("%s %v is set to different values", name, iter.Key())
+				}
+				dst.SetMapIndex(iter.Key(), iter.Value())
+			}
+		default:
+			if !dst.IsZero() && !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+				return fmt.Errorf("%s is set in more than one file", name)
+			}
+			dst.Set(src)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeFragment(t *testing.T) {
+	k := Kustomization{
+		Resources:    []string{"a.yaml"},
+		CommonLabels: map[string]string{"app": "web"},
+	}
+	f := Kustomization{
+		TypeMeta:     TypeMeta{Kind: KustomizationKind},
+		Resources:    []string{"b.yaml"},
+		CommonLabels: map[string]string{"app": "web", "team": "x"},
+		NamePrefix:   "dev-",
+		Patches:      []Patch{{Path: "patch.yaml"}},
+	}
+	if err := k.MergeFragment(&f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Kustomization{
+		Resources:    []string{"a.yaml", "b.yaml"},
+		CommonLabels: map[string]string{"app": "web", "team": "x"},
+		NamePrefix:   "dev-",
+		Patches:      []Patch{{Path: "patch.yaml"}},
+	}
+	if !reflect.DeepEqual(k, expected) {
+		t.Fatalf("expected %v but got %v", expected, k)
+	}
+}
+
+func TestMergeFragmentConflicts(t *testing.T) {
+	for name, tc := range map[string]struct {
+		k, f Kustomization
+		err  string
+	}{
+		"field": {
+			k:   Kustomization{NamePrefix: "dev-"},
+			f:   Kustomization{NamePrefix: "prod-"},
+			err: "namePrefix is set in more than one file",
+		},
+		"map key": {
+			k:   Kustomization{CommonLabels: map[string]string{"app": "web"}},
+			f:   Kustomization{CommonLabels: map[string]string{"app": "db"}},
+			err: "commonLabels app is set to different values",
+		},
+		"kind": {
+			k:   Kustomization{},
+			f:   Kustomization{TypeMeta: TypeMeta{Kind: ComponentKind}},
+			err: "kind Component differs from that of the kustomization",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.k.MergeFragment(&tc.f)
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("expected error %q but got %v", tc.err, err)
+			}
+		})
+	}
+}
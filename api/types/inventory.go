@@ -3,10 +3,46 @@
 
 package types
 
+const (
+	// InventoryTypeConfigMap is the type of inventories held
+	// in a ConfigMap, whose data keys name the objects.
+	InventoryTypeConfigMap = "ConfigMap"
+
+	// InventoryTypeResourceGroup is the type of inventories
+	// held in a ResourceGroup of the kpt.dev API group.
+	InventoryTypeResourceGroup = "ResourceGroup"
+
+	// InventoryIdLabel labels inventory objects with the id of
+	// the group of objects they record, as cli-utils does.
+	InventoryIdLabel = "cli-utils.sigs.k8s.io/inventory-id"
+)
+
 // Inventory records all objects touched in a build operation.
 type Inventory struct {
-	Type      string   `json:"type,omitempty" yaml:"type,omitempty"`
+	// Type is the type of the inventory object, ConfigMap if
+	// empty, or ResourceGroup.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// ConfigMap names the inventory object of type ConfigMap.
 	ConfigMap NameArgs `json:"configMap,omitempty" yaml:"configMap,omitempty"`
+
+	// ResourceGroup names the inventory object of type
+	// ResourceGroup.
+	ResourceGroup NameArgs `json:"resourceGroup,omitempty" yaml:"resourceGroup,omitempty"`
+
+	// Id identifies the group of objects recorded, across
+	// builds.  If empty, it's derived from the namespace and
+	// name of the inventory object.
+	Id string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// NameArgs returns the namespace and name of the inventory
+// object, per its type.
+func (inv *Inventory) NameArgs() NameArgs {
+	if inv.Type == InventoryTypeResourceGroup {
+		return inv.ResourceGroup
+	}
+	return inv.ConfigMap
 }
 
 // NameArgs holds both namespace and name.
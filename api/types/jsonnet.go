@@ -0,0 +1,22 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "path/filepath"
+
+// JsonnetArgs configures the evaluation of the jsonnet files
+// among the resources of a kustomization.
+type JsonnetArgs struct {
+	// ImportPaths are directories, relative to the
+	// kustomization, searched for the files jsonnet imports,
+	// in order, after the directory of the importing file.
+	ImportPaths []string `json:"importPaths,omitempty" yaml:"importPaths,omitempty"`
+}
+
+// IsJsonnetFile returns true if the resources entry is a
+// jsonnet file, evaluated into resources at build time.
+func IsJsonnetFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".jsonnet" || ext == ".libsonnet"
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/yaml"
 )
@@ -32,7 +33,9 @@ type Kustomization struct {
 	// MetaData is a pointer to avoid marshalling empty struct
 	MetaData *ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 
-	// OpenAPI contains information about what kubernetes schema to use.
+	// OpenAPI contains information about what kubernetes schema to use:
+	// a builtin "version", the "path" of a schema file, or a
+	// "source" of cluster, for the schema of the current cluster.
 	OpenAPI map[string]string `json:"openapi,omitempty" yaml:"openapi,omitempty"`
 
 	//
@@ -47,6 +50,14 @@ type Kustomization struct {
 	// file including generated configmaps and secrets.
 	NameSuffix string `json:"nameSuffix,omitempty" yaml:"nameSuffix,omitempty"`
 
+	// NamePrefixes add prefixes to the names of the resources of
+	// the kinds they select, after NamePrefix.
+	NamePrefixes []NameAffix `json:"namePrefixes,omitempty" yaml:"namePrefixes,omitempty"`
+
+	// NameSuffixes add suffixes to the names of the resources of
+	// the kinds they select, after NameSuffix.
+	NameSuffixes []NameAffix `json:"nameSuffixes,omitempty" yaml:"nameSuffixes,omitempty"`
+
 	// Namespace to add to all objects.
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
@@ -62,17 +73,23 @@ type Kustomization struct {
 	// PatchesStrategicMerge specifies the relative path to a file
 	// containing a strategic merge patch.  Format documented at
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/strategic-merge-patch.md
-	// URLs and globs are not supported.
+	// URLs and globs are not supported.  An entry may be written as an
+	// object, a PatchStrategicMergeEntry, to set how the patch merges
+	// lists, or to force it.
+	// Deprecated.  Use Patches, to which 'kustomize edit fix' moves
+	// the patches it can.
 	PatchesStrategicMerge []PatchStrategicMerge `json:"patchesStrategicMerge,omitempty" yaml:"patchesStrategicMerge,omitempty"`
 
 	// JSONPatches is a list of JSONPatch for applying JSON patch.
 	// Format documented at https://tools.ietf.org/html/rfc6902
 	// and http://jsonpatch.com
+	// Deprecated.  Use Patches, to which 'kustomize edit fix' moves them.
 	PatchesJson6902 []Patch `json:"patchesJson6902,omitempty" yaml:"patchesJson6902,omitempty"`
 
 	// Patches is a list of patches, where each one can be either a
 	// Strategic Merge Patch or a JSON patch.
-	// Each patch can be applied to multiple target objects.
+	// Each patch can be applied to multiple target objects,
+	// selected by a target whose fields may be regular expressions.
 	Patches []Patch `json:"patches,omitempty" yaml:"patches,omitempty"`
 
 	// Images is a list of (image name, new name, new tag or digest)
@@ -94,7 +111,11 @@ type Kustomization struct {
 	// contain a value of type string/bool/int/float, and defaults to the name field
 	// of the instance.  Any appearance of "$(FOO)" in the object
 	// spec will be replaced at kustomize build time, after the final
-	// value of the specified field has been determined.
+	// value of the specified field has been determined.  In the data of
+	// a ConfigMap or Secret, only the keys listed in its
+	// kustomize.config.k8s.io/substitute-keys annotation are replaced.
+	// Deprecated.  Use Replacements, to which 'kustomize edit fix --vars'
+	// converts vars.
 	Vars []Var `json:"vars,omitempty" yaml:"vars,omitempty"`
 
 	//
@@ -103,14 +124,27 @@ type Kustomization struct {
 
 	// Resources specifies relative paths to files holding YAML representations
 	// of kubernetes API objects, or specifications of other kustomizations
-	// via relative paths, absolute paths, or URLs.
+	// via relative paths, absolute paths, or URLs.  A path may be a glob
+	// pattern, e.g. manifests/*.yaml, with ** matching any number of
+	// directories, standing for the files it matches in order of their paths.
+	// An entry of the form {inline: OBJECT} is read as the YAML of the object;
+	// see IsInlineResource.  An entry of the form {path: PATH, options: OPTIONS}
+	// sets options for the resources at PATH; see ResourceEntry.
 	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
 
 	// Components specifies relative paths to specifications of other Components
 	// via relative paths, absolute paths, or URLs.
 	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
 
-	// Crds specifies relative paths to Custom Resource Definition files.
+	// Conditionals hold resources, components and patches that are
+	// included, after those listed above, only when their condition
+	// on the parameters given at build time holds.
+	Conditionals []Conditional `json:"conditionals,omitempty" yaml:"conditionals,omitempty"`
+
+	// Crds specifies relative paths, or URLs, to Custom Resource Definition
+	// files, holding either open API definitions or the
+	// CustomResourceDefinition objects.  The x-kubernetes-* extensions of
+	// their schemas tell which fields refer to names, vars and namespaces.
 	// This allows custom resources to be recognized as operands, making
 	// it possible to add them to the Resources list.
 	// CRDs themselves are not modified.
@@ -139,9 +173,18 @@ type Kustomization struct {
 	// the map will have a suffix hash generated from its contents.
 	SecretGenerator []SecretArgs `json:"secretGenerator,omitempty" yaml:"secretGenerator,omitempty"`
 
+	// DataSources is a list of ConfigMaps and Secrets to
+	// generate from the output of commands, e.g. to use as the
+	// sources of replacements.
+	DataSources []DataSourceArgs `json:"dataSources,omitempty" yaml:"dataSources,omitempty"`
+
 	// HelmGlobals contains helm configuration that isn't chart specific.
 	HelmGlobals *HelmGlobals `json:"helmGlobals,omitempty" yaml:"helmGlobals,omitempty"`
 
+	// Jsonnet configures the evaluation of the jsonnet files
+	// among the resources.
+	Jsonnet *JsonnetArgs `json:"jsonnet,omitempty" yaml:"jsonnet,omitempty"`
+
 	// HelmCharts is a list of helm chart configuration instances.
 	HelmCharts []HelmChart `json:"helmCharts,omitempty" yaml:"helmCharts,omitempty"`
 
@@ -158,18 +201,39 @@ type Kustomization struct {
 	// Generators is a list of files containing custom generators
 	Generators []string `json:"generators,omitempty" yaml:"generators,omitempty"`
 
-	// Transformers is a list of files containing transformers
+	// Transformers is a list of files containing transformers.
+	// They run after the builtin transformers, unless their config
+	// has a config.kubernetes.io/transformer-phase annotation.
 	Transformers []string `json:"transformers,omitempty" yaml:"transformers,omitempty"`
 
-	// Validators is a list of files containing validators
+	// Validators is a list of files containing validators, i.e.
+	// transformers given a copy of the output of the kustomization
+	// once it's final, which may fail the build but not change it.
 	Validators []string `json:"validators,omitempty" yaml:"validators,omitempty"`
 
+	// Pipeline declares KRM functions run as transformers and
+	// validators, as in a kpt Kptfile.
+	Pipeline *Pipeline `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
+
+	// ExternalReferences select the resources that resources of
+	// the build may refer to by name, e.g. in a configMapKeyRef,
+	// although they're not in the build, e.g. secrets managed
+	// in the cluster.  Only used when checking references.
+	ExternalReferences []Selector `json:"externalReferences,omitempty" yaml:"externalReferences,omitempty"`
+
 	// Inventory appends an object that contains the record
-	// of all other objects, which can be used in apply, prune and delete
+	// of all other objects, which can be used in apply, prune and delete.
+	// Only that of the kustomization being built is used.
 	Inventory *Inventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
 
-	// BuildMetadata is a list of strings used to toggle different build options
+	// BuildMetadata is a list of strings used to toggle different build options,
+	// each one of BuildMetadataOptions.
 	BuildMetadata []string `json:"buildMetadata,omitempty" yaml:"buildMetadata,omitempty"`
+
+	// SortOptions set the order of the resources in the output.
+	// Only those of the kustomization being built are used,
+	// in place of the --reorder flag.
+	SortOptions *SortOptions `json:"sortOptions,omitempty" yaml:"sortOptions,omitempty"`
 }
 
 // FixKustomizationPostUnmarshalling fixes things
@@ -246,24 +310,86 @@ func (k *Kustomization) EnforceFields() []string {
 	if k.Kind != "" && k.Kind != KustomizationKind && k.Kind != ComponentKind {
 		errs = append(errs, "kind should be "+KustomizationKind+" or "+ComponentKind)
 	}
-	requiredVersion := KustomizationVersion
-	if k.Kind == ComponentKind {
-		requiredVersion = ComponentVersion
-	}
+	requiredVersion := CurrentAPIVersion(k.Kind)
 	if k.APIVersion != "" && k.APIVersion != requiredVersion {
 		errs = append(errs, "apiVersion for "+k.Kind+" should be "+requiredVersion)
 	}
+	if k.SortOptions != nil {
+		switch k.SortOptions.Order {
+		case LegacySortOrder:
+		case FIFOSortOrder, NameSortOrder, NamespaceSortOrder:
+			if k.SortOptions.LegacySortOptions != nil {
+				errs = append(errs, "sortOptions.legacySortOptions can only be set with order "+
+					string(LegacySortOrder))
+			}
+		default:
+			errs = append(errs, fmt.Sprintf(
+				"unknown sortOptions.order %q; expected %s, %s, %s or %s",
+				k.SortOptions.Order, LegacySortOrder, FIFOSortOrder,
+				NameSortOrder, NamespaceSortOrder))
+		}
+	}
+	if k.Inventory != nil {
+		switch k.Inventory.Type {
+		case "", InventoryTypeConfigMap, InventoryTypeResourceGroup:
+			if k.Inventory.NameArgs().Name == "" {
+				errs = append(errs, "inventory needs the name of its object")
+			}
+		default:
+			errs = append(errs, fmt.Sprintf(
+				"unknown inventory.type %q; expected %s or %s",
+				k.Inventory.Type, InventoryTypeConfigMap, InventoryTypeResourceGroup))
+		}
+	}
+	if k.Pipeline != nil {
+		errs = append(errs, k.Pipeline.enforceFields()...)
+	}
+	for i := range k.DataSources {
+		errs = append(errs, k.DataSources[i].enforceFields(i)...)
+	}
+	for _, opt := range k.BuildMetadata {
+		if !isBuildMetadataOption(opt) {
+			errs = append(errs, fmt.Sprintf(
+				"unknown buildMetadata option %q; expected one of %s",
+				opt, strings.Join(BuildMetadataOptions, ", ")))
+		}
+	}
 	return errs
 }
 
+func isBuildMetadataOption(opt string) bool {
+	for _, o := range BuildMetadataOptions {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
 // Unmarshal replace k with the content in YAML input y
 func (k *Kustomization) Unmarshal(y []byte) error {
+	return k.unmarshal(y, true)
+}
+
+// UnmarshalLenient is like Unmarshal, but ignores unknown
+// fields, e.g. deprecated ones that are no longer read.
+func (k *Kustomization) UnmarshalLenient(y []byte) error {
+	return k.unmarshal(y, false)
+}
+
+func (k *Kustomization) unmarshal(y []byte, strict bool) error {
 	j, err := yaml.YAMLToJSON(y)
 	if err != nil {
 		return err
 	}
+	j, err = objectEntriesToText(j)
+	if err != nil {
+		return err
+	}
 	dec := json.NewDecoder(bytes.NewReader(j))
-	dec.DisallowUnknownFields()
+	if strict {
+		dec.DisallowUnknownFields()
+	}
 	var nk Kustomization
 	err = dec.Decode(&nk)
 	if err != nil {
@@ -272,3 +398,116 @@ func (k *Kustomization) Unmarshal(y []byte) error {
 	*k = nk
 	return nil
 }
+
+// IsInlineResource returns true if the resources entry holds
+// the content of resources, rather than their location.
+func IsInlineResource(entry string) bool {
+	if !strings.Contains(entry, "\n") {
+		return false
+	}
+	_, isEntry := ParseResourceEntry(entry)
+	return !isEntry
+}
+
+// objectEntriesToText replaces the entries written as objects
+// in the kustomization, given as JSON, with their YAML: the
+// resources entries of the form {inline: OBJECT}, with that
+// of the objects, the other resources entries, and the
+// patchesStrategicMerge entries.
+func objectEntriesToText(j []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(j, &fields); err != nil {
+		// Left to the decoder to report.
+		return j, nil
+	}
+	found := false
+	for field, toText := range map[string]func([]byte) (string, error){
+		"resources":             resourceToText,
+		"patchesStrategicMerge": patchStrategicMergeToText,
+	} {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(fields[field], &entries); err != nil {
+			continue
+		}
+		changed := false
+		for i, e := range entries {
+			if !bytes.HasPrefix(bytes.TrimSpace(e), []byte("{")) {
+				continue
+			}
+			text, err := toText(e)
+			if err != nil {
+				return nil, err
+			}
+			if entries[i], err = json.Marshal(text); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		var err error
+		if fields[field], err = json.Marshal(entries); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return j, nil
+	}
+	return json.Marshal(fields)
+}
+
+func resourceToText(e []byte) (string, error) {
+	var entry map[string]interface{}
+	if err := json.Unmarshal(e, &entry); err != nil {
+		return "", err
+	}
+	if _, ok := entry["path"]; ok {
+		return resourceEntryToText(e)
+	}
+	object, ok := entry["inline"]
+	if !ok || len(entry) != 1 {
+		return "", fmt.Errorf(
+			"resources entry %s is neither a location, {inline: OBJECT} "+
+				"nor {path: PATH, options: OPTIONS}", e)
+	}
+	y, err := yaml.Marshal(object)
+	return string(y), err
+}
+
+func resourceEntryToText(e []byte) (string, error) {
+	var entry ResourceEntry
+	dec := json.NewDecoder(bytes.NewReader(e))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entry); err != nil {
+		return "", fmt.Errorf("resources entry %s: %w", e, err)
+	}
+	if entry.Path == "" {
+		return "", fmt.Errorf("resources entry %s has an empty path", e)
+	}
+	y, err := yaml.Marshal(entry)
+	return string(y), err
+}
+
+func patchStrategicMergeToText(e []byte) (string, error) {
+	var entry PatchStrategicMergeEntry
+	dec := json.NewDecoder(bytes.NewReader(e))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entry); err != nil {
+		return "", fmt.Errorf("patchesStrategicMerge entry %s: %w", e, err)
+	}
+	if (entry.Path == "") == (entry.Patch == "") {
+		return "", fmt.Errorf(
+			"patchesStrategicMerge entry %s must have either a path or a patch", e)
+	}
+	switch entry.MergeLists {
+	case "", MergeListsByKey, MergeListsAppend, MergeListsReplace:
+	default:
+		return "", fmt.Errorf(
+			"patchesStrategicMerge entry %s: unknown mergeLists %q; expected %s, %s or %s",
+			e, entry.MergeLists, MergeListsByKey, MergeListsAppend, MergeListsReplace)
+	}
+	y, err := yaml.Marshal(entry)
+	return string(y), err
+}
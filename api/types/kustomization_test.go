@@ -157,6 +157,66 @@ func TestEnforceFields(t *testing.T) {
 	}
 }
 
+func TestEnforceFields_BuildMetadata(t *testing.T) {
+	k := Kustomization{
+		BuildMetadata: []string{OriginAnnotations, "originAnnotation"},
+	}
+
+	errs := k.EnforceFields()
+	if len(errs) != 1 {
+		t.Fatalf("number of errors should be 1 but got: %v", errs)
+	}
+
+	expected := `unknown buildMetadata option "originAnnotation"; ` +
+		"expected one of originAnnotations, transformerAnnotations, managedByLabel"
+	if errs[0] != expected {
+		t.Fatalf("error should be %v but got: %v", expected, errs[0])
+	}
+}
+
+func TestEnforceFields_Pipeline(t *testing.T) {
+	k := Kustomization{
+		Pipeline: &Pipeline{
+			Mutators: []Function{
+				{Image: "gcr.io/kpt-fn/set-labels:v0.1"},
+				{Image: "gcr.io/kpt-fn/set-namespace:v0.1", Exec: "./set-namespace"},
+			},
+			Validators: []Function{
+				{Exec: "./check", ConfigPath: "check.yaml",
+					ConfigMap: map[string]string{"strict": "true"}},
+			},
+		},
+	}
+
+	errs := k.EnforceFields()
+	expected := []string{
+		"pipeline.mutators[1] needs one of image or exec",
+		"pipeline.validators[0] can't have both configPath and configMap",
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Fatalf("errors should be %v but got: %v", expected, errs)
+	}
+}
+
+func TestEnforceFields_DataSources(t *testing.T) {
+	k := Kustomization{
+		DataSources: []DataSourceArgs{
+			{GeneratorArgs: GeneratorArgs{Name: "outputs"}, Command: "./outputs.sh"},
+			{Kind: "Service", Type: "Opaque"},
+		},
+	}
+
+	errs := k.EnforceFields()
+	expected := []string{
+		"dataSources[1] needs a name",
+		"dataSources[1] needs a command",
+		`unknown dataSources[1].kind "Service"; expected ConfigMap or Secret`,
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Fatalf("errors should be %v but got: %v", expected, errs)
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	y := []byte(`
 apiVersion: kustomize.config.k8s.io/v1beta1
@@ -212,6 +272,113 @@ unknown: foo`)
 	}
 }
 
+func TestUnmarshal_InlineResource(t *testing.T) {
+	y := []byte(`
+resources:
+- deployment.yaml
+- inline:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: config
+`)
+	var k Kustomization
+	err := k.Unmarshal(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"deployment.yaml",
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+	}
+	if !reflect.DeepEqual(k.Resources, expected) {
+		t.Fatalf("expected %q but got %q", expected, k.Resources)
+	}
+	if IsInlineResource(k.Resources[0]) || !IsInlineResource(k.Resources[1]) {
+		t.Fatalf("only the second resource should be inline")
+	}
+}
+
+func TestUnmarshal_InvalidResource(t *testing.T) {
+	y := []byte(`
+resources:
+- name: deployment.yaml
+`)
+	var k Kustomization
+	err := k.Unmarshal(y)
+	expect := `resources entry {"name":"deployment.yaml"} is neither a location, ` +
+		`{inline: OBJECT} nor {path: PATH, options: OPTIONS}`
+	if err == nil || err.Error() != expect {
+		t.Fatalf("expect %v but got: %v", expect, err)
+	}
+}
+
+func TestUnmarshal_ResourceEntry(t *testing.T) {
+	y := []byte(`
+resources:
+- deployment.yaml
+- path: ../base
+  options:
+    inheritCommonLabels: false
+    inheritNamePrefix: true
+`)
+	var k Kustomization
+	if err := k.Unmarshal(y); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"deployment.yaml",
+		"options:\n  inheritCommonLabels: false\n  inheritNamePrefix: true\npath: ../base\n",
+	}
+	if !reflect.DeepEqual(k.Resources, expected) {
+		t.Fatalf("expected %q but got %q", expected, k.Resources)
+	}
+	if IsInlineResource(k.Resources[1]) {
+		t.Fatalf("the entry shouldn't be inline")
+	}
+	e, ok := ParseResourceEntry(k.Resources[1])
+	if !ok || e.Path != "../base" {
+		t.Fatalf("unexpected entry %v", e)
+	}
+	if notInherited := e.Options.NotInherited(); !reflect.DeepEqual(
+		notInherited, []string{"commonLabels"}) {
+		t.Fatalf("unexpected fields not inherited %q", notInherited)
+	}
+	if _, ok = ParseResourceEntry(k.Resources[0]); ok {
+		t.Fatalf("a location isn't an entry")
+	}
+}
+
+func TestUnmarshal_InvalidResourceEntry(t *testing.T) {
+	y := []byte(`
+resources:
+- path: ../base
+  options:
+    inheritLabels: false
+`)
+	var k Kustomization
+	err := k.Unmarshal(y)
+	expect := `resources entry {"options":{"inheritLabels":false},"path":"../base"}: ` +
+		`json: unknown field "inheritLabels"`
+	if err == nil || err.Error() != expect {
+		t.Fatalf("expect %v but got: %v", expect, err)
+	}
+}
+
+func TestUnmarshalLenient_UnknownField(t *testing.T) {
+	y := []byte(`
+imageTags:
+- name: nginx
+namePrefix: cat`)
+	var k Kustomization
+	if err := k.UnmarshalLenient(y); err != nil {
+		t.Fatal(err)
+	}
+	if k.NamePrefix != "cat" {
+		t.Fatalf("wrong unmarshal result: %v", k)
+	}
+}
+
 func TestUnmarshal_InvalidYaml(t *testing.T) {
 	y := []byte(`
 apiVersion: kustomize.config.k8s.io/v1beta1
@@ -223,3 +390,58 @@ unknown`)
 		t.Fatalf("expect an error")
 	}
 }
+
+func TestMigrateAPIVersion(t *testing.T) {
+	testCases := map[string]struct {
+		kind       string
+		apiVersion string
+		migrated   string
+		expected   string
+	}{
+		"current": {
+			kind:       KustomizationKind,
+			apiVersion: KustomizationVersion,
+			expected:   KustomizationVersion,
+		},
+		"legacy": {
+			kind:       KustomizationKind,
+			apiVersion: "kustomize.config.k8s.io/v1alpha1",
+			migrated:   "kustomize.config.k8s.io/v1alpha1",
+			expected:   KustomizationVersion,
+		},
+		"component": {
+			kind:       ComponentKind,
+			apiVersion: "kustomize.config.k8s.io/v1",
+			migrated:   "kustomize.config.k8s.io/v1",
+			expected:   ComponentVersion,
+		},
+		"version of the other kind": {
+			kind:       ComponentKind,
+			apiVersion: KustomizationVersion,
+			expected:   KustomizationVersion,
+		},
+		"other group": {
+			kind:       KustomizationKind,
+			apiVersion: "kustomize.io/v1beta1",
+			expected:   "kustomize.io/v1beta1",
+		},
+		"other kind": {
+			kind:       "ConfigMap",
+			apiVersion: "kustomize.config.k8s.io/v1alpha1",
+			expected:   "kustomize.config.k8s.io/v1alpha1",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			k := Kustomization{
+				TypeMeta: TypeMeta{Kind: tc.kind, APIVersion: tc.apiVersion},
+			}
+			if migrated := k.MigrateAPIVersion(); migrated != tc.migrated {
+				t.Fatalf("expected migration from %q but got %q", tc.migrated, migrated)
+			}
+			if k.APIVersion != tc.expected {
+				t.Fatalf("expected apiVersion %q but got %q", tc.expected, k.APIVersion)
+			}
+		})
+	}
+}
@@ -12,6 +12,11 @@ type Label struct {
 	// is true.
 	IncludeSelectors bool        `json:"includeSelectors,omitempty" yaml:"includeSelectors,omitempty"`
 	FieldSpecs       []FieldSpec `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// IncludeTemplates indicates should transformer include the
+	// fieldSpecs for the labels of pod templates, but not those
+	// for selectors, which are often immutable.  It has no
+	// effect if IncludeSelectors is true.
+	IncludeTemplates bool `json:"includeTemplates,omitempty" yaml:"includeTemplates,omitempty"`
 }
 
 func labelFromCommonLabels(commonLabels map[string]string) *Label {
@@ -0,0 +1,20 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "sigs.k8s.io/kustomize/kyaml/resid"
+
+// NameAffix is a name prefix, or suffix, for the resources
+// of some kinds only.
+type NameAffix struct {
+	// Value is the prefix or suffix.
+	Value string `json:"value" yaml:"value"`
+
+	// Gvks select the resources that are given the affix;
+	// all of them if empty.  Empty fields match any value.
+	Gvks []resid.Gvk `json:"gvks,omitempty" yaml:"gvks,omitempty"`
+
+	// Skip selects resources that aren't given the affix.
+	Skip []resid.Gvk `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
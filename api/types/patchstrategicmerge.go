@@ -3,7 +3,63 @@
 
 package types
 
+import (
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
 // PatchStrategicMerge represents a relative path to a
 // stategic merge patch with the format
 // https://github.com/kubernetes/community/blob/master/contributors/devel/sig-api-machinery/strategic-merge-patch.md
+// An entry written as an object, a PatchStrategicMergeEntry,
+// is held as its YAML; see Entry.
 type PatchStrategicMerge string
+
+// The ways a strategic merge patch merges lists.
+const (
+	// MergeListsByKey merges the elements of lists that have
+	// merge keys by key, and replaces other lists.  The default.
+	MergeListsByKey = "mergeByKey"
+	// MergeListsAppend is MergeListsByKey, but appends to
+	// lists without merge keys rather than replace them.
+	MergeListsAppend = "append"
+	// MergeListsReplace replaces all lists.
+	MergeListsReplace = "replace"
+)
+
+// PatchStrategicMergeEntry is a patchesStrategicMerge entry
+// written as an object, to give options to the patch.
+type PatchStrategicMergeEntry struct {
+	// Path is a relative path to the patch file.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Patch is the content of the patch, if there's no Path.
+	Patch string `json:"patch,omitempty" yaml:"patch,omitempty"`
+
+	// MergeLists is the way the patch merges lists:
+	// mergeByKey, append or replace.
+	MergeLists string `json:"mergeLists,omitempty" yaml:"mergeLists,omitempty"`
+
+	// Force makes the fields of the patch replace those of the
+	// target that hold another type of value, e.g. a list rather
+	// than a map, instead of failing.
+	Force bool `json:"force,omitempty" yaml:"force,omitempty"`
+}
+
+// Entry returns the entry that p holds if it was written
+// as an object.
+func (p PatchStrategicMerge) Entry() (*PatchStrategicMergeEntry, bool) {
+	if !strings.Contains(string(p), "\n") {
+		return nil, false
+	}
+	var e PatchStrategicMergeEntry
+	if err := yaml.UnmarshalStrict([]byte(p), &e); err != nil {
+		// Legacy inline patch content.
+		return nil, false
+	}
+	if (e.Path == "") == (e.Patch == "") {
+		return nil, false
+	}
+	return &e, true
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+// Pipeline declares KRM functions run on the resources, as
+// the pipeline of a kpt Kptfile does.
+type Pipeline struct {
+	// Mutators run, in order, after the transformers that
+	// run after the builtin transformers.
+	Mutators []Function `json:"mutators,omitempty" yaml:"mutators,omitempty"`
+
+	// Validators run, in order, after the validators.
+	Validators []Function `json:"validators,omitempty" yaml:"validators,omitempty"`
+}
+
+// Function is a KRM function of a pipeline.
+type Function struct {
+	// Image is the container image of the function.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// Exec is the path of the executable of the function,
+	// instead of an image.
+	Exec string `json:"exec,omitempty" yaml:"exec,omitempty"`
+
+	// Name identifies the function in messages.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// ConfigPath is the file holding the config of the
+	// function, relative to the kustomization.
+	ConfigPath string `json:"configPath,omitempty" yaml:"configPath,omitempty"`
+
+	// ConfigMap is the data of a ConfigMap, named
+	// function-input, given as the config of the function,
+	// instead of that of ConfigPath.
+	ConfigMap map[string]string `json:"configMap,omitempty" yaml:"configMap,omitempty"`
+}
+
+// enforceFields returns the errors of the functions of the
+// pipeline.
+func (p *Pipeline) enforceFields() []string {
+	var errs []string
+	check := func(field string, fns []Function) {
+		for i, fn := range fns {
+			if (fn.Image == "") == (fn.Exec == "") {
+				errs = append(errs, fmt.Sprintf(
+					"pipeline.%s[%d] needs one of image or exec", field, i))
+			}
+			if fn.ConfigPath != "" && fn.ConfigMap != nil {
+				errs = append(errs, fmt.Sprintf(
+					"pipeline.%s[%d] can't have both configPath and configMap",
+					field, i))
+			}
+		}
+	}
+	check("mutators", p.Mutators)
+	check("validators", p.Validators)
+	return errs
+}
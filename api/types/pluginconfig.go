@@ -8,6 +8,50 @@ type HelmConfig struct {
 	Command string
 }
 
+// JsonnetConfig allows and configures the evaluation of the
+// jsonnet files of kustomization resources.
+type JsonnetConfig struct {
+	Enabled bool
+	// Command is the jsonnet command, e.g. jsonnet or jrsonnet.
+	Command string
+}
+
+// DataSourceConfig allows running the commands of the data
+// sources of kustomizations.
+type DataSourceConfig struct {
+	Enabled bool
+}
+
+// OpaConfig allows and configures the evaluation of Rego
+// policies by the PolicyTransformer.
+type OpaConfig struct {
+	Enabled bool
+	// Command is the opa command.
+	Command string
+}
+
+// ExecPluginPolicy restricts how exec plugins are run.
+// The zero value imposes no restrictions.
+type ExecPluginPolicy struct {
+	// DisableNetwork runs exec plugins in a new network namespace
+	// with no interfaces but loopback. Requires unshare(1), so
+	// is only available on Linux.
+	DisableNetwork bool
+
+	// IsolateWorkingDir runs exec plugins in an empty temporary
+	// directory rather than in the kustomization root.
+	IsolateWorkingDir bool
+
+	// CleanEnv passes exec plugins only PATH, the KUSTOMIZE_PLUGIN_*
+	// variables, and the variables named in EnvAllowlist, rather
+	// than the full environment of kustomize.
+	CleanEnv bool
+
+	// EnvAllowlist names environment variables passed to exec
+	// plugins when CleanEnv is set.
+	EnvAllowlist []string
+}
+
 // PluginConfig holds plugin configuration.
 type PluginConfig struct {
 	// PluginRestrictions distinguishes plugin restrictions.
@@ -21,6 +65,21 @@ type PluginConfig struct {
 
 	// HelmConfig contains metadata needed for allowing and running helm.
 	HelmConfig HelmConfig
+
+	// JsonnetConfig contains metadata needed for allowing and
+	// running jsonnet.
+	JsonnetConfig JsonnetConfig
+
+	// DataSourceConfig contains metadata needed for allowing
+	// data sources.
+	DataSourceConfig DataSourceConfig
+
+	// OpaConfig contains metadata needed for allowing and
+	// running opa.
+	OpaConfig OpaConfig
+
+	// ExecPluginPolicy restricts how exec plugins are run.
+	ExecPluginPolicy ExecPluginPolicy
 }
 
 func EnabledPluginConfig(b BuiltinPluginLoadingOptions) (pc *PluginConfig) {
@@ -29,6 +88,10 @@ func EnabledPluginConfig(b BuiltinPluginLoadingOptions) (pc *PluginConfig) {
 	pc.HelmConfig.Enabled = true
 	// If this command is not on PATH, tests needing it should skip.
 	pc.HelmConfig.Command = "helmV3"
+	// Jsonnet, data sources and opa run commands, so need
+	// enabling on their own.
+	pc.JsonnetConfig.Command = "jsonnet"
+	pc.OpaConfig.Command = "opa"
 	return
 }
 
@@ -48,9 +48,15 @@ type FnPluginLoadingOptions struct {
 	EnableExec bool
 	// Allow to run starlark
 	EnableStar bool
+	// Allow to run WebAssembly modules
+	EnableWasm bool
+	// WASI runtime used to run WebAssembly modules, e.g. wasmtime
+	WasmRuntime string
 	// Allow container access to network
 	Network     bool
 	NetworkName string
+	// Container engine used to run container functions, e.g. docker or podman
+	ContainerRuntime string
 	// list of mounts
 	Mounts []string
 	// list of env variables to pass to fn
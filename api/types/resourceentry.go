@@ -0,0 +1,77 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ResourceEntry is a resources entry written as an object, to
+// give options for the resources at its path.
+type ResourceEntry struct {
+	// Path is a file, directory or URL, as in other entries,
+	// but not a glob pattern.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Options control which fields of the kustomization apply
+	// to the resources at Path.
+	Options ResourceOptions `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ResourceOptions control which fields of a kustomization
+// apply to the resources of one of its entries, e.g. those
+// of a third party base.  Fields apply unless set to false.
+type ResourceOptions struct {
+	// InheritCommonLabels, if false, keeps the commonLabels
+	// and labels of the kustomization off the resources.
+	InheritCommonLabels *bool `json:"inheritCommonLabels,omitempty" yaml:"inheritCommonLabels,omitempty"`
+
+	// InheritCommonAnnotations, if false, keeps the
+	// commonAnnotations of the kustomization off the resources.
+	InheritCommonAnnotations *bool `json:"inheritCommonAnnotations,omitempty" yaml:"inheritCommonAnnotations,omitempty"`
+
+	// InheritNamePrefix, if false, keeps the namePrefix and
+	// namePrefixes of the kustomization off the resources.
+	InheritNamePrefix *bool `json:"inheritNamePrefix,omitempty" yaml:"inheritNamePrefix,omitempty"`
+
+	// InheritNameSuffix, if false, keeps the nameSuffix and
+	// nameSuffixes of the kustomization off the resources.
+	InheritNameSuffix *bool `json:"inheritNameSuffix,omitempty" yaml:"inheritNameSuffix,omitempty"`
+}
+
+// NotInherited returns the names of the kustomization fields
+// that the options keep off the resources.
+func (o ResourceOptions) NotInherited() []string {
+	var result []string
+	for _, f := range []struct {
+		name    string
+		inherit *bool
+	}{
+		{"commonLabels", o.InheritCommonLabels},
+		{"commonAnnotations", o.InheritCommonAnnotations},
+		{"namePrefix", o.InheritNamePrefix},
+		{"nameSuffix", o.InheritNameSuffix},
+	} {
+		if f.inherit != nil && !*f.inherit {
+			result = append(result, f.name)
+		}
+	}
+	return result
+}
+
+// ParseResourceEntry returns the entry that a resources entry
+// holds if it was written as {path: PATH, options: OPTIONS}.
+func ParseResourceEntry(entry string) (*ResourceEntry, bool) {
+	if !strings.Contains(entry, "\n") {
+		return nil, false
+	}
+	var e ResourceEntry
+	if err := yaml.UnmarshalStrict([]byte(entry), &e); err != nil || e.Path == "" {
+		// An inline resource.
+		return nil, false
+	}
+	return &e, true
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// SortOrder is the order the resources of a build are output in.
+type SortOrder string
+
+const (
+	// LegacySortOrder sorts the resources by kind, putting
+	// basic resources with no dependencies, like Namespace,
+	// first, and webhook configurations last.
+	LegacySortOrder SortOrder = "legacy"
+	// FIFOSortOrder keeps the depth-first order the resources
+	// are listed in by the kustomization file(s).
+	FIFOSortOrder SortOrder = "fifo"
+	// NameSortOrder sorts the resources by name, then
+	// namespace, then as the legacy order sorts kinds.
+	NameSortOrder SortOrder = "name"
+	// NamespaceSortOrder sorts the resources by namespace,
+	// cluster scoped ones first, then as the legacy order
+	// sorts kinds, then by name.
+	NamespaceSortOrder SortOrder = "namespace"
+)
+
+// SortOptions configure the order of the resources in the
+// output of a build.
+type SortOptions struct {
+	// Order is one of legacy, fifo, name or namespace.
+	Order SortOrder `json:"order,omitempty" yaml:"order,omitempty"`
+	// LegacySortOptions replace the kinds ordered first and
+	// last by the legacy order.  Only used with that order.
+	LegacySortOptions *LegacySortOptions `json:"legacySortOptions,omitempty" yaml:"legacySortOptions,omitempty"`
+}
+
+// LegacySortOptions list the kinds the legacy order puts first
+// and last, each in the order given.  Resources of other kinds
+// go in between, ordered by group, version and kind.
+type LegacySortOptions struct {
+	OrderFirst []string `json:"orderFirst,omitempty" yaml:"orderFirst,omitempty"`
+	OrderLast  []string `json:"orderLast,omitempty" yaml:"orderLast,omitempty"`
+}
@@ -23,6 +23,8 @@ type Var struct {
 	// purview of this kustomization. ObjRef should use the
 	// raw name of the object (the name specified in its YAML,
 	// before addition of a namePrefix and a nameSuffix).
+	// With a labelSelector, ObjRef refers to the first resource
+	// with matching labels, and the name may be omitted.
 	ObjRef Target `json:"objref" yaml:"objref"`
 
 	// FieldRef refers to the field of the object referred to by
@@ -30,6 +32,10 @@ type Var struct {
 	// replacing $(FOO).
 	// If unspecified, this defaults to fieldPath: $defaultFieldPath
 	FieldRef FieldSelector `json:"fieldref,omitempty" yaml:"fieldref,omitempty"`
+
+	// Default is the value of the var when no resource matches
+	// ObjRef, or the resource has no field at FieldRef.
+	Default *string `json:"default,omitempty" yaml:"default,omitempty"`
 }
 
 // Target refers to a kubernetes object by Group, Version, Kind and Name
@@ -41,6 +47,11 @@ type Target struct {
 	resid.Gvk  `json:",inline,omitempty" yaml:",inline,omitempty"`
 	Name       string `json:"name" yaml:"name"`
 	Namespace  string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// LabelSelector is a string that follows the label selection expression
+	// https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#api
+	// It is only used by var objrefs.
+	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
 }
 
 // GVK returns the Gvk object in Target
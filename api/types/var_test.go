@@ -154,7 +154,7 @@ func TestVarSetCopy(t *testing.T) {
 	set2 := set1.Copy()
 	for _, varInSet1 := range set1.AsSlice() {
 		if v := set2.Get(varInSet1.Name); v == nil {
-			t.Fatalf("set %v should contain a Var named %s", set2.AsSlice(), varInSet1)
+			t.Fatalf("set %v should contain a Var named %s", set2.AsSlice(), varInSet1.Name)
 		} else if !set2.Contains(*v) {
 			t.Fatalf("set %v should contain %v", set2.AsSlice(), v)
 		}
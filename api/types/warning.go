@@ -0,0 +1,96 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+// Codes of warnings.
+const (
+	// WarningDeprecatedField is about a kustomization using a
+	// deprecated field, or apiVersion.
+	WarningDeprecatedField = "deprecated-field"
+
+	// WarningUnusedPatch is about a patch whose target matches
+	// no resource.
+	WarningUnusedPatch = "unused-patch"
+
+	// WarningUnmatchedSelector is about a replacement target
+	// selecting no resource.
+	WarningUnmatchedSelector = "unmatched-selector"
+
+	// WarningUnusedVar is about a var that's never referenced.
+	WarningUnusedVar = "unused-var"
+
+	// WarningUnusedConfiguration is about a fieldSpec of a
+	// configuration that matches no field.
+	WarningUnusedConfiguration = "unused-configuration"
+
+	// WarningClusterScopedNamespace is about a cluster-scoped
+	// resource with a namespace, which the cluster ignores.
+	WarningClusterScopedNamespace = "cluster-scoped-namespace"
+
+	// WarningUnknownPluginField is about a field of a builtin
+	// plugin config that the plugin doesn't know, and ignores.
+	WarningUnknownPluginField = "unknown-plugin-field"
+)
+
+// Warning is a likely mistake found in a build, that doesn't
+// fail it unless the build is strict.
+type Warning struct {
+	// Code is the kind of mistake, e.g. deprecated-field.
+	Code string `json:"code" yaml:"code"`
+
+	// Path is the kustomization file the warning is about.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	Message string `json:"message" yaml:"message"`
+}
+
+func (w Warning) String() string {
+	if w.Path == "" {
+		return fmt.Sprintf("%s [%s]", w.Message, w.Code)
+	}
+	return fmt.Sprintf("%s: %s [%s]", w.Path, w.Message, w.Code)
+}
+
+// DeprecatedFields returns a message for each deprecated field
+// the kustomization uses, naming its replacement.  Call it
+// before FixKustomizationPostUnmarshalling, which moves some
+// of them to their replacement.
+func (k *Kustomization) DeprecatedFields() []string {
+	var result []string
+	if len(k.Bases) > 0 {
+		result = append(result,
+			"'bases' is deprecated; list bases under 'resources'")
+	}
+	if len(k.PatchesStrategicMerge) > 0 {
+		result = append(result,
+			"'patchesStrategicMerge' is deprecated; use 'patches'")
+	}
+	if len(k.PatchesJson6902) > 0 {
+		result = append(result,
+			"'patchesJson6902' is deprecated; use 'patches'")
+	}
+	if len(k.Vars) > 0 {
+		result = append(result,
+			"'vars' is deprecated; use 'replacements'")
+	}
+	if len(k.HelmChartInflationGenerator) > 0 {
+		result = append(result,
+			"'helmChartInflationGenerator' is deprecated; use 'helmCharts'")
+	}
+	for _, g := range k.ConfigMapGenerator {
+		if g.EnvSource != "" {
+			result = append(result, fmt.Sprintf(
+				"'env' of configMapGenerator %s is deprecated; use 'envs'", g.Name))
+		}
+	}
+	for _, g := range k.SecretGenerator {
+		if g.EnvSource != "" {
+			result = append(result, fmt.Sprintf(
+				"'env' of secretGenerator %s is deprecated; use 'envs'", g.Name))
+		}
+	}
+	return result
+}
@@ -6,29 +6,92 @@ package build
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/openapi/fetch"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 var theArgs struct {
 	kustomizationPath string
+	// All the paths given, when building several kustomizations.
+	kustomizationPaths []string
 }
 
 var theFlags struct {
-	outputPath string
-	enable     struct {
+	outputPath    string
+	outputPattern string
+	splitBy       string
+	enable        struct {
 		plugins        bool
 		managedByLabel bool
 		helm           bool
+		jsonnet        bool
+		dataSources    bool
+		opa            bool
+		sops           bool
+	}
+	helmCommand     string
+	jsonnetCommand  string
+	opaCommand      string
+	sopsCommand     string
+	discovery       string
+	clusterSchema   bool
+	loadRestrictor  string
+	reorderOutput   string
+	sortBy          string
+	parallel        bool
+	lenient         bool
+	validate        string
+	strict          bool
+	checkReferences bool
+	cacheDir        string
+	cacheFile       string
+	profile         []string
+	timings         bool
+	outputFormat    string
+	minify          bool
+	sourceComments  bool
+	pruneAllowlist  bool
+	listImages      bool
+	refreshCache    bool
+	fnOptions       types.FnPluginLoadingOptions
+	execPolicy      types.ExecPluginPolicy
+	watch           struct {
+		enabled  bool
+		interval time.Duration
+	}
+	matrix    string
+	set       []string
+	namespace string
+	images    []string
+	yamlStyle struct {
+		indent    int
+		seqIndent string
+		lineWidth int
+		quote     string
+	}
+	helmChart struct {
+		path    string
+		name    string
+		version string
+	}
+	inventory struct {
+		kind      string
+		name      string
+		namespace string
+		id        string
 	}
-	helmCommand    string
-	loadRestrictor string
-	reorderOutput  string
-	fnOptions      types.FnPluginLoadingOptions
 }
 
 type Help struct {
@@ -48,6 +111,17 @@ The DIR argument must be a path to a directory containing
 '%s', or a git repository URL with a path suffix
 specifying same with respect to the repository root.
 If DIR is omitted, '.' is assumed.
+
+Several DIRs may be given, with --output naming a directory.
+Each DIR is then built in turn by the same process, and its
+output is written to a file, named after DIR, below the
+output directory.
+
+With --matrix, DIR is built once per cluster listed in the
+given file, with the values of the cluster substituted by
+the replacements in that file, and the output of each is
+written to a file, named after the cluster, below the
+--output directory.
 `, fN, fN),
 		Example: fmt.Sprintf(`# Build the current working directory
   %s %s
@@ -55,9 +129,12 @@ If DIR is omitted, '.' is assumed.
 # Build some shared configuration directory
   %s %s /home/config/production
 
+# Build several overlays into out/overlays/dev.yaml and out/overlays/prod.yaml
+  %s %s overlays/dev overlays/prod -o out
+
 # Build from github
   %s %s https://github.com/kubernetes-sigs/kustomize.git/examples/helloWorld?ref=v1.0.6
-`, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName),
+`, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName),
 	}
 }
 
@@ -70,61 +147,277 @@ func NewCmdBuild(
 		Long:         help.Long,
 		Example:      help.Example,
 		SilenceUsage: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := Validate(args); err != nil {
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err = Validate(args); err != nil {
 				return err
 			}
-			k := krusty.MakeKustomizer(
-				HonorKustomizeFlags(krusty.MakeDefaultOptions()),
-			)
-			m, err := k.Run(fSys, theArgs.kustomizationPath)
+			stopProfiles, err := startProfiles(fSys)
 			if err != nil {
 				return err
 			}
-			if theFlags.outputPath != "" && fSys.IsDir(theFlags.outputPath) {
-				// Ignore writer; write to o.outputPath directly.
-				return MakeWriter(fSys).WriteIndividualFiles(
-					theFlags.outputPath, m)
+			theTimings = nil
+			if theFlags.timings {
+				theTimings = krusty.NewTimings()
 			}
-			yml, err := m.AsYaml()
-			if err != nil {
-				return err
+			defer func() {
+				if errP := stopProfiles(); err == nil {
+					err = errP
+				}
+				if theTimings != nil {
+					if errT := writeTimings(cmd.ErrOrStderr(), theTimings.List()); err == nil {
+						err = errT
+					}
+				}
+			}()
+			if theFlags.watch.enabled {
+				return watch(fSys, writer, os.Stderr, nil)
 			}
-			if theFlags.outputPath != "" {
-				// Ignore writer; write to o.outputPath directly.
-				return fSys.WriteFile(theFlags.outputPath, yml)
+			if theFlags.matrix != "" {
+				return runMatrixBuild(fSys)
 			}
-			_, err = writer.Write(yml)
-			return err
+			return runBuild(fSys, writer)
 		},
 	}
 	AddFlagOutputPath(cmd.Flags())
+	AddFlagOutputPattern(cmd.Flags())
+	AddFlagSplitBy(cmd.Flags())
+	AddFlagsWatch(cmd.Flags())
+	AddFlagMatrix(cmd.Flags())
+	AddFlagSet(cmd.Flags())
+	AddFlagNamespace(cmd.Flags())
+	AddFlagImage(cmd.Flags())
+	AddFlagOutputFormat(cmd.Flags())
+	AddFlagMinify(cmd.Flags())
+	AddFlagsYamlStyle(cmd.Flags())
+	AddFlagSourceComments(cmd.Flags())
+	AddFlagsAsHelmChart(cmd.Flags())
+	AddFlagPruneAllowlist(cmd.Flags())
+	AddFlagListImages(cmd.Flags())
 	AddFunctionBasicsFlags(cmd.Flags())
 	AddFlagLoadRestrictor(cmd.Flags())
 	AddFlagEnablePlugins(cmd.Flags())
+	AddFlagsExecPluginPolicy(cmd.Flags())
 	AddFlagReorderOutput(cmd.Flags())
+	cmd.Flags().MarkDeprecated(flagReorderOutputName,
+		"The flag `reorder` has been deprecated. Use the `sortOptions` field of the kustomization instead.")
+	AddFlagSortBy(cmd.Flags())
+	AddFlagsInventory(cmd.Flags())
+	AddFlagParallel(cmd.Flags())
+	AddFlagLenient(cmd.Flags())
+	AddFlagValidate(cmd.Flags())
+	AddFlagStrict(cmd.Flags())
+	AddFlagCheckReferences(cmd.Flags())
+	AddFlagsGeneratorCache(cmd.Flags())
+	AddFlagCacheFile(cmd.Flags())
+	AddFlagsProfile(cmd.Flags())
 	AddFlagEnableManagedbyLabel(cmd.Flags())
 	cmd.Flags().MarkDeprecated(managedByFlag,
 		"The flag `enable-managedby-label` has been deprecated. Use the `managedByLabel` option in the `buildMetadata` field instead.")
 	AddFlagEnableHelm(cmd.Flags())
+	AddFlagEnableJsonnet(cmd.Flags())
+	AddFlagEnableDataSources(cmd.Flags())
+	AddFlagEnableOpa(cmd.Flags())
+	AddFlagEnableSops(cmd.Flags())
+	AddFlagDiscovery(cmd.Flags())
+	AddFlagSchemaFromCluster(cmd.Flags())
 	return cmd
 }
 
+// runBuild builds the kustomization, and writes the output.
+func runBuild(fSys filesys.FileSystem, writer io.Writer) error {
+	opts := HonorKustomizeFlags(krusty.MakeDefaultOptions())
+	if len(theArgs.kustomizationPaths) > 1 {
+		// The kustomizations likely share bases.
+		opts.BaseCache = krusty.NewBaseCache()
+		return runMultiBuild(fSys, krusty.MakeKustomizer(opts))
+	}
+	k := krusty.MakeKustomizer(opts)
+	m, err := k.Run(fSys, theArgs.kustomizationPath)
+	if err != nil {
+		return err
+	}
+	return writeOutput(fSys, m, theFlags.outputPath, writer)
+}
+
+// runMultiBuild builds several kustomizations with the same
+// kustomizer, writing the output of each to a file, or with
+// --output-pattern a directory, named after its path below
+// the output directory.  All kustomizations are built, even
+// if some fail.
+func runMultiBuild(fSys filesys.FileSystem, k *krusty.Kustomizer) error {
+	if err := fSys.MkdirAll(theFlags.outputPath); err != nil {
+		return err
+	}
+	var failed []string
+	for _, path := range theArgs.kustomizationPaths {
+		m, err := k.Run(fSys, path)
+		if err == nil {
+			err = writeNamedOutput(fSys, m, outputName(path))
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to build %d of %d kustomizations:\n%s",
+			len(failed), len(theArgs.kustomizationPaths),
+			strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// outputName returns the relative path, below the output
+// directory, for the output of the kustomization at path.
+func outputName(path string) string {
+	var parts []string
+	for _, p := range strings.Split(filepath.ToSlash(filepath.Clean(path)), "/") {
+		if p != "" && p != "." && p != ".." {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "root"
+	}
+	return filepath.Join(parts...)
+}
+
+// writeNamedOutput writes the resources to a file, or with
+// --output-pattern or --split-by a directory, at the given
+// relative path below the output directory.
+func writeNamedOutput(
+	fSys filesys.FileSystem, m resmap.ResMap, name string) error {
+	out := filepath.Join(theFlags.outputPath, name)
+	if theFlags.outputPattern != "" || theFlags.splitBy != "" {
+		if err := fSys.MkdirAll(out); err != nil {
+			return err
+		}
+	} else {
+		if err := fSys.MkdirAll(filepath.Dir(out)); err != nil {
+			return err
+		}
+		out += fileExtension(theFlags.outputFormat)
+	}
+	return writeOutput(fSys, m, out, nil)
+}
+
+// writeOutput writes the resources to outputPath, which may
+// be a directory, or if it's empty to the writer, unless
+// they're written as a helm chart, or replaced by the report
+// of --prune-allowlist or --list-images.
+func writeOutput(fSys filesys.FileSystem, m resmap.ResMap,
+	outputPath string, writer io.Writer) error {
+	if theFlags.minify {
+		if err := minify(m); err != nil {
+			return err
+		}
+	}
+	if theFlags.helmChart.path != "" {
+		return writeHelmChart(fSys, m)
+	}
+	if theFlags.pruneAllowlist || theFlags.listImages {
+		report := pruneAllowlist
+		if theFlags.listImages {
+			report = listImages
+		}
+		out, err := report(m)
+		if err != nil {
+			return err
+		}
+		if outputPath != "" {
+			return fSys.WriteFile(outputPath, out)
+		}
+		_, err = writer.Write(out)
+		return err
+	}
+	if theFlags.splitBy != "" {
+		if err := fSys.MkdirAll(outputPath); err != nil {
+			return err
+		}
+	}
+	if outputPath != "" && fSys.IsDir(outputPath) {
+		// Ignore writer; write to outputPath directly.
+		w := MakeWriter(fSys)
+		w.format = theFlags.outputFormat
+		w.pattern = theFlags.outputPattern
+		w.splitBy = theFlags.splitBy
+		w.origins = theOrigins
+		return w.WriteIndividualFiles(outputPath, m)
+	}
+	out, err := encode(m, theFlags.outputFormat)
+	if err != nil {
+		return err
+	}
+	if outputPath != "" {
+		// Ignore writer; write to outputPath directly.
+		return fSys.WriteFile(outputPath, out)
+	}
+	_, err = writer.Write(out)
+	return err
+}
+
 // Validate validates build command args and flags.
 func Validate(args []string) error {
-	if len(args) > 1 {
+	if len(args) > 1 && (theFlags.outputPath == "" || theFlags.watch.enabled) {
 		return fmt.Errorf(
 			"specify one path to " +
-				konfig.DefaultKustomizationFileName())
+				konfig.DefaultKustomizationFileName() +
+				", or several paths and an --output directory without --watch")
 	}
 	if len(args) == 0 {
 		theArgs.kustomizationPath = filesys.SelfDir
 	} else {
 		theArgs.kustomizationPath = args[0]
 	}
+	theArgs.kustomizationPaths = args
+	if theFlags.matrix != "" &&
+		(len(args) > 1 || theFlags.outputPath == "" || theFlags.watch.enabled) {
+		return fmt.Errorf(
+			"--matrix needs one path to " +
+				konfig.DefaultKustomizationFileName() +
+				" and an --output directory, and can't be used with --watch")
+	}
 	if err := validateFlagLoadRestrictor(); err != nil {
 		return err
 	}
+	if err := validateFlagOutputFormat(); err != nil {
+		return err
+	}
+	if err := validateFlagsYamlStyle(); err != nil {
+		return err
+	}
+	if err := validateFlagSourceComments(); err != nil {
+		return err
+	}
+	if err := validateFlagsAsHelmChart(); err != nil {
+		return err
+	}
+	if err := validateFlagPruneAllowlist(); err != nil {
+		return err
+	}
+	if err := validateFlagListImages(); err != nil {
+		return err
+	}
+	if err := validateFlagSplitBy(); err != nil {
+		return err
+	}
+	if err := validateFlagSet(); err != nil {
+		return err
+	}
+	if err := validateFlagProfile(); err != nil {
+		return err
+	}
+	if err := validateFlagImage(); err != nil {
+		return err
+	}
+	if err := validateFlagSortBy(); err != nil {
+		return err
+	}
+	if err := validateFlagContainerRuntime(); err != nil {
+		return err
+	}
+	if err := validateFlagsInventory(); err != nil {
+		return err
+	}
 	return validateFlagReorderOutput()
 }
 
@@ -132,15 +425,52 @@ func Validate(args []string) error {
 // Flags and such are held in private package variables.
 func HonorKustomizeFlags(kOpts *krusty.Options) *krusty.Options {
 	kOpts.DoLegacyResourceSort = getFlagReorderOutput() == legacy
+	kOpts.SortOptions = getFlagSortByOptions()
+	kOpts.Inventory = getFlagsInventory()
 	kOpts.LoadRestrictions = getFlagLoadRestrictorValue()
+	kOpts.ParallelBuild = theFlags.parallel
+	kOpts.Lenient = theFlags.lenient
+	kOpts.Validate = theFlags.validate
+	kOpts.Strict = theFlags.strict
+	kOpts.CheckReferences = theFlags.checkReferences
+	kOpts.GeneratorCacheDir = theFlags.cacheDir
+	kOpts.RefreshGeneratorCache = theFlags.refreshCache
+	kOpts.CacheFile = theFlags.cacheFile
+	kOpts.Timings = theTimings
+	theOrigins = nil
+	if theFlags.splitBy == splitByOrigin || theFlags.sourceComments {
+		theOrigins = make(map[resid.ResId]*resource.Origin)
+		kOpts.Origins = theOrigins
+	}
+	kOpts.Overrides = getFlagSetValues()
+	kOpts.Namespace = theFlags.namespace
+	// Validated already.
+	kOpts.Images, _ = getFlagImageValues()
 	if theFlags.enable.plugins {
 		c := types.EnabledPluginConfig(types.BploUseStaticallyLinked)
 		c.FnpLoadingOptions = theFlags.fnOptions
+		c.ExecPluginPolicy = theFlags.execPolicy
 		kOpts.PluginConfig = c
 	} else {
 		kOpts.PluginConfig.HelmConfig.Enabled = theFlags.enable.helm
 	}
+	kOpts.PluginConfig.JsonnetConfig.Enabled = theFlags.enable.jsonnet
+	kOpts.PluginConfig.DataSourceConfig.Enabled = theFlags.enable.dataSources
+	kOpts.PluginConfig.OpaConfig.Enabled = theFlags.enable.opa
 	kOpts.PluginConfig.HelmConfig.Command = theFlags.helmCommand
+	kOpts.PluginConfig.JsonnetConfig.Command = theFlags.jsonnetCommand
+	kOpts.PluginConfig.OpaConfig.Command = theFlags.opaCommand
+	if theFlags.enable.sops {
+		kOpts.SopsCommand = theFlags.sopsCommand
+	}
 	kOpts.AddManagedbyLabel = isManagedByLabelEnabled()
+	kOpts.FetchOpenAPISchema = fetch.FetchSchema
+	kOpts.SchemaFromCluster = theFlags.clusterSchema
+	if theFlags.clusterSchema {
+		kOpts.FetchOpenAPISchema = fetch.CachingFetchSchema(
+			schemaCacheDir(), theFlags.refreshCache)
+	}
+	kOpts.Discovery = theFlags.discovery
+	kOpts.FetchAPIResources = fetch.FetchAPIResources
 	return kOpts
 }
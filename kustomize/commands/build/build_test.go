@@ -6,6 +6,7 @@ package build_test
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -197,6 +198,1087 @@ type: Opaque
 	}
 }
 
+func TestBuildJsonOutput(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- namespace.yaml
+- service.yaml
+`))
+	fSys.WriteFile("namespace.yaml", []byte(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns1
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 80
+`))
+	var cases = map[string]string{
+		"json": `{
+  "apiVersion": "v1",
+  "kind": "Namespace",
+  "metadata": {
+    "name": "ns1"
+  }
+}
+{
+  "apiVersion": "v1",
+  "kind": "Service",
+  "metadata": {
+    "name": "svc"
+  },
+  "spec": {
+    "ports": [
+      {
+        "port": 80
+      }
+    ]
+  }
+}
+`,
+		"jsonlines": `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns1"}}
+{"apiVersion":"v1","kind":"Service","metadata":{"name":"svc"},"spec":{"ports":[{"port":80}]}}
+`,
+		"list": `apiVersion: v1
+items:
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: ns1
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: svc
+  spec:
+    ports:
+    - port: 80
+kind: List
+`,
+	}
+	for format, expected := range cases {
+		buffy := new(bytes.Buffer)
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+		cmd.Flags().Set("output-format", format)
+		if err := cmd.RunE(cmd, []string{}); err != nil {
+			t.Fatal(err)
+		}
+		if buffy.String() != expected {
+			t.Fatalf("Expected %s output:\n%s\nBut got:\n%s\n",
+				format, expected, buffy)
+		}
+	}
+
+	fSys.Mkdir("someDir")
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "someDir")
+	cmd.Flags().Set("output-format", "jsonlines")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fSys.ReadFile("someDir/v1_namespace_ns1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns1"}}`+"\n" {
+		t.Fatalf("Unexpected file content:\n%s\n", string(data))
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output-format", "xml")
+	err = cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "illegal flag value --output-format xml") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBuildWithOutputPattern(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	fSys.Mkdir("someDir")
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "someDir")
+	cmd.Flags().Set("output-pattern", "{namespace}/{kind}_{name}.{ext}")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{
+		"someDir/namespace_ns1.yaml",
+		"someDir/ns1/configmap_foo-literalconfigmap-bar-g5f6t456f5.yaml",
+		"someDir/ns1/secret_foo-secret-bar-82c2g5f8f6.yaml",
+		"someDir/ns1/deployment_foo-dply1-bar.yaml",
+	} {
+		if !fSys.Exists(f) {
+			t.Fatalf("expected file %s", f)
+		}
+	}
+	data, err := fSys.ReadFile("someDir/namespace_ns1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: Namespace
+metadata:
+  annotations:
+    note: This is a test annotation
+  labels:
+    app: nginx
+  name: ns1
+`
+	if string(data) != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s\n", expected, string(data))
+	}
+
+	for pattern, erMsg := range map[string]string{
+		"{namespace}.yaml": "the same file",
+		"../{name}.yaml":   "outside of the output directory",
+		"/tmp/{name}.yaml": "outside of the output directory",
+	} {
+		cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("output", "someDir")
+		cmd.Flags().Set("output-pattern", pattern)
+		err = cmd.RunE(cmd, []string{})
+		if err == nil || !strings.Contains(err.Error(), erMsg) {
+			t.Fatalf("%s: expected error %s, but got %v", pattern, erMsg, err)
+		}
+	}
+}
+
+func TestBuildWithSplitBy(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.MkdirAll("base")
+	fSys.MkdirAll("app")
+	fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("base/resources.yaml", []byte(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web-canary
+`))
+	fSys.WriteFile("app/kustomization.yaml", []byte(`
+resources:
+- ../base
+configMapGenerator:
+- name: settings
+  namespace: web
+  literals:
+  - mode=dev
+`))
+	// The origin split goes last, as its output is read after.
+	for _, tc := range []struct {
+		splitBy string
+		files   []string
+	}{
+		{"namespace", []string{
+			"out/v1_namespace_web.yaml",
+			"out/web/apps_v1_deployment_web.yaml",
+			"out/web/v1_configmap_settings-t2hmhtdth5.yaml",
+			"out/web-canary/apps_v1_deployment_web.yaml",
+		}},
+		{"kind", []string{
+			"out/namespace/v1_namespace_web.yaml",
+			"out/deployment/web_apps_v1_deployment_web.yaml",
+			"out/deployment/web-canary_apps_v1_deployment_web.yaml",
+			"out/configmap/web_v1_configmap_settings-t2hmhtdth5.yaml",
+		}},
+		{"origin", []string{
+			"out/__/base/v1_namespace_web.yaml",
+			"out/__/base/web_apps_v1_deployment_web.yaml",
+			"out/__/base/web-canary_apps_v1_deployment_web.yaml",
+			"out/web_v1_configmap_settings-t2hmhtdth5.yaml",
+		}},
+	} {
+		fSys.RemoveAll("out")
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("output", "out")
+		cmd.Flags().Set("split-by", tc.splitBy)
+		if err := cmd.RunE(cmd, []string{"app"}); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range tc.files {
+			if !fSys.Exists(f) {
+				t.Fatalf("--split-by %s: expected file %s", tc.splitBy, f)
+			}
+		}
+	}
+	data, err := fSys.ReadFile("out/__/base/web_apps_v1_deployment_web.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web
+`
+	if string(data) != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s\n", expected, string(data))
+	}
+
+	for _, args := range [][]string{
+		{"split-by", "cluster"},
+		{"output-pattern", "{name}.yaml"},
+	} {
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("output", "out")
+		cmd.Flags().Set("split-by", "kind")
+		cmd.Flags().Set(args[0], args[1])
+		if err := cmd.RunE(cmd, []string{"app"}); err == nil {
+			t.Fatalf("--%s %s: expected error", args[0], args[1])
+		}
+	}
+}
+
+func TestBuildSeveralKustomizations(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	for _, dir := range []string{"base", "overlays/dev", "overlays/prod"} {
+		fSys.MkdirAll(dir)
+	}
+	fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+`))
+	fSys.WriteFile("base/cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	for _, env := range []string{"dev", "prod"} {
+		fSys.WriteFile("overlays/"+env+"/kustomization.yaml", []byte(`
+namePrefix: `+env+`-
+resources:
+- ../../base
+`))
+	}
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "out")
+	if err := cmd.RunE(cmd, []string{"overlays/dev", "overlays/prod"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, env := range []string{"dev", "prod"} {
+		data, err := fSys.ReadFile("out/overlays/" + env + ".yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ` + env + `-cm
+`
+		if string(data) != expected {
+			t.Fatalf("Expected:\n%s\nBut got:\n%s\n", expected, string(data))
+		}
+	}
+
+	// A failing kustomization doesn't stop the others being built.
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "out2")
+	err := cmd.RunE(cmd, []string{"overlays/missing", "base"})
+	if err == nil || !strings.Contains(err.Error(), "failed to build 1 of 2") ||
+		!strings.Contains(err.Error(), "overlays/missing: ") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !fSys.Exists("out2/base.yaml") {
+		t.Fatalf("expected file out2/base.yaml")
+	}
+}
+
+func TestBuildMatrix(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.MkdirAll("app")
+	fSys.WriteFile("app/kustomization.yaml", []byte(`
+resources:
+- deployment.yaml
+`))
+	fSys.WriteFile("app/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`))
+	fSys.WriteFile("clusters.yaml", []byte(`
+replacements:
+- source:
+    kind: ConfigMap
+    name: matrix-values
+    fieldPath: data.replicas
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - spec.replicas
+- source:
+    kind: ConfigMap
+    name: matrix-values
+    fieldPath: data.region
+  targets:
+  - select:
+      kind: Deployment
+    fieldPaths:
+    - metadata.labels.region
+    options:
+      create: true
+clusters:
+- name: east
+  values:
+    replicas: 3
+    region: us-east-1
+- name: west
+  values:
+    replicas: 2
+    region: us-west-2
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("output", "out")
+	cmd.Flags().Set("matrix", "clusters.yaml")
+	if err := cmd.RunE(cmd, []string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{
+		"out/east.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    region: us-east-1
+  name: app
+spec:
+  replicas: 3
+`,
+		"out/west.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    region: us-west-2
+  name: app
+spec:
+  replicas: 2
+`,
+	} {
+		data, err := fSys.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("%s: Expected:\n%s\nBut got:\n%s\n", name, expected, string(data))
+		}
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("matrix", "clusters.yaml")
+	err := cmd.RunE(cmd, []string{"app"})
+	if err == nil || !strings.Contains(err.Error(), "--output directory") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBuildWithSet(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- cm.yaml
+replacements:
+- source:
+    kind: ConfigMap
+    name: cluster
+    fieldPath: data.name
+  targets:
+  - select:
+      kind: ConfigMap
+    fieldPaths:
+    - metadata.labels.cluster
+    options:
+      create: true
+`))
+	fSys.WriteFile("cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster
+data:
+  name: dev
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("set", "ConfigMap/cluster/data.name=prod-eu")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+data:
+  name: prod-eu
+kind: ConfigMap
+metadata:
+  labels:
+    cluster: prod-eu
+  name: cluster
+`
+	if buffy.String() != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s\n", expected, buffy.String())
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("set", "prod-eu")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "expected NAME=VALUE") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBuildWithNamespace(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("namespace", "preview-42")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buffy.String(), "namespace: ns1") ||
+		strings.Count(buffy.String(), "namespace: preview-42") != 3 {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+}
+
+func TestBuildWithImage(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- pod.yaml
+`))
+	fSys.WriteFile("pod.yaml", []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.21
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("image", "nginx=registry.example.com/nginx:1.25.3")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffy.String(), "image: registry.example.com/nginx:1.25.3\n") {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+}
+
+func TestBuildWithMinify(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- deployment.yaml
+`))
+	fSys.WriteFile("deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  creationTimestamp: null
+  annotations: {}
+spec:
+  selector: {}
+  strategy: {}
+  template:
+    metadata:
+      creationTimestamp: null
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        args: []
+        resources: {}
+      - {}
+      volumes:
+      - name: cache
+        emptyDir: {}
+status:
+  conditions: []
+  availableReplicas: null
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("minify", "true")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector: {}
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: nginx
+        name: web
+      - {}
+      volumes:
+      - emptyDir: {}
+        name: cache
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+}
+
+func TestBuildWithSortBy(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- resources.yaml
+sortOptions:
+  order: fifo
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+  namespace: two
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  namespace: two
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: two
+`))
+	var cases = map[string]struct {
+		sortBy string
+		names  []string
+	}{
+		"kind":      {sortBy: "kind", names: []string{"two", "b", "a"}},
+		"name":      {sortBy: "name", names: []string{"a", "b", "two"}},
+		"namespace": {sortBy: "namespace", names: []string{"two", "b", "a"}},
+		"none":      {sortBy: "none", names: []string{"b", "a", "two"}},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			buffy := new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			cmd.Flags().Set("sort-by", tc.sortBy)
+			if err := cmd.RunE(cmd, []string{}); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, line := range strings.Split(buffy.String(), "\n") {
+				if strings.HasPrefix(line, "  name: ") {
+					names = append(names, strings.TrimPrefix(line, "  name: "))
+				}
+			}
+			if !reflect.DeepEqual(names, tc.names) {
+				t.Fatalf("expected names %v, got %v", tc.names, names)
+			}
+		})
+	}
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("sort-by", "size")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "illegal flag value --sort-by size") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildWithContainerRuntime(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- service.yaml
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	for _, runtime := range []string{"podman", "/usr/local/bin/docker"} {
+		buffy := new(bytes.Buffer)
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+		cmd.Flags().Set("container-runtime", runtime)
+		if err := cmd.RunE(cmd, []string{}); err != nil {
+			t.Fatalf("unexpected error with %s: %v", runtime, err)
+		}
+	}
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("container-runtime", "dokcer")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(),
+		"illegal flag value --container-runtime dokcer") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildWithYamlStyle(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  port: "8080"
+  debug: "on"
+  motd: |
+    Welcome to a host whose message of the day runs on for more than eighty columns.
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx
+    args: ["--port", "8080"]
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("yaml-indent", "4")
+	cmd.Flags().Set("yaml-seq-indent", "wide")
+	cmd.Flags().Set("yaml-line-width", "0")
+	cmd.Flags().Set("yaml-quote", "single")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+data:
+    debug: 'on'
+    motd: |
+        Welcome to a host whose message of the day runs on for more than eighty columns.
+    port: '8080'
+kind: ConfigMap
+metadata:
+    name: settings
+---
+apiVersion: v1
+kind: Pod
+metadata:
+    name: web
+spec:
+    containers:
+        - args:
+            - --port
+            - '8080'
+          image: nginx
+          name: web
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+
+	for _, args := range [][]string{
+		{"yaml-indent", "1"},
+		{"yaml-seq-indent", "deep"},
+		{"yaml-line-width", "-1"},
+		{"yaml-quote", "back"},
+	} {
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set(args[0], args[1])
+		if err := cmd.RunE(cmd, []string{}); err == nil {
+			t.Fatalf("--%s %s: expected error", args[0], args[1])
+		}
+	}
+}
+
+func TestBuildWithSourceComments(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.MkdirAll("base")
+	fSys.MkdirAll("app")
+	fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- deployment.yaml
+`))
+	fSys.WriteFile("base/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`))
+	fSys.WriteFile("app/kustomization.yaml", []byte(`
+resources:
+- ../base
+configMapGenerator:
+- name: settings
+  literals:
+  - mode=dev
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("source-comments", "true")
+	if err := cmd.RunE(cmd, []string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# Source: kustomization.yaml (ConfigMapGenerator)
+apiVersion: v1
+data:
+  mode: dev
+kind: ConfigMap
+metadata:
+  name: settings-t2hmhtdth5
+---
+# Source: ../base/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("source-comments", "true")
+	cmd.Flags().Set("output-format", "json")
+	if err := cmd.RunE(cmd, []string{"app"}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestBuildAsHelmChart(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  greeting: Hello {{ .Name }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("as-helm-chart", "out/app")
+	cmd.Flags().Set("chart-version", "1.2.3")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	for f, expected := range map[string]string{
+		"out/app/Chart.yaml": `apiVersion: v2
+name: app
+version: 1.2.3
+`,
+		"out/app/templates/v1_configmap_settings.yaml": `apiVersion: v1
+data:
+  greeting: Hello {{"{{"}} .Name }}
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+		"out/app/templates/v1_service_web.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`,
+	} {
+		data, err := fSys.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("%s: expected:\n%s\nbut got:\n%s", f, expected, string(data))
+		}
+	}
+
+	for _, args := range [][]string{
+		{"chart-version", "1.2"},
+		{"chart-name", "My_App"},
+		{"output", "out"},
+		{"output-format", "json"},
+	} {
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("as-helm-chart", "out/app")
+		cmd.Flags().Set("chart-version", "1.2.3")
+		cmd.Flags().Set(args[0], args[1])
+		if err := cmd.RunE(cmd, []string{}); err == nil {
+			t.Fatalf("--%s %s: expected error", args[0], args[1])
+		}
+	}
+}
+
+func TestBuildWithInventory(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- service.yaml
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("inventory", "configmap")
+	cmd.Flags().Set("inventory-namespace", "apps")
+	cmd.Flags().Set("inventory-id", "web")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: v1
+data:
+  apps_web__Service: ""
+kind: ConfigMap
+metadata:
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: web
+  name: inventory
+  namespace: apps
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("inventory", "secret")
+	if err := cmd.RunE(cmd, []string{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestBuildWithPruneAllowlist(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- resources.yaml
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("prune-allowlist", "true")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `--prune-allowlist=apps/v1/Deployment
+--prune-allowlist=core/v1/Service
+--prune-allowlist=networking.k8s.io/v1/Ingress
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("prune-allowlist", "true")
+	cmd.Flags().Set("output-format", "json")
+	if err := cmd.RunE(cmd, []string{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestBuildWithListImages(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("kustomization.yaml", []byte(`
+resources:
+- resources.yaml
+images:
+- name: nginx
+  newTag: "1.21"
+`))
+	fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: web
+        image: nginx
+      - name: sidecar
+        image: nginx
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            image: busybox
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("list-images", "true")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `IMAGE       RESOURCES
+busybox     Deployment apps/web, CronJob backup
+nginx:1.21  Deployment apps/web
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+
+	buffy = new(bytes.Buffer)
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("list-images", "true")
+	cmd.Flags().Set("output-format", "json")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	expected = `[
+  {
+    "image": "busybox",
+    "resources": [
+      {
+        "apiVersion": "apps/v1",
+        "kind": "Deployment",
+        "name": "web",
+        "namespace": "apps"
+      },
+      {
+        "apiVersion": "batch/v1",
+        "kind": "CronJob",
+        "name": "backup"
+      }
+    ]
+  },
+  {
+    "image": "nginx:1.21",
+    "resources": [
+      {
+        "apiVersion": "apps/v1",
+        "kind": "Deployment",
+        "name": "web",
+        "namespace": "apps"
+      }
+    ]
+  }
+]
+`
+	if buffy.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+}
+
+func TestBuildWithTimingsAndProfiles(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	buffy := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.SetErr(stderr)
+	cmd.Flags().Set("timings", "true")
+	cmd.Flags().Set("profile", "cpu=cpu.prof")
+	cmd.Flags().Set("profile", "mem=mem.prof")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if buffy.String() != expectedContent {
+		t.Fatalf("Unexpected output:\n%s", buffy.String())
+	}
+	if !strings.HasPrefix(stderr.String(), "TIME ") ||
+		!strings.Contains(stderr.String(), " transformer  NameReferenceTransformer\n") ||
+		!strings.Contains(stderr.String(), " generator    ConfigMapGenerator\n") {
+		t.Fatalf("Unexpected timings:\n%s", stderr.String())
+	}
+	for _, path := range []string{"cpu.prof", "mem.prof"} {
+		if content, err := fSys.ReadFile(path); err != nil || len(content) == 0 {
+			t.Fatalf("expected profile %s: %v", path, err)
+		}
+	}
+}
+
+func TestBuildWithIllegalProfile(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("profile", "block=block.prof")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "expected cpu=PATH or mem=PATH") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestHelp(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	buffy := new(bytes.Buffer)
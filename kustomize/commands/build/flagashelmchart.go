@@ -0,0 +1,125 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagAsHelmChartName  = "as-helm-chart"
+	flagChartNameName    = "chart-name"
+	flagChartVersionName = "chart-version"
+
+	// helmChartFile is the file describing a chart.
+	helmChartFile = "Chart.yaml"
+	// helmTemplatesDir is the directory of a chart's templates.
+	helmTemplatesDir = "templates"
+)
+
+var (
+	// helmChartName matches the names helm recommends for charts.
+	helmChartName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// semVer matches the semantic versions helm requires of charts.
+	semVer = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+		`(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+)
+
+func AddFlagsAsHelmChart(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.helmChart.path,
+		flagAsHelmChartName,
+		"",
+		"write the output as a helm chart in this directory: a "+
+			helmChartFile+", and a template per resource in its "+
+			helmTemplatesDir+" directory, which helm renders as is")
+	set.StringVar(
+		&theFlags.helmChart.name,
+		flagChartNameName,
+		"",
+		"name of the chart of --"+flagAsHelmChartName+
+			"; defaults to the name of its directory")
+	set.StringVar(
+		&theFlags.helmChart.version,
+		flagChartVersionName,
+		"",
+		"semantic version of the chart of --"+flagAsHelmChartName+
+			", e.g. 1.2.3")
+}
+
+func validateFlagsAsHelmChart() error {
+	c := theFlags.helmChart
+	if c.path == "" {
+		if c.name != "" || c.version != "" {
+			return fmt.Errorf("--%s and --%s need --%s",
+				flagChartNameName, flagChartVersionName, flagAsHelmChartName)
+		}
+		return nil
+	}
+	if theFlags.outputPath != "" || theFlags.outputFormat != outputFormatYaml {
+		return fmt.Errorf(
+			"--%s can't be used with --output, or an --%s other than %s",
+			flagAsHelmChartName, flagOutputFormatName, outputFormatYaml)
+	}
+	if !helmChartName.MatchString(chartName()) {
+		return fmt.Errorf(
+			"illegal chart name %q; expected lower case letters, digits and dashes",
+			chartName())
+	}
+	if !semVer.MatchString(c.version) {
+		return fmt.Errorf(
+			"illegal flag value --%s %q; expected a semantic version, e.g. 1.2.3",
+			flagChartVersionName, c.version)
+	}
+	return nil
+}
+
+// chartName returns the name of the chart of --as-helm-chart.
+func chartName() string {
+	if theFlags.helmChart.name != "" {
+		return theFlags.helmChart.name
+	}
+	return filepath.Base(filepath.Clean(theFlags.helmChart.path))
+}
+
+// writeHelmChart writes the resources as the templates of the
+// chart of --as-helm-chart.
+func writeHelmChart(fSys filesys.FileSystem, m resmap.ResMap) error {
+	dir := theFlags.helmChart.path
+	if err := fSys.MkdirAll(filepath.Join(dir, helmTemplatesDir)); err != nil {
+		return err
+	}
+	chart, err := yaml.Marshal(struct {
+		APIVersion string `json:"apiVersion"`
+		Name       string `json:"name"`
+		Version    string `json:"version"`
+	}{
+		APIVersion: "v2",
+		Name:       chartName(),
+		Version:    theFlags.helmChart.version,
+	})
+	if err != nil {
+		return err
+	}
+	if err = fSys.WriteFile(filepath.Join(dir, helmChartFile), chart); err != nil {
+		return err
+	}
+	w := MakeWriter(fSys)
+	w.escapeTemplates = true
+	return w.WriteIndividualFiles(filepath.Join(dir, helmTemplatesDir), m)
+}
+
+// escapeTemplate returns the content as a helm template that
+// renders it as is, i.e. with its template actions quoted.
+func escapeTemplate(content []byte) []byte {
+	return []byte(strings.ReplaceAll(string(content), "{{", `{{"{{"}}`))
+}
@@ -0,0 +1,16 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagCacheFile(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.cacheFile,
+		"cache-file",
+		"",
+		"keep the bases built in this file, and reuse those whose files are unchanged in later builds")
+}
@@ -0,0 +1,18 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagCheckReferences(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.checkReferences,
+		"check-references",
+		false,
+		"fail the build if resources refer by name, e.g. in a "+
+			"configMapKeyRef, to resources that are neither in the "+
+			"build nor in the externalReferences of the kustomization")
+}
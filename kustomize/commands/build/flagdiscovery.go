@@ -0,0 +1,21 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+// AddFlagDiscovery adds the --discovery flag.
+func AddFlagDiscovery(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.discovery,
+		"discovery",
+		"",
+		"Take whether resources, e.g. custom resources, are namespace scoped "+
+			"from the API resources of the cluster, if '"+
+			krusty.DiscoveryFromCluster+"', or of this file, as "+
+			"'kubectl api-resources' prints them or as APIResourceList documents.")
+}
@@ -0,0 +1,19 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableDataSources adds the --enable-data-sources flag.
+// Data sources run commands, so are disabled by default, and
+// not enabled by --enable-alpha-plugins.
+func AddFlagEnableDataSources(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.dataSources,
+		"enable-data-sources",
+		false,
+		"Enable the commands of the dataSources of kustomizations.")
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableJsonnet adds the --enable-jsonnet flag.
+// Unlike helm, jsonnet runs only with this flag, not with
+// --enable-alpha-plugins.
+func AddFlagEnableJsonnet(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.jsonnet,
+		"enable-jsonnet",
+		false,
+		"Enable the evaluation of .jsonnet and .libsonnet resources.")
+	set.StringVar(
+		&theFlags.jsonnetCommand,
+		"jsonnet-command",
+		"jsonnet", // default
+		"jsonnet command (path to executable)")
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableOpa adds the --enable-opa flag.
+// Unlike helm, opa runs only with this flag, not with
+// --enable-alpha-plugins.
+func AddFlagEnableOpa(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.opa,
+		"enable-opa",
+		false,
+		"Enable the evaluation of Rego policies by PolicyTransformer validators.")
+	set.StringVar(
+		&theFlags.opaCommand,
+		"opa-command",
+		"opa", // default
+		"opa command (path to executable)")
+}
@@ -0,0 +1,22 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableSops adds the --enable-sops flag.
+func AddFlagEnableSops(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.sops,
+		"enable-sops",
+		false,
+		"Decrypt files named e.g. secrets.sops.yaml with sops as they're loaded.")
+	set.StringVar(
+		&theFlags.sopsCommand,
+		"sops-command",
+		"sops", // default
+		"sops command (path to executable)")
+}
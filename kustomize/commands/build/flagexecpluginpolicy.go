@@ -0,0 +1,33 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagsExecPluginPolicy adds the flags restricting how exec
+// plugins are run. They only matter with --enable-alpha-plugins.
+func AddFlagsExecPluginPolicy(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.execPolicy.DisableNetwork,
+		"exec-plugin-no-network",
+		false,
+		"run exec plugins without network access (Linux only, needs unshare)")
+	set.BoolVar(
+		&theFlags.execPolicy.IsolateWorkingDir,
+		"exec-plugin-isolate-dir",
+		false,
+		"run exec plugins in an empty temporary directory instead of the kustomization root")
+	set.BoolVar(
+		&theFlags.execPolicy.CleanEnv,
+		"exec-plugin-clean-env",
+		false,
+		"pass exec plugins only PATH and the variables named by --exec-plugin-allow-env")
+	set.StringArrayVar(
+		&theFlags.execPolicy.EnvAllowlist,
+		"exec-plugin-allow-env",
+		[]string{},
+		"an environment variable passed to exec plugins despite --exec-plugin-clean-env")
+}
@@ -0,0 +1,22 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagsGeneratorCache(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.cacheDir,
+		"cache-dir",
+		"",
+		"cache the output of helm chart inflation, and of exec generators "+
+			"declaring their inputs with the config.kubernetes.io/plugin-inputs annotation, in this directory")
+	set.BoolVar(
+		&theFlags.refreshCache,
+		"refresh-cache",
+		false,
+		"ignore and replace the generator output held in --cache-dir, and the schemas kept by --schema-from-cluster")
+}
@@ -0,0 +1,37 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/util"
+)
+
+func AddFlagImage(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.images,
+		"image",
+		nil,
+		"set an image in the kustomization built, in any form taken by "+
+			"'kustomize edit set image', e.g. nginx=nginx:1.25.3; "+
+			"may be repeated")
+}
+
+func validateFlagImage() error {
+	_, err := getFlagImageValues()
+	return err
+}
+
+func getFlagImageValues() ([]types.Image, error) {
+	var images []types.Image
+	for _, arg := range theFlags.images {
+		im, err := util.ParseImage(arg)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, im)
+	}
+	return images, nil
+}
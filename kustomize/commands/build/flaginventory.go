@@ -0,0 +1,78 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagInventoryName = "inventory"
+
+	inventoryConfigMap     = "configmap"
+	inventoryResourceGroup = "resourcegroup"
+)
+
+func AddFlagsInventory(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.inventory.kind,
+		flagInventoryName,
+		"",
+		"append an inventory object recording the resources of the "+
+			"build, for pruning, replacing that of the kustomization: a "+
+			"'"+inventoryConfigMap+"', or a kpt.dev '"+
+			inventoryResourceGroup+"', as cli-utils reads them")
+	set.StringVar(
+		&theFlags.inventory.name,
+		"inventory-name",
+		"inventory",
+		"name of the object of --"+flagInventoryName)
+	set.StringVar(
+		&theFlags.inventory.namespace,
+		"inventory-namespace",
+		"",
+		"namespace of the object of --"+flagInventoryName)
+	set.StringVar(
+		&theFlags.inventory.id,
+		"inventory-id",
+		"",
+		"id of the group of resources recorded by the object of --"+
+			flagInventoryName+"; defaults to one derived from its "+
+			"namespace and name, the same in all builds")
+}
+
+func validateFlagsInventory() error {
+	switch theFlags.inventory.kind {
+	case "", inventoryConfigMap, inventoryResourceGroup:
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagInventoryName, theFlags.inventory.kind,
+			[]string{inventoryConfigMap, inventoryResourceGroup})
+	}
+	if theFlags.inventory.name == "" {
+		return fmt.Errorf("--inventory-name can't be empty")
+	}
+	return nil
+}
+
+// getFlagsInventory returns the inventory of --inventory, or
+// nil if it's not set.
+func getFlagsInventory() *types.Inventory {
+	i := theFlags.inventory
+	n := types.NameArgs{Name: i.name, Namespace: i.namespace}
+	switch i.kind {
+	case inventoryConfigMap:
+		return &types.Inventory{
+			Type: types.InventoryTypeConfigMap, ConfigMap: n, Id: i.id}
+	case inventoryResourceGroup:
+		return &types.Inventory{
+			Type: types.InventoryTypeResourceGroup, ResourceGroup: n, Id: i.id}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagLenient(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.lenient,
+		"lenient",
+		false,
+		"ignore the fields of kustomization files that are unknown, "+
+			"e.g. misspelled, rather than failing the build")
+}
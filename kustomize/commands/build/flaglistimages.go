@@ -0,0 +1,163 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const flagListImagesName = "list-images"
+
+// containerFields are the fields holding lists of containers,
+// whose images are listed, wherever they are in resources.
+var containerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+func AddFlagListImages(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.listImages,
+		flagListImagesName,
+		false,
+		"instead of the resources, write the images of their "+
+			"containers, each once with the resources using it, as a "+
+			"table, or with --"+flagOutputFormatName+" "+outputFormatJson+
+			" as JSON")
+}
+
+func validateFlagListImages() error {
+	if !theFlags.listImages {
+		return nil
+	}
+	if theFlags.outputPattern != "" || theFlags.splitBy != "" ||
+		theFlags.helmChart.path != "" || theFlags.pruneAllowlist ||
+		(theFlags.outputFormat != outputFormatYaml &&
+			theFlags.outputFormat != outputFormatJson) {
+		return fmt.Errorf(
+			"--%s can't be used with --%s, --%s, --%s, --%s, or an --%s other than %s",
+			flagListImagesName, flagOutputPatternName, flagSplitByName,
+			flagAsHelmChartName, flagPruneAllowlistName,
+			flagOutputFormatName, outputFormatJson)
+	}
+	return nil
+}
+
+// imageUse is an image, and the resources using it.
+type imageUse struct {
+	Image     string          `json:"image"`
+	Resources []imageResource `json:"resources"`
+}
+
+// imageResource is a resource using an image.
+type imageResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// String returns the resource as kubectl shows it, i.e. kind
+// and name, with its namespace if any.
+func (r imageResource) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+// listImages returns the images of the resources, sorted, as
+// a table or JSON, for --list-images.
+func listImages(m resmap.ResMap) ([]byte, error) {
+	uses := make(map[string]*imageUse)
+	for _, r := range m.Resources() {
+		if r.GetKind() == "CustomResourceDefinition" {
+			continue
+		}
+		user := imageResource{
+			APIVersion: r.GetApiVersion(),
+			Kind:       r.GetKind(),
+			Name:       r.GetName(),
+			Namespace:  r.GetNamespace(),
+		}
+		for _, image := range containerImages(r.YNode(), nil) {
+			u, ok := uses[image]
+			if !ok {
+				u = &imageUse{Image: image}
+				uses[image] = u
+			}
+			if n := len(u.Resources); n == 0 || u.Resources[n-1] != user {
+				u.Resources = append(u.Resources, user)
+			}
+		}
+	}
+	result := make([]*imageUse, 0, len(uses))
+	for _, u := range uses {
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Image < result[j].Image
+	})
+	if theFlags.outputFormat == outputFormatJson {
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+	}
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "IMAGE\tRESOURCES")
+	for _, u := range result {
+		users := make([]string, len(u.Resources))
+		for i, r := range u.Resources {
+			users[i] = r.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", u.Image, strings.Join(users, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// containerImages appends to images those of the containers
+// in the node, in lists of containerFields at any depth.
+func containerImages(n *yaml.Node, images []string) []string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i].Value, n.Content[i+1]
+			if value.Kind == yaml.SequenceNode && isContainerField(key) {
+				for _, c := range value.Content {
+					if image := yaml.NewRNode(c).Field("image"); image != nil &&
+						image.Value.YNode().Value != "" {
+						images = append(images, image.Value.YNode().Value)
+					}
+				}
+			}
+			images = containerImages(value, images)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			images = containerImages(c, images)
+		}
+	}
+	return images
+}
+
+func isContainerField(key string) bool {
+	for _, f := range containerFields {
+		if f == key {
+			return true
+		}
+	}
+	return false
+}
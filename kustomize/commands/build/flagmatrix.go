@@ -0,0 +1,17 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagMatrix(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.matrix,
+		"matrix",
+		"",
+		"build the kustomization once per entry of the clusters in this "+
+			"file, writing each output below the --output directory")
+}
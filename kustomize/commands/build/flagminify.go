@@ -0,0 +1,75 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func AddFlagMinify(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.minify,
+		"minify",
+		false,
+		"drop null values, e.g. creationTimestamp: null, and empty "+
+			"maps and lists, e.g. status: {}, from the output; empty "+
+			"emptyDir volumes and selectors, which mean something, are kept")
+}
+
+// minify drops the null values, and the empty maps and lists,
+// of the resources, for --minify.
+func minify(m resmap.ResMap) error {
+	return m.ApplyFilter(kio.FilterFunc(
+		func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			for _, n := range nodes {
+				minifyNode(n.YNode())
+			}
+			return nodes, nil
+		}))
+}
+
+// minifyNode drops the null values, and the empty maps and
+// lists, of the maps in the node, including those that are
+// empty once theirs are dropped.  Elements of lists are kept.
+func minifyNode(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			minifyNode(v)
+			if isNull(v) || (isEmpty(v) && !emptyMeansSomething(k.Value)) {
+				continue
+			}
+			content = append(content, k, v)
+		}
+		n.Content = content
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, e := range n.Content {
+			minifyNode(e)
+		}
+	}
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == yaml.NodeTagNull
+}
+
+func isEmpty(n *yaml.Node) bool {
+	return (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode) &&
+		len(n.Content) == 0
+}
+
+// emptyMeansSomething returns true for the fields that mean
+// something even when empty: an emptyDir volume, and label
+// selectors, which then select everything.
+func emptyMeansSomething(field string) bool {
+	return field == "emptyDir" || field == "selector" ||
+		strings.HasSuffix(field, "Selector")
+}
@@ -0,0 +1,17 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagNamespace(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.namespace,
+		"namespace",
+		"",
+		"put all namespaced resources of the output in this namespace, "+
+			"as if by a kustomization on top of the one built")
+}
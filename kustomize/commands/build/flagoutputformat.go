@@ -0,0 +1,130 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const (
+	flagOutputFormatName = "output-format"
+
+	outputFormatYaml      = "yaml"
+	outputFormatJson      = "json"
+	outputFormatJsonLines = "jsonlines"
+	outputFormatList      = "list"
+)
+
+func AddFlagOutputFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.outputFormat, flagOutputFormatName,
+		outputFormatYaml,
+		"Format of the output. "+
+			"Use '"+outputFormatJson+"' to emit each resource as an indented JSON document, "+
+			"or '"+outputFormatJsonLines+"' to emit each resource as JSON on a single line, "+
+			"or '"+outputFormatList+"' to emit all resources as the items of a single "+
+			"YAML document of kind List.")
+}
+
+func validateFlagOutputFormat() error {
+	switch theFlags.outputFormat {
+	case outputFormatYaml, outputFormatJson, outputFormatJsonLines, outputFormatList:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagOutputFormatName, theFlags.outputFormat,
+			[]string{outputFormatYaml, outputFormatJson, outputFormatJsonLines, outputFormatList})
+	}
+}
+
+// encode returns the resources in the given output format.
+func encode(m resmap.ResMap, format string) ([]byte, error) {
+	if format == outputFormatYaml && !yamlStyled() && !theFlags.sourceComments {
+		return m.AsYaml()
+	}
+	if format == outputFormatList {
+		return encodeList(m.Resources())
+	}
+	var b bytes.Buffer
+	for i, r := range m.Resources() {
+		content, err := encodeResource(r, format)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && format == outputFormatYaml {
+			b.WriteString("---\n")
+		}
+		b.Write(content)
+	}
+	return b.Bytes(), nil
+}
+
+// encodeResource returns one resource in the given output format.
+func encodeResource(r *resource.Resource, format string) ([]byte, error) {
+	if format == outputFormatList {
+		return encodeList([]*resource.Resource{r})
+	}
+	content, err := r.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if format == outputFormatYaml {
+		if content, err = jsonToYaml(content); err != nil {
+			return nil, err
+		}
+		if theFlags.sourceComments {
+			content = withSourceComment(content, r)
+		}
+		return content, nil
+	}
+	var b bytes.Buffer
+	if format == outputFormatJson {
+		err = json.Indent(&b, content, "", "  ")
+	} else {
+		err = json.Compact(&b, content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// encodeList returns the resources as the items of a v1 List,
+// in YAML, with fields sorted as kubectl sorts them.
+func encodeList(resources []*resource.Resource) ([]byte, error) {
+	items := make([]json.RawMessage, len(resources))
+	for i, r := range resources {
+		content, err := r.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = content
+	}
+	content, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYaml(content)
+}
+
+// fileExtension returns the extension of files holding
+// resources in the given output format.
+func fileExtension(format string) string {
+	if format == outputFormatYaml || format == outputFormatList {
+		return ".yaml"
+	}
+	return ".json"
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/pflag"
 )
 
+const flagOutputPatternName = "output-pattern"
+
 func AddFlagOutputPath(set *pflag.FlagSet) {
 	set.StringVarP(
 		&theFlags.outputPath,
@@ -15,3 +17,17 @@ func AddFlagOutputPath(set *pflag.FlagSet) {
 		"",  // default
 		"If specified, write output to this path.")
 }
+
+func AddFlagOutputPattern(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.outputPattern,
+		flagOutputPatternName,
+		"", // default
+		"If --output is a directory, write each resource to the path given "+
+			"by this pattern, relative to that directory, e.g. "+
+			"'{namespace}/{kind}_{name}.{ext}'. "+
+			"Known placeholders are {namespace}, {group}, {version}, "+
+			"{kind}, {name} and {ext}; cluster scoped resources have an "+
+			"empty {namespace}. By default, files are named "+
+			"[namespace_]group_version_kind_name.yaml.")
+}
@@ -0,0 +1,17 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagParallel(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.parallel,
+		"parallel",
+		false,
+		"read the resource files and build the bases of a kustomization concurrently; "+
+			"bases may not set the openapi field, only the top kustomization")
+}
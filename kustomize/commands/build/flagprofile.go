@@ -0,0 +1,112 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// theTimings, if not nil, adds up the time spent by the
+// builds, for --timings.
+var theTimings *krusty.Timings
+
+func AddFlagsProfile(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.profile,
+		"profile",
+		nil,
+		"cpu=PATH or mem=PATH, writing a pprof profile of the CPU "+
+			"used by the build, or of the memory in use once it's "+
+			"done, to PATH; may be repeated")
+	set.BoolVar(
+		&theFlags.timings,
+		"timings",
+		false,
+		"print to stderr the time the build spent on each base, "+
+			"generator, transformer and validator, and on fetching "+
+			"remote bases and files")
+}
+
+func validateFlagProfile() error {
+	for _, p := range theFlags.profile {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 || parts[1] == "" ||
+			(parts[0] != "cpu" && parts[0] != "mem") {
+			return fmt.Errorf(
+				"illegal flag value --profile %s; expected cpu=PATH or mem=PATH", p)
+		}
+	}
+	return nil
+}
+
+// startProfiles starts the profiles of --profile, and returns
+// the function writing them.
+func startProfiles(fSys filesys.FileSystem) (func() error, error) {
+	var files []filesys.File
+	closeAll := func() error {
+		var result error
+		for _, f := range files {
+			if err := f.Close(); err != nil && result == nil {
+				result = err
+			}
+		}
+		return result
+	}
+	var cpu, mem filesys.File
+	for _, p := range theFlags.profile {
+		parts := strings.SplitN(p, "=", 2)
+		f, err := fSys.Create(parts[1])
+		if err != nil {
+			_ = closeAll()
+			return nil, err
+		}
+		files = append(files, f)
+		if parts[0] == "cpu" {
+			cpu = f
+		} else {
+			mem = f
+		}
+	}
+	if cpu != nil {
+		if err := pprof.StartCPUProfile(cpu); err != nil {
+			_ = closeAll()
+			return nil, err
+		}
+	}
+	return func() error {
+		if cpu != nil {
+			pprof.StopCPUProfile()
+		}
+		if mem != nil {
+			// Collect garbage, to profile the memory in use.
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(mem); err != nil {
+				_ = closeAll()
+				return err
+			}
+		}
+		return closeAll()
+	}, nil
+}
+
+// writeTimings writes the timings, longest first, as a table.
+func writeTimings(w io.Writer, timings []krusty.Timing) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tCOUNT\tKIND\tNAME")
+	for _, t := range timings {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n",
+			t.Duration.Round(time.Microsecond), t.Count, t.Kind, t.Name)
+	}
+	return tw.Flush()
+}
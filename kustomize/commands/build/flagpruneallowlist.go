@@ -0,0 +1,65 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+const flagPruneAllowlistName = "prune-allowlist"
+
+func AddFlagPruneAllowlist(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.pruneAllowlist,
+		flagPruneAllowlistName,
+		false,
+		"instead of the resources, write the kubectl apply "+
+			"--prune-allowlist flags of their kinds, one per line, e.g. "+
+			"for kubectl apply --prune $(kustomize build --"+
+			flagPruneAllowlistName+")")
+}
+
+func validateFlagPruneAllowlist() error {
+	if theFlags.pruneAllowlist &&
+		(theFlags.outputPattern != "" || theFlags.splitBy != "" ||
+			theFlags.helmChart.path != "" ||
+			theFlags.outputFormat != outputFormatYaml) {
+		return fmt.Errorf(
+			"--%s can't be used with --%s, --%s, --%s or --%s",
+			flagPruneAllowlistName, flagOutputPatternName, flagSplitByName,
+			flagAsHelmChartName, flagOutputFormatName)
+	}
+	return nil
+}
+
+// pruneAllowlist returns the --prune-allowlist flags of the
+// kinds of the resources, sorted, naming the core group as
+// kubectl does.
+func pruneAllowlist(m resmap.ResMap) ([]byte, error) {
+	seen := make(map[string]bool)
+	var flags []string
+	for _, r := range m.Resources() {
+		gvk := r.GetGvk()
+		group := gvk.Group
+		if group == "" {
+			group = "core"
+		}
+		flag := "--prune-allowlist=" +
+			group + "/" + gvk.Version + "/" + gvk.Kind
+		if !seen[flag] {
+			seen[flag] = true
+			flags = append(flags, flag)
+		}
+	}
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	sort.Strings(flags)
+	return []byte(strings.Join(flags, "\n") + "\n"), nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+)
+
+// AddFlagSchemaFromCluster adds the --schema-from-cluster flag.
+func AddFlagSchemaFromCluster(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.clusterSchema,
+		"schema-from-cluster",
+		false,
+		"Merge patches per the openapi schema of the cluster, with its custom "+
+			"resources, unless the openapi field of the kustomization names "+
+			"another schema.  The schema is kept in --cache-dir, or else in the "+
+			"user cache directory, per kubectl context and cluster version.")
+}
+
+// schemaCacheDir returns the directory keeping the
+// schemas of clusters.
+func schemaCacheDir() string {
+	if theFlags.cacheDir != "" {
+		return theFlags.cacheDir
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "kustomize")
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func AddFlagSet(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.set,
+		"set",
+		nil,
+		"NAME=VALUE overriding the value of the var NAME, or setting the "+
+			"parameter NAME that the conditions of conditionals test, or with NAME "+
+			"of the form KIND/NAME/FIELDPATH, e.g. ConfigMap/cluster/data.name, "+
+			"the value of a resource field that replacements take as their source; "+
+			"may be repeated")
+}
+
+func validateFlagSet() error {
+	for _, s := range theFlags.set {
+		if i := strings.Index(s, "="); i < 1 {
+			return fmt.Errorf("illegal flag value --set %s; expected NAME=VALUE", s)
+		}
+	}
+	return nil
+}
+
+// getFlagSetValues returns the --set values keyed by name.
+func getFlagSetValues() map[string]string {
+	if len(theFlags.set) == 0 {
+		return nil
+	}
+	values := make(map[string]string)
+	for _, s := range theFlags.set {
+		parts := strings.SplitN(s, "=", 2)
+		values[parts[0]] = parts[1]
+	}
+	return values
+}
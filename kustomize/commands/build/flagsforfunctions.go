@@ -4,9 +4,18 @@
 package build
 
 import (
+	"fmt"
+	"path/filepath"
+
 	"github.com/spf13/pflag"
 )
 
+const flagContainerRuntimeName = "container-runtime"
+
+// containerRuntimes are the container engines that
+// can run container functions.
+var containerRuntimes = []string{"docker", "podman"}
+
 func AddFunctionBasicsFlags(set *pflag.FlagSet) {
 	set.BoolVar(
 		&theFlags.fnOptions.Network, "network", false,
@@ -14,6 +23,9 @@ func AddFunctionBasicsFlags(set *pflag.FlagSet) {
 	set.StringVar(
 		&theFlags.fnOptions.NetworkName, "network-name", "bridge",
 		"the docker network to run the container in")
+	set.StringVar(
+		&theFlags.fnOptions.ContainerRuntime, flagContainerRuntimeName, "docker",
+		"the container engine (docker or podman) used to run container functions")
 	set.StringArrayVar(
 		&theFlags.fnOptions.Mounts, "mount", []string{},
 		"a list of storage options read from the filesystem")
@@ -25,6 +37,21 @@ func AddFunctionBasicsFlags(set *pflag.FlagSet) {
 		"use the uid and gid of the command executor to run the function in the container")
 }
 
+// validateFlagContainerRuntime rejects container engines other
+// than docker and podman, which may be named by their path.
+func validateFlagContainerRuntime() error {
+	name := filepath.Base(theFlags.fnOptions.ContainerRuntime)
+	for _, r := range containerRuntimes {
+		if name == r {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"illegal flag value --%s %s; legal values: %v",
+		flagContainerRuntimeName, theFlags.fnOptions.ContainerRuntime,
+		containerRuntimes)
+}
+
 func AddFunctionAlphaEnablementFlags(set *pflag.FlagSet) {
 	set.BoolVar(
 		&theFlags.fnOptions.EnableExec, "enable-exec", false,
@@ -33,4 +60,10 @@ func AddFunctionAlphaEnablementFlags(set *pflag.FlagSet) {
 	set.BoolVar(
 		&theFlags.fnOptions.EnableStar, "enable-star", false,
 		"enable support for starlark functions. (Alpha)")
+	set.BoolVar(
+		&theFlags.fnOptions.EnableWasm, "enable-wasm", false,
+		"enable support for WebAssembly (WASI) functions. (Alpha)")
+	set.StringVar(
+		&theFlags.fnOptions.WasmRuntime, "wasm-runtime", "wasmtime",
+		"the WASI runtime used to run WebAssembly functions (Alpha)")
 }
@@ -0,0 +1,61 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagSortByName = "sort-by"
+
+	sortByKind      = "kind"
+	sortByName      = "name"
+	sortByNamespace = "namespace"
+	sortByNone      = "none"
+)
+
+func AddFlagSortBy(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.sortBy,
+		flagSortByName,
+		"",
+		"Sort the output resources, replacing the sortOptions of the "+
+			"kustomization. Use '"+sortByKind+"' for the legacy order "+
+			"(Namespaces first, Webhooks last, etc), '"+sortByName+
+			"' or '"+sortByNamespace+"' to sort by name or namespace, "+
+			"then kind, and '"+sortByNone+"' to keep the input order.")
+}
+
+func validateFlagSortBy() error {
+	switch theFlags.sortBy {
+	case "", sortByKind, sortByName, sortByNamespace, sortByNone:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagSortByName, theFlags.sortBy,
+			[]string{sortByKind, sortByName, sortByNamespace, sortByNone})
+	}
+}
+
+// getFlagSortByOptions returns the sort options of --sort-by,
+// or nil if it's not set.
+func getFlagSortByOptions() *types.SortOptions {
+	switch theFlags.sortBy {
+	case sortByKind:
+		return &types.SortOptions{Order: types.LegacySortOrder}
+	case sortByName:
+		return &types.SortOptions{Order: types.NameSortOrder}
+	case sortByNamespace:
+		return &types.SortOptions{Order: types.NamespaceSortOrder}
+	case sortByNone:
+		return &types.SortOptions{Order: types.FIFOSortOrder}
+	default:
+		return nil
+	}
+}
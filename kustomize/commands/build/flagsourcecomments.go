@@ -0,0 +1,68 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const flagSourceCommentsName = "source-comments"
+
+func AddFlagSourceComments(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.sourceComments,
+		flagSourceCommentsName,
+		false,
+		"prefix each YAML document of the output with a comment naming "+
+			"where it comes from, e.g. '# Source: ../base/deployment.yaml', "+
+			"or the kustomization and generator making it")
+}
+
+func validateFlagSourceComments() error {
+	if theFlags.sourceComments && theFlags.outputFormat != outputFormatYaml {
+		return fmt.Errorf(
+			"--%s needs --%s %s", flagSourceCommentsName,
+			flagOutputFormatName, outputFormatYaml)
+	}
+	return nil
+}
+
+// withSourceComment returns the YAML content of the resource,
+// prefixed with its source comment, for --source-comments.
+func withSourceComment(content []byte, r *resource.Resource) []byte {
+	origin := theOrigins[r.CurId()]
+	if origin == nil {
+		return content
+	}
+	return append([]byte("# Source: "+originSource(origin)+"\n"), content...)
+}
+
+// originSource returns the file a resource comes from, as
+// kustomization resources name it, e.g. with its repository
+// if it's remote, or the kustomization and the generator
+// making it.
+func originSource(origin *resource.Origin) string {
+	if origin.Path == "" {
+		source := origin.ConfiguredIn
+		if by := origin.ConfiguredBy; by.Kind != "" {
+			source += " (" + by.Kind
+			if by.Name != "" {
+				source += " " + by.Name
+			}
+			source += ")"
+		}
+		return source
+	}
+	if origin.Repo == "" {
+		return origin.Path
+	}
+	source := origin.Repo + "//" + origin.Path
+	if origin.Ref != "" {
+		source += "?ref=" + origin.Ref
+	}
+	return source
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+const (
+	flagSplitByName = "split-by"
+
+	splitByNamespace = "namespace"
+	splitByKind      = "kind"
+	splitByOrigin    = "origin"
+)
+
+// theOrigins, if not nil, holds the origins of the resources
+// built, for --split-by origin and --source-comments.
+var theOrigins map[resid.ResId]*resource.Origin
+
+func AddFlagSplitBy(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.splitBy,
+		flagSplitByName,
+		"",
+		"If --output is a directory, write each resource below a "+
+			"subdirectory named after its '"+splitByNamespace+"', its '"+
+			splitByKind+"', or its '"+splitByOrigin+"', i.e. the "+
+			"directory of the file, or kustomization, it comes from. "+
+			"Cluster scoped resources are written to the output "+
+			"directory itself when split by namespace.")
+}
+
+func validateFlagSplitBy() error {
+	switch theFlags.splitBy {
+	case "":
+		return nil
+	case splitByNamespace, splitByKind, splitByOrigin:
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagSplitByName, theFlags.splitBy,
+			[]string{splitByNamespace, splitByKind, splitByOrigin})
+	}
+	if theFlags.outputPath == "" || theFlags.outputPattern != "" {
+		return fmt.Errorf(
+			"--%s needs an --output directory, and can't be used with --%s",
+			flagSplitByName, flagOutputPatternName)
+	}
+	return nil
+}
+
+// splitDir returns the subdirectory of the resource's file,
+// relative to the output directory, for --split-by.
+func (w Writer) splitDir(res *resource.Resource) string {
+	switch w.splitBy {
+	case splitByNamespace:
+		return strings.ToLower(res.GetNamespace())
+	case splitByKind:
+		return strings.ToLower(res.GetKind())
+	default:
+		return originDir(w.origins[res.CurId()])
+	}
+}
+
+// originDir returns the directory of the file a resource
+// comes from, or of the kustomization generating it, below
+// its repository if it's remote.  Directories above the
+// kustomization built are named "__", so that none is
+// outside the output directory.
+func originDir(origin *resource.Origin) string {
+	if origin == nil {
+		return ""
+	}
+	path := origin.Path
+	if path == "" {
+		path = origin.ConfiguredIn
+	}
+	dir := filepath.ToSlash(filepath.Dir(path))
+	if origin.Repo != "" {
+		repo := origin.Repo
+		if i := strings.Index(repo, "://"); i >= 0 {
+			repo = repo[i+len("://"):]
+		}
+		dir = repo + "/" + dir
+	}
+	var parts []string
+	for _, p := range strings.Split(dir, "/") {
+		switch p {
+		case "", ".":
+		case "..":
+			parts = append(parts, "__")
+		default:
+			parts = append(parts, p)
+		}
+	}
+	return filepath.Join(parts...)
+}
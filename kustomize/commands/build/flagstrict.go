@@ -0,0 +1,18 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+func AddFlagStrict(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.strict,
+		"strict",
+		false,
+		"fail the build if it has warnings, e.g. about deprecated "+
+			"fields or selectors matching nothing, rather than "+
+			"printing them to stderr")
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const flagValidateName = "validate"
+
+func AddFlagValidate(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.validate,
+		flagValidateName,
+		"",
+		"check the output against an openapi schema, failing on unknown "+
+			"fields and values of the wrong type: '"+krusty.ValidateWithBuildSchema+
+			"' (the default) for the schema of the build, '"+
+			krusty.ValidateWithClusterSchema+"' for that of the cluster, or "+
+			"'k8s-VERSION', e.g. k8s-1.21, for a bundled one")
+	set.Lookup(flagValidateName).NoOptDefVal = krusty.ValidateWithBuildSchema
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func AddFlagsWatch(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.watch.enabled,
+		"watch",
+		false,
+		"rebuild, and write the output again, whenever a file "+
+			"below the kustomization or one of its local bases changes")
+	set.DurationVar(
+		&theFlags.watch.interval,
+		"watch-interval",
+		time.Second,
+		"how often to check for changes with --watch")
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagYamlIndentName    = "yaml-indent"
+	flagYamlSeqIndentName = "yaml-seq-indent"
+	flagYamlLineWidthName = "yaml-line-width"
+	flagYamlQuoteName     = "yaml-quote"
+
+	yamlQuoteDouble = "double"
+	yamlQuoteSingle = "single"
+
+	// The defaults are the style of sigs.k8s.io/yaml, which
+	// encodes the output unless another style is asked for.
+	defaultYamlIndent    = kyaml.DefaultIndent
+	defaultYamlSeqIndent = string(kyaml.CompactSequenceStyle)
+	defaultYamlLineWidth = 80
+	defaultYamlQuote     = yamlQuoteDouble
+)
+
+func AddFlagsYamlStyle(set *pflag.FlagSet) {
+	set.IntVar(
+		&theFlags.yamlStyle.indent,
+		flagYamlIndentName,
+		defaultYamlIndent,
+		"number of spaces, 2 to 9, nested YAML maps are indented by")
+	set.StringVar(
+		&theFlags.yamlStyle.seqIndent,
+		flagYamlSeqIndentName,
+		defaultYamlSeqIndent,
+		"indentation of YAML lists: '"+string(kyaml.CompactSequenceStyle)+
+			"' writes their '- ' at the indentation of the field holding "+
+			"them, '"+string(kyaml.WideSequenceStyle)+"' indents it")
+	set.IntVar(
+		&theFlags.yamlStyle.lineWidth,
+		flagYamlLineWidthName,
+		defaultYamlLineWidth,
+		"width past which long YAML strings are folded onto the next "+
+			"line; 0 never folds them")
+	set.StringVar(
+		&theFlags.yamlStyle.quote,
+		flagYamlQuoteName,
+		defaultYamlQuote,
+		"quotes, '"+yamlQuoteDouble+"' or '"+yamlQuoteSingle+"', put "+
+			"around YAML strings that would otherwise read as numbers, "+
+			"bools or null, e.g. \"8080\" or \"on\"")
+}
+
+func validateFlagsYamlStyle() error {
+	s := theFlags.yamlStyle
+	if s.indent < 2 || s.indent > 9 {
+		return fmt.Errorf(
+			"illegal flag value --%s %d; expected 2 to 9",
+			flagYamlIndentName, s.indent)
+	}
+	switch kyaml.SequenceIndentStyle(s.seqIndent) {
+	case kyaml.CompactSequenceStyle, kyaml.WideSequenceStyle:
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagYamlSeqIndentName, s.seqIndent,
+			[]string{string(kyaml.CompactSequenceStyle), string(kyaml.WideSequenceStyle)})
+	}
+	if s.lineWidth < 0 {
+		return fmt.Errorf(
+			"illegal flag value --%s %d; expected 0 or more",
+			flagYamlLineWidthName, s.lineWidth)
+	}
+	switch s.quote {
+	case yamlQuoteDouble, yamlQuoteSingle:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagYamlQuoteName, s.quote,
+			[]string{yamlQuoteDouble, yamlQuoteSingle})
+	}
+}
+
+// yamlStyled returns true if the --yaml flags ask for a style
+// other than the default one.
+func yamlStyled() bool {
+	s := theFlags.yamlStyle
+	return s.indent != defaultYamlIndent ||
+		s.seqIndent != defaultYamlSeqIndent ||
+		s.lineWidth != defaultYamlLineWidth ||
+		s.quote != defaultYamlQuote
+}
+
+// jsonToYaml returns the JSON content as YAML, with its fields
+// sorted, in the style of the --yaml flags.
+func jsonToYaml(content []byte) ([]byte, error) {
+	if !yamlStyled() {
+		return yaml.JSONToYAML(content)
+	}
+	var doc kyaml.Node
+	if err := kyaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	restyle(&doc, theFlags.yamlStyle.quote == yamlQuoteSingle)
+	var b bytes.Buffer
+	e := kyaml.NewEncoderWithOptions(&b, &kyaml.EncoderOptions{
+		SeqIndent: kyaml.SequenceIndentStyle(theFlags.yamlStyle.seqIndent),
+		Indent:    theFlags.yamlStyle.indent,
+		LineWidth: theFlags.yamlStyle.lineWidth,
+	})
+	if err := e.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// restyle drops the JSON styles of the node, i.e. flow maps
+// and lists and quoted strings, so that it's written as block
+// YAML.  Strings that would otherwise read as other values are
+// quoted, with single quotes if asked to, else double ones.
+func restyle(n *kyaml.Node, single bool) {
+	n.Style = 0
+	if n.Kind == kyaml.ScalarNode && n.Tag == kyaml.NodeTagString &&
+		kyaml.IsValueNonString(n.Value) {
+		n.Style = kyaml.DoubleQuotedStyle
+		if single {
+			n.Style = kyaml.SingleQuotedStyle
+		}
+	}
+	for _, c := range n.Content {
+		restyle(c, single)
+	}
+}
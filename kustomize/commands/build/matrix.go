@@ -0,0 +1,186 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// matrixValuesName is the name of the ConfigMap holding the
+// values of a cluster, which replacements take as their source.
+const matrixValuesName = "matrix-values"
+
+// matrix is the content of the file given to --matrix, e.g.
+//
+//	replacements:
+//	- source:
+//	    kind: ConfigMap
+//	    name: matrix-values
+//	    fieldPath: data.replicas
+//	  targets:
+//	  - select:
+//	      kind: Deployment
+//	    fieldPaths:
+//	    - spec.replicas
+//	clusters:
+//	- name: east
+//	  values:
+//	    replicas: 3
+//	- name: west
+//	  values:
+//	    replicas: 1
+//
+// The kustomization is built once per cluster, with the
+// cluster's values in the data of a ConfigMap named
+// matrix-values, and the replacements applied to the output.
+// The ConfigMap itself isn't part of the output.
+type matrix struct {
+	Replacements []types.ReplacementField `json:"replacements,omitempty"`
+	Clusters     []matrixCluster          `json:"clusters"`
+}
+
+type matrixCluster struct {
+	Name   string                 `json:"name"`
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+func loadMatrix(fSys filesys.FileSystem, path string) (*matrix, error) {
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var mx matrix
+	if err = yaml.UnmarshalStrict(content, &mx); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(mx.Clusters) == 0 {
+		return nil, fmt.Errorf("%s: no clusters", path)
+	}
+	seen := make(map[string]bool)
+	for _, c := range mx.Clusters {
+		if c.Name == "" || c.Name == "." || c.Name == ".." ||
+			strings.ContainsAny(c.Name, `/\`) {
+			return nil, fmt.Errorf("%s: illegal cluster name '%s'", path, c.Name)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("%s: cluster '%s' is listed twice", path, c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return &mx, nil
+}
+
+// runMatrixBuild builds the kustomization once per cluster of
+// the matrix, writing the output of each to a file, or with
+// --output-pattern a directory, named after the cluster.  All
+// clusters are built, even if some fail.
+//
+// Each build is of a kustomization made for the cluster in a
+// temporary directory, that includes the kustomization being
+// built, the values ConfigMap, and the matrix replacements.
+func runMatrixBuild(fSys filesys.FileSystem) error {
+	mx, err := loadMatrix(fSys, theFlags.matrix)
+	if err != nil {
+		return err
+	}
+	tmp, err := makeTempDir(fSys)
+	if err != nil {
+		return err
+	}
+	defer fSys.RemoveAll(tmp)
+	resource := theArgs.kustomizationPath
+	if fSys.IsDir(resource) {
+		// The temporary kustomization can only refer to the
+		// kustomization being built by a relative path.
+		abs, _, err := fSys.CleanedAbs(resource)
+		if err != nil {
+			return err
+		}
+		resource, err = filepath.Rel(tmp, abs.String())
+		if err != nil {
+			return err
+		}
+	}
+	if err = fSys.MkdirAll(theFlags.outputPath); err != nil {
+		return err
+	}
+	opts := HonorKustomizeFlags(krusty.MakeDefaultOptions())
+	// Only the kustomization written per cluster changes, and
+	// the one built for each cluster is its base.
+	opts.BaseCache = krusty.NewBaseCache()
+	k := krusty.MakeKustomizer(opts)
+	var failed []string
+	for _, c := range mx.Clusters {
+		err = writeMatrixKustomization(fSys, tmp, resource, mx, c)
+		if err == nil {
+			m, err := k.Run(fSys, tmp)
+			if err == nil {
+				err = writeNamedOutput(fSys, m, c.Name)
+			}
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", c.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to build %d of %d clusters:\n%s",
+			len(failed), len(mx.Clusters), strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+func writeMatrixKustomization(fSys filesys.FileSystem,
+	dir, resource string, mx *matrix, c matrixCluster) error {
+	values, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": matrixValuesName,
+			"annotations": map[string]string{
+				konfig.IgnoredByKustomizeAnnotation: "true",
+			},
+		},
+		"data": c.Values,
+	})
+	if err != nil {
+		return err
+	}
+	if err = fSys.WriteFile(filepath.Join(dir, "values.yaml"), values); err != nil {
+		return err
+	}
+	kust, err := yaml.Marshal(&types.Kustomization{
+		TypeMeta: types.TypeMeta{
+			APIVersion: types.KustomizationVersion,
+			Kind:       types.KustomizationKind,
+		},
+		Resources:    []string{resource, "values.yaml"},
+		Replacements: mx.Replacements,
+	})
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(
+		filepath.Join(dir, konfig.DefaultKustomizationFileName()), kust)
+}
+
+func makeTempDir(fSys filesys.FileSystem) (string, error) {
+	for {
+		dir := filepath.Join(os.TempDir(),
+			fmt.Sprintf("kustomize-matrix-%d", rand.Int63()))
+		if fSys.Exists(dir) {
+			continue
+		}
+		return dir, fSys.MkdirAll(dir)
+	}
+}
@@ -27,7 +27,8 @@ func AddFlagReorderOutput(set *pflag.FlagSet) {
 		"Reorder the resources just before output. "+
 			"Use '"+legacy.String()+"' to apply a legacy reordering "+
 			"(Namespaces first, Webhooks last, etc). "+
-			"Use '"+none.String()+"' to suppress a final reordering.")
+			"Use '"+none.String()+"' to suppress a final reordering. "+
+			"Ignored if the kustomization has sortOptions.")
 }
 
 func validateFlagReorderOutput() error {
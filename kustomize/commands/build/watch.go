@@ -0,0 +1,116 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// watch builds the kustomization whenever the files below it,
+// or below its local bases, change, until stop is closed.
+// Changes are found by polling, which works the same on all
+// platforms and file systems, including in-memory ones.
+// Build errors are reported to errWriter, and don't end
+// the watch.
+func watch(fSys filesys.FileSystem, writer, errWriter io.Writer,
+	stop <-chan struct{}) error {
+	// Plugin servers stay warm across the builds.
+	release := krusty.KeepPluginServers()
+	defer release()
+	last := ""
+	for {
+		current, err := fingerprint(fSys, theArgs.kustomizationPath)
+		if err != nil {
+			return err
+		}
+		if current != last {
+			last = current
+			if err = runBuild(fSys, writer); err != nil {
+				fmt.Fprintf(errWriter, "Error: %v\n", err)
+			}
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(theFlags.watch.interval):
+		}
+	}
+}
+
+// fingerprint returns a hash of the content of all files
+// below the kustomization in dir and its local bases.
+func fingerprint(fSys filesys.FileSystem, dir string) (string, error) {
+	dirs := make(map[string]bool)
+	collectDirs(fSys, dir, dirs)
+	var sorted []string
+	for d := range dirs {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+	h := sha256.New()
+	for _, d := range sorted {
+		if err := hashDir(fSys, d, h); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func hashDir(fSys filesys.FileSystem, dir string, h hash.Hash) error {
+	return fSys.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			// Removed while walking; the next poll sees it.
+			return nil
+		}
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(h, "%s\n%d\n", path, len(content))
+		h.Write(content)
+		return nil
+	})
+}
+
+// collectDirs adds dir, and the directories of the local
+// bases and components of its kustomization, to dirs.
+// Kustomizations that can't be read are left to the build
+// to report.
+func collectDirs(fSys filesys.FileSystem, dir string, dirs map[string]bool) {
+	dir = filepath.Clean(dir)
+	if dirs[dir] || !fSys.IsDir(dir) {
+		return
+	}
+	dirs[dir] = true
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		content, err := fSys.ReadFile(filepath.Join(dir, n))
+		if err != nil {
+			continue
+		}
+		var k types.Kustomization
+		if err = k.Unmarshal(content); err != nil {
+			return
+		}
+		k.FixKustomizationPostUnmarshalling()
+		for _, entry := range append(k.Resources, k.Components...) {
+			collectDirs(fSys, filepath.Join(dir, entry), dirs)
+		}
+		return
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// chanWriter sends everything written to it on a channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func nextOutput(t *testing.T, c chanWriter) string {
+	t.Helper()
+	select {
+	case s := <-c:
+		return s
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a build")
+	}
+	return ""
+}
+
+// writeAtomically writes a file the watcher never sees half written.
+func writeAtomically(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	tmp := path + ".tmp"
+	require.NoError(t, ioutil.WriteFile(tmp, []byte(content), 0644))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestWatch(t *testing.T) {
+	// The in-memory file system isn't safe for concurrent use.
+	fSys := filesys.MakeFsOnDisk()
+	dir := t.TempDir()
+	writeAtomically(t, filepath.Join(dir, "overlay", "kustomization.yaml"), `
+namePrefix: p-
+resources:
+- ../base
+`)
+	base := filepath.Join(dir, "base", "kustomization.yaml")
+	baseWithValue := func(v string) string {
+		return `
+configMapGenerator:
+- name: cm
+  options:
+    disableNameSuffixHash: true
+  literals:
+  - a=` + v + `
+`
+	}
+	writeAtomically(t, base, baseWithValue("1"))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), nil)
+	require.NoError(t, cmd.Flags().Set("watch-interval", "5ms"))
+	require.NoError(t, Validate([]string{filepath.Join(dir, "overlay")}))
+
+	out := make(chanWriter, 100)
+	errs := make(chanWriter, 100)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- watch(fSys, out, errs, stop)
+	}()
+	assert.Contains(t, nextOutput(t, out), "a: \"1\"")
+
+	// A change in a base triggers a build.
+	writeAtomically(t, base, baseWithValue("2"))
+	assert.Contains(t, nextOutput(t, out), "a: \"2\"")
+
+	// Errors are reported, and watching goes on.
+	writeAtomically(t, base, `
+resources:
+- missing.yaml
+`)
+	assert.Contains(t, nextOutput(t, errs), "missing.yaml")
+	writeAtomically(t, base, baseWithValue("3"))
+	assert.Contains(t, nextOutput(t, out), "a: \"3\"")
+
+	close(stop)
+	assert.NoError(t, <-done)
+}
+
+func TestFingerprint(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("app/kustomization.yaml", []byte(`
+resources:
+- ../base
+`)))
+	require.NoError(t, fSys.WriteFile("base/kustomization.yaml", []byte("")))
+	require.NoError(t, fSys.WriteFile("unrelated/file.yaml", []byte("a")))
+	before, err := fingerprint(fSys, "app")
+	require.NoError(t, err)
+
+	require.NoError(t, fSys.WriteFile("unrelated/file.yaml", []byte("b")))
+	same, err := fingerprint(fSys, "app")
+	require.NoError(t, err)
+	assert.Equal(t, before, same)
+
+	require.NoError(t, fSys.WriteFile("base/new.yaml", []byte("")))
+	after, err := fingerprint(fSys, "app")
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+}
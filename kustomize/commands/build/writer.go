@@ -4,30 +4,52 @@
 package build
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 	"sigs.k8s.io/yaml"
 )
 
 type Writer struct {
-	fSys filesys.FileSystem
+	fSys   filesys.FileSystem
+	format string
+	// If not empty, pattern gives the path of each
+	// resource's file; see AddFlagOutputPattern.
+	pattern string
+	// If not empty, splitBy names the subdirectory of each
+	// resource's file; see AddFlagSplitBy.
+	splitBy string
+	// origins are the origins of the resources, by id, for
+	// splitBy origin.
+	origins map[resid.ResId]*resource.Origin
+	// When true, files are written as helm templates; see
+	// AddFlagsAsHelmChart.
+	escapeTemplates bool
 }
 
 func MakeWriter(fSys filesys.FileSystem) *Writer {
 	return &Writer{
-		fSys: fSys,
+		fSys:   fSys,
+		format: outputFormatYaml,
 	}
 }
 
 func (w Writer) WriteIndividualFiles(dirPath string, m resmap.ResMap) error {
+	if w.pattern != "" {
+		return w.writeByPattern(dirPath, m)
+	}
+	if w.splitBy != "" {
+		return w.writeSplit(dirPath, m)
+	}
 	byNamespace := m.GroupedByCurrentNamespace()
 	for namespace, resList := range byNamespace {
 		for _, res := range resList {
-			fName := fileName(res)
+			fName := fileName(res, w.format)
 			if len(byNamespace) > 1 {
 				fName = strings.ToLower(namespace) + "_" + fName
 			}
@@ -37,27 +59,124 @@ func (w Writer) WriteIndividualFiles(dirPath string, m resmap.ResMap) error {
 		}
 	}
 	for _, res := range m.ClusterScoped() {
-		err := w.write(dirPath, fileName(res), res)
+		err := w.write(dirPath, fileName(res, w.format), res)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w Writer) writeByPattern(dirPath string, m resmap.ResMap) error {
+	written := make(map[string]resid.ResId)
+	for _, res := range m.Resources() {
+		fName, err := w.patternFileName(res)
 		if err != nil {
 			return err
 		}
+		if id, ok := written[fName]; ok {
+			return fmt.Errorf(
+				"--%s %s gives resources %s and %s the same file %s",
+				flagOutputPatternName, w.pattern, id, res.CurId(), fName)
+		}
+		written[fName] = res.CurId()
+		if dir := filepath.Dir(fName); dir != "." {
+			if err = w.fSys.MkdirAll(filepath.Join(dirPath, dir)); err != nil {
+				return err
+			}
+		}
+		if err = w.write(dirPath, fName, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSplit writes each resource to a subdirectory, named
+// as splitBy says, with the default file name, prefixed with
+// the namespace unless it's the subdirectory or all resources
+// have the same.
+func (w Writer) writeSplit(dirPath string, m resmap.ResMap) error {
+	prefixNamespace := w.splitBy != splitByNamespace &&
+		len(m.GroupedByCurrentNamespace()) > 1
+	written := make(map[string]resid.ResId)
+	for _, res := range m.Resources() {
+		fName := fileName(res, w.format)
+		if ns := res.GetNamespace(); prefixNamespace && ns != "" {
+			fName = strings.ToLower(ns) + "_" + fName
+		}
+		dir := w.splitDir(res)
+		fName = filepath.Join(dir, fName)
+		if id, ok := written[fName]; ok {
+			return fmt.Errorf(
+				"--%s %s gives resources %s and %s the same file %s",
+				flagSplitByName, w.splitBy, id, res.CurId(), fName)
+		}
+		written[fName] = res.CurId()
+		if dir != "" {
+			if err := w.fSys.MkdirAll(filepath.Join(dirPath, dir)); err != nil {
+				return err
+			}
+		}
+		if err := w.write(dirPath, fName, res); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// patternFileName returns the path of the resource's file,
+// relative to the output directory.
+func (w Writer) patternFileName(res *resource.Resource) (string, error) {
+	gvk := res.GetGvk()
+	r := strings.NewReplacer(
+		"{namespace}", strings.ToLower(res.GetNamespace()),
+		"{group}", strings.ToLower(gvk.Group),
+		"{version}", strings.ToLower(gvk.Version),
+		"{kind}", strings.ToLower(gvk.Kind),
+		"{name}", strings.ToLower(res.GetName()),
+		"{ext}", strings.TrimPrefix(fileExtension(w.format), "."),
+	)
+	// Empty placeholders, like the namespace of a cluster
+	// scoped resource, drop out of the path.
+	var parts []string
+	for _, p := range strings.Split(r.Replace(w.pattern), "/") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	fName := filepath.Clean(filepath.Join(parts...))
+	if strings.HasPrefix(w.pattern, "/") || fName == "." || fName == ".." ||
+		strings.HasPrefix(fName, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf(
+			"--%s %s puts %s outside of the output directory",
+			flagOutputPatternName, w.pattern, res.CurId())
+	}
+	return fName, nil
+}
+
 func (w Writer) write(path, fName string, res *resource.Resource) error {
-	m, err := res.Map()
-	if err != nil {
-		return err
+	var content []byte
+	var err error
+	if w.format == outputFormatYaml && !yamlStyled() && !theFlags.sourceComments {
+		var m map[string]interface{}
+		if m, err = res.Map(); err != nil {
+			return err
+		}
+		content, err = yaml.Marshal(m)
+	} else {
+		content, err = encodeResource(res, w.format)
 	}
-	yml, err := yaml.Marshal(m)
 	if err != nil {
 		return err
 	}
-	return w.fSys.WriteFile(filepath.Join(path, fName), yml)
+	if w.escapeTemplates {
+		content = escapeTemplate(content)
+	}
+	return w.fSys.WriteFile(filepath.Join(path, fName), content)
 }
 
-func fileName(res *resource.Resource) string {
+func fileName(res *resource.Resource, format string) string {
 	return strings.ToLower(res.GetGvk().StringWoEmptyField()) +
-		"_" + strings.ToLower(res.GetName()) + ".yaml"
+		"_" + strings.ToLower(res.GetName()) + fileExtension(format)
 }
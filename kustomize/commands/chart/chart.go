@@ -0,0 +1,459 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package chart
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// valuesFile holds the default values of a chart.
+	valuesFile = "values.yaml"
+	// enabledValue toggles the resources that only some
+	// overlays hold.
+	enabledValue = "enabled"
+)
+
+var (
+	// helmChartName matches the names helm recommends for charts.
+	helmChartName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// semVer matches the semantic versions helm requires of charts.
+	semVer = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+		`(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+	// words matches the words of the names of values.
+	words = regexp.MustCompile(`[A-Za-z0-9]+`)
+)
+
+type chartOptions struct {
+	overlays   []string
+	outputPath string
+	name       string
+	version    string
+}
+
+// NewCmdChart makes a new chart command.
+func NewCmdChart(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o chartOptions
+	c := &cobra.Command{
+		Use:   "chart OVERLAY OVERLAY...",
+		Short: "Scaffold a helm chart from the overlays of a base",
+		Long: `Scaffold a helm chart from the overlays of a base.
+
+The overlays are built, and their resources are paired up by
+their ids in the base, i.e. before prefixes, suffixes and
+namespaces are set.  Each resource is written as a template,
+from the first overlay holding it, in which the fields that
+differ between the overlays are set from values, named after
+the resource and the path of the field:
+
+  replicas: {{ .Values.deploymentWeb.specReplicas | toJson }}
+
+The values of the first overlay are written to values.yaml,
+and those of each overlay to values-OVERLAY.yaml.  Resources
+that only some overlays hold are rendered if their 'enabled'
+value is true.
+
+The chart is a starting point, to review: values aren't
+merged, e.g. when several resources have the same image.
+`,
+		Example: `
+	kustomize chart overlays/staging overlays/production --output chart
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVarP(&o.outputPath, "output", "o", "chart",
+		"the directory to write the chart to")
+	c.Flags().StringVar(&o.name, "chart-name", "",
+		"name of the chart; defaults to the name of its directory")
+	c.Flags().StringVar(&o.version, "chart-version", "0.1.0",
+		"semantic version of the chart")
+	build.AddFlagLoadRestrictor(c.Flags())
+	build.AddFlagEnablePlugins(c.Flags())
+	build.AddFlagEnableHelm(c.Flags())
+	build.AddFlagEnableJsonnet(c.Flags())
+	build.AddFlagEnableDataSources(c.Flags())
+	build.AddFlagEnableOpa(c.Flags())
+	build.AddFlagEnableSops(c.Flags())
+	build.AddFlagDiscovery(c.Flags())
+	build.AddFlagSchemaFromCluster(c.Flags())
+	return c
+}
+
+// Validate validates chart command args.
+func (o *chartOptions) Validate(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("specify at least two overlays")
+	}
+	o.overlays = args
+	seen := make(map[string]bool)
+	for _, overlay := range args {
+		name := overlayName(overlay)
+		if seen[name] {
+			return fmt.Errorf(
+				"overlays %s have the same name, which names their values files",
+				name)
+		}
+		seen[name] = true
+	}
+	if o.name == "" {
+		o.name = filepath.Base(filepath.Clean(o.outputPath))
+	}
+	if !helmChartName.MatchString(o.name) {
+		return fmt.Errorf(
+			"illegal chart name %q; expected lower case letters, digits and dashes",
+			o.name)
+	}
+	if !semVer.MatchString(o.version) {
+		return fmt.Errorf(
+			"illegal flag value --chart-version %q; expected a semantic version, e.g. 1.2.3",
+			o.version)
+	}
+	return nil
+}
+
+// overlayName names the values file of an overlay.
+func overlayName(path string) string {
+	return filepath.Base(filepath.Clean(path))
+}
+
+// chartResource is a resource of the overlays.
+type chartResource struct {
+	// key names the values of the resource.
+	key string
+	// file is the name of the template of the resource.
+	file string
+	// objects holds the resource in each overlay, or nil.
+	objects []interface{}
+}
+
+// Run builds the overlays, and writes the chart.
+func (o *chartOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	resources, err := o.build(fSys)
+	if err != nil {
+		return err
+	}
+	values := make([]map[string]interface{}, len(o.overlays))
+	for i := range values {
+		values[i] = make(map[string]interface{})
+	}
+	templates := make(map[string][]byte, len(resources))
+	for _, r := range resources {
+		templates[r.file], err = r.template(values)
+		if err != nil {
+			return err
+		}
+	}
+
+	dir := o.outputPath
+	if err = fSys.MkdirAll(filepath.Join(dir, "templates")); err != nil {
+		return err
+	}
+	chart, err := yaml.Marshal(struct {
+		APIVersion string `json:"apiVersion"`
+		Name       string `json:"name"`
+		Version    string `json:"version"`
+	}{APIVersion: "v2", Name: o.name, Version: o.version})
+	if err != nil {
+		return err
+	}
+	if err = fSys.WriteFile(filepath.Join(dir, "Chart.yaml"), chart); err != nil {
+		return err
+	}
+	for file, content := range templates {
+		err = fSys.WriteFile(filepath.Join(dir, "templates", file), content)
+		if err != nil {
+			return err
+		}
+	}
+	for i, overlay := range o.overlays {
+		content, err := yaml.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		header := fmt.Sprintf("# Values of %s.\n", overlay)
+		content = append([]byte(header), content...)
+		files := []string{"values-" + overlayName(overlay) + ".yaml"}
+		if i == 0 {
+			files = append(files, valuesFile)
+		}
+		for _, f := range files {
+			if err = fSys.WriteFile(filepath.Join(dir, f), content); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(w, "Wrote chart %s, with %d templates, to %s\n",
+		o.name, len(templates), dir)
+	return nil
+}
+
+// build returns the resources of the overlays, in the order
+// the overlays hold them.
+func (o *chartOptions) build(fSys filesys.FileSystem) ([]*chartResource, error) {
+	var resources []*chartResource
+	byId := make(map[string]*chartResource)
+	keys := make(map[string]bool)
+	for i, overlay := range o.overlays {
+		opts := build.HonorKustomizeFlags(krusty.MakeDefaultOptions())
+		// The build annotations hold the ids in the base.
+		opts.KeepBuildAnnotations = true
+		m, err := krusty.MakeKustomizer(opts).Run(fSys, overlay)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range m.Resources() {
+			id := r.OrgId()
+			cr, ok := byId[id.String()]
+			if !ok {
+				key := valueName([]string{id.Kind, id.Name})
+				for n := 2; keys[key]; n++ {
+					key = valueName([]string{id.Kind, id.Name, fmt.Sprint(n)})
+				}
+				keys[key] = true
+				cr = &chartResource{
+					key:     key,
+					file:    strings.ToLower(id.Kind+"_"+id.Name) + ".yaml",
+					objects: make([]interface{}, len(o.overlays)),
+				}
+				byId[id.String()] = cr
+				resources = append(resources, cr)
+			}
+			r.RemoveBuildAnnotations()
+			content, err := r.AsYAML()
+			if err != nil {
+				return nil, err
+			}
+			if err = yaml.Unmarshal(content, &cr.objects[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	files := make(map[string]bool)
+	for _, r := range resources {
+		if files[r.file] {
+			r.file = r.key + ".yaml"
+		}
+		files[r.file] = true
+	}
+	return resources, nil
+}
+
+// template returns the template of the resource, and adds
+// its values to those of each overlay.
+func (r *chartResource) template(values []map[string]interface{}) ([]byte, error) {
+	var base interface{}
+	var present []interface{}
+	for _, obj := range r.objects {
+		if obj != nil {
+			if base == nil {
+				base = obj
+			}
+			present = append(present, obj)
+		}
+	}
+	var paths [][]interface{}
+	diffPaths(present, nil, &paths)
+
+	resourceValues := make([]map[string]interface{}, len(values))
+	for i := range values {
+		resourceValues[i] = make(map[string]interface{})
+		values[i][r.key] = resourceValues[i]
+	}
+	partial := len(present) < len(r.objects)
+	if partial {
+		for i, obj := range r.objects {
+			resourceValues[i][enabledValue] = obj != nil
+		}
+	}
+
+	names := map[string]bool{enabledValue: true}
+	oldNew := make([]string, 0, 2*len(paths))
+	for i, path := range paths {
+		segments := make([]string, len(path))
+		for j, s := range path {
+			segments[j] = fmt.Sprint(s)
+		}
+		name := valueName(segments)
+		for n := 2; names[name]; n++ {
+			name = valueName(append(segments, fmt.Sprint(n)))
+		}
+		names[name] = true
+		for j, obj := range r.objects {
+			if obj != nil {
+				resourceValues[j][name] = valueAt(obj, path)
+			}
+		}
+		oldNew = append(oldNew, fmt.Sprintf("KUSTOMIZE_VALUE_%d_", i),
+			fmt.Sprintf("{{ .Values.%s.%s | toJson }}", r.key, name))
+	}
+	// The values are read before the base holds placeholders.
+	for i, path := range paths {
+		base = setValueAt(base, path, oldNew[2*i])
+	}
+
+	content, err := yaml.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	// Template actions in the resources are rendered as is.
+	template := strings.ReplaceAll(string(content), "{{", `{{"{{"}}`)
+	template = strings.NewReplacer(oldNew...).Replace(template)
+	if partial {
+		template = fmt.Sprintf("{{- if .Values.%s.%s }}\n%s{{- end }}\n",
+			r.key, enabledValue, template)
+	}
+	return []byte(template), nil
+}
+
+// diffPaths appends to paths those of the fields whose values,
+// below path, differ between the objects.  Lists are compared
+// item by item if they're as long in all objects.
+func diffPaths(objects []interface{}, path []interface{}, paths *[][]interface{}) {
+	same := true
+	for _, obj := range objects[1:] {
+		if !reflect.DeepEqual(obj, objects[0]) {
+			same = false
+			break
+		}
+	}
+	if same {
+		return
+	}
+	switch first := objects[0].(type) {
+	case map[string]interface{}:
+		keys := make(map[string]bool)
+		for _, obj := range objects {
+			m, ok := obj.(map[string]interface{})
+			if !ok {
+				*paths = append(*paths, path)
+				return
+			}
+			for k := range m {
+				keys[k] = true
+			}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			fields := make([]interface{}, len(objects))
+			for i, obj := range objects {
+				fields[i] = obj.(map[string]interface{})[k]
+			}
+			diffPaths(fields, appendPath(path, k), paths)
+		}
+		return
+	case []interface{}:
+		for _, obj := range objects {
+			l, ok := obj.([]interface{})
+			if !ok || len(l) != len(first) {
+				*paths = append(*paths, path)
+				return
+			}
+		}
+		for i := range first {
+			items := make([]interface{}, len(objects))
+			for j, obj := range objects {
+				items[j] = obj.([]interface{})[i]
+			}
+			diffPaths(items, appendPath(path, i), paths)
+		}
+		return
+	}
+	*paths = append(*paths, path)
+}
+
+// appendPath returns a copy of the path with the segment,
+// a field name or a list index, appended.
+func appendPath(path []interface{}, segment interface{}) []interface{} {
+	result := make([]interface{}, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, segment)
+}
+
+// valueAt returns the value at the path in the object, or nil.
+func valueAt(obj interface{}, path []interface{}) interface{} {
+	for _, s := range path {
+		switch x := obj.(type) {
+		case map[string]interface{}:
+			obj = x[s.(string)]
+		case []interface{}:
+			obj = x[s.(int)]
+		default:
+			return nil
+		}
+	}
+	return obj
+}
+
+// setValueAt sets the value at the path in the object, adding
+// the fields it lacks, and returns the object.
+func setValueAt(obj interface{}, path []interface{}, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	switch s := path[0].(type) {
+	case string:
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+		}
+		m[s] = setValueAt(m[s], path[1:], value)
+		return m
+	default:
+		l := obj.([]interface{})
+		l[s.(int)] = setValueAt(l[s.(int)], path[1:], value)
+		return l
+	}
+}
+
+// valueName returns the words of the segments in camel case,
+// e.g. deploymentWeb for Deployment and web.
+func valueName(segments []string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		for _, w := range words.FindAllString(s, -1) {
+			if b.Len() == 0 {
+				if strings.ToUpper(w) == w {
+					w = strings.ToLower(w)
+				} else {
+					r := []rune(w)
+					r[0] = unicode.ToLower(r[0])
+					w = string(r)
+				}
+			} else {
+				r := []rune(w)
+				r[0] = unicode.ToUpper(r[0])
+				w = string(r)
+			}
+			b.WriteString(w)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "v" + name
+	}
+	return name
+}
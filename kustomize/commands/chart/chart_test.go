@@ -0,0 +1,147 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package chart_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/chart"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeOverlays(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"base/kustomization.yaml": `
+resources:
+- deployment.yaml
+`,
+		"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    description: '{{ not a template }}'
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+`,
+		"staging/kustomization.yaml": `
+namePrefix: staging-
+resources:
+- ../base
+`,
+		"production/kustomization.yaml": `
+namePrefix: prod-
+resources:
+- ../base
+- monitor.yaml
+images:
+- name: web
+  newTag: "1.1"
+replicas:
+- name: web
+  count: 3
+`,
+		"production/monitor.yaml": `
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return fSys
+}
+
+func TestChart(t *testing.T) {
+	fSys := writeOverlays(t)
+	var out bytes.Buffer
+	cmd := chart.NewCmdChart(fSys, &out)
+	cmd.SetArgs([]string{"staging", "production", "--output", "web"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "Wrote chart web, with 2 templates, to web\n", out.String())
+
+	for path, expected := range map[string]string{
+		"web/Chart.yaml": `apiVersion: v2
+name: web
+version: 0.1.0
+`,
+		"web/values.yaml": `# Values of staging.
+deploymentWeb:
+  metadataName: staging-web
+  specReplicas: 1
+  specTemplateSpecContainers0Image: web:1.0
+serviceMonitorWeb:
+  enabled: false
+`,
+		"web/values-production.yaml": `# Values of production.
+deploymentWeb:
+  metadataName: prod-web
+  specReplicas: 3
+  specTemplateSpecContainers0Image: web:1.1
+serviceMonitorWeb:
+  enabled: true
+`,
+		"web/templates/deployment_web.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    description: '{{"{{"}} not a template }}'
+  name: {{ .Values.deploymentWeb.metadataName | toJson }}
+spec:
+  replicas: {{ .Values.deploymentWeb.specReplicas | toJson }}
+  template:
+    spec:
+      containers:
+      - image: {{ .Values.deploymentWeb.specTemplateSpecContainers0Image | toJson }}
+        name: web
+`,
+		"web/templates/servicemonitor_web.yaml": `{{- if .Values.serviceMonitorWeb.enabled }}
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: prod-web
+{{- end }}
+`,
+	} {
+		content, err := fSys.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), path)
+	}
+	content, err := fSys.ReadFile("web/values-staging.yaml")
+	require.NoError(t, err)
+	values, err := fSys.ReadFile("web/values.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(values), string(content))
+}
+
+func TestChartErrors(t *testing.T) {
+	fSys := writeOverlays(t)
+	for args, expected := range map[string][]string{
+		"specify at least two overlays": {"staging"},
+		"overlays staging have the same name, which names their values files": {
+			"staging", "other/staging"},
+		`illegal chart name "Web"; expected lower case letters, digits and dashes`: {
+			"staging", "production", "--output", "Web"},
+		`illegal flag value --chart-version "1.0"; expected a semantic version, e.g. 1.2.3`: {
+			"staging", "production", "--chart-version", "1.0"},
+	} {
+		cmd := chart.NewCmdChart(fSys, &bytes.Buffer{})
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(expected)
+		assert.EqualError(t, cmd.Execute(), args)
+	}
+}
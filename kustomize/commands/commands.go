@@ -12,12 +12,25 @@ import (
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/cmd/config/completion"
 	"sigs.k8s.io/kustomize/cmd/config/configcobra"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/chart"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/compare"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/create"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/diff"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/explain"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/filter"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/format"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/graph"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/importer"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/lint"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/localize"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/openapi"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/plugin"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/tree"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/version"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -41,21 +54,38 @@ func NewDefaultCommand() *cobra.Command {
 		Long: `
 Manages declarative configuration of Kubernetes.
 See https://sigs.k8s.io/kustomize
-`,
+` + exitCodesHelp,
 	}
+	c.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return types.WithErrorClass(err, types.ErrorClassConfig)
+	})
 
 	pvd := provider.NewDefaultDepProvider()
 	c.AddCommand(
 		completion.NewCommand(),
 		makeBuildCommand(fSys, stdOut),
+		diff.NewCmdDiff(fSys, stdOut),
+		compare.NewCmdCompare(fSys, stdOut),
+		chart.NewCmdChart(fSys, stdOut),
+		filter.NewCmdFilter(fSys, os.Stdin, stdOut),
+		lint.NewCmdLint(fSys, stdOut),
+		graph.NewCmdGraph(fSys, stdOut),
+		localize.NewCmdLocalize(fSys, stdOut),
+		format.NewCmdFormat(fSys, stdOut),
+		tree.NewCmdTree(fSys, stdOut),
+		explain.NewCmdExplain(stdOut),
 		edit.NewCmdEdit(
 			fSys, pvd.GetFieldValidator(), pvd.GetResourceFactory(), stdOut),
 		create.NewCmdCreate(fSys, pvd.GetResourceFactory()),
+		importer.NewCmdImport(fSys, stdOut),
 		version.NewCmdVersion(stdOut),
 		openapi.NewCmdOpenAPI(stdOut),
+		plugin.NewCmdPlugin(fSys, stdOut),
 	)
 	configcobra.AddCommands(c, konfig.ProgramName)
 
+	addLogFlags(c.PersistentFlags())
+
 	c.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
 	// Workaround for this issue:
@@ -0,0 +1,254 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package compare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrDifferencesFound is returned when the build
+// outputs of the two kustomizations differ.
+var ErrDifferencesFound = errors.New(
+	"the build outputs of the kustomizations differ")
+
+// hashSuffix matches the content hash kustomize
+// appends to the names of generated resources.
+var hashSuffix = regexp.MustCompile(`-[245-9bcdfghkmt]{10}$`)
+
+type compareOptions struct {
+	pathA        string
+	pathB        string
+	ignoreHashes bool
+	ignoreOrder  bool
+}
+
+// NewCmdCompare makes a new compare command.
+func NewCmdCompare(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o compareOptions
+	c := &cobra.Command{
+		Use:   "compare DIR_A DIR_B",
+		Short: "Compare the build outputs of two kustomizations",
+		Long: `Compare the build outputs of two kustomizations.
+
+Both kustomizations are built, and resources are paired up by
+apiVersion, kind, namespace and name.  For each resource that
+only one output holds, or that differs between the outputs,
+a line is printed, followed by a unified diff of the resource
+when it differs:
+
+  - v1 ConfigMap staging/only-in-a
+  + v1 ConfigMap prod/only-in-b
+  ~ apps/v1 Deployment prod/in-both
+
+The command fails if the outputs differ.
+`,
+		Example: `
+	kustomize compare overlays/staging overlays/production
+	kustomize compare overlays/staging overlays/production --ignore-hashes
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().BoolVar(&o.ignoreHashes, "ignore-hashes", false,
+		"ignore the content hashes appended to the names of generated "+
+			"ConfigMaps and Secrets, and to references to them")
+	c.Flags().BoolVar(&o.ignoreOrder, "ignore-order", false,
+		"ignore the order of items in lists, e.g. of containers or env vars")
+	build.AddFlagLoadRestrictor(c.Flags())
+	build.AddFlagEnablePlugins(c.Flags())
+	build.AddFlagEnableHelm(c.Flags())
+	build.AddFlagEnableJsonnet(c.Flags())
+	build.AddFlagEnableDataSources(c.Flags())
+	build.AddFlagEnableOpa(c.Flags())
+	build.AddFlagEnableSops(c.Flags())
+	build.AddFlagDiscovery(c.Flags())
+	build.AddFlagSchemaFromCluster(c.Flags())
+	return c
+}
+
+// Validate validates compare command args.
+func (o *compareOptions) Validate(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("specify two kustomization directories")
+	}
+	o.pathA, o.pathB = args[0], args[1]
+	return nil
+}
+
+// Run builds both kustomizations and prints their differences.
+func (o *compareOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	a, err := o.build(fSys, o.pathA)
+	if err != nil {
+		return err
+	}
+	b, err := o.build(fSys, o.pathB)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	differ := false
+	for _, k := range sorted {
+		ya, inA := a[k]
+		yb, inB := b[k]
+		switch {
+		case !inB:
+			fmt.Fprintf(w, "- %s\n", k)
+		case !inA:
+			fmt.Fprintf(w, "+ %s\n", k)
+		case ya != yb:
+			fmt.Fprintf(w, "~ %s\n", k)
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(ya),
+				B:        difflib.SplitLines(yb),
+				FromFile: o.pathA,
+				ToFile:   o.pathB,
+				Context:  3,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(w, diff)
+		default:
+			continue
+		}
+		differ = true
+	}
+	if differ {
+		return ErrDifferencesFound
+	}
+	return nil
+}
+
+// build returns the resources of a kustomization as YAML,
+// keyed by a description of their ids.
+func (o *compareOptions) build(
+	fSys filesys.FileSystem, path string) (map[string]string, error) {
+	k := krusty.MakeKustomizer(
+		build.HonorKustomizeFlags(krusty.MakeDefaultOptions()))
+	m, err := k.Run(fSys, path)
+	if err != nil {
+		return nil, err
+	}
+	var replacer *strings.Replacer
+	if o.ignoreHashes {
+		replacer = hashReplacer(m)
+	}
+	result := make(map[string]string)
+	for _, r := range m.Resources() {
+		content, err := o.normalize(r, replacer)
+		if err != nil {
+			return nil, err
+		}
+		key := describe(r)
+		if replacer != nil {
+			key = replacer.Replace(key)
+		}
+		result[key] = content
+	}
+	return result, nil
+}
+
+// hashReplacer strips the content hashes from the
+// names of generated resources.
+func hashReplacer(m resmap.ResMap) *strings.Replacer {
+	var oldNew []string
+	for _, r := range m.Resources() {
+		kind := r.GetKind()
+		if kind != "ConfigMap" && kind != "Secret" {
+			continue
+		}
+		name := r.GetName()
+		if hashSuffix.MatchString(name) {
+			oldNew = append(oldNew, name, hashSuffix.ReplaceAllString(name, ""))
+		}
+	}
+	return strings.NewReplacer(oldNew...)
+}
+
+func (o *compareOptions) normalize(
+	r *resource.Resource, replacer *strings.Replacer) (string, error) {
+	content, err := r.AsYAML()
+	if err != nil {
+		return "", err
+	}
+	if replacer != nil {
+		content = []byte(replacer.Replace(string(content)))
+	}
+	if !o.ignoreOrder {
+		return string(content), nil
+	}
+	var obj interface{}
+	if err = yaml.Unmarshal(content, &obj); err != nil {
+		return "", err
+	}
+	if content, err = yaml.Marshal(sortLists(obj)); err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// sortLists sorts all lists in the object by the
+// JSON form of their items.
+func sortLists(obj interface{}) interface{} {
+	switch x := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			x[k] = sortLists(v)
+		}
+	case []interface{}:
+		for i := range x {
+			x[i] = sortLists(x[i])
+		}
+		sort.SliceStable(x, func(i, j int) bool {
+			return jsonOf(x[i]) < jsonOf(x[j])
+		})
+	}
+	return obj
+}
+
+func jsonOf(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// describe returns e.g. "apps/v1 Deployment prod/app".
+func describe(r *resource.Resource) string {
+	name := r.GetName()
+	if ns := r.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+	return r.GetApiVersion() + " " + r.GetKind() + " " + name
+}
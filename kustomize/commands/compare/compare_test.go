@@ -0,0 +1,137 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package compare_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/compare"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeOverlays(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"base/kustomization.yaml": `
+resources:
+- deployment.yaml
+`,
+		"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: settings
+`,
+		"staging/kustomization.yaml": `
+resources:
+- ../base
+- extra.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - env=staging
+`,
+		"staging/extra.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: extra
+`,
+		"prod/kustomization.yaml": `
+namespace: prod
+resources:
+- ../base
+configMapGenerator:
+- name: settings
+  literals:
+  - env=prod
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return fSys
+}
+
+func TestCompare(t *testing.T) {
+	fSys := writeOverlays(t)
+	var out bytes.Buffer
+	cmd := compare.NewCmdCompare(fSys, &out)
+	err := cmd.RunE(cmd, []string{"staging", "prod"})
+	assert.Equal(t, compare.ErrDifferencesFound, err)
+	assert.Equal(t, `- apps/v1 Deployment app
++ apps/v1 Deployment prod/app
+- v1 ConfigMap extra
++ v1 ConfigMap prod/settings-b7k5b48kg7
+- v1 ConfigMap settings-d29b79gdhf
+`, out.String())
+
+	require.NoError(t, fSys.WriteFile("prod/kustomization.yaml", []byte(`
+resources:
+- ../base
+- ../staging/extra.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - env=prod
+`)))
+	out.Reset()
+	cmd = compare.NewCmdCompare(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("ignore-hashes", "true"))
+	require.NoError(t, cmd.Flags().Set("load-restrictor", "LoadRestrictionsNone"))
+	err = cmd.RunE(cmd, []string{"staging", "prod"})
+	assert.Equal(t, compare.ErrDifferencesFound, err)
+	assert.Equal(t, `~ v1 ConfigMap settings
+--- staging
++++ prod
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  env: staging
++  env: prod
+ kind: ConfigMap
+ metadata:
+   name: settings
+`, out.String())
+}
+
+func TestCompareIgnoreOrder(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	for dir, order := range map[string]string{
+		"a": "[x, y]",
+		"b": "[y, x]",
+	} {
+		require.NoError(t, fSys.WriteFile(dir+"/kustomization.yaml",
+			[]byte("resources:\n- cm.yaml\n")))
+		require.NoError(t, fSys.WriteFile(dir+"/cm.yaml", []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: p
+spec:
+  containers:
+  - name: c
+    args: `+order+`
+`)))
+	}
+	var out bytes.Buffer
+	cmd := compare.NewCmdCompare(fSys, &out)
+	assert.Equal(t, compare.ErrDifferencesFound, cmd.RunE(cmd, []string{"a", "b"}))
+
+	cmd = compare.NewCmdCompare(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("ignore-order", "true"))
+	assert.NoError(t, cmd.RunE(cmd, []string{"a", "b"}))
+}
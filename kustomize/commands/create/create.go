@@ -5,8 +5,10 @@ package create
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -37,7 +39,17 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 		Use:     "create",
 		Aliases: []string{"init"},
 		Short:   "Create a new kustomization in the current directory",
-		Long:    "",
+		Long: `Create a new kustomization in the current directory.
+
+With --autodetect, the files in the current directory, and
+with --recursive the directories below it, are classified:
+files of Kubernetes objects are added as resources, as are
+directories holding a kustomization, which aren't searched
+further.  Other files, and hidden files and directories, are
+skipped.  What was added and skipped is printed, along with
+the namespace and common labels shared by all the objects
+found, as suggested values for --namespace and --labels.
+`,
 		Example: `
 	# Create a new overlay from the base '../base".
 	kustomize create --resources ../base
@@ -49,7 +61,7 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 	kustomize create --resources deployment.yaml,service.yaml,../base --namespace staging --nameprefix acme-
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCreate(opts, fSys, rf)
+			return runCreate(opts, fSys, rf, cmd.OutOrStdout())
 		},
 	}
 	c.Flags().StringVar(
@@ -95,7 +107,8 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 	return c
 }
 
-func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory) error {
+func runCreate(
+	opts createFlags, fSys filesys.FileSystem, rf *resource.Factory, w io.Writer) error {
 	var resources []string
 	var err error
 	if opts.resources != "" {
@@ -108,11 +121,12 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 		return fmt.Errorf("kustomization file already exists")
 	}
 	if opts.detectResources {
-		detected, err := detectResources(fSys, rf, opts.path, opts.detectRecursive)
+		d, err := detectResources(fSys, rf, opts.path, opts.detectRecursive)
 		if err != nil {
 			return err
 		}
-		for _, resource := range detected {
+		d.report(w, opts)
+		for _, resource := range d.paths {
 			if kustfile.StringInSlice(resource, resources) {
 				continue
 			}
@@ -149,8 +163,20 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 	return mf.Write(m)
 }
 
-func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string, recursive bool) ([]string, error) {
-	var paths []string
+// detection holds the outcome of resource auto-detection.
+type detection struct {
+	// paths are those of the resource files and
+	// kustomization directories found.
+	paths []string
+	// skipped are the files skipped, with the reason.
+	skipped []string
+	// objects are those of the resource files found.
+	objects []*resource.Resource
+}
+
+func detectResources(
+	fSys filesys.FileSystem, rf *resource.Factory, base string, recursive bool) (*detection, error) {
+	d := &detection{}
 	err := fSys.Walk(base, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -158,6 +184,12 @@ func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string,
 		if path == base {
 			return nil
 		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if info.IsDir() {
 			if !recursive {
 				return filepath.SkipDir
@@ -166,7 +198,7 @@ func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string,
 			// directory as a resource and do not decend into it.
 			for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
 				if fSys.Exists(filepath.Join(path, kfilename)) {
-					paths = append(paths, path)
+					d.paths = append(d.paths, path)
 					return filepath.SkipDir
 				}
 			}
@@ -176,11 +208,85 @@ func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string,
 		if err != nil {
 			return err
 		}
-		if _, err := rf.SliceFromBytes(fContents); err != nil {
+		objects, err := rf.SliceFromBytes(fContents)
+		if err != nil {
+			d.skipped = append(d.skipped, path+": not Kubernetes objects")
+			return nil
+		}
+		if len(objects) == 0 {
+			d.skipped = append(d.skipped, path+": no objects")
 			return nil
 		}
-		paths = append(paths, path)
+		d.paths = append(d.paths, path)
+		d.objects = append(d.objects, objects...)
 		return nil
 	})
-	return paths, err
+	return d, err
+}
+
+// report prints what was detected and skipped, and suggests
+// values of unset flags shared by all objects found.
+func (d *detection) report(w io.Writer, opts createFlags) {
+	fmt.Fprintln(w, "Detected resources:")
+	for _, p := range d.paths {
+		fmt.Fprintln(w, "  "+p)
+	}
+	if len(d.skipped) > 0 {
+		fmt.Fprintln(w, "Skipped:")
+		for _, s := range d.skipped {
+			fmt.Fprintln(w, "  "+s)
+		}
+	}
+	var suggestions []string
+	if ns := d.commonNamespace(); ns != "" && opts.namespace == "" {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"all objects found are in namespace %s; set it with --namespace %s", ns, ns))
+	}
+	if labels := d.commonLabels(); labels != "" && opts.labels == "" {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"all objects found have the labels %s; set them with --labels %s", labels, labels))
+	}
+	if len(suggestions) > 0 {
+		fmt.Fprintln(w, "Suggestions:")
+		for _, s := range suggestions {
+			fmt.Fprintln(w, "  "+s)
+		}
+	}
+}
+
+// commonNamespace returns the namespace of all objects
+// found, or "" if they don't all have the same one.
+func (d *detection) commonNamespace() string {
+	ns := ""
+	for i, o := range d.objects {
+		if i > 0 && o.GetNamespace() != ns {
+			return ""
+		}
+		ns = o.GetNamespace()
+	}
+	return ns
+}
+
+// commonLabels returns the labels all objects found have,
+// in the form taken by --labels, e.g. app:web,tier:front.
+func (d *detection) commonLabels() string {
+	var common map[string]string
+	for _, o := range d.objects {
+		labels := o.GetLabels()
+		if common == nil {
+			common = labels
+			continue
+		}
+		for k, v := range common {
+			if labels[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+	var pairs []string
+	for k, v := range common {
+		pairs = append(pairs, k+":"+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
 }
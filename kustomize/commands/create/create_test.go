@@ -4,6 +4,8 @@
 package create
 
 import (
+	"bytes"
+	"io"
 	"reflect"
 	"testing"
 
@@ -41,7 +43,7 @@ func TestCreateWithResources(t *testing.T) {
 	fSys.WriteFile("foo.yaml", []byte(""))
 	fSys.WriteFile("bar.yaml", []byte(""))
 	opts := createFlags{resources: "foo.yaml,bar.yaml"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -56,7 +58,7 @@ func TestCreateWithNamespace(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	want := "foo"
 	opts := createFlags{namespace: want}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -70,7 +72,7 @@ func TestCreateWithNamespace(t *testing.T) {
 func TestCreateWithLabels(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	opts := createFlags{labels: "foo:bar"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -84,7 +86,7 @@ func TestCreateWithLabels(t *testing.T) {
 func TestCreateWithAnnotations(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	opts := createFlags{annotations: "foo:bar"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -99,7 +101,7 @@ func TestCreateWithNamePrefix(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	want := "foo-"
 	opts := createFlags{prefix: want}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -113,7 +115,7 @@ func TestCreateWithNamePrefix(t *testing.T) {
 func TestCreateWithNameSuffix(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	opts := createFlags{suffix: "-foo"}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Errorf("unexpected cmd error: %v", err)
 	}
@@ -168,7 +170,7 @@ func TestCreateWithDetect(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	writeDetectContent(fSys)
 	opts := createFlags{path: "/", detectResources: true}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected cmd error: %v", err)
 	}
@@ -183,7 +185,7 @@ func TestCreateWithDetectRecursive(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	writeDetectContent(fSys)
 	opts := createFlags{path: "/", detectResources: true, detectRecursive: true}
-	err := runCreate(opts, fSys, factory)
+	err := runCreate(opts, fSys, factory, io.Discard)
 	if err != nil {
 		t.Fatalf("unexpected cmd error: %v", err)
 	}
@@ -193,3 +195,63 @@ func TestCreateWithDetectRecursive(t *testing.T) {
 		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
 	}
 }
+
+func TestCreateWithDetectReport(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: staging
+  labels:
+    app: web
+    tier: front
+`))
+	fSys.WriteFile("/service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: staging
+  labels:
+    app: web
+`))
+	fSys.WriteFile("/values.yaml", []byte(`
+replicas: 3
+`))
+	fSys.WriteFile("/empty.yaml", []byte(`
+# Nothing yet.
+`))
+	fSys.Mkdir("/.github")
+	fSys.WriteFile("/.github/workflow.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-resource
+`))
+	var out bytes.Buffer
+	opts := createFlags{path: "/", detectResources: true, detectRecursive: true}
+	err := runCreate(opts, fSys, factory, &out)
+	if err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	m := readKustomizationFS(t, fSys)
+	expected := []string{"/deployment.yaml", "/service.yaml"}
+	if !reflect.DeepEqual(m.Resources, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
+	}
+	expectedOut := `Detected resources:
+  /deployment.yaml
+  /service.yaml
+Skipped:
+  /empty.yaml: no objects
+  /values.yaml: not Kubernetes objects
+Suggestions:
+  all objects found are in namespace staging; set it with --namespace staging
+  all objects found have the labels app:web; set them with --labels app:web
+`
+	if out.String() != expectedOut {
+		t.Fatalf("expected output\n%s\nbut got\n%s", expectedOut, out.String())
+	}
+}
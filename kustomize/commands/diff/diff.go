@@ -0,0 +1,130 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// ErrDifferencesFound is returned when the build output
+// differs from the live objects in the cluster.
+var ErrDifferencesFound = errors.New(
+	"the build output differs from the live objects")
+
+type diffOptions struct {
+	kustomizationPath string
+	kubectlCommand    string
+	kubeconfig        string
+	context           string
+	serverSide        bool
+}
+
+// NewCmdDiff makes a new diff command.
+func NewCmdDiff(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o diffOptions
+	c := &cobra.Command{
+		Use:   "diff DIR",
+		Short: "Diff the build output of a kustomization against a live cluster",
+		Long: `Diff the build output of a kustomization against a live cluster.
+
+The kustomization is built, and the output is handed to
+'kubectl diff', which fetches the live objects from the
+cluster in the kubeconfig, and compares them to the result
+of applying the output with a server side dry run.
+
+The command fails if there are differences, so it can be used
+to check a cluster before piping the output into kubectl apply.
+If DIR is omitted, '.' is assumed.
+`,
+		Example: `
+	kustomize diff overlays/production
+	kustomize diff overlays/production --context production
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVar(&o.kubectlCommand, "kubectl-command", "kubectl",
+		"the kubectl program to run")
+	c.Flags().StringVar(&o.kubeconfig, "kubeconfig", "",
+		"path to the kubeconfig file; defaults to kubectl's default")
+	c.Flags().StringVar(&o.context, "context", "",
+		"the kubeconfig context to use")
+	c.Flags().BoolVar(&o.serverSide, "server-side", false,
+		"compare against a server side apply rather than a client side one")
+	build.AddFlagLoadRestrictor(c.Flags())
+	build.AddFlagEnablePlugins(c.Flags())
+	build.AddFlagEnableHelm(c.Flags())
+	build.AddFlagEnableJsonnet(c.Flags())
+	build.AddFlagEnableDataSources(c.Flags())
+	build.AddFlagEnableOpa(c.Flags())
+	build.AddFlagEnableSops(c.Flags())
+	build.AddFlagDiscovery(c.Flags())
+	build.AddFlagSchemaFromCluster(c.Flags())
+	return c
+}
+
+// Validate validates diff command args.
+func (o *diffOptions) Validate(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("specify one kustomization directory")
+	}
+	o.kustomizationPath = filesys.SelfDir
+	if len(args) == 1 {
+		o.kustomizationPath = args[0]
+	}
+	return nil
+}
+
+// Run builds the kustomization and diffs the output.
+func (o *diffOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	k := krusty.MakeKustomizer(
+		build.HonorKustomizeFlags(krusty.MakeDefaultOptions()))
+	m, err := k.Run(fSys, o.kustomizationPath)
+	if err != nil {
+		return err
+	}
+	yml, err := m.AsYaml()
+	if err != nil {
+		return err
+	}
+	args := []string{"diff", "-f", "-"}
+	if o.kubeconfig != "" {
+		args = append(args, "--kubeconfig", o.kubeconfig)
+	}
+	if o.context != "" {
+		args = append(args, "--context", o.context)
+	}
+	if o.serverSide {
+		args = append(args, "--server-side")
+	}
+	cmd := exec.Command(o.kubectlCommand, args...)
+	cmd.Stdin = bytes.NewReader(yml)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// kubectl diff exits with 1 if there are differences.
+		return ErrDifferencesFound
+	}
+	if err != nil {
+		return fmt.Errorf("running %s diff: %w", o.kubectlCommand, err)
+	}
+	return nil
+}
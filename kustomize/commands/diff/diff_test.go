@@ -0,0 +1,81 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/diff"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// writeFakeKubectl writes a kubectl that records its
+// arguments and stdin, and exits with the given code.
+func writeFakeKubectl(t *testing.T, exitCode string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	kubectl := filepath.Join(dir, "kubectl")
+	require.NoError(t, ioutil.WriteFile(kubectl, []byte(`#!/bin/sh
+echo "$@" > `+dir+`/args
+cat > `+dir+`/stdin
+echo "-replicas: 1"
+echo "+replicas: 2"
+exit `+exitCode+`
+`), 0700))
+	return kubectl, dir
+}
+
+func TestDiff(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script as kubectl")
+	}
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("kustomization.yaml", []byte(`
+namePrefix: p-
+resources:
+- cm.yaml
+`)))
+	require.NoError(t, fSys.WriteFile("cm.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)))
+
+	kubectl, dir := writeFakeKubectl(t, "1")
+	var out bytes.Buffer
+	cmd := diff.NewCmdDiff(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("kubectl-command", kubectl))
+	require.NoError(t, cmd.Flags().Set("context", "prod"))
+	err := cmd.RunE(cmd, []string{})
+	assert.Equal(t, diff.ErrDifferencesFound, err)
+	assert.Equal(t, "-replicas: 1\n+replicas: 2\n", out.String())
+	args, err := ioutil.ReadFile(filepath.Join(dir, "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "diff -f - --context prod\n", string(args))
+	stdin, err := ioutil.ReadFile(filepath.Join(dir, "stdin"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-cm
+`, string(stdin))
+
+	kubectl, _ = writeFakeKubectl(t, "0")
+	cmd = diff.NewCmdDiff(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("kubectl-command", kubectl))
+	assert.NoError(t, cmd.RunE(cmd, []string{}))
+
+	kubectl, _ = writeFakeKubectl(t, "2")
+	cmd = diff.NewCmdDiff(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("kubectl-command", kubectl))
+	err = cmd.RunE(cmd, []string{})
+	assert.Contains(t, err.Error(), "exit status 2")
+}
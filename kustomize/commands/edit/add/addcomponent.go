@@ -5,10 +5,14 @@ package add
 
 import (
 	"errors"
-	"log"
+	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/logging"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -25,8 +29,14 @@ func newCmdAddComponent(fSys filesys.FileSystem) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "component",
 		Short: "Add the name of a file containing a component to the kustomization file.",
+		Long: `Add the name of a file containing a component to the kustomization file.
+
+A local directory added must hold a kustomization of kind
+` + types.ComponentKind + `; remote components aren't checked.
+`,
 		Example: `
-		add component {filepath}`,
+		add component {filepath}
+		add component ../components/monitoring`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
@@ -67,10 +77,16 @@ func (o *addComponentOptions) RunAddComponent(fSys filesys.FileSystem) error {
 		return err
 	}
 
+	for _, component := range components {
+		if err = checkComponent(fSys, component); err != nil {
+			return err
+		}
+	}
+
 	for _, component := range components {
 		if mf.GetPath() != component {
 			if kustfile.StringInSlice(component, m.Components) {
-				log.Printf("component %s already in kustomization file", component)
+				logging.Warning("component already in kustomization file", "component", component)
 				continue
 			}
 			m.Components = append(m.Components, component)
@@ -79,3 +95,28 @@ func (o *addComponentOptions) RunAddComponent(fSys filesys.FileSystem) error {
 
 	return mf.Write(m)
 }
+
+// checkComponent returns an error if path is a local
+// directory whose kustomization isn't a component.
+func checkComponent(fSys filesys.FileSystem, path string) error {
+	if !fSys.IsDir(path) {
+		return nil
+	}
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		content, err := fSys.ReadFile(filepath.Join(path, n))
+		if err != nil {
+			continue
+		}
+		var k types.Kustomization
+		if err = k.Unmarshal(content); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Join(path, n), err)
+		}
+		if k.Kind != types.ComponentKind {
+			return fmt.Errorf(
+				"%s is not a component; its kind must be %s",
+				path, types.ComponentKind)
+		}
+		return nil
+	}
+	return fmt.Errorf("no kustomization file found in %s", path)
+}
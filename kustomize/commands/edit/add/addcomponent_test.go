@@ -73,3 +73,31 @@ func TestAddComponentNoArgs(t *testing.T) {
 	err := cmd.Execute()
 	assert.EqualError(t, err, "must specify a component file")
 }
+
+func TestAddComponentChecksKind(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	require.NoError(t, fSys.MkdirAll("components/monitoring"))
+	require.NoError(t, fSys.WriteFile("components/monitoring/kustomization.yaml", []byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+`)))
+	require.NoError(t, fSys.MkdirAll("base"))
+	require.NoError(t, fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- deployment.yaml
+`)))
+	require.NoError(t, fSys.MkdirAll("empty"))
+
+	cmd := newCmdAddComponent(fSys)
+	assert.NoError(t, cmd.RunE(cmd, []string{"components/monitoring"}))
+	assert.EqualError(t, cmd.RunE(cmd, []string{"base"}),
+		"base is not a component; its kind must be Component")
+	assert.EqualError(t, cmd.RunE(cmd, []string{"empty"}),
+		"no kustomization file found in empty")
+
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "components/monitoring")
+	assert.NotContains(t, string(content), "- base")
+}
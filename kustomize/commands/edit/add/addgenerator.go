@@ -5,9 +5,9 @@ package add
 
 import (
 	"errors"
-	"log"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -63,7 +63,7 @@ func (o *addGeneratorOptions) RunAddGenerator(fSys filesys.FileSystem) error {
 	}
 	for _, t := range o.generatorFilePaths {
 		if kustfile.StringInSlice(t, m.Generators) {
-			log.Printf("generator %s already in kustomization file", t)
+			logging.Warning("generator already in kustomization file", "generator", t)
 			continue
 		}
 		m.Generators = append(m.Generators, t)
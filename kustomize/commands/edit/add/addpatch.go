@@ -5,16 +5,19 @@ package add
 
 import (
 	"errors"
-	"log"
+	"fmt"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 type addPatchOptions struct {
-	Patch types.Patch
+	Patch           types.Patch
+	allowNameChange bool
+	allowKindChange bool
 }
 
 // newCmdAddPatch adds the name of a file containing a patch to the kustomization file.
@@ -35,12 +38,17 @@ Each item may:
 For more information please see https://kubernetes-sigs.github.io/kustomize/api-reference/kustomization/patches/
 `,
 		Example: `
-		add patch --path {filepath} --group {target group name} --version {target version}`,
+		add patch --path {filepath} --group {target group name} --version {target version}
+		add patch --path patch.yaml --kind Deployment --name web
+		add patch --path rename.yaml --kind Deployment --name web --allow-name-change`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate()
 			if err != nil {
 				return err
 			}
+			if o.Patch.Path != "" && !fSys.Exists(o.Patch.Path) {
+				return fmt.Errorf("patch file '%s' doesn't exist", o.Patch.Path)
+			}
 			return o.RunAddPatch(fSys)
 		},
 	}
@@ -53,6 +61,8 @@ For more information please see https://kubernetes-sigs.github.io/kustomize/api-
 	cmd.Flags().StringVar(&o.Patch.Target.Namespace, "namespace", "", "Resource namespace in patch target")
 	cmd.Flags().StringVar(&o.Patch.Target.AnnotationSelector, "annotation-selector", "", "annotationSelector in patch target")
 	cmd.Flags().StringVar(&o.Patch.Target.LabelSelector, "label-selector", "", "labelSelector in patch target")
+	cmd.Flags().BoolVar(&o.allowNameChange, "allow-name-change", false, "Let the patch change the name of its targets")
+	cmd.Flags().BoolVar(&o.allowKindChange, "allow-kind-change", false, "Let the patch change the kind of its targets")
 
 	return cmd
 }
@@ -80,6 +90,15 @@ func (o *addPatchOptions) RunAddPatch(fSys filesys.FileSystem) error {
 		return err
 	}
 
+	if o.allowNameChange || o.allowKindChange {
+		o.Patch.Options = make(map[string]bool)
+		if o.allowNameChange {
+			o.Patch.Options["allowNameChange"] = true
+		}
+		if o.allowKindChange {
+			o.Patch.Options["allowKindChange"] = true
+		}
+	}
 	// Omit target if it's empty
 	emptyTarget := types.Selector{}
 	if o.Patch.Target != nil && *o.Patch.Target == emptyTarget {
@@ -87,7 +106,7 @@ func (o *addPatchOptions) RunAddPatch(fSys filesys.FileSystem) error {
 	}
 	for _, p := range m.Patches {
 		if p.Equals(o.Patch) {
-			log.Printf("patch %#v already in kustomization file", p)
+			logging.Warning("patch already in kustomization file", "patch", fmt.Sprintf("%#v", p))
 			return nil
 		}
 	}
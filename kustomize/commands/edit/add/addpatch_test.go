@@ -111,3 +111,39 @@ func TestAddPatchNoArgs(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "must provide either patch or path", err.Error())
 }
+
+func TestAddPatchWithOptions(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	err := fSys.WriteFile(patchFileName, []byte(patchFileContent))
+	require.NoError(t, err)
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.SetArgs([]string{
+		"--path", patchFileName,
+		"--kind", "Deployment",
+		"--name", "web",
+		"--allow-name-change",
+	})
+	assert.NoError(t, cmd.Execute())
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `patches:
+- options:
+    allowNameChange: true
+  path: myWonderfulPatch.yaml
+  target:
+    kind: Deployment
+    name: web
+`)
+}
+
+func TestAddPatchMissingFile(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdAddPatch(fSys)
+	cmd.SetArgs([]string{"--path", "nope.yaml", "--kind", "Deployment"})
+	err := cmd.Execute()
+	assert.EqualError(t, err, "patch file 'nope.yaml' doesn't exist")
+}
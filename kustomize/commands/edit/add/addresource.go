@@ -5,10 +5,10 @@ package add
 
 import (
 	"errors"
-	"log"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -70,7 +70,7 @@ func (o *addResourceOptions) RunAddResource(fSys filesys.FileSystem) error {
 	for _, resource := range resources {
 		if mf.GetPath() != resource {
 			if kustfile.StringInSlice(resource, m.Resources) {
-				log.Printf("resource %s already in kustomization file", resource)
+				logging.Warning("resource already in kustomization file", "resource", resource)
 				continue
 			}
 			m.Resources = append(m.Resources, resource)
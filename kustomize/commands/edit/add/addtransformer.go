@@ -5,9 +5,9 @@ package add
 
 import (
 	"errors"
-	"log"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -63,7 +63,7 @@ func (o *addTransformerOptions) RunAddTransformer(fSys filesys.FileSystem) error
 	}
 	for _, t := range o.transformerFilePaths {
 		if kustfile.StringInSlice(t, m.Transformers) {
-			log.Printf("transformer %s already in kustomization file", t)
+			logging.Warning("transformer already in kustomization file", "transformer", t)
 			continue
 		}
 		m.Transformers = append(m.Transformers, t)
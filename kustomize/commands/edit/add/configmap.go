@@ -21,7 +21,11 @@ func newCmdAddConfigMap(
 	cmd := &cobra.Command{
 		Use:   "configmap NAME [--behavior={create|merge|replace}] [--from-file=[key=]source] [--from-literal=key1=value1]",
 		Short: "Adds a configmap to the kustomization file.",
-		Long:  "",
+		Long: `Adds a configmap generator to the kustomization file, or adds
+sources to the generator of that name if there is one.  A
+literal or file source replaces the one with the same key,
+so running the command again updates the generator.
+`,
 		Example: `
 	# Adds a configmap to the kustomization file (with a specified key)
 	kustomize edit add configmap my-configmap --from-file=my-key=file/path --from-literal=my-literal=12345
@@ -109,7 +113,7 @@ func addConfigMap(
 	k *types.Kustomization,
 	flags flagsAndArgs, rf *resource.Factory) error {
 	args := findOrMakeConfigMapArgs(k, flags.Name)
-	mergeFlagsIntoCmArgs(args, flags)
+	mergeFlagsIntoGeneratorArgs(&args.GeneratorArgs, flags)
 	// Validate by trying to create corev1.configmap.
 	args.Options = types.MergeGlobalOptionsIntoLocal(
 		args.Options, k.GeneratorOptions)
@@ -128,26 +132,3 @@ func findOrMakeConfigMapArgs(m *types.Kustomization, name string) *types.ConfigM
 	m.ConfigMapGenerator = append(m.ConfigMapGenerator, *cm)
 	return &m.ConfigMapGenerator[len(m.ConfigMapGenerator)-1]
 }
-
-func mergeFlagsIntoCmArgs(args *types.ConfigMapArgs, flags flagsAndArgs) {
-	if len(flags.LiteralSources) > 0 {
-		args.LiteralSources = append(
-			args.LiteralSources, flags.LiteralSources...)
-	}
-	if len(flags.FileSources) > 0 {
-		args.FileSources = append(
-			args.FileSources, flags.FileSources...)
-	}
-	if flags.EnvFileSource != "" {
-		args.EnvSources = append(
-			args.EnvSources, flags.EnvFileSource)
-	}
-	if flags.DisableNameSuffixHash {
-		args.Options = &types.GeneratorOptions{
-			DisableNameSuffixHash: true,
-		}
-	}
-	if flags.Behavior != "" {
-		args.Behavior = flags.Behavior
-	}
-}
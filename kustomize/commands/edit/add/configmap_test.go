@@ -112,3 +112,36 @@ func TestMergeFlagsIntoConfigMapArgs_Behavior(t *testing.T) {
 		replaceBehaviorFlags)
 	assert.Equal(t, "replace", k.ConfigMapGenerator[0].Behavior)
 }
+
+func TestMergeFlagsIntoConfigMapArgs_UpdateSources(t *testing.T) {
+	k := &types.Kustomization{}
+	args := findOrMakeConfigMapArgs(k, "foo")
+	mergeFlagsIntoGeneratorArgs(
+		&args.GeneratorArgs,
+		flagsAndArgs{
+			LiteralSources: []string{"k1=v1", "k2=v2"},
+			FileSources:    []string{"dir/file1", "key=file2"},
+			EnvFileSource:  "env1",
+		})
+	mergeFlagsIntoGeneratorArgs(
+		&args.GeneratorArgs,
+		flagsAndArgs{
+			LiteralSources: []string{"k1=new"},
+			FileSources:    []string{"other/file1", "key=file3"},
+			EnvFileSource:  "env1",
+		})
+	assert.Equal(t, []string{"k1=new", "k2=v2"}, k.ConfigMapGenerator[0].LiteralSources)
+	assert.Equal(t, []string{"other/file1", "key=file3"}, k.ConfigMapGenerator[0].FileSources)
+	assert.Equal(t, []string{"env1"}, k.ConfigMapGenerator[0].EnvSources)
+}
+
+func TestMergeFlagsIntoConfigMapArgs_KeepOptions(t *testing.T) {
+	k := &types.Kustomization{}
+	args := findOrMakeConfigMapArgs(k, "foo")
+	args.Options = &types.GeneratorOptions{Labels: map[string]string{"a": "b"}}
+	mergeFlagsIntoGeneratorArgs(
+		&args.GeneratorArgs,
+		flagsAndArgs{DisableNameSuffixHash: true})
+	assert.True(t, k.ConfigMapGenerator[0].Options.DisableNameSuffixHash)
+	assert.Equal(t, map[string]string{"a": "b"}, k.ConfigMapGenerator[0].Options.Labels)
+}
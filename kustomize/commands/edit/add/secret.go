@@ -4,6 +4,9 @@
 package add
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resource"
@@ -21,7 +24,11 @@ func newCmdAddSecret(
 	cmd := &cobra.Command{
 		Use:   "secret NAME [--from-file=[key=]source] [--from-literal=key1=value1] [--type=Opaque|kubernetes.io/tls]",
 		Short: "Adds a secret to the kustomization file.",
-		Long:  "",
+		Long: `Adds a secret generator to the kustomization file, or adds
+sources to the generator of that name if there is one.  A
+literal or file source replaces the one with the same key,
+so running the command again updates the generator.
+`,
 		Example: `
 	# Adds a secret to the kustomization file (with a specified key)
 	kustomize edit add secret my-secret --from-file=my-key=file/path --from-literal=my-literal=12345
@@ -132,25 +139,50 @@ func findOrMakeSecretArgs(m *types.Kustomization, name, namespace, secretType st
 	return &m.SecretGenerator[len(m.SecretGenerator)-1]
 }
 
+// mergeFlagsIntoGeneratorArgs adds the sources of the flags to
+// the generator.  A literal or file replaces the one with the
+// same key, so that adding a source again updates it.
 func mergeFlagsIntoGeneratorArgs(args *types.GeneratorArgs, flags flagsAndArgs) {
-	if len(flags.LiteralSources) > 0 {
-		args.LiteralSources = append(
-			args.LiteralSources, flags.LiteralSources...)
+	for _, s := range flags.LiteralSources {
+		args.LiteralSources = setSource(args.LiteralSources, s)
 	}
-	if len(flags.FileSources) > 0 {
-		args.FileSources = append(
-			args.FileSources, flags.FileSources...)
+	for _, s := range flags.FileSources {
+		args.FileSources = setSource(args.FileSources, s)
 	}
-	if flags.EnvFileSource != "" {
+	if flags.EnvFileSource != "" &&
+		!kustfile.StringInSlice(flags.EnvFileSource, args.EnvSources) {
 		args.EnvSources = append(
 			args.EnvSources, flags.EnvFileSource)
 	}
 	if flags.DisableNameSuffixHash {
-		args.Options = &types.GeneratorOptions{
-			DisableNameSuffixHash: true,
+		if args.Options == nil {
+			args.Options = &types.GeneratorOptions{}
 		}
+		args.Options.DisableNameSuffixHash = true
 	}
 	if flags.Behavior != "" {
 		args.Behavior = flags.Behavior
 	}
 }
+
+// setSource replaces the source with the same key as s in
+// sources, or appends s.
+func setSource(sources []string, s string) []string {
+	for i, old := range sources {
+		if sourceKey(old) == sourceKey(s) {
+			sources[i] = s
+			return sources
+		}
+	}
+	return append(sources, s)
+}
+
+// sourceKey returns the key of a literal source, e.g. k of
+// k=v, or of a file source, e.g. k of k=path, or the base
+// name of path.
+func sourceKey(s string) string {
+	if i := strings.Index(s, "="); i >= 0 {
+		return s[:i]
+	}
+	return filepath.Base(s)
+}
@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/add"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/fix"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/list"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/listbuiltin"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/remove"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/set"
@@ -36,6 +37,9 @@ func NewCmdEdit(
 
 	# Sets the namesuffix field
 	kustomize edit set namesuffix <suffix-value>
+
+	# Lists the images of the kustomization file as JSON
+	kustomize edit list images --output json
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
@@ -51,6 +55,7 @@ func NewCmdEdit(
 			v),
 		fix.NewCmdFix(fSys, w),
 		remove.NewCmdRemove(fSys, v),
+		list.NewCmdList(fSys, w),
 		listbuiltin.NewCmdListBuiltinPlugin(),
 	)
 	return c
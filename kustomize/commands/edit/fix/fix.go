@@ -7,17 +7,21 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 var flags struct {
-	vars bool
+	vars      bool
+	toVersion string
 }
 
 // NewCmdFix returns an instance of 'fix' subcommand.
@@ -25,17 +29,35 @@ func NewCmdFix(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "fix",
 		Short: "Fix the missing fields in kustomization file",
-		Long:  "",
+		Long: `Fix the missing fields in kustomization file, and migrate
+deprecated fields to their replacements:
+
+  bases -> resources
+  patchesStrategicMerge -> patches
+  patchesJson6902 -> patches
+  commonLabels -> labels
+  env -> envs
+  helmChartInflationGenerator -> helmCharts
+  vars -> replacements, with --vars
+  apiVersion -> the current one, with --to-version
+
+Fields that can't be migrated without changing the output
+are left in place, and reported.
+`,
 		Example: `
 	# Fix the missing and deprecated fields in kustomization file
 	kustomize edit fix
 
+	# Also migrate a legacy apiVersion to the current one
+	kustomize edit fix --to-version kustomize.config.k8s.io/v1beta1
+
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return RunFix(fSys, w)
 		},
 	}
 	AddFlagVars(cmd.Flags())
+	AddFlagToVersion(cmd.Flags())
 	return cmd
 }
 
@@ -50,11 +72,33 @@ func RunFix(fSys filesys.FileSystem, w io.Writer) error {
 		return err
 	}
 
+	// Read leniently, to find the deprecated fields in use.
+	data, err := fSys.ReadFile(mf.GetPath())
+	if err != nil {
+		return err
+	}
+	var old types.Kustomization
+	if err = old.UnmarshalLenient(data); err != nil {
+		return err
+	}
+
 	m, err := mf.Read()
 	if err != nil {
 		return err
 	}
 
+	if flags.toVersion != "" {
+		if err = migrateAPIVersion(m, flags.toVersion); err != nil {
+			return err
+		}
+	}
+
+	notes, err := ConvertPatchesStrategicMerge(
+		fSys, filepath.Dir(mf.GetPath()), m)
+	if err != nil {
+		return err
+	}
+
 	err = m.FixKustomizationPreMarshalling()
 	if err != nil {
 		return err
@@ -65,24 +109,8 @@ func RunFix(fSys filesys.FileSystem, w io.Writer) error {
 		if err != nil {
 			return err
 		}
-		fmt.Fprintln(w, `
-Fixed fields:
-  patchesJson6902 -> patches
-  commonLabels -> labels
-  vars -> replacements`)
-
-	} else {
-		fmt.Fprintln(w, `
-Fixed fields:
-  patchesJson6902 -> patches
-  commonLabels -> labels
-
-To convert vars -> replacements, run the command `+"`kustomize edit fix --vars`"+`
-
-WARNING: Converting vars to replacements will potentially overwrite many resource files 
-and the resulting files may not produce the same output when `+"`kustomize build`"+` is run. 
-We recommend doing this in a clean git repository where the change is easy to undo.`)
 	}
+	writeReport(w, &old, m, notes)
 
 	writeErr := mf.Write(m)
 
@@ -106,3 +134,102 @@ func AddFlagVars(set *pflag.FlagSet) {
 		`If specified, kustomize will attempt to convert vars to replacements. 
 We recommend doing this in a clean git repository where the change is easy to undo.`)
 }
+
+func AddFlagToVersion(set *pflag.FlagSet) {
+	set.StringVar(
+		&flags.toVersion,
+		"to-version",
+		"", // default
+		`If specified, the apiVersion of the kustomization is migrated to this one,
+which must be the current one for its kind, e.g. `+types.KustomizationVersion+`.`)
+}
+
+// migrateAPIVersion converts the kustomization to the given
+// apiVersion, or version of the kustomize.config.k8s.io group.
+func migrateAPIVersion(k *types.Kustomization, to string) error {
+	current := types.CurrentAPIVersion(k.Kind)
+	if to != current && !strings.HasSuffix(current, "/"+to) {
+		return fmt.Errorf(
+			"cannot migrate %s to apiVersion %s; expected %s", k.Kind, to, current)
+	}
+	if k.APIVersion != current && !types.IsKustomizationAPIVersion(k.APIVersion) {
+		return fmt.Errorf(
+			"cannot migrate %s from apiVersion %s, of another group", k.Kind, k.APIVersion)
+	}
+	k.MigrateAPIVersion()
+	return nil
+}
+
+// writeReport lists the deprecated fields that were fixed,
+// and those that weren't with the reason.
+func writeReport(w io.Writer, old, k *types.Kustomization, notes []string) {
+	var fixed []string
+	if old.APIVersion != "" && old.APIVersion != k.APIVersion {
+		fixed = append(fixed, "apiVersion "+old.APIVersion+" -> "+k.APIVersion)
+	}
+	if len(old.Bases) > 0 {
+		fixed = append(fixed, "bases -> resources")
+	}
+	if len(k.PatchesStrategicMerge) < len(old.PatchesStrategicMerge) {
+		fixed = append(fixed, "patchesStrategicMerge -> patches")
+	}
+	if len(old.PatchesJson6902) > 0 {
+		fixed = append(fixed, "patchesJson6902 -> patches")
+	}
+	if len(old.CommonLabels) > 0 {
+		fixed = append(fixed, "commonLabels -> labels")
+	}
+	if hasEnvSource(old) {
+		fixed = append(fixed, "env -> envs")
+	}
+	if len(old.HelmChartInflationGenerator) > 0 {
+		fixed = append(fixed, "helmChartInflationGenerator -> helmCharts")
+	}
+	if len(old.Vars) > 0 && flags.vars {
+		fixed = append(fixed, "vars -> replacements")
+	}
+
+	fmt.Fprintln(w)
+	if len(fixed) == 0 {
+		fmt.Fprintln(w, "No deprecated fields to fix.")
+	} else {
+		fmt.Fprintln(w, "Fixed fields:")
+		for _, f := range fixed {
+			fmt.Fprintln(w, "  "+f)
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(w, "\nNot fixed:")
+		for _, n := range notes {
+			fmt.Fprintln(w, "  "+n)
+		}
+	}
+	if current := types.CurrentAPIVersion(k.Kind); k.APIVersion != current &&
+		types.IsKustomizationAPIVersion(k.APIVersion) {
+		fmt.Fprintln(w, `
+To migrate apiVersion `+k.APIVersion+`, run the command `+
+			"`kustomize edit fix --to-version "+current+"`")
+	}
+	if len(old.Vars) > 0 && !flags.vars {
+		fmt.Fprintln(w, `
+To convert vars -> replacements, run the command `+"`kustomize edit fix --vars`"+`
+
+WARNING: Converting vars to replacements will potentially overwrite many resource files 
+and the resulting files may not produce the same output when `+"`kustomize build`"+` is run. 
+We recommend doing this in a clean git repository where the change is easy to undo.`)
+	}
+}
+
+func hasEnvSource(k *types.Kustomization) bool {
+	for _, g := range k.ConfigMapGenerator {
+		if g.EnvSource != "" {
+			return true
+		}
+	}
+	for _, g := range k.SecretGenerator {
+		if g.EnvSource != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@
 package fix
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -163,3 +164,124 @@ labels:
 	assert.Error(t, err)
 	assert.Equal(t, err.Error(), "label name 'foo' exists in both commonLabels and labels")
 }
+
+func TestFixOutdatedPatchesStrategicMerge(t *testing.T) {
+	kustomizationContentWithPatchesStrategicMerge := []byte(`
+patchesStrategicMerge:
+- patch1.yaml
+- |-
+  apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: inline
+- patch2.yaml
+- delete.yaml
+- path: patch1.yaml
+  mergeLists: append
+patches:
+- path: patch3.yaml
+`)
+
+	expected := []byte(`
+patchesStrategicMerge:
+- patch2.yaml
+- delete.yaml
+- mergeLists: append
+  path: patch1.yaml
+patches:
+- path: patch1.yaml
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: inline
+- path: patch3.yaml
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`)
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, kustomizationContentWithPatchesStrategicMerge)
+	assert.NoError(t, fSys.WriteFile("patch1.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: one
+`)))
+	assert.NoError(t, fSys.WriteFile("patch2.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: one
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: two
+`)))
+	assert.NoError(t, fSys.WriteFile("delete.yaml", []byte(`
+$patch: delete
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: one
+`)))
+	var out bytes.Buffer
+	cmd := NewCmdFix(fSys, &out)
+	assert.NoError(t, cmd.RunE(cmd, nil))
+
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	assert.NoError(t, err)
+	if diff := cmp.Diff(expected, content); diff != "" {
+		t.Errorf("Mismatch (-expected, +actual):\n%s", diff)
+	}
+	assert.Contains(t, out.String(), `
+Fixed fields:
+  patchesStrategicMerge -> patches
+
+Not fixed:
+  patchesStrategicMerge patch2.yaml holds 2 patches; split it into one file per patch
+  patchesStrategicMerge delete.yaml deletes a resource
+  patchesStrategicMerge patch1.yaml has options that patches lack
+`)
+}
+
+func TestFixToVersion(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Kustomization
+namePrefix: dev-
+`))
+	var out bytes.Buffer
+	cmd := NewCmdFix(fSys, &out)
+	assert.NoError(t, cmd.RunE(cmd, nil))
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "apiVersion: kustomize.config.k8s.io/v1alpha1\n")
+	assert.Contains(t, out.String(),
+		"To migrate apiVersion kustomize.config.k8s.io/v1alpha1, run the command "+
+			"`kustomize edit fix --to-version kustomize.config.k8s.io/v1beta1`")
+
+	out.Reset()
+	cmd = NewCmdFix(fSys, &out)
+	assert.NoError(t, cmd.Flags().Set("to-version", "v1beta1"))
+	assert.NoError(t, cmd.RunE(cmd, nil))
+	content, err = testutils_test.ReadTestKustomization(fSys)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "apiVersion: kustomize.config.k8s.io/v1beta1\n")
+	assert.Contains(t, out.String(),
+		"apiVersion kustomize.config.k8s.io/v1alpha1 -> kustomize.config.k8s.io/v1beta1")
+	assert.NotContains(t, out.String(), "To migrate")
+}
+
+func TestFixToVersionUnknown(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+kind: Component
+`))
+	cmd := NewCmdFix(fSys, os.Stdout)
+	assert.NoError(t, cmd.Flags().Set("to-version", "v1beta1"))
+	err := cmd.RunE(cmd, nil)
+	assert.EqualError(t, err,
+		"cannot migrate Component to apiVersion v1beta1; expected kustomize.config.k8s.io/v1alpha1")
+}
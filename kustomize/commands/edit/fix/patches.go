@@ -0,0 +1,81 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fix
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ConvertPatchesStrategicMerge moves the patchesStrategicMerge
+// entries of the kustomization in dir to patches, ahead of the
+// patches already there, as they're applied first.  An entry is
+// left in place if it can't be applied the same way as a patch,
+// i.e. if it has options, holds more or less than one patch, or
+// deletes a resource; the returned notes say why.
+func ConvertPatchesStrategicMerge(
+	fSys filesys.FileSystem, dir string, k *types.Kustomization) ([]string, error) {
+	var patches []types.Patch
+	var kept []types.PatchStrategicMerge
+	var notes []string
+	for _, psm := range k.PatchesStrategicMerge {
+		if e, ok := psm.Entry(); ok {
+			name := e.Path
+			if name == "" {
+				name = "inline patch"
+			}
+			notes = append(notes, fmt.Sprintf(
+				"patchesStrategicMerge %s has options that patches lack", name))
+			kept = append(kept, psm)
+			continue
+		}
+		entry := string(psm)
+		patch := types.Patch{Path: entry}
+		content := entry
+		name := entry
+		if p := filepath.Join(dir, entry); fSys.Exists(p) {
+			b, err := fSys.ReadFile(p)
+			if err != nil {
+				return nil, err
+			}
+			content = string(b)
+		} else {
+			// Legacy inline patch content.
+			patch = types.Patch{Patch: entry}
+			name = "inline patch"
+		}
+		nodes, err := kio.FromBytes([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("patchesStrategicMerge %s: %w", name, err)
+		}
+		for _, n := range nodes {
+			if n.YNode().Kind != yaml.MappingNode {
+				return nil, fmt.Errorf(
+					"patchesStrategicMerge %s is neither a file nor a patch", entry)
+			}
+		}
+		switch {
+		case len(nodes) != 1:
+			notes = append(notes, fmt.Sprintf(
+				"patchesStrategicMerge %s holds %d patches; "+
+					"split it into one file per patch", name, len(nodes)))
+		case strings.Contains(content, "$patch: delete"):
+			notes = append(notes, fmt.Sprintf(
+				"patchesStrategicMerge %s deletes a resource", name))
+		default:
+			patches = append(patches, patch)
+			continue
+		}
+		kept = append(kept, psm)
+	}
+	k.Patches = append(patches, k.Patches...)
+	k.PatchesStrategicMerge = kept
+	return notes, nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	formatText = "text"
+	formatJson = "json"
+)
+
+const (
+	itemResources  = "resources"
+	itemPatches    = "patches"
+	itemImages     = "images"
+	itemGenerators = "generators"
+)
+
+// Generator is a generator of the kustomization, as listed.
+type Generator struct {
+	// Kind is one of ConfigMap, Secret, HelmChart or Plugin.
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
+	// Path is the file holding the config of a plugin.
+	Path string `json:"path,omitempty"`
+	// Config is the generator entry in the kustomization.
+	Config interface{} `json:"config,omitempty"`
+}
+
+type listOptions struct {
+	item   string
+	format string
+}
+
+// NewCmdList returns an instance of 'list' subcommand.
+func NewCmdList(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o listOptions
+	cmd := &cobra.Command{
+		Use:   "list {resources|patches|images|generators}",
+		Short: "Lists items of the kustomization file",
+		Long: `Lists items of the kustomization file, one per line, or as
+a JSON list with --output json.
+
+Deprecated fields are listed as the fields replacing them,
+i.e. bases with resources, patchesStrategicMerge and
+patchesJson6902 with patches, in the order the patches are
+applied.  Generators include configMapGenerator,
+secretGenerator and helmCharts entries as well as the
+generator plugin configs.
+`,
+		Example: `
+	kustomize edit list resources
+	kustomize edit list images --output json
+`,
+		ValidArgs:    []string{itemResources, itemPatches, itemImages, itemGenerators},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	cmd.Flags().StringVarP(&o.format, "output", "o", formatText,
+		"the format of the list, one of text or json")
+	return cmd
+}
+
+// Validate validates list command.
+func (o *listOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("specify one of resources, patches, images or generators")
+	}
+	switch args[0] {
+	case itemResources, itemPatches, itemImages, itemGenerators:
+		o.item = args[0]
+	default:
+		return fmt.Errorf(
+			"unknown item %q; expected resources, patches, images or generators", args[0])
+	}
+	if o.format != formatText && o.format != formatJson {
+		return fmt.Errorf(
+			"unknown output format %q; expected text or json", o.format)
+	}
+	return nil
+}
+
+// Run prints the items.
+func (o *listOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+	k, err := mf.Read()
+	if err != nil {
+		return err
+	}
+	var items interface{}
+	var lines []string
+	switch o.item {
+	case itemResources:
+		resources := []interface{}{}
+		for _, r := range k.Resources {
+			if e, ok := types.ParseResourceEntry(r); ok {
+				resources = append(resources, e)
+				r = e.Path
+			} else {
+				resources = append(resources, r)
+			}
+			lines = append(lines, r)
+		}
+		items = resources
+	case itemPatches:
+		patches := listPatches(fSys, filepath.Dir(mf.GetPath()), k)
+		items = patches
+		for _, p := range patches {
+			lines = append(lines, describePatch(p))
+		}
+	case itemImages:
+		images := append([]types.Image{}, k.Images...)
+		items = images
+		for _, img := range images {
+			lines = append(lines, describeImage(img))
+		}
+	case itemGenerators:
+		generators := listGenerators(k)
+		items = generators
+		for _, g := range generators {
+			lines = append(lines, strings.TrimSpace(g.Kind+" "+g.Name+g.Path))
+		}
+	}
+	if o.format == formatJson {
+		b, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+	for _, l := range lines {
+		fmt.Fprintln(w, l)
+	}
+	return nil
+}
+
+// listPatches returns all patches in the order they're
+// applied: those of patchesStrategicMerge, patches, then
+// patchesJson6902.
+func listPatches(
+	fSys filesys.FileSystem, dir string, k *types.Kustomization) []types.Patch {
+	patches := []types.Patch{}
+	for _, psm := range k.PatchesStrategicMerge {
+		if e, ok := psm.Entry(); ok {
+			patches = append(patches, types.Patch{Path: e.Path, Patch: e.Patch})
+		} else if fSys.Exists(filepath.Join(dir, string(psm))) {
+			patches = append(patches, types.Patch{Path: string(psm)})
+		} else {
+			// Legacy inline patch content.
+			patches = append(patches, types.Patch{Patch: string(psm)})
+		}
+	}
+	patches = append(patches, k.Patches...)
+	return append(patches, k.PatchesJson6902...)
+}
+
+func listGenerators(k *types.Kustomization) []Generator {
+	generators := []Generator{}
+	for _, g := range k.ConfigMapGenerator {
+		generators = append(generators,
+			Generator{Kind: "ConfigMap", Name: g.Name, Config: g})
+	}
+	for _, g := range k.SecretGenerator {
+		generators = append(generators,
+			Generator{Kind: "Secret", Name: g.Name, Config: g})
+	}
+	for _, c := range k.HelmCharts {
+		generators = append(generators,
+			Generator{Kind: "HelmChart", Name: c.Name, Config: c})
+	}
+	for _, p := range k.Generators {
+		generators = append(generators, Generator{Kind: "Plugin", Path: p})
+	}
+	return generators
+}
+
+// describePatch returns e.g. "patch.yaml kind=Deployment name=app".
+func describePatch(p types.Patch) string {
+	parts := []string{p.Path}
+	if p.Path == "" {
+		parts = []string{"inline patch"}
+	}
+	if s := p.Target; s != nil {
+		for _, f := range []struct{ key, value string }{
+			{"group", s.Group},
+			{"version", s.Version},
+			{"kind", s.Kind},
+			{"name", s.Name},
+			{"namespace", s.Namespace},
+			{"labelSelector", s.LabelSelector},
+			{"annotationSelector", s.AnnotationSelector},
+		} {
+			if f.value != "" {
+				parts = append(parts, f.key+"="+f.value)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeImage returns the image in the form taken by
+// 'kustomize edit set image', e.g. nginx=my-nginx:1.21.
+func describeImage(img types.Image) string {
+	s := img.Name
+	if img.NewName != "" {
+		s += "=" + img.NewName
+	}
+	if img.Digest != "" {
+		return s + "@" + img.Digest
+	}
+	if img.NewTag != "" {
+		s += ":" + img.NewTag
+	}
+	return s
+}
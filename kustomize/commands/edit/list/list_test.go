@@ -0,0 +1,158 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package list
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v4/commands/internal/testutils"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const kustomization = `
+bases:
+- ../base
+resources:
+- deployment.yaml
+- path: ../vendor
+  options:
+    inheritNamePrefix: false
+patchesJson6902:
+- path: json.yaml
+  target:
+    kind: Service
+    name: app
+patchesStrategicMerge:
+- sm.yaml
+patches:
+- path: patch.yaml
+images:
+- name: nginx
+  newName: my-nginx
+  newTag: "1.21"
+- name: redis
+  digest: sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+configMapGenerator:
+- name: app-config
+  literals:
+  - a=b
+generators:
+- generator.yaml
+`
+
+func TestList(t *testing.T) {
+	testCases := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"resources": {
+			args: []string{"resources"},
+			expected: `deployment.yaml
+../vendor
+../base
+`,
+		},
+		"resources as json": {
+			args: []string{"resources", "-o", "json"},
+			expected: `[
+  "deployment.yaml",
+  {
+    "path": "../vendor",
+    "options": {
+      "inheritNamePrefix": false
+    }
+  },
+  "../base"
+]
+`,
+		},
+		"patches": {
+			args: []string{"patches"},
+			expected: `sm.yaml
+patch.yaml
+json.yaml kind=Service name=app
+`,
+		},
+		"images": {
+			args: []string{"images"},
+			expected: `nginx=my-nginx:1.21
+redis@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+`,
+		},
+		"generators": {
+			args: []string{"generators"},
+			expected: `ConfigMap app-config
+Plugin generator.yaml
+`,
+		},
+		"images as json": {
+			args: []string{"images", "--output", "json"},
+			expected: `[
+  {
+    "name": "nginx",
+    "newName": "my-nginx",
+    "newTag": "1.21"
+  },
+  {
+    "name": "redis",
+    "digest": "sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"
+  }
+]
+`,
+		},
+		"generators as json": {
+			args: []string{"generators", "-o", "json"},
+			expected: `[
+  {
+    "kind": "ConfigMap",
+    "name": "app-config",
+    "config": {
+      "name": "app-config",
+      "literals": [
+        "a=b"
+      ]
+    }
+  },
+  {
+    "kind": "Plugin",
+    "path": "generator.yaml"
+  }
+]
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			testutils_test.WriteTestKustomizationWith(fSys, []byte(kustomization))
+			require.NoError(t, fSys.WriteFile("sm.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`)))
+			var out bytes.Buffer
+			cmd := NewCmdList(fSys, &out)
+			cmd.SetArgs(tc.args)
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestListErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	cmd := NewCmdList(fSys, &bytes.Buffer{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"vars"})
+	assert.EqualError(t, cmd.Execute(),
+		`unknown item "vars"; expected resources, patches, images or generators`)
+	cmd.SetArgs([]string{"images", "--output", "xml"})
+	assert.EqualError(t, cmd.Execute(),
+		`unknown output format "xml"; expected text or json`)
+}
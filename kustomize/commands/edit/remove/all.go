@@ -33,6 +33,12 @@ func NewCmdRemove(
 
 	# Removes one or more transformers from the kustomization file
 	kustomize edit remove transformer <filepath>
+
+	# Removes one or more generators from the kustomization file
+	kustomize edit remove generator <filepath>
+
+	# Removes one or more components from the kustomization file
+	kustomize edit remove component <path>
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
@@ -42,6 +48,8 @@ func NewCmdRemove(
 		newCmdRemoveAnnotation(fSys, v.MakeAnnotationNameValidator()),
 		newCmdRemovePatch(fSys),
 		newCmdRemoveTransformer(fSys),
+		newCmdRemoveGenerator(fSys),
+		newCmdRemoveComponent(fSys),
 		newCmdRemoveBuildMetadata(fSys),
 	)
 	return c
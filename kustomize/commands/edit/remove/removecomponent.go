@@ -0,0 +1,83 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package remove
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type removeComponentOptions struct {
+	componentPaths []string
+}
+
+// newCmdRemoveComponent removes the path of a component from the kustomization file.
+func newCmdRemoveComponent(fSys filesys.FileSystem) *cobra.Command {
+	var o removeComponentOptions
+
+	cmd := &cobra.Command{
+		Use: "component",
+		Short: "Removes one or more components from " +
+			konfig.DefaultKustomizationFileName(),
+		Example: `
+		remove component ../components/monitoring
+		remove component ../components/monitoring ../components/tracing
+		remove component ../components/*
+		`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+			return o.RunRemoveComponent(fSys)
+		},
+	}
+	return cmd
+}
+
+// Validate validates removeComponent command.
+func (o *removeComponentOptions) Validate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify a component")
+	}
+	o.componentPaths = args
+	return nil
+}
+
+// RunRemoveComponent runs removeComponent command (do real work).
+func (o *removeComponentOptions) RunRemoveComponent(fSys filesys.FileSystem) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+
+	components, err := globPatterns(m.Components, o.componentPaths)
+	if err != nil {
+		return err
+	}
+
+	if len(components) == 0 {
+		return nil
+	}
+
+	newComponents := make([]string, 0, len(m.Components))
+	for _, component := range m.Components {
+		if kustfile.StringInSlice(component, components) {
+			continue
+		}
+		newComponents = append(newComponents, component)
+	}
+
+	m.Components = newComponents
+	return mf.Write(m)
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package remove
+
+import (
+	"errors"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/remove_test"
+)
+
+func TestRemoveComponent(t *testing.T) {
+	testCases := []remove_test.Case{
+		{
+			Description: "remove components",
+			Given: remove_test.Given{
+				Items: []string{
+					"../components/monitoring",
+					"../components/tracing",
+				},
+				RemoveArgs: []string{"../components/monitoring"},
+			},
+			Expected: remove_test.Expected{
+				Items: []string{
+					"../components/tracing",
+				},
+				Deleted: []string{
+					"../components/monitoring",
+				},
+			},
+		},
+		{
+			Description: "remove components with pattern",
+			Given: remove_test.Given{
+				Items: []string{
+					"../components/monitoring",
+					"../components/tracing",
+					"../other/component",
+				},
+				RemoveArgs: []string{"../components/*"},
+			},
+			Expected: remove_test.Expected{
+				Items: []string{
+					"../other/component",
+				},
+				Deleted: []string{
+					"../components/monitoring",
+					"../components/tracing",
+				},
+			},
+		},
+		{
+			Description: "no arguments",
+			Given:       remove_test.Given{},
+			Expected: remove_test.Expected{
+				Err: errors.New("must specify a component"),
+			},
+		},
+	}
+
+	remove_test.ExecuteTestCases(t, testCases, "components", newCmdRemoveComponent)
+}
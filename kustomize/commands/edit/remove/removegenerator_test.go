@@ -0,0 +1,111 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package remove
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/remove_test"
+)
+
+func TestRemoveGenerator(t *testing.T) {
+	testCases := []remove_test.Case{
+		{
+			Description: "remove generators",
+			Given: remove_test.Given{
+				Items: []string{
+					"generator1.yaml",
+					"generator2.yaml",
+					"generator3.yaml",
+				},
+				RemoveArgs: []string{"generator1.yaml"},
+			},
+			Expected: remove_test.Expected{
+				Items: []string{
+					"generator2.yaml",
+					"generator3.yaml",
+				},
+				Deleted: []string{
+					"generator1.yaml",
+				},
+			},
+		},
+		{
+			Description: "remove generator with pattern",
+			Given: remove_test.Given{
+				Items: []string{
+					"foo/generator1.yaml",
+					"foo/generator2.yaml",
+					"foo/generator3.yaml",
+					"do/not/deleteme/please.yaml",
+				},
+				RemoveArgs: []string{"foo/generator*.yaml"},
+			},
+			Expected: remove_test.Expected{
+				Items: []string{
+					"do/not/deleteme/please.yaml",
+				},
+				Deleted: []string{
+					"foo/generator1.yaml",
+					"foo/generator2.yaml",
+					"foo/generator3.yaml",
+				},
+			},
+		},
+		{
+			Description: "nothing found to remove",
+			Given: remove_test.Given{
+				Items: []string{
+					"generator1.yaml",
+					"generator2.yaml",
+					"generator3.yaml",
+				},
+				RemoveArgs: []string{"foo"},
+			},
+			Expected: remove_test.Expected{
+				Items: []string{
+					"generator2.yaml",
+					"generator3.yaml",
+					"generator1.yaml",
+				},
+			},
+		},
+		{
+			Description: "no arguments",
+			Given:       remove_test.Given{},
+			Expected: remove_test.Expected{
+				Err: errors.New("must specify a generator file"),
+			},
+		},
+		{
+			Description: "remove with multiple pattern arguments",
+			Given: remove_test.Given{
+				Items: []string{
+					"foo/foo.yaml",
+					"bar/bar.yaml",
+					"generator3.yaml",
+					"do/not/deleteme/please.yaml",
+				},
+				RemoveArgs: []string{
+					"foo/*.*",
+					"bar/*.*",
+					"gen*.yaml",
+				},
+			},
+			Expected: remove_test.Expected{
+				Items: []string{
+					"do/not/deleteme/please.yaml",
+				},
+				Deleted: []string{
+					"foo/foo.yaml",
+					"bar/bar.yaml",
+					"generator3.yaml",
+				},
+			},
+		},
+	}
+
+	remove_test.ExecuteTestCases(t, testCases, "generators", newCmdRemoveGenerator)
+}
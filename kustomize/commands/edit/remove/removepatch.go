@@ -4,11 +4,10 @@
 package remove
 
 import (
-	"log"
-
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -85,7 +84,7 @@ func (o *removePatchOptions) RunRemovePatch(fSys filesys.FileSystem) error {
 		}
 	}
 	if len(patches) == len(m.Patches) {
-		log.Printf("patch %s doesn't exist in kustomization file", o.Patch.Patch)
+		logging.Warning("patch doesn't exist in kustomization file", "patch", o.Patch.Patch)
 		return nil
 	}
 	m.Patches = patches
@@ -5,14 +5,13 @@ package set
 
 import (
 	"errors"
-	"regexp"
 	"sort"
-	"strings"
 
 	"sigs.k8s.io/kustomize/api/types"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -20,24 +19,15 @@ type setImageOptions struct {
 	imageMap map[string]types.Image
 }
 
-var pattern = regexp.MustCompile(`^(.*):([a-zA-Z0-9._-]*|\*)$`)
-
 var preserveSeparator = "*"
 
 // errors
 
 var (
 	errImageNoArgs      = errors.New("no image specified")
-	errImageInvalidArgs = errors.New(`invalid format of image, use one of the following options:
-- <image>=<newimage>:<newtag>
-- <image>=<newimage>@<digest>
-- <image>=<newimage>
-- <image>:<newtag>
-- <image>@<digest>`)
+	errImageInvalidArgs = util.ErrImageInvalidArgs
 )
 
-const separator = "="
-
 // newCmdSetImage sets the new names, tags or digests for images in the kustomization.
 func newCmdSetImage(fSys filesys.FileSystem) *cobra.Command {
 	var o setImageOptions
@@ -78,6 +68,12 @@ and overwrite the previous ones if the image name exists.
 
 The image tag can only contain alphanumeric, '.', '_' and '-'. Passing * (asterisk) either as the new name, 
 the new tag, or the digest will preserve the appropriate values from the kustomization file.
+
+An image name of * matches all images, and a * within a new name stands
+for the old name, so the command
+  set image '*=registry.example.com/*'
+moves all images to registry.example.com.  Images matching all images are
+applied after the others.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
@@ -90,12 +86,6 @@ the new tag, or the digest will preserve the appropriate values from the kustomi
 	return cmd
 }
 
-type overwrite struct {
-	name   string
-	digest string
-	tag    string
-}
-
 // Validate validates setImage command.
 func (o *setImageOptions) Validate(args []string) error {
 	if len(args) == 0 {
@@ -106,7 +96,7 @@ func (o *setImageOptions) Validate(args []string) error {
 
 	for _, arg := range args {
 
-		img, err := parse(arg)
+		img, err := util.ParseImage(arg)
 		if err != nil {
 			return err
 		}
@@ -172,6 +162,11 @@ func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
 	}
 
 	sort.Slice(images, func(i, j int) bool {
+		// The wildcard goes last, so applies to images
+		// as changed by the others.
+		if (images[i].Name == "*") != (images[j].Name == "*") {
+			return images[j].Name == "*"
+		}
 		return images[i].Name < images[j].Name
 	})
 
@@ -205,55 +200,3 @@ func replaceDigest(image types.Image, digest string) types.Image {
 		Digest:  digest,
 	}
 }
-
-func parse(arg string) (types.Image, error) {
-
-	// matches if there is an image name to overwrite
-	// <image>=<new-image><:|@><new-tag>
-	if s := strings.Split(arg, separator); len(s) == 2 {
-		p, err := parseOverwrite(s[1], true)
-		return types.Image{
-			Name:    s[0],
-			NewName: p.name,
-			NewTag:  p.tag,
-			Digest:  p.digest,
-		}, err
-	}
-
-	// matches only for <tag|digest> overwrites
-	// <image><:|@><new-tag>
-	p, err := parseOverwrite(arg, false)
-	return types.Image{
-		Name:   p.name,
-		NewTag: p.tag,
-		Digest: p.digest,
-	}, err
-}
-
-// parseOverwrite parses the overwrite parameters
-// from the given arg into a struct
-func parseOverwrite(arg string, overwriteImage bool) (overwrite, error) {
-	// match <image>@<digest>
-	if d := strings.Split(arg, "@"); len(d) > 1 {
-		return overwrite{
-			name:   d[0],
-			digest: d[1],
-		}, nil
-	}
-
-	// match <image>:<tag>
-	if t := pattern.FindStringSubmatch(arg); len(t) == 3 {
-		return overwrite{
-			name: t[1],
-			tag:  t[2],
-		}, nil
-	}
-
-	// match <image>
-	if len(arg) > 0 && overwriteImage {
-		return overwrite{
-			name: arg,
-		}, nil
-	}
-	return overwrite{}, errImageInvalidArgs
-}
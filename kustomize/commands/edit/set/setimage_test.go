@@ -74,6 +74,39 @@ func TestSetImage(t *testing.T) {
 					"  newName: my-image1",
 				}},
 		},
+		{
+			description: "wildcard goes last",
+			given: given{
+				args: []string{"*=registry.example.com/*", "image1:my-tag"},
+			},
+			expected: expected{
+				fileOutput: []string{
+					"images:",
+					"- name: image1",
+					"  newTag: my-tag",
+					"- name: '*'",
+					"  newName: registry.example.com/*",
+				}},
+		},
+		{
+			description: "digest after a colon",
+			given: given{
+				args: []string{"image1=*:sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"},
+				infileImages: []string{
+					"images:",
+					"- name: image1",
+					"  newName: my-image1",
+					"  newTag: my-tag",
+				},
+			},
+			expected: expected{
+				fileOutput: []string{
+					"images:",
+					"- digest: sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3",
+					"  name: image1",
+					"  newName: my-image1",
+				}},
+		},
 		{
 			given: given{
 				args: []string{"image1:my-tag"},
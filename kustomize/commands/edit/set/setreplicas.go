@@ -23,7 +23,7 @@ type setReplicasOptions struct {
 
 var (
 	errReplicasNoArgs      = errors.New("no replicas specified")
-	errReplicasInvalidArgs = errors.New(`invalid format of replica, use the following format: <name>=<count>`)
+	errReplicasInvalidArgs = errors.New(`invalid format of replica, use the following format: <name>=<count>, with a count of zero or more`)
 )
 
 const replicasSeparator = "="
@@ -33,7 +33,7 @@ func newCmdSetReplicas(fSys filesys.FileSystem) *cobra.Command {
 	var o setReplicasOptions
 
 	cmd := &cobra.Command{
-		Use:   "replicas",
+		Use:   "replicas NAME=COUNT [NAME=COUNT...]",
 		Short: `Sets replicas count for resources in the kustomization file`,
 		Example: `
 The command
@@ -118,7 +118,7 @@ func parseReplicasArg(arg string) (types.Replica, error) {
 	// <name>=<count>
 	if s := strings.Split(arg, replicasSeparator); len(s) == 2 {
 		count, err := strconv.ParseInt(s[1], 10, 64)
-		if err != nil {
+		if err != nil || count < 0 || s[0] == "" {
 			return types.Replica{}, errReplicasInvalidArgs
 		}
 
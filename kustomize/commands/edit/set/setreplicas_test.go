@@ -106,6 +106,24 @@ func TestSetReplicas(t *testing.T) {
 				err: errReplicasInvalidArgs,
 			},
 		},
+		{
+			description: "error: invalid args -- negative count",
+			given: given{
+				args: []string{"app=-1"},
+			},
+			expected: expected{
+				err: errReplicasInvalidArgs,
+			},
+		},
+		{
+			description: "error: invalid args -- no name",
+			given: given{
+				args: []string{"=1"},
+			},
+			expected: expected{
+				err: errReplicasInvalidArgs,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,30 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/kustomize/cmd/config/configcobra"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// PrintErrorDetails prints, if the stack-trace flag is set,
+// the chain of errors that made a command fail, e.g. the
+// kustomization, transformer and resource it failed in,
+// followed by the stack trace where the failure happened.
+func PrintErrorDetails(w io.Writer, err error) {
+	if err == nil || !*configcobra.StackOnError {
+		return
+	}
+	fmt.Fprintln(w, "Error chain:")
+	for _, line := range errors.Chain(err) {
+		fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(line, "\n", "\n  "))
+	}
+	if stack := errors.Stack(err); stack != "" {
+		fmt.Fprintf(w, "Stack trace:\n%s\n", strings.TrimSuffix(stack, "\n"))
+	}
+}
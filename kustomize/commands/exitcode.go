@@ -0,0 +1,58 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Exit codes of kustomize, per class of failure.
+const (
+	ExitOK = 0
+	// ExitError is a failure of no known class.
+	ExitError = 1
+	// ExitConfigError is an invalid kustomization, plugin
+	// config or command line.
+	ExitConfigError = 2
+	// ExitMissingFile is a file or directory that doesn't exist.
+	ExitMissingFile = 3
+	// ExitRemoteError is a failure to fetch something remote,
+	// which may be worth retrying.
+	ExitRemoteError = 4
+	// ExitPluginError is an external plugin failing to load or run.
+	ExitPluginError = 5
+	// ExitInternalError is a bug of kustomize.
+	ExitInternalError = 6
+)
+
+const exitCodesHelp = `
+Exit codes:
+  0  success
+  1  failure of no known class
+  2  invalid kustomization, plugin config or command line
+  3  missing file or directory
+  4  failure to fetch a remote base, resource or plugin
+  5  external plugin failure
+  6  internal error
+`
+
+var exitCodes = map[types.ErrorClass]int{
+	types.ErrorClassConfig:      ExitConfigError,
+	types.ErrorClassMissingFile: ExitMissingFile,
+	types.ErrorClassRemote:      ExitRemoteError,
+	types.ErrorClassPlugin:      ExitPluginError,
+	types.ErrorClassInternal:    ExitInternalError,
+}
+
+// ExitCode returns the code kustomize exits with after the
+// error, per its class.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if code, ok := exitCodes[types.ClassOfError(err)]; ok {
+		return code
+	}
+	return ExitError
+}
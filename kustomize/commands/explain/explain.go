@@ -0,0 +1,311 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package explain
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+type explainOptions struct {
+	kind string
+	path []string
+}
+
+// NewCmdExplain makes a new explain command.
+func NewCmdExplain(w io.Writer) *cobra.Command {
+	var o explainOptions
+	c := &cobra.Command{
+		Use:   "explain [KIND[.FIELD]...]",
+		Short: "Describe the config of builtin generators and transformers",
+		Long: `Describe the config of builtin generators and transformers.
+
+Without arguments, the builtins are listed.  Given the kind
+of a builtin, e.g. PatchTransformer, its config fields are
+printed with their types, followed by an example config.
+Given a field path, e.g. PatchTransformer.target, only that
+field is described.
+
+The config of a builtin is given in a file listed under
+'generators' or 'transformers' in a kustomization, with
+apiVersion 'builtin' and the kind of the builtin.
+`,
+		Example: `
+	kustomize explain
+	kustomize explain PatchTransformer
+	kustomize explain ConfigMapGenerator.options
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(w)
+		},
+	}
+	return c
+}
+
+// Validate validates explain command args.
+func (o *explainOptions) Validate(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("specify one builtin kind, or field path")
+	}
+	if len(args) == 1 {
+		parts := strings.Split(args[0], ".")
+		o.kind, o.path = parts[0], parts[1:]
+	}
+	return nil
+}
+
+// Run prints the explanation.
+func (o *explainOptions) Run(w io.Writer) error {
+	if o.kind == "" {
+		listBuiltins(w)
+		return nil
+	}
+	p := krusty.NewBuiltinPlugin(o.kind)
+	if p == nil {
+		return fmt.Errorf(
+			"unknown builtin %q; run 'explain' without arguments to list them", o.kind)
+	}
+	fields := configFields(p)
+	if len(o.path) == 0 {
+		fmt.Fprintf(w, "KIND:     %s\nTYPE:     %s\n\nFIELDS:\n", o.kind, pluginType(p))
+		writeFields(w, fields, "   ")
+		fmt.Fprintf(w, "\nEXAMPLE:\napiVersion: builtin\nkind: %s\n", o.kind)
+		if !hasField(fields, "metadata") {
+			fmt.Fprintf(w, "metadata:\n  name: example\n")
+		}
+		writeExample(w, fields, "")
+		return nil
+	}
+	var f *field
+	for i, name := range o.path {
+		f = findField(fields, name)
+		if f == nil {
+			return fmt.Errorf("%s has no field %q",
+				strings.Join(append([]string{o.kind}, o.path[:i]...), "."), name)
+		}
+		fields = f.fields
+	}
+	fmt.Fprintf(w, "KIND:     %s\nFIELD:    %s %s\n", o.kind, f.name, f.typeName)
+	if len(f.fields) > 0 {
+		fmt.Fprintf(w, "\nFIELDS:\n")
+		writeFields(w, f.fields, "   ")
+	}
+	return nil
+}
+
+func listBuiltins(w io.Writer) {
+	kinds := map[string][]string{}
+	for _, name := range krusty.GetBuiltinPluginNames() {
+		if p := krusty.NewBuiltinPlugin(name); p != nil {
+			t := pluginType(p)
+			kinds[t] = append(kinds[t], name)
+		}
+	}
+	for i, t := range []string{"generator", "transformer"} {
+		sort.Strings(kinds[t])
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%sS:\n", strings.ToUpper(t))
+		for _, k := range kinds[t] {
+			fmt.Fprintf(w, "   %s\n", k)
+		}
+	}
+}
+
+func pluginType(p resmap.Configurable) string {
+	if _, ok := p.(resmap.Generator); ok {
+		return "generator"
+	}
+	return "transformer"
+}
+
+// field is a config field, as it appears in yaml.
+type field struct {
+	name     string
+	typeName string
+	// kind is the kind of the field, or of the elements
+	// of a list or map.
+	kind   reflect.Kind
+	isList bool
+	isMap  bool
+	fields []*field
+}
+
+// configFields returns the config fields of the plugin.
+// A plugin made of several, e.g. PrefixSuffixTransformer,
+// has the fields of all of them.
+func configFields(p resmap.Configurable) []*field {
+	if m, ok := p.(interface {
+		Transformers() []resmap.TransformerPlugin
+	}); ok {
+		var fields []*field
+		for _, t := range m.Transformers() {
+			for _, f := range configFields(t) {
+				if !hasField(fields, f.name) {
+					fields = append(fields, f)
+				}
+			}
+		}
+		return fields
+	}
+	return structFields(reflect.TypeOf(p).Elem(), map[reflect.Type]bool{})
+}
+
+// structFields returns the fields of the struct type t.
+// Seen holds the types being described, to stop on cycles.
+func structFields(t reflect.Type, seen map[reflect.Type]bool) []*field {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	var fields []*field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "omitempty" {
+			// A misspelled tag, on a field not meant to be
+			// configured, e.g. ReplacementTransformer's
+			// Replacements.
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// Inlined.
+			fields = append(fields, structFields(ft, seen)...)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, newField(name, ft, seen))
+	}
+	return fields
+}
+
+func newField(name string, t reflect.Type, seen map[reflect.Type]bool) *field {
+	f := &field{name: name}
+	elem := t
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		f.isList = true
+		elem = t.Elem()
+	case reflect.Map:
+		f.isMap = true
+		elem = t.Elem()
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	f.kind = elem.Kind()
+	typeName := kindName(elem)
+	if f.kind == reflect.Struct {
+		f.fields = structFields(elem, seen)
+	}
+	switch {
+	case f.isList:
+		typeName = "[]" + typeName
+	case f.isMap:
+		typeName = "map[" + kindName(t.Key()) + "]" + typeName
+	}
+	f.typeName = "<" + typeName + ">"
+	return f
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Struct:
+		return "Object"
+	default:
+		return t.Kind().String()
+	}
+}
+
+func hasField(fields []*field, name string) bool {
+	return findField(fields, name) != nil
+}
+
+func findField(fields []*field, name string) *field {
+	for _, f := range fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func writeFields(w io.Writer, fields []*field, indent string) {
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s%s\t%s\n", indent, f.name, f.typeName)
+		writeFields(w, f.fields, indent+"   ")
+	}
+}
+
+// writeExample writes a yaml skeleton of the fields, with
+// their types as values.
+func writeExample(w io.Writer, fields []*field, indent string) {
+	for _, f := range fields {
+		if f.kind != reflect.Struct && !f.isList && !f.isMap {
+			fmt.Fprintf(w, "%s%s: %s\n", indent, f.name, f.typeName)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s:\n", indent, f.name)
+		switch {
+		case f.isList && f.kind == reflect.Struct:
+			var b strings.Builder
+			writeExample(&b, f.fields, "")
+			for i, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+				if i == 0 {
+					fmt.Fprintf(w, "%s- %s\n", indent, line)
+				} else {
+					fmt.Fprintf(w, "%s  %s\n", indent, line)
+				}
+			}
+		case f.isList:
+			fmt.Fprintf(w, "%s- <%s\n", indent, strings.TrimPrefix(f.typeName, "<[]"))
+		case f.isMap && f.kind == reflect.Struct:
+			fmt.Fprintf(w, "%s  <key>:\n", indent)
+			writeExample(w, f.fields, indent+"    ")
+		case f.isMap:
+			fmt.Fprintf(w, "%s  <key>: <%s\n", indent,
+				f.typeName[strings.Index(f.typeName, "]")+1:])
+		default:
+			writeExample(w, f.fields, indent+"  ")
+		}
+	}
+}
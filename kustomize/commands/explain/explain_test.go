@@ -0,0 +1,84 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package explain_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/explain"
+)
+
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	out := new(bytes.Buffer)
+	cmd := explain.NewCmdExplain(out)
+	err := cmd.RunE(cmd, args)
+	return out.String(), err
+}
+
+func TestExplainList(t *testing.T) {
+	out, err := run(t)
+	require.NoError(t, err)
+	assert.Contains(t, out, "GENERATORS:\n   ConfigMapGenerator\n")
+	assert.Contains(t, out, "\n   PatchTransformer\n")
+}
+
+func TestExplainKind(t *testing.T) {
+	out, err := run(t, "ReplicaCountTransformer")
+	require.NoError(t, err)
+	assert.Equal(t, `KIND:     ReplicaCountTransformer
+TYPE:     transformer
+
+FIELDS:
+   replica	<Object>
+      name	<string>
+      count	<integer>
+   fieldSpecs	<[]Object>
+      group	<string>
+      version	<string>
+      kind	<string>
+      path	<string>
+      create	<boolean>
+
+EXAMPLE:
+apiVersion: builtin
+kind: ReplicaCountTransformer
+metadata:
+  name: example
+replica:
+  name: <string>
+  count: <integer>
+fieldSpecs:
+- group: <string>
+  version: <string>
+  kind: <string>
+  path: <string>
+  create: <boolean>
+`, out)
+}
+
+func TestExplainField(t *testing.T) {
+	out, err := run(t, "PatchTransformer.target")
+	require.NoError(t, err)
+	assert.Equal(t, `KIND:     PatchTransformer
+FIELD:    target <Object>
+
+FIELDS:
+   group	<string>
+   version	<string>
+   kind	<string>
+   name	<string>
+   namespace	<string>
+   annotationSelector	<string>
+   labelSelector	<string>
+`, out)
+
+	_, err = run(t, "PatchTransformer.target.nope")
+	assert.EqualError(t, err, `PatchTransformer.target has no field "nope"`)
+	_, err = run(t, "NoSuchTransformer")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filter
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type filterOptions struct {
+	path string
+}
+
+// NewCmdFilter makes a new filter command.
+func NewCmdFilter(
+	fSys filesys.FileSystem, r io.Reader, w io.Writer) *cobra.Command {
+	var o filterOptions
+	c := &cobra.Command{
+		Use:   "filter [DIR]",
+		Short: "Run resources read from stdin through a kustomization",
+		Long: `Run resources read from stdin through a kustomization.
+
+The resources read are taken by the kustomization in DIR, the
+current directory by default, as if they were the first of
+its resources, so that its generators, transformers and
+patches apply to them.  The result is written to stdout.
+
+This makes kustomize usable as a helm post-renderer as is,
+with helm 3.10 or later.
+`,
+		Example: `
+	helm template web ./chart | kustomize filter overlays/prod
+	helm install web ./chart --post-renderer kustomize --post-renderer-args filter --post-renderer-args overlays/prod
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, r, w)
+		},
+	}
+	build.AddFlagLoadRestrictor(c.Flags())
+	build.AddFlagEnablePlugins(c.Flags())
+	build.AddFlagEnableHelm(c.Flags())
+	build.AddFlagEnableJsonnet(c.Flags())
+	build.AddFlagEnableDataSources(c.Flags())
+	build.AddFlagEnableOpa(c.Flags())
+	build.AddFlagEnableSops(c.Flags())
+	build.AddFlagDiscovery(c.Flags())
+	build.AddFlagSchemaFromCluster(c.Flags())
+	return c
+}
+
+// Validate validates filter command args.
+func (o *filterOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		o.path = filesys.SelfDir
+	case 1:
+		o.path = args[0]
+	default:
+		return fmt.Errorf("specify at most one kustomization directory")
+	}
+	return nil
+}
+
+// Run builds the kustomization with the resources read.
+func (o *filterOptions) Run(
+	fSys filesys.FileSystem, r io.Reader, w io.Writer) error {
+	input, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	opts := build.HonorKustomizeFlags(krusty.MakeDefaultOptions())
+	opts.Input = input
+	m, err := krusty.MakeKustomizer(opts).Run(fSys, o.path)
+	if err != nil {
+		return err
+	}
+	out, err := m.AsYaml()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/filter"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestFilter(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("prod/kustomization.yaml", []byte(`
+namespace: prod
+commonLabels:
+  env: prod
+patches:
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: web
+    spec:
+      replicas: 3
+`)))
+	in := strings.NewReader(`---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	var out bytes.Buffer
+	cmd := filter.NewCmdFilter(fSys, in, &out)
+	require.NoError(t, cmd.RunE(cmd, []string{"prod"}))
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+`, out.String())
+}
+
+func TestFilterTooManyArgs(t *testing.T) {
+	cmd := filter.NewCmdFilter(
+		filesys.MakeFsInMemory(), strings.NewReader(""), new(bytes.Buffer))
+	assert.Error(t, cmd.RunE(cmd, []string{"a", "b"}))
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/logging"
+)
+
+// addLogFlags adds the flags setting the verbosity and the
+// format of the messages that commands log to stderr.
+func addLogFlags(set *pflag.FlagSet) {
+	set.Var(verbosityValue{}, "v",
+		"log verbosity: 1 logs the kustomization built, 2 the "+
+			"kustomizations, bases and components accumulated, 3 the "+
+			"generators, transformers and validators run, 4 the plugins loaded")
+	set.Var(&logFormatValue{format: logging.FormatText}, "log-format",
+		fmt.Sprintf("format of log messages, one of %v", logging.Formats))
+}
+
+// verbosityValue sets the verbosity of the logger.
+type verbosityValue struct{}
+
+func (verbosityValue) String() string {
+	return strconv.Itoa(logging.Verbosity())
+}
+
+func (verbosityValue) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return fmt.Errorf("expected a non-negative integer")
+	}
+	logging.SetVerbosity(v)
+	return nil
+}
+
+func (verbosityValue) Type() string {
+	return "int"
+}
+
+// logFormatValue sets the format of the logger.
+type logFormatValue struct {
+	format logging.Format
+}
+
+func (v *logFormatValue) String() string {
+	return string(v.format)
+}
+
+func (v *logFormatValue) Set(s string) error {
+	if err := logging.SetFormat(logging.Format(s)); err != nil {
+		return err
+	}
+	v.format = logging.Format(s)
+	return nil
+}
+
+func (v *logFormatValue) Type() string {
+	return "string"
+}
@@ -0,0 +1,250 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ErrNotFormatted is returned by --check if some files
+// aren't formatted.
+var ErrNotFormatted = errors.New("some files are not formatted")
+
+type formatOptions struct {
+	paths     []string
+	check     bool
+	resources bool
+}
+
+// NewCmdFormat makes a new fmt command.
+func NewCmdFormat(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o formatOptions
+	c := &cobra.Command{
+		Use:   "fmt [DIR|FILE]...",
+		Short: "Format kustomization files",
+		Long: `Format kustomization files.
+
+The kustomization files in each DIR, and in the directories
+below it, are rewritten with their fields in a canonical
+order, block style lists and maps, and two space indentation.
+Comments are kept.  With --resources, the local resource files
+the kustomizations list are formatted too, like by
+'kustomize cfg fmt'.
+
+With --check, no file is changed; the files that aren't
+formatted are listed, and the command fails if there are any.
+
+If no DIR is given, '.' is assumed.
+`,
+		Example: `
+	kustomize fmt
+	kustomize fmt overlays --resources
+	kustomize fmt --check
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().BoolVar(&o.check, "check", false,
+		"list the files that aren't formatted, without changing them, "+
+			"and fail if there are any")
+	c.Flags().BoolVar(&o.resources, "resources", false,
+		"also format the local resource files listed in the kustomizations")
+	return c
+}
+
+// Validate validates fmt command args and flags.
+func (o *formatOptions) Validate(args []string) error {
+	o.paths = args
+	if len(o.paths) == 0 {
+		o.paths = []string{filesys.SelfDir}
+	}
+	return nil
+}
+
+// Run formats the files.
+func (o *formatOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	files := make(map[string]func([]byte) ([]byte, error))
+	for _, path := range o.paths {
+		if err := o.collect(fSys, path, files); err != nil {
+			return err
+		}
+	}
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	unformatted := 0
+	for _, path := range paths {
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := files[path](content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if bytes.Equal(content, formatted) {
+			continue
+		}
+		unformatted++
+		if o.check {
+			fmt.Fprintln(w, path)
+			continue
+		}
+		if err = fSys.WriteFile(path, formatted); err != nil {
+			return err
+		}
+	}
+	if o.check && unformatted > 0 {
+		return ErrNotFormatted
+	}
+	return nil
+}
+
+// collect adds the files to format below path to files,
+// mapped to the function that formats them.
+func (o *formatOptions) collect(fSys filesys.FileSystem, path string,
+	files map[string]func([]byte) ([]byte, error)) error {
+	if !fSys.IsDir(path) {
+		if !fSys.Exists(path) {
+			return fmt.Errorf("'%s' doesn't exist", path)
+		}
+		if isKustomizationFile(path) {
+			return o.addKustomization(fSys, path, files)
+		}
+		files[path] = formatResources
+		return nil
+	}
+	return fSys.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isKustomizationFile(p) {
+			return nil
+		}
+		return o.addKustomization(fSys, p, files)
+	})
+}
+
+func (o *formatOptions) addKustomization(fSys filesys.FileSystem,
+	path string, files map[string]func([]byte) ([]byte, error)) error {
+	files[path] = formatKustomization
+	if !o.resources {
+		return nil
+	}
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// Unknown fields are kept by formatting, so mustn't fail it.
+	var k types.Kustomization
+	if err = k.UnmarshalLenient(content); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for _, r := range append(k.Resources, k.Bases...) {
+		p := filepath.Join(dir, r)
+		if fSys.Exists(p) && !fSys.IsDir(p) {
+			if _, ok := files[p]; !ok {
+				files[p] = formatResources
+			}
+		}
+	}
+	return nil
+}
+
+func isKustomizationFile(path string) bool {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if filepath.Base(path) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// formatKustomization orders the fields of a kustomization
+// canonically, and rewrites it in block style.
+func formatKustomization(content []byte) ([]byte, error) {
+	node, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	if node.YNode().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping")
+	}
+	rank := make(map[string]int)
+	for i, key := range kustfile.FieldOrder() {
+		rank[key] = i + 1
+	}
+	pairs := node.YNode().Content
+	type pair struct{ key, value *yaml.Node }
+	var ps []pair
+	for i := 0; i+1 < len(pairs); i += 2 {
+		ps = append(ps, pair{pairs[i], pairs[i+1]})
+	}
+	// Unknown fields keep their order, after the known ones.
+	sort.SliceStable(ps, func(i, j int) bool {
+		ri, rj := rank[ps[i].key.Value], rank[ps[j].key.Value]
+		if ri == 0 || rj == 0 {
+			return ri != 0 && rj == 0
+		}
+		return ri < rj
+	})
+	pairs = pairs[:0]
+	for _, p := range ps {
+		pairs = append(pairs, p.key, p.value)
+	}
+	node.YNode().Content = pairs
+	blockStyle(node.YNode())
+	s, err := node.String()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// blockStyle clears the flow style of all maps and lists.
+func blockStyle(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		n.Style &^= yaml.FlowStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// formatResources formats resources like 'kustomize cfg fmt'.
+func formatResources(content []byte) ([]byte, error) {
+	out, err := filters.FormatInput(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package format_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/format"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const unformatted = `# The app.
+namePrefix: dev-
+resources: [deployment.yaml]
+apiVersion: kustomize.config.k8s.io/v1beta1
+commonLabels: {app: web}
+kind: Kustomization
+# Unknown to kustomize.
+custom: x
+`
+
+const formatted = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+# The app.
+namePrefix: dev-
+commonLabels:
+  app: web
+# Unknown to kustomize.
+custom: x
+`
+
+const deployment = `spec:
+  replicas: 1
+kind: Deployment
+metadata:
+  name: web
+apiVersion: apps/v1
+`
+
+func makeFs(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("app/overlay"))
+	require.NoError(t, fSys.WriteFile("app/kustomization.yaml", []byte(unformatted)))
+	require.NoError(t, fSys.WriteFile("app/deployment.yaml", []byte(deployment)))
+	require.NoError(t, fSys.WriteFile("app/overlay/kustomization.yaml", []byte(formatted)))
+	return fSys
+}
+
+func TestFormat(t *testing.T) {
+	fSys := makeFs(t)
+	cmd := format.NewCmdFormat(fSys, new(bytes.Buffer))
+	require.NoError(t, cmd.RunE(cmd, []string{"app"}))
+	content, err := fSys.ReadFile("app/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(content))
+	content, err = fSys.ReadFile("app/deployment.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, deployment, string(content))
+}
+
+func TestFormatResources(t *testing.T) {
+	fSys := makeFs(t)
+	cmd := format.NewCmdFormat(fSys, new(bytes.Buffer))
+	require.NoError(t, cmd.Flags().Set("resources", "true"))
+	require.NoError(t, cmd.RunE(cmd, []string{"app"}))
+	content, err := fSys.ReadFile("app/deployment.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`, string(content))
+}
+
+func TestFormatCheck(t *testing.T) {
+	fSys := makeFs(t)
+	out := new(bytes.Buffer)
+	cmd := format.NewCmdFormat(fSys, out)
+	require.NoError(t, cmd.Flags().Set("check", "true"))
+	assert.Equal(t, format.ErrNotFormatted, cmd.RunE(cmd, []string{"app"}))
+	assert.Regexp(t, "^/?app/kustomization.yaml\n$", out.String())
+	content, err := fSys.ReadFile("app/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, unformatted, string(content))
+
+	out.Reset()
+	assert.NoError(t, cmd.RunE(cmd, []string{"app/overlay"}))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,292 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+const (
+	formatDot     = "dot"
+	formatMermaid = "mermaid"
+
+	// dependsOnAnnotation lists the objects an object
+	// depends on, as understood by kpt and cli-utils.
+	dependsOnAnnotation = "config.kubernetes.io/depends-on"
+)
+
+type graphOptions struct {
+	kustomizationPath string
+	format            string
+	resources         bool
+}
+
+// NewCmdGraph makes a new graph command.
+func NewCmdGraph(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o graphOptions
+	c := &cobra.Command{
+		Use:   "graph DIR",
+		Short: "Print the graph of kustomizations, and optionally resources, in DOT or Mermaid",
+		Long: `Print the graph of kustomizations, and optionally resources, in DOT or Mermaid.
+
+The graph has an edge from each kustomization to the bases
+and components it includes.  Remote kustomizations are shown,
+but not followed.  With --resources, the graph also holds the
+resources of the build output, with an edge from each resource
+to the resources it refers to by name, e.g. a Deployment to its
+ConfigMaps, or lists in its '` + dependsOnAnnotation + `'
+annotation.
+
+If DIR is omitted, '.' is assumed.
+`,
+		Example: `
+	kustomize graph overlays/production | dot -Tsvg > production.svg
+	kustomize graph overlays/production --resources --format mermaid
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVar(&o.format, "format", formatDot,
+		"the format of the graph, one of dot or mermaid")
+	c.Flags().BoolVar(&o.resources, "resources", false,
+		"add the resources of the build output, and their references, to the graph")
+	build.AddFlagLoadRestrictor(c.Flags())
+	build.AddFlagEnablePlugins(c.Flags())
+	build.AddFlagEnableHelm(c.Flags())
+	build.AddFlagEnableJsonnet(c.Flags())
+	build.AddFlagEnableDataSources(c.Flags())
+	build.AddFlagEnableOpa(c.Flags())
+	build.AddFlagEnableSops(c.Flags())
+	build.AddFlagDiscovery(c.Flags())
+	build.AddFlagSchemaFromCluster(c.Flags())
+	return c
+}
+
+// Validate validates graph command args and flags.
+func (o *graphOptions) Validate(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("specify one kustomization directory")
+	}
+	o.kustomizationPath = filesys.SelfDir
+	if len(args) == 1 {
+		o.kustomizationPath = args[0]
+	}
+	if o.format != formatDot && o.format != formatMermaid {
+		return fmt.Errorf(
+			"unknown format %q; expected dot or mermaid", o.format)
+	}
+	return nil
+}
+
+// Run prints the graph.
+func (o *graphOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	g := newGraph()
+	dir := filepath.Clean(o.kustomizationPath)
+	if err := g.addKustomizations(fSys, dir); err != nil {
+		return err
+	}
+	if o.resources {
+		opts := build.HonorKustomizeFlags(krusty.MakeDefaultOptions())
+		// The build annotations record the references.
+		opts.KeepBuildAnnotations = true
+		m, err := krusty.MakeKustomizer(opts).Run(fSys, o.kustomizationPath)
+		if err != nil {
+			return err
+		}
+		g.addResources(m)
+	}
+	if o.format == formatMermaid {
+		g.writeMermaid(w)
+	} else {
+		g.writeDot(w)
+	}
+	return nil
+}
+
+type edge struct {
+	from, to int
+	label    string
+}
+
+// graph holds nodes, identified by their labels, and edges.
+type graph struct {
+	labels []string
+	index  map[string]int
+	edges  []edge
+	seen   map[edge]bool
+}
+
+func newGraph() *graph {
+	return &graph{index: make(map[string]int), seen: make(map[edge]bool)}
+}
+
+// node returns the number of the node with the label,
+// adding it if needed.
+func (g *graph) node(label string) int {
+	if n, ok := g.index[label]; ok {
+		return n
+	}
+	g.labels = append(g.labels, label)
+	g.index[label] = len(g.labels) - 1
+	return len(g.labels) - 1
+}
+
+func (g *graph) edge(from, to int, label string) {
+	e := edge{from: from, to: to, label: label}
+	if !g.seen[e] {
+		g.seen[e] = true
+		g.edges = append(g.edges, e)
+	}
+}
+
+// addKustomizations adds the kustomization in dir, and
+// all local kustomizations it includes, to the graph.
+func (g *graph) addKustomizations(fSys filesys.FileSystem, dir string) error {
+	from, known := g.index[dir]
+	if known {
+		return nil
+	}
+	from = g.node(dir)
+	var k types.Kustomization
+	found := false
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		content, err := fSys.ReadFile(filepath.Join(dir, n))
+		if err != nil {
+			continue
+		}
+		if err = k.Unmarshal(content); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Join(dir, n), err)
+		}
+		found = true
+		break
+	}
+	if !found {
+		return fmt.Errorf("no kustomization file found in %s", dir)
+	}
+	k.FixKustomizationPostUnmarshalling()
+	for _, x := range []struct {
+		label   string
+		entries []string
+	}{{"resource", k.Resources}, {"component", k.Components}} {
+		for _, entry := range x.entries {
+			sub := filepath.Join(dir, entry)
+			if fSys.IsDir(sub) {
+				if err := g.addKustomizations(fSys, sub); err != nil {
+					return err
+				}
+				g.edge(from, g.index[sub], x.label)
+			} else if strings.Contains(entry, "://") || strings.HasPrefix(entry, "github.com/") {
+				g.edge(from, g.node(entry), x.label)
+			}
+		}
+	}
+	return nil
+}
+
+// addResources adds the resources to the graph, with edges
+// for their references to one another.
+func (g *graph) addResources(m resmap.ResMap) {
+	resources := m.Resources()
+	for _, r := range resources {
+		g.node(describe(r.CurId()))
+	}
+	for _, r := range resources {
+		to := g.index[describe(r.CurId())]
+		for _, id := range r.GetRefBy() {
+			if referrer := find(resources, id); referrer != nil {
+				g.edge(g.index[describe(referrer.CurId())], to, "references")
+			}
+		}
+		from := g.index[describe(r.CurId())]
+		for _, id := range dependsOn(r) {
+			label := describe(id)
+			if target := find(resources, id); target != nil {
+				label = describe(target.CurId())
+			}
+			g.edge(from, g.node(label), "depends on")
+		}
+	}
+}
+
+// find returns the resource that has, or once had, the id.
+func find(resources []*resource.Resource, id resid.ResId) *resource.Resource {
+	for _, r := range resources {
+		for _, rid := range append(r.PrevIds(), r.CurId()) {
+			if rid.Kind == id.Kind && rid.Name == id.Name &&
+				(id.Group == "" || rid.Group == id.Group) &&
+				rid.IsNsEquals(id) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// dependsOn parses the depends-on annotation, which lists
+// objects as group/kind/name or
+// group/namespaces/namespace/kind/name.
+func dependsOn(r *resource.Resource) []resid.ResId {
+	var ids []resid.ResId
+	value := r.GetAnnotations()[dependsOnAnnotation]
+	for _, s := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(s), "/")
+		switch {
+		case len(parts) == 3:
+			ids = append(ids, resid.NewResId(
+				resid.Gvk{Group: parts[0], Kind: parts[1]}, parts[2]))
+		case len(parts) == 5 && parts[1] == "namespaces":
+			ids = append(ids, resid.NewResIdWithNamespace(
+				resid.Gvk{Group: parts[0], Kind: parts[3]}, parts[4], parts[2]))
+		}
+	}
+	return ids
+}
+
+// describe returns e.g. "Deployment prod/app".
+func describe(id resid.ResId) string {
+	if id.Namespace == "" {
+		return id.Kind + " " + id.Name
+	}
+	return id.Kind + " " + id.Namespace + "/" + id.Name
+}
+
+func (g *graph) writeDot(w io.Writer) {
+	fmt.Fprintln(w, "digraph kustomize {")
+	for _, l := range g.labels {
+		fmt.Fprintf(w, "  %q;\n", l)
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  %q -> %q [label=%q];\n",
+			g.labels[e.from], g.labels[e.to], e.label)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *graph) writeMermaid(w io.Writer) {
+	fmt.Fprintln(w, "graph TD")
+	for i, l := range g.labels {
+		fmt.Fprintf(w, "  n%d[\"%s\"]\n", i, strings.ReplaceAll(l, `"`, "#quot;"))
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  n%d -->|%s| n%d\n", e.from, e.label, e.to)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/graph"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeTree(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"base/kustomization.yaml": `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - a=b
+`,
+		"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/depends-on: /namespaces/prod/Service/db
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: settings
+`,
+		"component/kustomization.yaml": `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+`,
+		"overlay/kustomization.yaml": `
+namespace: prod
+namePrefix: prod-
+resources:
+- ../base
+- https://github.com/example/repo//db?ref=v1
+components:
+- ../component
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return fSys
+}
+
+func TestGraphDot(t *testing.T) {
+	var out bytes.Buffer
+	cmd := graph.NewCmdGraph(writeTree(t), &out)
+	require.NoError(t, cmd.RunE(cmd, []string{"overlay"}))
+	assert.Equal(t, `digraph kustomize {
+  "overlay";
+  "base";
+  "https://github.com/example/repo//db?ref=v1";
+  "component";
+  "overlay" -> "base" [label="resource"];
+  "overlay" -> "https://github.com/example/repo//db?ref=v1" [label="resource"];
+  "overlay" -> "component" [label="component"];
+}
+`, out.String())
+}
+
+func TestGraphResourcesMermaid(t *testing.T) {
+	fSys := writeTree(t)
+	require.NoError(t, fSys.WriteFile("overlay/kustomization.yaml", []byte(`
+namespace: prod
+namePrefix: prod-
+resources:
+- ../base
+`)))
+	var out bytes.Buffer
+	cmd := graph.NewCmdGraph(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("format", "mermaid"))
+	require.NoError(t, cmd.Flags().Set("resources", "true"))
+	require.NoError(t, cmd.RunE(cmd, []string{"overlay"}))
+	assert.Equal(t, `graph TD
+  n0["overlay"]
+  n1["base"]
+  n2["Deployment prod/prod-app"]
+  n3["ConfigMap prod/prod-settings-4h2mbtbbt6"]
+  n4["Service prod/db"]
+  n0 -->|resource| n1
+  n2 -->|depends on| n4
+  n2 -->|references| n3
+`, out.String())
+}
@@ -0,0 +1,449 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// composeNameLabel selects the objects of a compose service.
+const composeNameLabel = "app.kubernetes.io/name"
+
+type importComposeOptions struct {
+	composeFile string
+	outputPath  string
+}
+
+func newCmdImportCompose(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o importComposeOptions
+	c := &cobra.Command{
+		Use:   "compose [FILE]",
+		Short: "Imports a docker compose file as a kustomize base",
+		Long: `Imports a docker compose file as a kustomize base.
+
+Each service of the file becomes a Deployment, a Service if
+it has ports, and a ConfigMap holding its environment, if
+any, read from its environment and env_file entries.  As
+with 'kustomize import helm', each object is written to a
+file of its own in the --output directory, along with a
+kustomization listing the files and setting the images.
+
+Only services are imported, with their image, entrypoint,
+command, environment, ports, labels and replicas; volumes,
+networks and builds aren't, and are reported.  FILE
+defaults to docker-compose.yaml.
+`,
+		Example: `
+	kustomize import compose docker-compose.yaml --output base
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVarP(&o.outputPath, "output", "o", filesys.SelfDir,
+		"the directory to write the base to")
+	return c
+}
+
+// Validate validates import compose command.
+func (o *importComposeOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		o.composeFile = "docker-compose.yaml"
+	case 1:
+		o.composeFile = args[0]
+	default:
+		return fmt.Errorf("specify at most one compose file")
+	}
+	return nil
+}
+
+// Run converts the services and writes the base.
+func (o *importComposeOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	if err := checkNoKustomization(fSys, o.outputPath); err != nil {
+		return err
+	}
+	m, err := convertCompose(fSys, o.composeFile, w)
+	if err != nil {
+		return err
+	}
+	if err = fSys.MkdirAll(o.outputPath); err != nil {
+		return err
+	}
+	files, err := writeBase(fSys, o.outputPath, m)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %d resources, and %s, to %s\n",
+		len(files), konfig.DefaultKustomizationFileName(), o.outputPath)
+	return nil
+}
+
+// composeFile is the part of a docker compose file that's
+// imported.  The fields with several syntaxes are decoded
+// by the conversion.
+type composeFile struct {
+	Services map[string]composeService `json:"services"`
+}
+
+type composeService struct {
+	Image       string            `json:"image"`
+	Build       interface{}       `json:"build"`
+	Entrypoint  interface{}       `json:"entrypoint"`
+	Command     interface{}       `json:"command"`
+	Environment interface{}       `json:"environment"`
+	EnvFile     interface{}       `json:"env_file"`
+	Ports       []interface{}     `json:"ports"`
+	Labels      map[string]string `json:"labels"`
+	Volumes     []interface{}     `json:"volumes"`
+	Networks    interface{}       `json:"networks"`
+	Deploy      struct {
+		Replicas *int `json:"replicas"`
+	} `json:"deploy"`
+}
+
+// composePort is a port of a compose service.
+type composePort struct {
+	target    int
+	published int
+	protocol  string
+}
+
+// convertCompose returns the objects of the services of the
+// compose file, in the order of their names, reporting to w
+// what isn't imported.
+func convertCompose(
+	fSys filesys.FileSystem, path string, w io.Writer) (resmap.ResMap, error) {
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f composeFile
+	if err = yaml.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("reading compose file %s: %w", path, err)
+	}
+	if len(f.Services) == 0 {
+		return nil, fmt.Errorf("compose file %s has no services", path)
+	}
+	names := make([]string, 0, len(f.Services))
+	for n := range f.Services {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	m := resmap.New()
+	for _, n := range names {
+		objects, err := convertComposeService(
+			fSys, filepath.Dir(path), n, f.Services[n], w)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", n, err)
+		}
+		for _, obj := range objects {
+			if err = m.Append(rf.FromMap(obj)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// convertComposeService returns the ConfigMap, Deployment and
+// Service of a compose service, those it needs.
+func convertComposeService(fSys filesys.FileSystem, dir, service string,
+	s composeService, w io.Writer) ([]map[string]interface{}, error) {
+	if s.Image == "" {
+		return nil, fmt.Errorf("no image; build and push it, then set its image")
+	}
+	if s.Build != nil {
+		fmt.Fprintf(w, "warning: service %s: build isn't imported\n", service)
+	}
+	if len(s.Volumes) > 0 {
+		fmt.Fprintf(w, "warning: service %s: volumes aren't imported\n", service)
+	}
+	if s.Networks != nil {
+		fmt.Fprintf(w, "warning: service %s: networks aren't imported\n", service)
+	}
+	name := strings.ToLower(strings.ReplaceAll(service, "_", "-"))
+	labels := map[string]interface{}{composeNameLabel: name}
+	var objects []map[string]interface{}
+
+	container := map[string]interface{}{"name": name, "image": s.Image}
+	entrypoint, err := composeCommand(s.Entrypoint)
+	if err != nil {
+		return nil, fmt.Errorf("entrypoint: %w", err)
+	}
+	if entrypoint != nil {
+		container["command"] = entrypoint
+	}
+	command, err := composeCommand(s.Command)
+	if err != nil {
+		return nil, fmt.Errorf("command: %w", err)
+	}
+	if command != nil {
+		container["args"] = command
+	}
+	env, err := composeEnvironment(fSys, dir, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(env) > 0 {
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":   name + "-env",
+				"labels": labels,
+			},
+			"data": env,
+		})
+		container["envFrom"] = []interface{}{
+			map[string]interface{}{
+				"configMapRef": map[string]interface{}{"name": name + "-env"},
+			},
+		}
+	}
+	ports, err := composePorts(s.Ports)
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) > 0 {
+		containerPorts := make([]interface{}, len(ports))
+		for i, p := range ports {
+			containerPorts[i] = map[string]interface{}{
+				"containerPort": p.target,
+				"protocol":      p.protocol,
+			}
+		}
+		container["ports"] = containerPorts
+	}
+
+	podLabels := map[string]interface{}{}
+	for k, v := range s.Labels {
+		podLabels[k] = v
+	}
+	podLabels[composeNameLabel] = name
+	spec := map[string]interface{}{
+		"selector": map[string]interface{}{"matchLabels": labels},
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": podLabels},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{container},
+			},
+		},
+	}
+	if s.Deploy.Replicas != nil {
+		spec["replicas"] = *s.Deploy.Replicas
+	}
+	objects = append(objects, map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": labels,
+		},
+		"spec": spec,
+	})
+
+	if len(ports) > 0 {
+		servicePorts := make([]interface{}, len(ports))
+		for i, p := range ports {
+			portName := strconv.Itoa(p.published)
+			if p.protocol != "TCP" {
+				portName += "-" + strings.ToLower(p.protocol)
+			}
+			servicePorts[i] = map[string]interface{}{
+				"name":       portName,
+				"port":       p.published,
+				"targetPort": p.target,
+				"protocol":   p.protocol,
+			}
+		}
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":   name,
+				"labels": labels,
+			},
+			"spec": map[string]interface{}{
+				"selector": labels,
+				"ports":    servicePorts,
+			},
+		})
+	}
+	return objects, nil
+}
+
+// composeCommand returns the arguments of an entrypoint or a
+// command, either a list or a string split on spaces.
+func composeCommand(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		var args []interface{}
+		for _, a := range strings.Fields(v) {
+			args = append(args, a)
+		}
+		return args, nil
+	case []interface{}:
+		args := make([]interface{}, len(v))
+		for i, a := range v {
+			args[i] = fmt.Sprint(a)
+		}
+		return args, nil
+	default:
+		return nil, fmt.Errorf("expected a string or a list, got %v", v)
+	}
+}
+
+// composeEnvironment returns the environment of the service,
+// that of its env files, in order, then of its environment,
+// written as a map or as a list of NAME=VALUE.  Variables
+// without values, taken from the host by compose, are empty.
+func composeEnvironment(fSys filesys.FileSystem, dir string,
+	s composeService) (map[string]interface{}, error) {
+	env := make(map[string]interface{})
+	var envFiles []interface{}
+	switch v := s.EnvFile.(type) {
+	case nil:
+	case string:
+		envFiles = []interface{}{v}
+	case []interface{}:
+		envFiles = v
+	default:
+		return nil, fmt.Errorf("env_file: expected a string or a list, got %v", v)
+	}
+	for _, f := range envFiles {
+		path := fmt.Sprint(f)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("env_file: %w", err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			k, v := splitEnvVar(line)
+			env[k] = v
+		}
+	}
+	switch v := s.Environment.(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, value := range v {
+			if value == nil {
+				env[k] = ""
+			} else {
+				env[k] = fmt.Sprint(value)
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			k, value := splitEnvVar(fmt.Sprint(e))
+			env[k] = value
+		}
+	default:
+		return nil, fmt.Errorf("environment: expected a map or a list, got %v", v)
+	}
+	return env, nil
+}
+
+func splitEnvVar(s string) (string, string) {
+	if i := strings.Index(s, "="); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// composePorts returns the ports of the service, written as
+// [[IP:]PUBLISHED:]TARGET[/PROTOCOL], or as maps.  A port
+// that isn't published is published as its target.
+func composePorts(values []interface{}) ([]composePort, error) {
+	var ports []composePort
+	for _, value := range values {
+		var p composePort
+		var err error
+		switch v := value.(type) {
+		case float64:
+			p.target = int(v)
+		case string:
+			p, err = parseComposePort(v)
+		case map[string]interface{}:
+			p.target, err = composePortNumber(v["target"])
+			if err == nil && v["published"] != nil {
+				p.published, err = composePortNumber(v["published"])
+			}
+			if protocol, ok := v["protocol"].(string); ok {
+				p.protocol = protocol
+			}
+		default:
+			err = fmt.Errorf("expected a string or a map, got %v", v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ports: %w", err)
+		}
+		if p.published == 0 {
+			p.published = p.target
+		}
+		if p.protocol == "" {
+			p.protocol = "tcp"
+		}
+		p.protocol = strings.ToUpper(p.protocol)
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+func parseComposePort(s string) (composePort, error) {
+	var p composePort
+	if i := strings.Index(s, "/"); i >= 0 {
+		s, p.protocol = s[:i], s[i+1:]
+	}
+	parts := strings.Split(s, ":")
+	var err error
+	p.target, err = composePortNumber(parts[len(parts)-1])
+	if err != nil {
+		return p, err
+	}
+	if len(parts) > 1 && parts[len(parts)-2] != "" {
+		p.published, err = composePortNumber(parts[len(parts)-2])
+	}
+	return p, err
+}
+
+func composePortNumber(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 65535 {
+			return 0, fmt.Errorf("port %q isn't a port number; ranges aren't imported", v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("port %v isn't a port number", v)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestImportCompose(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app"))
+	require.NoError(t, fSys.WriteFile("/app/docker-compose.yaml", []byte(`
+services:
+  web_app:
+    image: example/web:1.2
+    command: serve --verbose
+    env_file: web.env
+    environment:
+      LOG_LEVEL: debug
+      WORKERS: 4
+    ports:
+    - "8080:80"
+    - 9090/udp
+    deploy:
+      replicas: 2
+  db:
+    image: postgres:14
+    volumes:
+    - data:/var/lib/postgresql/data
+    environment:
+    - POSTGRES_DB=web
+`)))
+	require.NoError(t, fSys.WriteFile("/app/web.env", []byte(`
+# the database
+DB_HOST=db
+LOG_LEVEL=info
+`)))
+
+	var out bytes.Buffer
+	cmd := newCmdImportCompose(fSys, &out)
+	cmd.SetArgs([]string{"/app/docker-compose.yaml", "--output", "/base"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, `warning: service db: volumes aren't imported
+Wrote 5 resources, and kustomization.yaml, to /base
+`, out.String())
+
+	content, err := fSys.ReadFile("/base/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- configmap_db-env.yaml
+- deployment_db.yaml
+- configmap_web-app-env.yaml
+- deployment_web-app.yaml
+- service_web-app.yaml
+images:
+- name: postgres
+  newTag: "14"
+- name: example/web
+  newTag: "1.2"
+`, string(content))
+	content, err = fSys.ReadFile("/base/configmap_web-app-env.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+data:
+  DB_HOST: db
+  LOG_LEVEL: debug
+  WORKERS: "4"
+kind: ConfigMap
+metadata:
+  labels:
+    app.kubernetes.io/name: web-app
+  name: web-app-env
+`, string(content))
+	content, err = fSys.ReadFile("/base/deployment_web-app.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app.kubernetes.io/name: web-app
+  name: web-app
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: web-app
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: web-app
+    spec:
+      containers:
+      - args:
+        - serve
+        - --verbose
+        envFrom:
+        - configMapRef:
+            name: web-app-env
+        image: example/web:1.2
+        name: web-app
+        ports:
+        - containerPort: 80
+          protocol: TCP
+        - containerPort: 9090
+          protocol: UDP
+`, string(content))
+	content, err = fSys.ReadFile("/base/service_web-app.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app.kubernetes.io/name: web-app
+  name: web-app
+spec:
+  ports:
+  - name: "8080"
+    port: 8080
+    protocol: TCP
+    targetPort: 80
+  - name: 9090-udp
+    port: 9090
+    protocol: UDP
+    targetPort: 9090
+  selector:
+    app.kubernetes.io/name: web-app
+`, string(content))
+}
+
+func TestImportComposeErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("docker-compose.yaml", []byte(`
+services:
+  web:
+    build: .
+`)))
+	cmd := newCmdImportCompose(fSys, &bytes.Buffer{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--output", "/base"})
+	assert.EqualError(t, cmd.Execute(),
+		"service web: no image; build and push it, then set its image")
+
+	require.NoError(t, fSys.WriteFile("docker-compose.yaml", []byte(`
+services:
+  web:
+    image: nginx
+    ports:
+    - "8000-8010:80"
+`)))
+	assert.EqualError(t, cmd.Execute(),
+		`service web: ports: port "8000-8010" isn't a port number; ranges aren't imported`)
+}
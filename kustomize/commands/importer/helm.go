@@ -0,0 +1,279 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/image"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type importHelmOptions struct {
+	chart       string
+	repo        string
+	version     string
+	releaseName string
+	namespace   string
+	valuesFile  string
+	includeCRDs bool
+	helmCommand string
+	outputPath  string
+}
+
+func newCmdImportHelm(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o importHelmOptions
+	c := &cobra.Command{
+		Use:   "helm CHART",
+		Short: "Imports a helm chart as a kustomize base",
+		Long: `Imports a helm chart as a kustomize base.
+
+The chart is inflated once, with 'helm template', and each
+object of the output is written to a file of its own in the
+--output directory, along with a kustomization listing the
+files.  The kustomization also sets the images the objects
+use to their current tags, as a starting point for changing
+them with 'kustomize edit set image'.
+
+CHART is a local chart directory, or with --repo the name
+of a chart in that repository.
+`,
+		Example: `
+	kustomize import helm ./charts/web --values values.yaml --output base
+	kustomize import helm minecraft --repo https://itzg.github.io/minecraft-server-charts --version 3.1.3 --output base
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVar(&o.repo, "repo", "",
+		"the URL of the repository holding the chart")
+	c.Flags().StringVar(&o.version, "version", "",
+		"the version of the chart")
+	c.Flags().StringVar(&o.releaseName, "release-name", "",
+		"the name of the release")
+	c.Flags().StringVar(&o.namespace, "namespace", "",
+		"the namespace of the release")
+	c.Flags().StringVar(&o.valuesFile, "values", "",
+		"a values file to use instead of the default values of the chart")
+	c.Flags().BoolVar(&o.includeCRDs, "include-crds", false,
+		"include the CustomResourceDefinitions of the chart")
+	c.Flags().StringVar(&o.helmCommand, "helm-command", "helm",
+		"helm command (path to executable)")
+	c.Flags().StringVarP(&o.outputPath, "output", "o", filesys.SelfDir,
+		"the directory to write the base to")
+	return c
+}
+
+// Validate validates import helm command.
+func (o *importHelmOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("specify one chart")
+	}
+	o.chart = args[0]
+	return nil
+}
+
+// Run inflates the chart and writes the base.
+func (o *importHelmOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	if err := checkNoKustomization(fSys, o.outputPath); err != nil {
+		return err
+	}
+	m, err := o.inflate(fSys)
+	if err != nil {
+		return err
+	}
+	if err = fSys.MkdirAll(o.outputPath); err != nil {
+		return err
+	}
+	files, err := writeBase(fSys, o.outputPath, m)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %d resources, and %s, to %s\n",
+		len(files), konfig.DefaultKustomizationFileName(), o.outputPath)
+	return nil
+}
+
+func checkNoKustomization(fSys filesys.FileSystem, dir string) error {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if fSys.Exists(filepath.Join(dir, n)) {
+			return fmt.Errorf("kustomization file already exists in %s", dir)
+		}
+	}
+	return nil
+}
+
+// inflate builds a kustomization, in a temporary directory,
+// holding only the chart.
+func (o *importHelmOptions) inflate(fSys filesys.FileSystem) (resmap.ResMap, error) {
+	chart := types.HelmChart{
+		Name:        o.chart,
+		Version:     o.version,
+		Repo:        o.repo,
+		ReleaseName: o.releaseName,
+		Namespace:   o.namespace,
+		IncludeCRDs: o.includeCRDs,
+	}
+	var globals types.HelmGlobals
+	if o.repo == "" {
+		if !fSys.IsDir(o.chart) {
+			return nil, fmt.Errorf(
+				"chart %s is not a local directory; give its repository with --repo", o.chart)
+		}
+		dir, err := filepath.Abs(o.chart)
+		if err != nil {
+			return nil, err
+		}
+		globals.ChartHome = filepath.Dir(dir)
+		chart.Name = filepath.Base(dir)
+	}
+	if o.valuesFile != "" {
+		values, err := filepath.Abs(o.valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		chart.ValuesFile = values
+	}
+	dir, err := makeTempDir(fSys)
+	if err != nil {
+		return nil, err
+	}
+	defer fSys.RemoveAll(dir)
+	if globals.ChartHome == "" {
+		globals.ChartHome = filepath.Join(dir, "charts")
+	}
+	k := types.Kustomization{
+		HelmGlobals: &globals,
+		HelmCharts:  []types.HelmChart{chart},
+	}
+	k.FixKustomizationPostUnmarshalling()
+	b, err := yaml.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+	err = fSys.WriteFile(
+		filepath.Join(dir, konfig.DefaultKustomizationFileName()), b)
+	if err != nil {
+		return nil, err
+	}
+	opts := krusty.MakeDefaultOptions()
+	// The chart and values are outside the temporary directory.
+	opts.LoadRestrictions = types.LoadRestrictionsNone
+	opts.PluginConfig.HelmConfig.Enabled = true
+	opts.PluginConfig.HelmConfig.Command = o.helmCommand
+	return krusty.MakeKustomizer(opts).Run(fSys, dir)
+}
+
+func makeTempDir(fSys filesys.FileSystem) (string, error) {
+	for {
+		dir := filepath.Join(os.TempDir(),
+			fmt.Sprintf("kustomize-import-%d", rand.Int63()))
+		if fSys.Exists(dir) {
+			continue
+		}
+		return dir, fSys.MkdirAll(dir)
+	}
+}
+
+// writeBase writes each resource to a file of its own in
+// dir, and a kustomization listing the files, and setting
+// the images of the resources.  It returns the file names.
+func writeBase(
+	fSys filesys.FileSystem, dir string, m resmap.ResMap) ([]string, error) {
+	var files []string
+	written := make(map[string]bool)
+	var images []types.Image
+	for _, r := range m.Resources() {
+		base := strings.ToLower(r.GetKind() + "_" + r.GetName())
+		name := base
+		for i := 2; written[name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		written[name] = true
+		b, err := r.AsYAML()
+		if err != nil {
+			return nil, err
+		}
+		if err = fSys.WriteFile(filepath.Join(dir, name+".yaml"), b); err != nil {
+			return nil, err
+		}
+		files = append(files, name+".yaml")
+		images = appendImages(images, r.YNode())
+	}
+	k := types.Kustomization{Resources: files, Images: images}
+	k.FixKustomizationPostUnmarshalling()
+	b, err := kustfile.Marshal(&k)
+	if err != nil {
+		return nil, err
+	}
+	return files, fSys.WriteFile(
+		filepath.Join(dir, konfig.DefaultKustomizationFileName()), b)
+}
+
+// appendImages appends the images of the containers in the
+// object to images, unless they're there already.
+func appendImages(images []types.Image, object *yaml.Node) []types.Image {
+	for _, s := range containerImages(object) {
+		name, tag, digest := image.Split(s)
+		img := types.Image{Name: name, NewTag: tag}
+		if digest != "" {
+			img = types.Image{Name: name, Digest: digest}
+		}
+		known := false
+		for _, i := range images {
+			if i.Name == img.Name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// containerImages returns the images of all containers
+// below the node.
+func containerImages(n *yaml.Node) []string {
+	var images []string
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i].Value, n.Content[i+1]
+			if (key == "containers" || key == "initContainers") &&
+				value.Kind == yaml.SequenceNode {
+				for _, c := range value.Content {
+					if img := yaml.NewRNode(c).Field("image"); img != nil {
+						images = append(images, yaml.GetValue(img.Value))
+					}
+				}
+				continue
+			}
+			images = append(images, containerImages(value)...)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			images = append(images, containerImages(c)...)
+		}
+	}
+	return images
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestWriteBase(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/chart"))
+	require.NoError(t, fSys.WriteFile("/chart/kustomization.yaml", []byte(`
+resources:
+- objects.yaml
+`)))
+	require.NoError(t, fSys.WriteFile("/chart/objects.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+      containers:
+      - name: web
+        image: nginx:1.21
+      - name: sidecar
+        image: nginx:1.20
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: other
+`)))
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, "/chart")
+	require.NoError(t, err)
+
+	require.NoError(t, fSys.MkdirAll("/base"))
+	files, err := writeBase(fSys, "/base", m)
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]string{"deployment_web.yaml", "service_web.yaml", "service_web_2.yaml"}, files)
+	content, err := fSys.ReadFile("/base/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment_web.yaml
+- service_web.yaml
+- service_web_2.yaml
+images:
+- digest: sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+  name: busybox
+- name: nginx
+  newTag: "1.21"
+`, string(content))
+	content, err = fSys.ReadFile("/base/service_web_2.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: other
+`, string(content))
+}
+
+func TestImportHelmErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/base"))
+	require.NoError(t, fSys.WriteFile("/base/kustomization.yaml", []byte(``)))
+
+	cmd := newCmdImportHelm(fSys, &bytes.Buffer{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"web", "--output", "/base"})
+	assert.EqualError(t, cmd.Execute(), "kustomization file already exists in /base")
+	cmd.SetArgs([]string{"web", "--output", "/other"})
+	assert.EqualError(t, cmd.Execute(),
+		"chart web is not a local directory; give its repository with --repo")
+}
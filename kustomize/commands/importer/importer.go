@@ -0,0 +1,33 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// NewCmdImport returns an instance of 'import' subcommand.
+func NewCmdImport(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "import",
+		Short: "Imports configuration from other tools as a kustomization",
+		Long:  "",
+		Example: `
+	# Imports a local helm chart as a kustomize base
+	kustomize import helm ./charts/web --values values.yaml --output base
+
+	# Imports the services of a docker compose file as a kustomize base
+	kustomize import compose docker-compose.yaml --output base
+`,
+		Args: cobra.MinimumNArgs(1),
+	}
+	c.AddCommand(
+		newCmdImportHelm(fSys, w),
+		newCmdImportCompose(fSys, w),
+	)
+	return c
+}
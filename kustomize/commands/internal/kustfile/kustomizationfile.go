@@ -43,6 +43,8 @@ func determineFieldOrder() []string {
 		"Bases",
 		"NamePrefix",
 		"NameSuffix",
+		"NamePrefixes",
+		"NameSuffixes",
 		"Namespace",
 		"Crds",
 		"CommonLabels",
@@ -53,9 +55,11 @@ func determineFieldOrder() []string {
 		"Patches",
 		"ConfigMapGenerator",
 		"SecretGenerator",
+		"DataSources",
 		"HelmCharts",
 		"HelmChartInflationGenerator",
 		"HelmGlobals",
+		"Jsonnet",
 		"GeneratorOptions",
 		"Vars",
 		"Images",
@@ -64,10 +68,13 @@ func determineFieldOrder() []string {
 		"Configurations",
 		"Generators",
 		"Transformers",
+		"ExternalReferences",
 		"Inventory",
 		"Components",
+		"Conditionals",
 		"OpenAPI",
 		"BuildMetadata",
+		"SortOptions",
 	}
 
 	// Add deprecated fields here.
@@ -93,6 +100,21 @@ func determineFieldOrder() []string {
 	return result
 }
 
+// FieldOrder returns the keys of the kustomization fields, as
+// they appear in a file, in the preferred order.
+func FieldOrder() []string {
+	t := reflect.TypeOf(types.Kustomization{})
+	var keys []string
+	for _, name := range fieldMarshallingOrder {
+		f, ok := t.FieldByName(name)
+		if !ok {
+			continue
+		}
+		keys = append(keys, strings.Split(f.Tag.Get("json"), ",")[0])
+	}
+	return keys
+}
+
 // commentedField records the comment associated with a kustomization field
 // field has to be a recognized kustomization field
 // comment can be empty
@@ -229,6 +251,12 @@ func (mf *kustomizationFile) parseCommentedFields(content []byte) error {
 	return nil
 }
 
+// Marshal converts a kustomization to a byte stream, with
+// its fields in the order used for new kustomization files.
+func Marshal(kustomization *types.Kustomization) ([]byte, error) {
+	return (&kustomizationFile{}).marshal(kustomization)
+}
+
 // marshal converts a kustomization to a byte stream.
 func (mf *kustomizationFile) marshal(kustomization *types.Kustomization) ([]byte, error) {
 	var output []byte
@@ -297,6 +325,30 @@ func marshalField(field string, kustomization *types.Kustomization) ([]byte, err
 		return []byte{}, nil
 	}
 
+	switch field {
+	case "Resources":
+		return marshalObjectEntries("resources", kustomization.Resources,
+			func(e string) (interface{}, bool) {
+				if entry, ok := types.ParseResourceEntry(e); ok {
+					return entry, true
+				}
+				var object map[string]interface{}
+				if !types.IsInlineResource(e) || yaml.Unmarshal([]byte(e), &object) != nil {
+					return nil, false
+				}
+				return map[string]interface{}{"inline": object}, true
+			})
+	case "PatchesStrategicMerge":
+		var entries []string
+		for _, psm := range kustomization.PatchesStrategicMerge {
+			entries = append(entries, string(psm))
+		}
+		return marshalObjectEntries("patchesStrategicMerge", entries,
+			func(e string) (interface{}, bool) {
+				return types.PatchStrategicMerge(e).Entry()
+			})
+	}
+
 	k := &types.Kustomization{}
 	kr := reflect.ValueOf(k)
 	kv := kr.Elem().FieldByName(strings.Title(field))
@@ -305,6 +357,22 @@ func marshalField(field string, kustomization *types.Kustomization) ([]byte, err
 	return yaml.Marshal(k)
 }
 
+// marshalObjectEntries marshals the entries of a list field,
+// writing those held as YAML, e.g. inline resources, as the
+// objects they were written as.
+func marshalObjectEntries(name string, entries []string,
+	asObject func(string) (interface{}, bool)) ([]byte, error) {
+	var list []interface{}
+	for _, e := range entries {
+		if o, ok := asObject(e); ok {
+			list = append(list, o)
+		} else {
+			list = append(list, e)
+		}
+	}
+	return yaml.Marshal(map[string]interface{}{name: list})
+}
+
 func isEmpty(v reflect.Value) bool {
 	// If v is a pointer type
 	if v.Type().Kind() == reflect.Ptr {
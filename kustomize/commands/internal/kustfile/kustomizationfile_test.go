@@ -25,6 +25,8 @@ func TestFieldOrder(t *testing.T) {
 		"Bases",
 		"NamePrefix",
 		"NameSuffix",
+		"NamePrefixes",
+		"NameSuffixes",
 		"Namespace",
 		"Crds",
 		"CommonLabels",
@@ -35,9 +37,11 @@ func TestFieldOrder(t *testing.T) {
 		"Patches",
 		"ConfigMapGenerator",
 		"SecretGenerator",
+		"DataSources",
 		"HelmCharts",
 		"HelmChartInflationGenerator",
 		"HelmGlobals",
+		"Jsonnet",
 		"GeneratorOptions",
 		"Vars",
 		"Images",
@@ -46,10 +50,13 @@ func TestFieldOrder(t *testing.T) {
 		"Configurations",
 		"Generators",
 		"Transformers",
+		"ExternalReferences",
 		"Inventory",
 		"Components",
+		"Conditionals",
 		"OpenAPI",
 		"BuildMetadata",
+		"SortOptions",
 	}
 	actual := determineFieldOrder()
 	if len(expected) != len(actual) {
@@ -423,3 +430,43 @@ foo:
 		t.Fatalf("Expect an unknown field error but got: %v", err)
 	}
 }
+
+func TestPreserveObjectEntries(t *testing.T) {
+	kustomizationContent := []byte(
+		`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- pod.yaml
+- inline:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: flags
+- options:
+    inheritCommonLabels: false
+  path: ../vendor
+patchesStrategicMerge:
+- service.yaml
+- force: true
+  mergeLists: replace
+  path: pod.yaml
+`)
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, kustomizationContent)
+	mf, err := NewKustomizationFile(fSys)
+	if err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	kustomization, err := mf.Read()
+	if err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	if err = mf.Write(kustomization); err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	bytes, _ := fSys.ReadFile(mf.path)
+
+	if diff := cmp.Diff(kustomizationContent, bytes); diff != "" {
+		t.Errorf("Mismatch (-expected, +actual):\n%s", diff)
+	}
+}
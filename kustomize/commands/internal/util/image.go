@@ -0,0 +1,96 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+var (
+	pattern       = regexp.MustCompile(`^(.*):([a-zA-Z0-9._-]*|\*)$`)
+	digestPattern = regexp.MustCompile(`^(.*):(sha256:[a-fA-F0-9]+)$`)
+)
+
+// ErrImageInvalidArgs is returned by ParseImage for an
+// argument in none of the accepted forms.
+var ErrImageInvalidArgs = errors.New(`invalid format of image, use one of the following options:
+- <image>=<newimage>:<newtag>
+- <image>=<newimage>@<digest>
+- <image>=<newimage>
+- <image>:<newtag>
+- <image>@<digest>`)
+
+const separator = "="
+
+type overwrite struct {
+	name   string
+	digest string
+	tag    string
+}
+
+// ParseImage parses an image, as given to 'edit set image'.
+func ParseImage(arg string) (types.Image, error) {
+
+	// matches if there is an image name to overwrite
+	// <image>=<new-image><:|@><new-tag>
+	if s := strings.Split(arg, separator); len(s) == 2 {
+		p, err := parseOverwrite(s[1], true)
+		return types.Image{
+			Name:    s[0],
+			NewName: p.name,
+			NewTag:  p.tag,
+			Digest:  p.digest,
+		}, err
+	}
+
+	// matches only for <tag|digest> overwrites
+	// <image><:|@><new-tag>
+	p, err := parseOverwrite(arg, false)
+	return types.Image{
+		Name:   p.name,
+		NewTag: p.tag,
+		Digest: p.digest,
+	}, err
+}
+
+// parseOverwrite parses the overwrite parameters
+// from the given arg into a struct
+func parseOverwrite(arg string, overwriteImage bool) (overwrite, error) {
+	// match <image>@<digest>
+	if d := strings.Split(arg, "@"); len(d) > 1 {
+		return overwrite{
+			name:   d[0],
+			digest: d[1],
+		}, nil
+	}
+
+	// match <image>:sha256:<hex>, a digest given with
+	// a colon rather than an at sign
+	if d := digestPattern.FindStringSubmatch(arg); len(d) == 3 {
+		return overwrite{
+			name:   d[1],
+			digest: d[2],
+		}, nil
+	}
+
+	// match <image>:<tag>
+	if t := pattern.FindStringSubmatch(arg); len(t) == 3 {
+		return overwrite{
+			name: t[1],
+			tag:  t[2],
+		}, nil
+	}
+
+	// match <image>
+	if len(arg) > 0 && overwriteImage {
+		return overwrite{
+			name: arg,
+		}, nil
+	}
+	return overwrite{}, ErrImageInvalidArgs
+}
@@ -5,10 +5,10 @@ package util
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/logging"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -22,7 +22,7 @@ func GlobPatterns(fSys filesys.FileSystem, patterns []string) ([]string, error)
 			return nil, err
 		}
 		if len(files) == 0 {
-			log.Printf("%s has no match", pattern)
+			logging.Warning("pattern has no match", "pattern", pattern)
 			continue
 		}
 		result = append(result, files...)
@@ -42,7 +42,7 @@ func GlobPatternsWithLoader(fSys filesys.FileSystem, ldr ifc.Loader, patterns []
 		if len(files) == 0 {
 			loader, err := ldr.New(pattern)
 			if err != nil {
-				log.Printf("%s has no match", pattern)
+				logging.Warning("pattern has no match", "pattern", pattern)
 			} else {
 				result = append(result, pattern)
 				if loader != nil {
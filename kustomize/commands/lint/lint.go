@@ -0,0 +1,140 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	formatText = "text"
+	formatJson = "json"
+)
+
+type lintOptions struct {
+	kustomizationPath string
+	format            string
+	severities        map[string]string
+}
+
+// NewCmdLint makes a new lint command.
+func NewCmdLint(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o lintOptions
+	c := &cobra.Command{
+		Use:   "lint DIR",
+		Short: "Check a kustomization and its local bases for likely mistakes",
+		Long: `Check a kustomization and its local bases for likely mistakes.
+
+The rules, and their default severities, are
+` + describeRules() + `
+Findings are printed one per line, or as a JSON list with
+--output json.  The command fails if any finding has the
+severity 'error'.  If DIR is omitted, '.' is assumed.
+`,
+		Example: `
+	kustomize lint overlays/production
+	kustomize lint overlays/production --severity unused-patch=error --output json
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVar(&o.format, "output", formatText,
+		"the format of the findings, one of text or json")
+	c.Flags().StringToStringVar(&o.severities, "severity", nil,
+		"override the severity of a rule, e.g. unused-patch=error; "+
+			"the severity 'off' disables a rule")
+	build.AddFlagLoadRestrictor(c.Flags())
+	build.AddFlagEnablePlugins(c.Flags())
+	build.AddFlagEnableHelm(c.Flags())
+	build.AddFlagEnableJsonnet(c.Flags())
+	build.AddFlagEnableDataSources(c.Flags())
+	build.AddFlagEnableOpa(c.Flags())
+	build.AddFlagEnableSops(c.Flags())
+	build.AddFlagDiscovery(c.Flags())
+	build.AddFlagSchemaFromCluster(c.Flags())
+	return c
+}
+
+// Validate validates lint command args and flags.
+func (o *lintOptions) Validate(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("specify one kustomization directory")
+	}
+	o.kustomizationPath = filesys.SelfDir
+	if len(args) == 1 {
+		o.kustomizationPath = args[0]
+	}
+	if o.format != formatText && o.format != formatJson {
+		return fmt.Errorf(
+			"unknown output format %q; expected text or json", o.format)
+	}
+	for rule, severity := range o.severities {
+		if _, ok := defaultSeverities[rule]; !ok {
+			return fmt.Errorf("unknown rule %q", rule)
+		}
+		switch severity {
+		case severityOff, severityInfo, severityWarning, severityError:
+		default:
+			return fmt.Errorf(
+				"unknown severity %q for rule %s; expected off, info, warning or error",
+				severity, rule)
+		}
+	}
+	return nil
+}
+
+// Run lints the kustomization tree and prints the findings.
+func (o *lintOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	l := &linter{fSys: fSys, visited: make(map[string]bool)}
+	// Findings made before a build failure are printed too.
+	lintErr := l.lintTree(o.kustomizationPath)
+	findings := []Finding{}
+	errCount := 0
+	for _, f := range l.findings {
+		f.Severity = defaultSeverities[f.Rule]
+		if s, ok := o.severities[f.Rule]; ok {
+			f.Severity = s
+		}
+		if f.Severity == severityOff {
+			continue
+		}
+		if f.Severity == severityError {
+			errCount++
+		}
+		findings = append(findings, f)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	if o.format == formatJson {
+		b, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	} else {
+		for _, f := range findings {
+			fmt.Fprintf(w, "%s: %s: [%s] %s\n", f.Severity, f.Path, f.Rule, f.Message)
+		}
+	}
+	if lintErr != nil {
+		return lintErr
+	}
+	if errCount > 0 {
+		return fmt.Errorf("found %d lint error(s)", errCount)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lint_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/lint"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeTree(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"base/kustomization.yaml": `
+namePrefix: base-
+bases:
+- ../common
+resources:
+- deployment.yaml
+`,
+		"base/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`,
+		"common/kustomization.yaml": `
+resources:
+- service.yaml
+`,
+		"common/service.yaml": `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`,
+		"overlay/kustomization.yaml": `
+resources:
+- ../base
+patches:
+- path: app.yaml
+- target:
+    kind: Deployment
+    name: app
+  patch: |-
+    - op: add
+      path: /spec/replicas
+      value: 2
+- target:
+    kind: StatefulSet
+  patch: |-
+    - op: add
+      path: /spec/replicas
+      value: 2
+replacements:
+- source:
+    kind: Service
+    name: svc
+  targets:
+  - select:
+      kind: Ingress
+    fieldPaths:
+    - spec.rules.0.host
+`,
+		"overlay/app.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  paused: true
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return fSys
+}
+
+func TestLint(t *testing.T) {
+	fSys := writeTree(t)
+	var out bytes.Buffer
+	cmd := lint.NewCmdLint(fSys, &out)
+	err := cmd.RunE(cmd, []string{"overlay"})
+	assert.NoError(t, err)
+	assert.Equal(t, `warning: base/kustomization.yaml: [deprecated-field] 'bases' is deprecated; list bases under 'resources'
+warning: overlay/kustomization.yaml: [unused-patch] inline patch targets kind=StatefulSet, which matches no resource
+warning: overlay/kustomization.yaml: [unmatched-selector] replacement target kind=Ingress matches no resource
+info: overlay/kustomization.yaml: [missing-namespace] Deployment base-app has no namespace, so is created in the namespace of the kubectl context
+info: overlay/kustomization.yaml: [missing-namespace] Service base-svc has no namespace, so is created in the namespace of the kubectl context
+`, out.String())
+}
+
+func TestLintDeprecatedPatches(t *testing.T) {
+	fSys := writeTree(t)
+	require.NoError(t, fSys.WriteFile("common/kustomization.yaml", []byte(`
+resources:
+- service.yaml
+patchesStrategicMerge:
+- service-patch.yaml
+patchesJson6902:
+- target:
+    kind: Service
+    name: svc
+  patch: |-
+    - op: add
+      path: /spec/type
+      value: NodePort
+`)))
+	require.NoError(t, fSys.WriteFile("common/service-patch.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  clusterIP: None
+`)))
+	var out bytes.Buffer
+	cmd := lint.NewCmdLint(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("severity", "missing-namespace=off"))
+	assert.NoError(t, cmd.RunE(cmd, []string{"overlay"}))
+	assert.Contains(t, out.String(), `warning: common/kustomization.yaml: [deprecated-field] 'patchesStrategicMerge' is deprecated; use 'patches'
+warning: common/kustomization.yaml: [deprecated-field] 'patchesJson6902' is deprecated; use 'patches'
+`)
+}
+
+func TestLintDeprecatedVars(t *testing.T) {
+	fSys := writeTree(t)
+	require.NoError(t, fSys.WriteFile("common/kustomization.yaml", []byte(`
+resources:
+- service.yaml
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: svc
+`)))
+	var out bytes.Buffer
+	cmd := lint.NewCmdLint(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("severity", "missing-namespace=off"))
+	assert.NoError(t, cmd.RunE(cmd, []string{"overlay"}))
+	assert.Contains(t, out.String(), `warning: common/kustomization.yaml: [deprecated-field] 'vars' is deprecated; use 'replacements'
+`)
+}
+
+func TestLintDuplicateResource(t *testing.T) {
+	fSys := writeTree(t)
+	require.NoError(t, fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- deployment.yaml
+- deployment.yaml
+`)))
+	var out bytes.Buffer
+	cmd := lint.NewCmdLint(fSys, &out)
+	err := cmd.RunE(cmd, []string{"overlay"})
+	// The duplicate fails the build, but is reported first.
+	assert.Contains(t, err.Error(), "may not add resource with an already registered id")
+	assert.Equal(t, `error: base/kustomization.yaml: [duplicate-resource] resource deployment.yaml is listed more than once
+`, out.String())
+}
+
+func TestLintSeverityAndJson(t *testing.T) {
+	fSys := writeTree(t)
+	require.NoError(t, fSys.WriteFile("common/kustomization.yaml", []byte(`
+resources:
+- service.yaml
+- service.yaml
+`)))
+	var out bytes.Buffer
+	cmd := lint.NewCmdLint(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+	require.NoError(t, cmd.Flags().Set("severity",
+		"duplicate-resource=warning,deprecated-field=info"))
+	assert.Error(t, cmd.RunE(cmd, []string{"overlay"}))
+	assert.Equal(t, `[
+  {
+    "rule": "deprecated-field",
+    "severity": "info",
+    "path": "base/kustomization.yaml",
+    "message": "'bases' is deprecated; list bases under 'resources'"
+  },
+  {
+    "rule": "duplicate-resource",
+    "severity": "warning",
+    "path": "common/kustomization.yaml",
+    "message": "resource service.yaml is listed more than once"
+  }
+]
+`, out.String())
+
+	cmd = lint.NewCmdLint(fSys, &out)
+	require.NoError(t, cmd.Flags().Set("severity", "no-such-rule=error"))
+	assert.EqualError(t, cmd.RunE(cmd, []string{"overlay"}), `unknown rule "no-such-rule"`)
+}
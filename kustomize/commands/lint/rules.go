@@ -0,0 +1,294 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	severityOff     = "off"
+	severityInfo    = "info"
+	severityWarning = "warning"
+	severityError   = "error"
+)
+
+const (
+	ruleDeprecatedField   = types.WarningDeprecatedField
+	ruleDuplicateResource = "duplicate-resource"
+	ruleUnusedPatch       = types.WarningUnusedPatch
+	ruleUnmatchedSelector = types.WarningUnmatchedSelector
+	ruleMissingNamespace  = "missing-namespace"
+)
+
+var defaultSeverities = map[string]string{
+	ruleDeprecatedField:   severityWarning,
+	ruleDuplicateResource: severityError,
+	ruleUnusedPatch:       severityWarning,
+	ruleUnmatchedSelector: severityWarning,
+	ruleMissingNamespace:  severityInfo,
+}
+
+var ruleDescriptions = map[string]string{
+	ruleDeprecatedField:   "a kustomization uses a deprecated field",
+	ruleDuplicateResource: "a resource, base or component is listed twice",
+	ruleUnusedPatch:       "the target of a patch matches no resource",
+	ruleUnmatchedSelector: "a replacement target selects no resource",
+	ruleMissingNamespace:  "a namespaced resource in the output has no namespace",
+}
+
+func describeRules() string {
+	var rules []string
+	for r := range defaultSeverities {
+		rules = append(rules, r)
+	}
+	sort.Strings(rules)
+	var b strings.Builder
+	for _, r := range rules {
+		fmt.Fprintf(&b, "\n  %-20s %-8s %s", r, defaultSeverities[r], ruleDescriptions[r])
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Finding is a likely mistake in a kustomization.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// Path is the kustomization file the finding is about.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type linter struct {
+	fSys     filesys.FileSystem
+	visited  map[string]bool
+	findings []Finding
+}
+
+func (l *linter) report(rule, path, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{
+		Rule: rule, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// kustDir is a kustomization found in the tree being linted.
+type kustDir struct {
+	dir   string
+	kFile string
+	k     types.Kustomization
+}
+
+// lintTree lints the kustomization in dir, which is the
+// one being built, and all local kustomizations it uses.
+// Kustomizations are first checked on their own, so that
+// a broken base is reported even though it fails the build.
+func (l *linter) lintTree(dir string) error {
+	var dirs []*kustDir
+	if err := l.collect(dir, &dirs); err != nil {
+		return err
+	}
+	for i, kd := range dirs {
+		if kd.k.Kind == types.ComponentKind {
+			// Components can't be built on their own.
+			continue
+		}
+		opts := build.HonorKustomizeFlags(krusty.MakeDefaultOptions())
+		opts.KeepBuildAnnotations = true
+		// Linting reports its own findings.
+		opts.Warn = func(types.Warning) {}
+		m, err := krusty.MakeKustomizer(opts).Run(l.fSys, kd.dir)
+		if err != nil {
+			return err
+		}
+		if err = l.checkPatches(kd, m); err != nil {
+			return err
+		}
+		if err = l.checkReplacements(kd, m); err != nil {
+			return err
+		}
+		if i == 0 {
+			l.checkNamespaces(kd, m)
+		}
+	}
+	return nil
+}
+
+func (l *linter) kustomizationFile(dir string) string {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if p := filepath.Join(dir, n); l.fSys.Exists(p) {
+			return p
+		}
+	}
+	return filepath.Join(dir, konfig.DefaultKustomizationFileName())
+}
+
+// collect checks the kustomization in dir, and the local
+// kustomizations it uses, on their own, and appends them
+// to dirs.
+func (l *linter) collect(dir string, dirs *[]*kustDir) error {
+	kd := &kustDir{dir: dir, kFile: l.kustomizationFile(dir)}
+	content, err := l.fSys.ReadFile(kd.kFile)
+	if err != nil {
+		return err
+	}
+	if err = kd.k.Unmarshal(content); err != nil {
+		return fmt.Errorf("%s: %w", kd.kFile, err)
+	}
+	l.checkDeprecatedFields(kd.kFile, &kd.k)
+	l.checkDuplicates(kd.kFile, "resource", append(kd.k.Resources, kd.k.Bases...))
+	l.checkDuplicates(kd.kFile, "component", kd.k.Components)
+	kd.k.FixKustomizationPostUnmarshalling()
+	*dirs = append(*dirs, kd)
+	for _, entry := range append(kd.k.Resources, kd.k.Components...) {
+		sub := filepath.Join(dir, entry)
+		if !l.fSys.IsDir(sub) || l.visited[sub] {
+			continue
+		}
+		l.visited[sub] = true
+		if err = l.collect(sub, dirs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *linter) checkNamespaces(kd *kustDir, m resmap.ResMap) {
+	for _, r := range m.Resources() {
+		if r.GetNamespace() == "" && !r.GetGvk().IsClusterScoped() {
+			l.report(ruleMissingNamespace, kd.kFile,
+				"%s %s has no namespace, so is created in the "+
+					"namespace of the kubectl context", r.GetKind(), r.GetName())
+		}
+	}
+}
+
+func (l *linter) checkDeprecatedFields(kFile string, k *types.Kustomization) {
+	for _, msg := range k.DeprecatedFields() {
+		l.report(ruleDeprecatedField, kFile, "%s", msg)
+	}
+}
+
+func (l *linter) checkDuplicates(kFile, what string, entries []string) {
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if seen[e] {
+			l.report(ruleDuplicateResource, kFile, "%s %s is listed more than once", what, e)
+		}
+		seen[e] = true
+	}
+}
+
+// checkPatches reports patches whose target matches nothing.
+// Patches without a target name the resource they patch, and
+// fail the build if it doesn't exist.
+func (l *linter) checkPatches(kd *kustDir, m resmap.ResMap) error {
+	for _, p := range append(kd.k.Patches, kd.k.PatchesJson6902...) {
+		if p.Target == nil || isDeletion(p.Patch) {
+			// Deleted resources aren't in the output.
+			continue
+		}
+		ok, err := selects(m, p.Target)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			name := p.Path
+			if name == "" {
+				name = "inline patch"
+			}
+			l.report(ruleUnusedPatch, kd.kFile,
+				"%s targets %s, which matches no resource", name, describe(p.Target))
+		}
+	}
+	return nil
+}
+
+func (l *linter) checkReplacements(kd *kustDir, m resmap.ResMap) error {
+	for _, r := range kd.k.Replacements {
+		for _, t := range r.Targets {
+			if t.Select == nil {
+				continue
+			}
+			ok, err := selects(m, t.Select)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				l.report(ruleUnmatchedSelector, kd.kFile,
+					"replacement target %s matches no resource", describe(t.Select))
+			}
+		}
+	}
+	return nil
+}
+
+// describe returns e.g. "kind=Deployment name=app".
+func describe(s *types.Selector) string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{
+		{"group", s.Group},
+		{"version", s.Version},
+		{"kind", s.Kind},
+		{"name", s.Name},
+		{"namespace", s.Namespace},
+		{"labelSelector", s.LabelSelector},
+		{"annotationSelector", s.AnnotationSelector},
+	} {
+		if f.value != "" {
+			parts = append(parts, f.key+"="+f.value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func isDeletion(patch string) bool {
+	return strings.Contains(patch, "$patch: delete")
+}
+
+// selects returns true if the selector matches any resource,
+// by its current id or by any id it had earlier in the build.
+func selects(m resmap.ResMap, s *types.Selector) (bool, error) {
+	sr, err := types.NewSelectorRegex(s)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range m.Resources() {
+		idMatched := false
+		for _, id := range append(r.PrevIds(), r.CurId()) {
+			if sr.MatchGvk(id.Gvk) && sr.MatchName(id.Name) &&
+				sr.MatchNamespace(id.EffectiveNamespace()) {
+				idMatched = true
+				break
+			}
+		}
+		if !idMatched {
+			continue
+		}
+		matched, err := r.MatchesLabelSelector(s.LabelSelector)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			continue
+		}
+		matched, err = r.MatchesAnnotationSelector(s.AnnotationSelector)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
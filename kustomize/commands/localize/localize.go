@@ -0,0 +1,418 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package localize
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Fields of a kustomization holding lists of paths that
+// may be remote.  Entries of the first list may be bases.
+var (
+	baseFields = []string{"resources", "bases", "components"}
+	fileFields = []string{
+		"patchesStrategicMerge", "crds", "configurations",
+		"generators", "transformers", "validators"}
+	// pathFields hold lists of objects with a path field.
+	pathFields = []string{"patches", "patchesJson6902"}
+)
+
+type localizeOptions struct {
+	src       string
+	dst       string
+	vendorDir string
+}
+
+// NewCmdLocalize makes a new localize command.
+func NewCmdLocalize(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o localizeOptions
+	c := &cobra.Command{
+		Use:   "localize SRC DST",
+		Short: "Copy a kustomization tree, vendoring all remote bases and files",
+		Long: `Copy a kustomization tree, vendoring all remote bases and files.
+
+SRC, a directory holding a kustomization and all the local
+files it uses, is copied to DST, which must not exist.  Remote
+bases, components, resources and patches, referenced anywhere in
+the copy, are downloaded, and the references are rewritten to
+point at them, so that DST builds without network access.
+
+Remote git bases are copied, with their entire repository so that
+they may refer to other directories of it, into DST/vendor.
+Remote files are copied into the vendor directory next to the
+kustomization using them, as the default load restrictions don't
+allow a kustomization to load files from outside its directory.
+`,
+		Example: `
+	kustomize localize overlays/production production-airgapped
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(fSys, args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVar(&o.vendorDir, "vendor-dir", "vendor",
+		"the name of the directories to download remote content to")
+	return c
+}
+
+// Validate validates localize command args and flags.
+func (o *localizeOptions) Validate(fSys filesys.FileSystem, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("specify a source and a destination directory")
+	}
+	o.src, o.dst = args[0], args[1]
+	if !fSys.IsDir(o.src) {
+		return fmt.Errorf("%s is not a directory", o.src)
+	}
+	if fSys.Exists(o.dst) {
+		return fmt.Errorf("%s already exists", o.dst)
+	}
+	if filepath.IsAbs(o.vendorDir) || strings.HasPrefix(filepath.Clean(o.vendorDir), "..") {
+		return fmt.Errorf("--vendor-dir must be a path inside DST")
+	}
+	return nil
+}
+
+// Run copies the tree and vendors its remote content.
+func (o *localizeOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	if err := copyDir(fSys, o.src, o.dst); err != nil {
+		return err
+	}
+	l := &localizer{
+		fSys:      fSys,
+		dst:       o.dst,
+		vendorDir: o.vendorDir,
+		vendored:  make(map[string]string),
+		w:         w,
+	}
+	return l.localizeTree(o.dst)
+}
+
+type localizer struct {
+	fSys filesys.FileSystem
+	dst  string
+	// vendorDir is the name of the vendor directories.
+	vendorDir string
+	// vendored maps remote references, made in a given
+	// directory, to local paths.
+	vendored map[string]string
+	w        io.Writer
+}
+
+// localizeTree localizes every kustomization below dir.
+func (l *localizer) localizeTree(dir string) error {
+	var kFiles []string
+	err := l.fSys.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isKustomizationFile(filepath.Base(path)) {
+			kFiles = append(kFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range kFiles {
+		if err = l.localizeFile(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isKustomizationFile(name string) bool {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// localizeFile rewrites the remote references of one
+// kustomization file, keeping its comments and layout.
+func (l *localizer) localizeFile(kFile string) error {
+	content, err := l.fSys.ReadFile(kFile)
+	if err != nil {
+		return err
+	}
+	k, err := yaml.Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("%s: %w", kFile, err)
+	}
+	dir := filepath.Dir(kFile)
+	changed := false
+	visit := func(node *yaml.RNode, isBase bool) error {
+		ref := node.YNode().Value
+		if !isRemote(l.fSys, dir, ref) {
+			return nil
+		}
+		local, err := l.vendor(dir, ref, isBase)
+		if err != nil {
+			return fmt.Errorf("%s: vendoring %s: %w", kFile, ref, err)
+		}
+		rel, err := filepath.Rel(dir, local)
+		if err != nil {
+			return err
+		}
+		node.YNode().Value = filepath.ToSlash(rel)
+		changed = true
+		return nil
+	}
+	for _, fields := range []struct {
+		names  []string
+		isBase bool
+	}{{baseFields, true}, {fileFields, false}} {
+		for _, name := range fields.names {
+			list, err := k.Pipe(yaml.Lookup(name))
+			if err != nil || list == nil {
+				continue
+			}
+			elements, err := list.Elements()
+			if err != nil {
+				continue
+			}
+			for _, e := range elements {
+				if e.YNode().Kind != yaml.ScalarNode {
+					continue
+				}
+				if err = visit(e, fields.isBase); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, name := range pathFields {
+		list, err := k.Pipe(yaml.Lookup(name))
+		if err != nil || list == nil {
+			continue
+		}
+		elements, err := list.Elements()
+		if err != nil {
+			continue
+		}
+		for _, e := range elements {
+			path, err := e.Pipe(yaml.Lookup("path"))
+			if err != nil || path == nil {
+				continue
+			}
+			if err = visit(path, false); err != nil {
+				return err
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	out, err := k.String()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(l.w, "localized %s\n", kFile)
+	return l.fSys.WriteFile(kFile, []byte(out))
+}
+
+// isRemote returns true if the reference, made in dir,
+// can't be a local file or directory.
+func isRemote(fSys filesys.FileSystem, dir, ref string) bool {
+	if ref == "" || filepath.IsAbs(ref) || fSys.Exists(filepath.Join(dir, ref)) {
+		return false
+	}
+	if u, err := url.Parse(ref); err == nil && u.Scheme != "" {
+		return true
+	}
+	// E.g. github.com/org/repo/path?ref=v1, or git@github.com:org/repo.
+	return strings.Contains(ref, "?ref=") || strings.HasPrefix(ref, "git@") ||
+		strings.HasPrefix(ref, "github.com/") || strings.Contains(ref, ".git")
+}
+
+// vendor downloads the remote reference, made in dir, and
+// returns the local path of its copy.
+func (l *localizer) vendor(dir, ref string, isBase bool) (string, error) {
+	key := dir + "\n" + ref
+	if local, ok := l.vendored[key]; ok {
+		return local, nil
+	}
+	ldr, err := loader.NewLoader(loader.RestrictionNone, dir, l.fSys)
+	if err != nil {
+		return "", err
+	}
+	defer ldr.Cleanup()
+	local, err := l.vendorFile(ldr, filepath.Join(dir, l.vendorDir), ref, isBase)
+	if errors.Is(err, errNotAFile) && isBase {
+		local, err = l.vendorRepo(ldr, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	l.vendored[key] = local
+	return local, nil
+}
+
+var errNotAFile = errors.New("not a file of resources")
+
+// vendorFile downloads a remote file into vendorDir.  If the
+// file must hold resources, and doesn't, it returns errNotAFile.
+func (l *localizer) vendorFile(
+	ldr ifc.Loader, vendorDir, ref string, isBase bool) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", errNotAFile
+	}
+	content, err := ldr.Load(ref)
+	if errors.Is(err, loader.ErrorHTTP) {
+		return "", err
+	}
+	if err != nil {
+		return "", errNotAFile
+	}
+	if isBase && !holdsResources(content) {
+		return "", errNotAFile
+	}
+	local := filepath.Join(vendorDir, sanitize(u.Host+u.Path))
+	if u.RawQuery != "" {
+		local += "_" + sanitize(u.RawQuery)
+	}
+	if err = l.fSys.MkdirAll(filepath.Dir(local)); err != nil {
+		return "", err
+	}
+	return local, l.fSys.WriteFile(local, content)
+}
+
+// holdsResources returns true if the content parses
+// as kubernetes objects.
+func holdsResources(content []byte) bool {
+	nodes, err := kio.FromBytes(content)
+	if err != nil || len(nodes) == 0 {
+		return false
+	}
+	for _, n := range nodes {
+		if n.GetKind() == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// vendorRepo clones a remote base, copies its repository
+// into the vendor directory, and localizes the copy.
+func (l *localizer) vendorRepo(ldr ifc.Loader, ref string) (string, error) {
+	repoLdr, err := ldr.New(ref)
+	if err != nil {
+		return "", err
+	}
+	defer repoLdr.Cleanup()
+	root := repoLdr.Root()
+	repo := root
+	for !l.fSys.Exists(filepath.Join(repo, ".git")) {
+		parent := filepath.Dir(repo)
+		if parent == repo {
+			// Not a clone; copy just the base.
+			repo = root
+			break
+		}
+		repo = parent
+	}
+	sub, err := filepath.Rel(repo, root)
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(l.dst, l.vendorDir, repoName(ref, sub))
+	if !l.fSys.Exists(dst) {
+		if err = copyDir(l.fSys, repo, dst); err != nil {
+			return "", err
+		}
+		if err = l.localizeTree(dst); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dst, sub), nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._/-]+`)
+
+// sanitize turns a reference into a relative path.
+func sanitize(s string) string {
+	s = unsafeChars.ReplaceAllString(s, "_")
+	var parts []string
+	for _, p := range strings.Split(s, "/") {
+		if p != "" && p != "." && p != ".." {
+			parts = append(parts, p)
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// repoName returns the vendor path for the repository of a
+// remote base, from its reference minus the path in the repo,
+// e.g. github.com/org/repo_v1 for
+// https://github.com/org/repo//deploy/base?ref=v1.
+func repoName(ref, sub string) string {
+	name, query := ref, ""
+	if i := strings.Index(ref, "?"); i >= 0 {
+		name, query = ref[:i], ref[i+1:]
+	}
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	name = strings.TrimPrefix(name, "git@")
+	name = strings.TrimSuffix(name, "/")
+	if sub != "." {
+		name = strings.TrimSuffix(name, filepath.ToSlash(sub))
+	}
+	name = strings.TrimRight(strings.TrimSuffix(strings.TrimRight(name, "/"), ".git"), "/")
+	for _, param := range strings.Split(query, "&") {
+		if strings.HasPrefix(param, "ref=") || strings.HasPrefix(param, "version=") {
+			name += "_" + param[strings.Index(param, "=")+1:]
+		}
+	}
+	return sanitize(name)
+}
+
+// copyDir copies the directory tree at src to dst,
+// leaving out git metadata.
+func copyDir(fSys filesys.FileSystem, src, dst string) error {
+	return fSys.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return fSys.MkdirAll(target)
+		}
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return fSys.WriteFile(target, content)
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package localize_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/localize"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestLocalize(t *testing.T) {
+	files := map[string]string{
+		"/config/cm.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+data:
+  a: b
+`,
+		"/config/other.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+`,
+		"/config/patch.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+data:
+  a: patched
+`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			content, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		}))
+	defer srv.Close()
+
+	fSys := filesys.MakeFsOnDisk()
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	dst := filepath.Join(tmp, "dst")
+	require.NoError(t, fSys.MkdirAll(filepath.Join(src, "base")))
+	require.NoError(t, fSys.WriteFile(filepath.Join(src, "kustomization.yaml"), []byte(`
+# The overlay.
+namePrefix: p-
+resources:
+- base
+- `+srv.URL+`/config/other.yaml # remote
+patchesStrategicMerge:
+- `+srv.URL+`/config/patch.yaml
+`)))
+	require.NoError(t, fSys.WriteFile(filepath.Join(src, "base", "kustomization.yaml"), []byte(`
+resources:
+- `+srv.URL+`/config/cm.yaml
+`)))
+	require.NoError(t, fSys.WriteFile(filepath.Join(src, "base", "unrelated.txt"), []byte("x")))
+
+	var out bytes.Buffer
+	cmd := localize.NewCmdLocalize(fSys, &out)
+	require.NoError(t, cmd.RunE(cmd, []string{src, dst}))
+
+	host := strings.NewReplacer(":", "_").Replace(strings.TrimPrefix(srv.URL, "http://"))
+	content, err := fSys.ReadFile(filepath.Join(dst, "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# The overlay.
+namePrefix: p-
+resources:
+- base
+- vendor/`+host+`/config/other.yaml # remote
+patchesStrategicMerge:
+- vendor/`+host+`/config/patch.yaml
+`, string(content))
+	content, err = fSys.ReadFile(filepath.Join(dst, "base", "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `resources:
+- vendor/`+host+`/config/cm.yaml
+`, string(content))
+	assert.True(t, fSys.Exists(filepath.Join(dst, "base", "unrelated.txt")))
+
+	// The copy builds without the server.
+	srv.Close()
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, dst)
+	require.NoError(t, err)
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+data:
+  a: patched
+kind: ConfigMap
+metadata:
+  name: p-remote
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-other
+`, string(yml))
+
+	cmd = localize.NewCmdLocalize(fSys, &out)
+	assert.EqualError(t, cmd.RunE(cmd, []string{src, dst}), dst+" already exists")
+}
@@ -2,10 +2,15 @@ package fetch
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
@@ -37,6 +42,30 @@ func printSchema(w io.Writer) error {
 		return fmt.Errorf("format must be either 'json' or 'yaml'")
 	}
 
+	output, err := FetchSchema()
+	if err != nil {
+		return err
+	}
+
+	// format and output
+	var jsonSchema map[string]interface{}
+	json.Unmarshal(output, &jsonSchema)
+	output, _ = json.MarshalIndent(jsonSchema, "", "  ")
+
+	if format == "yaml" {
+		output, err = yaml.JSONToYAML(output)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w, string(output))
+	return nil
+}
+
+// FetchSchema returns the OpenAPI specification of the current
+// kubernetes cluster, as JSON, using kubectl.
+func FetchSchema() ([]byte, error) {
 	errMsg := `
 Error fetching schema from cluster.
 Please make sure kubectl is installed, its context is set correctly, and your cluster is up.
@@ -49,24 +78,92 @@ Installation and setup instructions: https://kubernetes.io/docs/tasks/tools/inst
 	command.Stderr = &stderr
 	err := command.Run()
 	if err != nil {
-		return fmt.Errorf("%w\n%s", err, stderr.String()+errMsg)
+		return nil, fmt.Errorf("%w\n%s", err, stderr.String()+errMsg)
 	} else if stdout.String() == "" {
-		return fmt.Errorf(stderr.String() + errMsg)
+		return nil, fmt.Errorf(stderr.String() + errMsg)
 	}
+	return stdout.Bytes(), nil
+}
 
-	// format and output
-	var jsonSchema map[string]interface{}
-	output := stdout.Bytes()
-	json.Unmarshal(output, &jsonSchema)
-	output, _ = json.MarshalIndent(jsonSchema, "", "  ")
+// CachingFetchSchema returns a function returning the OpenAPI
+// specification of the current kubernetes cluster, as FetchSchema
+// does, that keeps it in the directory, so that later builds
+// against the same kubectl context and cluster version needn't
+// fetch it again.  If refresh is true, kept specifications are
+// fetched and replaced, e.g. after custom resources were installed.
+func CachingFetchSchema(dir string, refresh bool) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		key, err := clusterKey()
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, "openapi-"+key+".json")
+		if !refresh {
+			if schema, err := ioutil.ReadFile(path); err == nil {
+				return schema, nil
+			}
+		}
+		schema, err := FetchSchema()
+		if err != nil {
+			return nil, err
+		}
+		return schema, writeAtomically(path, schema)
+	}
+}
 
-	if format == "yaml" {
-		output, err = yaml.JSONToYAML(output)
+// clusterKey identifies the current kubectl context, and
+// the version of its cluster.
+func clusterKey() (string, error) {
+	h := sha256.New()
+	for _, args := range [][]string{
+		{"config", "current-context"},
+		{"get", "--raw", "/version"},
+	} {
+		var stderr bytes.Buffer
+		command := exec.Command("kubectl", args...)
+		command.Stderr = &stderr
+		output, err := command.Output()
 		if err != nil {
-			return err
+			return "", fmt.Errorf("%w\n%s", err, stderr.String())
 		}
+		h.Write(output)
 	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	fmt.Fprintln(w, string(output))
-	return nil
+// writeAtomically writes the file, so that concurrent
+// builds don't read it half-written.
+func writeAtomically(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// FetchAPIResources returns the API resources of the current
+// kubernetes cluster, as kubectl api-resources prints them.
+func FetchAPIResources() ([]byte, error) {
+	command := exec.Command("kubectl", "api-resources")
+	var stderr bytes.Buffer
+	var stdout bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, stderr.String()+`
+Error fetching API resources from cluster.
+Please make sure kubectl is installed, its context is set correctly, and your cluster is up.`)
+	}
+	return stdout.Bytes(), nil
 }
@@ -0,0 +1,69 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fetch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKubectl serves a cluster whose context and version are
+// read from files, and counts the fetches of its schema.
+const fakeKubectl = `#!/bin/sh
+dir=$(dirname "$0")
+case "$*" in
+"config current-context") cat "$dir/context" ;;
+"get --raw /version") echo '{"gitVersion": "v1.24.0"}' ;;
+"get --raw /openapi/v2")
+  echo x >> "$dir/fetches"
+  echo '{"swagger": "2.0"}'
+  ;;
+*) exit 1 ;;
+esac
+`
+
+func TestCachingFetchSchema(t *testing.T) {
+	bin, err := ioutil.TempDir("", "kubectl-")
+	require.NoError(t, err)
+	defer os.RemoveAll(bin)
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(bin, "kubectl"), []byte(fakeKubectl), 0777))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(bin, "context"), []byte("staging\n"), 0644))
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	require.NoError(t, os.Setenv(
+		"PATH", bin+string(os.PathListSeparator)+path))
+	fetches := func() int {
+		content, _ := ioutil.ReadFile(filepath.Join(bin, "fetches"))
+		return len(content) / 2
+	}
+
+	dir := filepath.Join(bin, "cache")
+	for i := 0; i < 2; i++ {
+		schema, err := CachingFetchSchema(dir, false)()
+		require.NoError(t, err)
+		assert.Equal(t, "{\"swagger\": \"2.0\"}\n", string(schema))
+	}
+	assert.Equal(t, 1, fetches())
+
+	_, err = CachingFetchSchema(dir, true)()
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches())
+
+	// Another context has another schema.
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(bin, "context"), []byte("production\n"), 0644))
+	_, err = CachingFetchSchema(dir, false)()
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetches())
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
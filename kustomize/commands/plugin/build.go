@@ -0,0 +1,131 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/compiler"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const apiModule = "sigs.k8s.io/kustomize/api"
+
+type buildOptions struct {
+	pluginHome string
+	noPin      bool
+	group      string
+	version    string
+	kind       string
+}
+
+func newCmdBuild(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o buildOptions
+	c := &cobra.Command{
+		Use:   "build GROUP/VERSION/KIND",
+		Short: "Rebuild a Go plugin against this kustomize",
+		Long: `Rebuild a Go plugin against this kustomize.
+
+Go plugins only load into a kustomize built from the same versions
+of the packages they share, e.g. sigs.k8s.io/kustomize/api.
+This command compiles the plugin source at
+
+  ${pluginHome}/${group}/${version}/LOWERCASE(${kind})/${kind}.go
+
+into ${kind}.so next to it.  If the plugin has its own go.mod, its
+kustomize/api requirement is first set to the version this kustomize
+was built with.
+`,
+		Example: `
+	kustomize plugin build someteam.example.com/v1/SedTransformer
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+			if o.pluginHome == "" {
+				o.pluginHome, err = konfig.DefaultAbsPluginHome(fSys)
+				if err != nil {
+					return err
+				}
+			}
+			return o.Run(w)
+		},
+	}
+	c.Flags().StringVar(&o.pluginHome, "plugin-home", "",
+		"directory holding plugin sources; defaults to the plugin home kustomize loads plugins from")
+	c.Flags().BoolVar(&o.noPin, "no-pin", false,
+		"don't set the kustomize/api requirement in the plugin's go.mod")
+	return c
+}
+
+// Validate validates build command args.
+func (o *buildOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("specify one plugin as GROUP/VERSION/KIND")
+	}
+	parts := strings.Split(args[0], "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf(
+			"plugin %q should be given as GROUP/VERSION/KIND", args[0])
+	}
+	o.group, o.version, o.kind = parts[0], parts[1], parts[2]
+	return nil
+}
+
+// Run compiles the plugin, pinning its kustomize/api
+// dependency first if possible.
+func (o *buildOptions) Run(w io.Writer) error {
+	c := compiler.NewCompiler(o.pluginHome)
+	c.SetGVK(o.group, o.version, o.kind)
+	dir := filepath.Dir(c.ObjPath())
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !o.noPin {
+		if v := apiVersion(); v != "" {
+			fmt.Fprintf(w, "requiring %s@%s\n", apiModule, v)
+			//nolint:gosec
+			cmd := exec.Command(compiler.GoBin(), "get", apiModule+"@"+v)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return errors.Wrapf(err, "pinning %s: %s", apiModule, out)
+			}
+		}
+	}
+	if err := c.Compile(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "built %s\n", c.ObjPath())
+	return nil
+}
+
+// apiVersion returns the version of kustomize/api this
+// program was built with, or "" if it's not a release,
+// e.g. when built from a local checkout.
+func apiVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, m := range info.Deps {
+		if m.Path != apiModule {
+			continue
+		}
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		if strings.HasPrefix(m.Version, "v") {
+			return m.Version
+		}
+	}
+	return ""
+}
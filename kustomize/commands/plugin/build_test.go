@@ -0,0 +1,67 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildValidate(t *testing.T) {
+	var o buildOptions
+	require.NoError(t, o.Validate([]string{"someteam.example.com/v1/SedTransformer"}))
+	assert.Equal(t, "someteam.example.com", o.group)
+	assert.Equal(t, "v1", o.version)
+	assert.Equal(t, "SedTransformer", o.kind)
+
+	for _, args := range [][]string{
+		{},
+		{"a/v1/K", "b/v1/K"},
+		{"SedTransformer"},
+		{"v1/SedTransformer"},
+		{"someteam.example.com/v1/"},
+		{"/v1/SedTransformer"},
+	} {
+		assert.Error(t, o.Validate(args), args)
+	}
+}
+
+func TestBuildMissingSource(t *testing.T) {
+	o := buildOptions{pluginHome: t.TempDir()}
+	require.NoError(t, o.Validate([]string{"someteam.example.com/v1/Missing"}))
+	err := o.Run(&bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot find source")
+}
+
+func TestBuild(t *testing.T) {
+	out, err := exec.Command("go", "env", "CGO_ENABLED").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Skip("building Go plugins needs cgo")
+	}
+	home := t.TempDir()
+	dir := filepath.Join(home, "someteam.example.com", "v1", "noop")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"),
+		[]byte("module example.com/noop\n\ngo 1.16\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Noop.go"),
+		[]byte("package main\n\nvar KustomizePlugin struct{}\n"), 0644))
+
+	o := buildOptions{pluginHome: home, noPin: true}
+	require.NoError(t, o.Validate([]string{"someteam.example.com/v1/Noop"}))
+	var w bytes.Buffer
+	require.NoError(t, o.Run(&w))
+	obj := filepath.Join(dir, "Noop.so")
+	assert.Equal(t, "built "+obj+"\n", w.String())
+	_, err = os.Stat(obj)
+	assert.NoError(t, err)
+}
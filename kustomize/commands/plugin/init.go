@@ -0,0 +1,335 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	pluginTypeExec     = "exec"
+	pluginTypeGo       = "go"
+	pluginTypeStarlark = "starlark"
+)
+
+type initOptions struct {
+	pluginHome string
+	pluginType string
+	group      string
+	version    string
+	kind       string
+}
+
+func newCmdInit(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o initOptions
+	c := &cobra.Command{
+		Use:   "init GROUP/VERSION/KIND",
+		Short: "Create the skeleton of a transformer plugin",
+		Long: `Create the skeleton of a transformer plugin.
+
+The skeleton is written to
+
+  ${pluginHome}/${group}/${version}/LOWERCASE(${kind})
+
+and holds the plugin source, a Go test running the plugin
+with the kusttest harness, and an example transformer config.
+The skeleton plugin sets a 'greeting' annotation, taken
+from its config, on every resource.
+`,
+		Example: `
+	kustomize plugin init someteam.example.com/v1/Greeter --type exec
+	kustomize plugin init someteam.example.com/v1/Greeter --type go
+	kustomize plugin init someteam.example.com/v1/Greeter --type starlark
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+			if o.pluginHome == "" {
+				o.pluginHome, err = konfig.DefaultAbsPluginHome(fSys)
+				if err != nil {
+					return err
+				}
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	c.Flags().StringVar(&o.pluginHome, "plugin-home", "",
+		"directory to create the plugin in; defaults to the plugin home kustomize loads plugins from")
+	c.Flags().StringVar(&o.pluginType, "type", pluginTypeExec,
+		"the kind of plugin to create, one of exec, go or starlark")
+	return c
+}
+
+// Validate validates init command args and flags.
+func (o *initOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("specify one plugin as GROUP/VERSION/KIND")
+	}
+	parts := strings.Split(args[0], "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf(
+			"plugin %q should be given as GROUP/VERSION/KIND", args[0])
+	}
+	o.group, o.version, o.kind = parts[0], parts[1], parts[2]
+	if _, ok := skeletons[o.pluginType]; !ok {
+		return fmt.Errorf(
+			"unknown plugin type %q; expected exec, go or starlark", o.pluginType)
+	}
+	return nil
+}
+
+// Run writes the skeleton, refusing to overwrite an existing plugin.
+func (o *initOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	lowerKind := strings.ToLower(o.kind)
+	dir := filepath.Join(o.pluginHome, o.group, o.version, lowerKind)
+	if fSys.Exists(dir) {
+		return fmt.Errorf("plugin directory %s already exists", dir)
+	}
+	if err := fSys.MkdirAll(dir); err != nil {
+		return err
+	}
+	data := map[string]string{
+		"Group":      o.group,
+		"Version":    o.version,
+		"Kind":       o.kind,
+		"LowerKind":  lowerKind,
+		"APIVersion": o.group + "/" + o.version,
+		"Module":     path.Join(o.group, o.version, lowerKind),
+		"Type":       o.pluginType,
+	}
+	for _, f := range skeletons[o.pluginType] {
+		name, content, err := f.render(data)
+		if err != nil {
+			return err
+		}
+		p := filepath.Join(dir, name)
+		if err = fSys.WriteFile(p, content); err != nil {
+			return err
+		}
+		if f.executable {
+			// The executable bit can only be set on disk.
+			if err = os.Chmod(p, 0755); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		fmt.Fprintf(w, "created %s\n", p)
+	}
+	fmt.Fprintf(w, "\nNext, fetch the plugin's dependencies and run its test:\n"+
+		"  cd %s\n  go mod tidy\n  go test ./...\n", dir)
+	if o.pluginType == pluginTypeGo {
+		fmt.Fprintf(w, "Then build it against this kustomize with\n"+
+			"  kustomize plugin build %s/%s/%s\n", o.group, o.version, o.kind)
+	}
+	return nil
+}
+
+type skeletonFile struct {
+	name       string
+	content    string
+	executable bool
+}
+
+func (f skeletonFile) render(data map[string]string) (string, []byte, error) {
+	var name, content bytes.Buffer
+	for _, x := range []struct {
+		out *bytes.Buffer
+		tpl string
+	}{{&name, f.name}, {&content, f.content}} {
+		t, err := template.New(f.name).Parse(x.tpl)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = t.Execute(x.out, data); err != nil {
+			return "", nil, err
+		}
+	}
+	return name.String(), content.Bytes(), nil
+}
+
+var skeletons = map[string][]skeletonFile{
+	pluginTypeExec: {
+		{name: "{{.Kind}}", content: execSource, executable: true},
+		{name: "{{.Kind}}_test.go", content: pluginTest},
+		{name: "go.mod", content: goMod},
+		{name: "example.yaml", content: exampleConfig},
+	},
+	pluginTypeGo: {
+		{name: "{{.Kind}}.go", content: goSource},
+		{name: "{{.Kind}}_test.go", content: pluginTest},
+		{name: "go.mod", content: goMod},
+		{name: "example.yaml", content: exampleConfig},
+	},
+	pluginTypeStarlark: {
+		{name: "{{.LowerKind}}.star", content: starlarkSource},
+		{name: "{{.Kind}}_test.go", content: pluginTest},
+		{name: "go.mod", content: goMod},
+		{name: "example.yaml", content: starlarkConfig},
+	},
+}
+
+const execSource = `#!/bin/bash
+
+# {{.Kind}} is a kustomize exec transformer plugin.
+#
+# kustomize runs it with the path to its config file as
+# the first argument and the resources to transform as
+# YAML on stdin, and reads the transformed resources
+# from stdout.  A non-zero exit fails the build.
+#
+# This skeleton sets a 'greeting' annotation, taken from
+# the config's 'greeting' field, on every resource.
+
+config=$1
+greeting=$(sed -n 's/^greeting: *//p' "$config")
+
+# kustomize annotates each resource it passes to exec
+# transformers, so every resource has an annotations field.
+awk -v greeting="$greeting" \
+  '{ print } /^  annotations:$/ { print "    greeting: " greeting }'
+`
+
+const goSource = `// {{.Kind}} is a kustomize Go transformer plugin.
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/yaml"
+)
+
+// plugin is configured by the fields of its config,
+// other than apiVersion, kind and metadata.
+type plugin struct {
+	Greeting string ` + "`" + `json:"greeting,omitempty" yaml:"greeting,omitempty"` + "`" + `
+}
+
+//nolint: golint
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(_ *resmap.PluginHelpers, c []byte) error {
+	return yaml.Unmarshal(c, p)
+}
+
+// Transform sets a greeting annotation on every resource.
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, r := range m.Resources() {
+		annotations := r.GetAnnotations()
+		annotations["greeting"] = p.Greeting
+		if err := r.SetAnnotations(annotations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`
+
+const pluginTest = `package main_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func Test{{.Kind}}(t *testing.T) {
+	pluginRoot, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := kusttest_test.MakeEnhancedHarnessWithPluginRoot(t, pluginRoot).
+{{- if eq .Type "go"}}
+		BuildGoPlugin("{{.Group}}", "{{.Version}}", "{{.Kind}}")
+{{- else}}
+		PrepExecPlugin("{{.Group}}", "{{.Version}}", "{{.Kind}}")
+{{- end}}
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(` + "`" + `
+apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: example
+{{- if eq .Type "starlark"}}
+starlark:
+  path: {{.LowerKind}}.star
+{{- end}}
+greeting: hello
+` + "`" + `, ` + "`" + `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  foo: bar
+` + "`" + `, ` + "`" + `
+apiVersion: v1
+data:
+  foo: bar
+kind: ConfigMap
+metadata:
+  annotations:
+    greeting: hello
+  name: cm
+` + "`" + `)
+}
+`
+
+const starlarkSource = `# {{.Kind}} is a kustomize starlark transformer plugin.
+#
+# ctx.resource_list["items"] holds the resources to
+# transform, and ctx.resource_list["functionConfig"]
+# the plugin's config.
+#
+# This skeleton sets a 'greeting' annotation, taken from
+# the config's 'greeting' field, on every resource.
+
+def run(items, config):
+  for resource in items:
+    metadata = resource.setdefault("metadata", {})
+    annotations = metadata.setdefault("annotations", {})
+    annotations["greeting"] = config["greeting"]
+
+run(ctx.resource_list["items"], ctx.resource_list["functionConfig"])
+`
+
+const goMod = `module {{.Module}}
+
+go 1.16
+`
+
+const exampleConfig = `# Refer to this file from the transformers field of a
+# kustomization, and build with --enable-alpha-plugins.
+apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: example
+greeting: hello
+`
+
+const starlarkConfig = `# Refer to this file from the transformers field of a
+# kustomization, and build with --enable-alpha-plugins
+# --enable-star.  Copy {{.LowerKind}}.star next to the
+# kustomization, or adjust the path below.
+apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: example
+starlark:
+  path: {{.LowerKind}}.star
+greeting: hello
+`
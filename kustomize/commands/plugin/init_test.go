@@ -0,0 +1,78 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestInitValidate(t *testing.T) {
+	o := initOptions{pluginType: "go"}
+	require.NoError(t, o.Validate([]string{"someteam.example.com/v1/Greeter"}))
+	assert.Equal(t, "someteam.example.com", o.group)
+	assert.Equal(t, "v1", o.version)
+	assert.Equal(t, "Greeter", o.kind)
+
+	assert.Error(t, o.Validate([]string{"v1/Greeter"}))
+	assert.Error(t, o.Validate([]string{"/v1/Greeter"}))
+	o.pluginType = "python"
+	err := o.Validate([]string{"someteam.example.com/v1/Greeter"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown plugin type "python"`)
+}
+
+func TestInit(t *testing.T) {
+	testCases := map[string][]string{
+		"exec":     {"Greeter", "Greeter_test.go", "example.yaml", "go.mod"},
+		"go":       {"Greeter.go", "Greeter_test.go", "example.yaml", "go.mod"},
+		"starlark": {"Greeter_test.go", "example.yaml", "go.mod", "greeter.star"},
+	}
+	for pluginType, files := range testCases {
+		t.Run(pluginType, func(t *testing.T) {
+			home := t.TempDir()
+			fSys := filesys.MakeFsOnDisk()
+			o := initOptions{pluginHome: home, pluginType: pluginType}
+			require.NoError(t, o.Validate([]string{"someteam.example.com/v1/Greeter"}))
+			var w bytes.Buffer
+			require.NoError(t, o.Run(fSys, &w))
+
+			dir := filepath.Join(home, "someteam.example.com", "v1", "greeter")
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			assert.Equal(t, files, names)
+			assert.Contains(t, w.String(), "created "+filepath.Join(dir, "example.yaml"))
+
+			content, err := fSys.ReadFile(filepath.Join(dir, "example.yaml"))
+			require.NoError(t, err)
+			assert.Contains(t, string(content), `apiVersion: someteam.example.com/v1
+kind: Greeter
+`)
+			content, err = fSys.ReadFile(filepath.Join(dir, "go.mod"))
+			require.NoError(t, err)
+			assert.Equal(t, "module someteam.example.com/v1/greeter\n\ngo 1.16\n", string(content))
+
+			if pluginType == "exec" && runtime.GOOS != "windows" {
+				info, err := os.Stat(filepath.Join(dir, "Greeter"))
+				require.NoError(t, err)
+				assert.NotZero(t, info.Mode()&0100, "exec plugin should be executable")
+			}
+
+			err = o.Run(fSys, &w)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "already exists")
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// NewCmdPlugin makes a new plugin command.
+func NewCmdPlugin(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "plugin",
+		Short: "Commands for developing kustomize plugins",
+		Example: `
+	# Create a new exec plugin
+	kustomize plugin init someteam.example.com/v1/SedTransformer --type exec
+
+	# Rebuild a Go plugin against this kustomize
+	kustomize plugin build someteam.example.com/v1/SedTransformer
+`,
+	}
+	c.AddCommand(newCmdInit(fSys, w))
+	c.AddCommand(newCmdBuild(fSys, w))
+	return c
+}
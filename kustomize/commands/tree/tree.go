@@ -0,0 +1,323 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+type treeOptions struct {
+	kustomizationPath string
+}
+
+// NewCmdTree makes a new tree command.
+func NewCmdTree(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var o treeOptions
+	c := &cobra.Command{
+		Use:   "tree DIR",
+		Short: "Print the structure of a kustomization as a tree",
+		Long: `Print the structure of a kustomization as a tree.
+
+The tree shows the resources of the kustomization, i.e. its
+bases and resource files, its components, generators,
+transformers and validators.  Local bases and components are
+shown in turn, and each resource file is shown with the
+resources it holds.  Remote bases are shown, but not followed.
+Nothing is built, so the tree shows the resources as they are
+in the files, before any kustomization changes them.
+
+If DIR is omitted, '.' is assumed.
+`,
+		Example: `
+	kustomize tree overlays/production
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(args); err != nil {
+				return err
+			}
+			return o.Run(fSys, w)
+		},
+	}
+	return c
+}
+
+// Validate validates tree command args.
+func (o *treeOptions) Validate(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("specify one kustomization directory")
+	}
+	o.kustomizationPath = filesys.SelfDir
+	if len(args) == 1 {
+		o.kustomizationPath = args[0]
+	}
+	return nil
+}
+
+// Run prints the tree.
+func (o *treeOptions) Run(fSys filesys.FileSystem, w io.Writer) error {
+	dir := filepath.Clean(o.kustomizationPath)
+	root := &node{label: dir}
+	if err := addKustomization(fSys, dir, root, map[string]bool{}); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, root.label)
+	root.write(w, "")
+	return nil
+}
+
+type node struct {
+	label    string
+	children []*node
+}
+
+func (n *node) add(label string) *node {
+	c := &node{label: label}
+	n.children = append(n.children, c)
+	return c
+}
+
+// section adds a child holding the given children, if any.
+func (n *node) section(label string, children []*node) {
+	if len(children) > 0 {
+		n.children = append(n.children, &node{label: label, children: children})
+	}
+}
+
+func (n *node) write(w io.Writer, indent string) {
+	for i, c := range n.children {
+		branch, next := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintln(w, indent+branch+c.label)
+		c.write(w, indent+next)
+	}
+}
+
+func readKustomization(
+	fSys filesys.FileSystem, dir string) (*types.Kustomization, error) {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		content, err := fSys.ReadFile(filepath.Join(dir, n))
+		if err != nil {
+			continue
+		}
+		var k types.Kustomization
+		if err = k.Unmarshal(content); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, n), err)
+		}
+		k.FixKustomizationPostUnmarshalling()
+		return &k, nil
+	}
+	return nil, fmt.Errorf("no kustomization file found in %s", dir)
+}
+
+// addKustomization adds the structure of the kustomization
+// in dir to n.  Dirs holds the kustomizations being added,
+// to stop on cycles.
+func addKustomization(fSys filesys.FileSystem, dir string,
+	n *node, dirs map[string]bool) error {
+	k, err := readKustomization(fSys, dir)
+	if err != nil {
+		return err
+	}
+	dirs[dir] = true
+	defer delete(dirs, dir)
+
+	resources, err := entries(fSys, dir, k.Resources, dirs)
+	if err != nil {
+		return err
+	}
+	n.section("resources", resources)
+	components, err := entries(fSys, dir, k.Components, dirs)
+	if err != nil {
+		return err
+	}
+	n.section("components", components)
+	n.section("generators", generators(k))
+	n.section("transformers", transformers(fSys, dir, k))
+	var validators []*node
+	for _, v := range k.Validators {
+		validators = append(validators, &node{label: v})
+	}
+	n.section("validators", validators)
+	return nil
+}
+
+// entries returns the nodes of resource or component entries.
+func entries(fSys filesys.FileSystem, dir string,
+	list []string, dirs map[string]bool) ([]*node, error) {
+	var nodes []*node
+	for _, entry := range list {
+		if e, ok := types.ParseResourceEntry(entry); ok {
+			entry = e.Path
+		}
+		n := &node{label: entry}
+		nodes = append(nodes, n)
+		path := filepath.Join(dir, entry)
+		switch {
+		case dirs[path]:
+			n.label += " (cycle)"
+		case fSys.IsDir(path):
+			if err := addKustomization(fSys, path, n, dirs); err != nil {
+				return nil, err
+			}
+		case fSys.Exists(path):
+			if err := addResources(fSys, path, n); err != nil {
+				return nil, err
+			}
+		default:
+			n.label += " (remote)"
+		}
+	}
+	return nodes, nil
+}
+
+// addResources adds the resources in the file to n.
+func addResources(fSys filesys.FileSystem, path string, n *node) error {
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rnodes, err := (&kio.ByteReader{
+		Reader:                bytes.NewReader(content),
+		OmitReaderAnnotations: true,
+	}).Read()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, r := range rnodes {
+		name := r.GetName()
+		if ns := r.GetNamespace(); ns != "" {
+			name = ns + "/" + name
+		}
+		n.add(r.GetKind() + " " + name)
+	}
+	return nil
+}
+
+func generators(k *types.Kustomization) []*node {
+	var nodes []*node
+	add := func(format string, args ...interface{}) {
+		nodes = append(nodes, &node{label: fmt.Sprintf(format, args...)})
+	}
+	for _, g := range k.ConfigMapGenerator {
+		add("configMapGenerator: ConfigMap %s", g.Name)
+	}
+	for _, g := range k.SecretGenerator {
+		add("secretGenerator: Secret %s", g.Name)
+	}
+	for _, c := range k.HelmCharts {
+		add("helmCharts: %s", strings.TrimSpace(c.Name+" "+c.Version))
+	}
+	for _, c := range k.HelmChartInflationGenerator {
+		add("helmChartInflationGenerator: %s", strings.TrimSpace(c.ChartName+" "+c.ChartVersion))
+	}
+	for _, g := range k.Generators {
+		add("generators: %s", g)
+	}
+	return nodes
+}
+
+func transformers(fSys filesys.FileSystem, dir string, k *types.Kustomization) []*node {
+	var nodes []*node
+	add := func(format string, args ...interface{}) {
+		nodes = append(nodes, &node{label: fmt.Sprintf(format, args...)})
+	}
+	if k.NamePrefix != "" {
+		add("namePrefix: %s", k.NamePrefix)
+	}
+	if k.NameSuffix != "" {
+		add("nameSuffix: %s", k.NameSuffix)
+	}
+	if k.Namespace != "" {
+		add("namespace: %s", k.Namespace)
+	}
+	if len(k.CommonLabels) > 0 {
+		add("commonLabels: %s", pairs(k.CommonLabels))
+	}
+	for _, l := range k.Labels {
+		add("labels: %s", pairs(l.Pairs))
+	}
+	if len(k.CommonAnnotations) > 0 {
+		add("commonAnnotations: %s", pairs(k.CommonAnnotations))
+	}
+	for _, p := range k.PatchesStrategicMerge {
+		if e, ok := p.Entry(); ok {
+			p = types.PatchStrategicMerge(e.Path)
+		}
+		add("patchesStrategicMerge: %s", patchName(fSys, dir, string(p)))
+	}
+	for _, p := range k.Patches {
+		name := p.Path
+		if name == "" {
+			name = "inline patch"
+		}
+		add("patches: %s", name)
+	}
+	for _, i := range k.Images {
+		add("images: %s", image(i))
+	}
+	for _, r := range k.Replicas {
+		add("replicas: %s=%d", r.Name, r.Count)
+	}
+	for _, r := range k.Replacements {
+		if r.Path != "" {
+			add("replacements: %s", r.Path)
+		} else if r.Source != nil {
+			add("replacements: from %s %s", r.Source.Kind, r.Source.Name)
+		}
+	}
+	for _, v := range k.Vars {
+		add("vars: %s", v.Name)
+	}
+	for _, t := range k.Transformers {
+		add("transformers: %s", t)
+	}
+	return nodes
+}
+
+// pairs returns e.g. "a=b, c=d".
+func pairs(m map[string]string) string {
+	var ps []string
+	for k, v := range m {
+		ps = append(ps, k+"="+v)
+	}
+	sort.Strings(ps)
+	return strings.Join(ps, ", ")
+}
+
+func patchName(fSys filesys.FileSystem, dir, patch string) string {
+	if patch == "" || strings.Contains(patch, "\n") ||
+		!fSys.Exists(filepath.Join(dir, patch)) {
+		return "inline patch"
+	}
+	return patch
+}
+
+// image returns e.g. "nginx -> my/nginx:1.2".
+func image(i types.Image) string {
+	to := i.NewName
+	if to == "" {
+		to = i.Name
+	}
+	if i.NewTag != "" {
+		to += ":" + i.NewTag
+	}
+	if i.Digest != "" {
+		to += "@" + i.Digest
+	}
+	return i.Name + " -> " + to
+}
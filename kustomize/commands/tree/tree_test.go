@@ -0,0 +1,89 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tree_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/tree"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestTree(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("base"))
+	require.NoError(t, fSys.MkdirAll("overlay"))
+	require.NoError(t, fSys.MkdirAll("component"))
+	require.NoError(t, fSys.WriteFile("base/kustomization.yaml", []byte(`
+resources:
+- app.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - a=b
+`)))
+	require.NoError(t, fSys.WriteFile("base/app.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: web
+`)))
+	require.NoError(t, fSys.WriteFile("component/kustomization.yaml", []byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+commonLabels:
+  tier: web
+  app: app
+`)))
+	require.NoError(t, fSys.WriteFile("overlay/patch.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`)))
+	require.NoError(t, fSys.WriteFile("overlay/kustomization.yaml", []byte(`
+resources:
+- ../base
+- github.com/example/config/base?ref=v1
+components:
+- ../component
+namePrefix: prod-
+patchesStrategicMerge:
+- patch.yaml
+images:
+- name: nginx
+  newTag: "1.21"
+`)))
+	out := new(bytes.Buffer)
+	cmd := tree.NewCmdTree(fSys, out)
+	require.NoError(t, cmd.RunE(cmd, []string{"overlay"}))
+	assert.Equal(t, `overlay
+├── resources
+│   ├── ../base
+│   │   ├── resources
+│   │   │   └── app.yaml
+│   │   │       ├── Deployment app
+│   │   │       └── Service web/app
+│   │   └── generators
+│   │       └── configMapGenerator: ConfigMap config
+│   └── github.com/example/config/base?ref=v1 (remote)
+├── components
+│   └── ../component
+│       └── transformers
+│           └── commonLabels: app=app, tier=web
+└── transformers
+    ├── namePrefix: prod-
+    ├── patchesStrategicMerge: patch.yaml
+    └── images: nginx -> nginx:1.21
+`, out.String())
+}
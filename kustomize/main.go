@@ -5,14 +5,23 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"runtime/debug"
 
 	"sigs.k8s.io/kustomize/kustomize/v4/commands"
 )
 
 func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "internal error: %v\n%s", r, debug.Stack())
+			os.Exit(commands.ExitInternalError)
+		}
+	}()
 	if err := commands.NewDefaultCommand().Execute(); err != nil {
-		os.Exit(1)
+		commands.PrintErrorDetails(os.Stderr, err)
+		os.Exit(commands.ExitCode(err))
 	}
-	os.Exit(0)
+	os.Exit(commands.ExitOK)
 }
@@ -0,0 +1,88 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	goerrors "github.com/go-errors/errors"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// contextError annotates an error with context, e.g. the
+// transformer that failed, without changing its message.
+type contextError struct {
+	context string
+	err     error
+}
+
+func (e *contextError) Error() string { return e.err.Error() }
+
+// Unwrap lets errors.Is and errors.As see the wrapped error.
+func (e *contextError) Unwrap() error { return e.err }
+
+// Cause lets github.com/pkg/errors.Cause see the wrapped error.
+func (e *contextError) Cause() error { return e.err }
+
+// WithContext returns err annotated with context, e.g. the
+// kustomization or the resource being worked on when err
+// happened.  The message of err is unchanged, but Chain
+// reports the context.  If err is nil, returns nil.
+func WithContext(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &contextError{context: fmt.Sprintf(format, args...), err: err}
+}
+
+// Chain returns a line for each error in the chain of err,
+// outermost first: the part of its message that the error
+// adds to that of the error it wraps, or its context if
+// it's annotated with WithContext.
+func Chain(err error) []string {
+	var result []string
+	for err != nil {
+		inner := unwrap(err)
+		if c, ok := err.(*contextError); ok {
+			result = append(result, "in "+c.context)
+			err = inner
+			continue
+		}
+		msg := err.Error()
+		if inner != nil {
+			msg = strings.TrimSuffix(msg, inner.Error())
+			msg = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(msg), ":"))
+		}
+		if msg != "" {
+			result = append(result, msg)
+		}
+		err = inner
+	}
+	return result
+}
+
+// Stack returns the stack trace recorded by the innermost
+// error in the chain of err that has one, or "" if none has.
+func Stack(err error) string {
+	var result string
+	for ; err != nil; err = unwrap(err) {
+		switch e := err.(type) {
+		case *goerrors.Error:
+			result = string(e.Stack())
+		case interface{ StackTrace() pkgerrors.StackTrace }:
+			result = strings.TrimPrefix(fmt.Sprintf("%+v", e.StackTrace()), "\n")
+		}
+	}
+	return result
+}
+
+// unwrap returns the error wrapped by err, if any.
+func unwrap(err error) error {
+	if e, ok := err.(*goerrors.Error); ok {
+		return e.Err
+	}
+	return stderrors.Unwrap(err)
+}
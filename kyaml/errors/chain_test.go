@@ -0,0 +1,46 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+type notFound struct{}
+
+func (notFound) Error() string { return "no such file" }
+
+func TestChain(t *testing.T) {
+	err := pkgerrors.Wrap(notFound{}, "loading 'missing.yaml'")
+	err = errors.WithContext(err, "resource %s", "ConfigMap/settings")
+	err = fmt.Errorf("accumulating resources: %w", err)
+	assert.ErrorAs(t, err, &notFound{})
+	err = errors.WrapPrefixf(err, "kustomization")
+
+	assert.Equal(t, "kustomization: accumulating resources: "+
+		"loading 'missing.yaml': no such file", err.Error())
+	assert.Equal(t, []string{
+		"kustomization",
+		"accumulating resources",
+		"in resource ConfigMap/settings",
+		"loading 'missing.yaml'",
+		"no such file",
+	}, errors.Chain(err))
+	assert.Equal(t, notFound{}, pkgerrors.Cause(
+		errors.WithContext(notFound{}, "transformer")))
+	assert.Nil(t, errors.WithContext(nil, "transformer"))
+}
+
+func TestStack(t *testing.T) {
+	assert.Equal(t, "", errors.Stack(notFound{}))
+	// The innermost stack is where the error happened.
+	err := errors.Wrap(pkgerrors.Wrap(notFound{}, "loading"))
+	assert.Contains(t, errors.Stack(err), "errors_test.TestStack")
+	assert.NotContains(t, errors.Stack(err), "go-errors")
+}
@@ -13,6 +13,9 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+// DefaultRuntime is the container engine used when none is specified.
+const DefaultRuntime = "docker"
+
 // Filter filters Resources using a container image.
 // The container must start a process that reads the list of
 // input Resources from stdin, reads the Configuration from the env
@@ -127,6 +130,10 @@ type Filter struct {
 	Exec runtimeexec.Filter
 
 	UIDGID string
+
+	// Runtime is the container engine command used to run the image,
+	// e.g. "docker" or "podman".  Defaults to "docker".
+	Runtime string
 }
 
 func (c Filter) String() string {
@@ -169,9 +176,10 @@ func (c *Filter) getCommand() (string, []string) {
 	if c.ContainerSpec.Network {
 		network = runtimeutil.NetworkNameHost
 	}
-	// run the container using docker.  this is simpler than using the docker
-	// libraries, and ensures things like auth work the same as if the container
-	// was run from the cli.
+	// run the container using the docker cli (or a cli compatible with it,
+	// such as podman).  this is simpler than using the docker libraries, and
+	// ensures things like auth work the same as if the container was run
+	// from the cli.
 	args := []string{"run",
 		"--rm",                                              // delete the container afterward
 		"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR", // attach stdin, stdout, stderr
@@ -190,7 +198,11 @@ func (c *Filter) getCommand() (string, []string) {
 
 	args = append(args, runtimeutil.NewContainerEnvFromStringSlice(c.Env).GetDockerFlags()...)
 	a := append(args, c.Image)
-	return "docker", a
+	runtime := c.Runtime
+	if runtime == "" {
+		runtime = DefaultRuntime
+	}
+	return runtime, a
 }
 
 // NewContainer returns a new container filter
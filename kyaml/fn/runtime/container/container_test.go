@@ -146,6 +146,16 @@ metadata:
 	}
 }
 
+func TestFilter_setupExecRuntime(t *testing.T) {
+	instance := NewContainer(
+		runtimeutil.ContainerSpec{Image: "example.com:version"}, "nobody")
+	instance.Runtime = "podman"
+	assert.NoError(t, instance.setupExec())
+	assert.Equal(t, "podman", instance.Exec.Path)
+	assert.Equal(t, "example.com:version",
+		instance.Exec.Args[len(instance.Exec.Args)-1])
+}
+
 func TestFilter_Filter(t *testing.T) {
 	cfg, err := yaml.Parse(`apiVersion: apps/v1
 kind: Deployment
@@ -137,6 +137,9 @@ type FunctionSpec struct {
 	// ExecSpec is the spec for running a function as an executable
 	Exec ExecSpec `json:"exec,omitempty" yaml:"exec,omitempty"`
 
+	// Wasm is the spec for running a function as a WebAssembly module
+	Wasm WasmSpec `json:"wasm,omitempty" yaml:"wasm,omitempty"`
+
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
 }
@@ -145,6 +148,13 @@ type ExecSpec struct {
 	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
+// WasmSpec defines a spec for running a function as a WebAssembly module
+type WasmSpec struct {
+	// Path specifies a path to a .wasm module implementing the
+	// WASI command interface
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
 // ContainerSpec defines a spec for running a function as a container
 type ContainerSpec struct {
 	// Image is the container image to run
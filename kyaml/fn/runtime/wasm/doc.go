@@ -0,0 +1,5 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasm contains the WebAssembly function implementation.
+package wasm
@@ -0,0 +1,79 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"fmt"
+
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultRuntime is the WASI runtime used when none is specified.
+const DefaultRuntime = "wasmtime"
+
+// Filter filters Resources using a WebAssembly module.
+//
+// The module must be a WASI command: it reads the input ResourceList
+// from stdin and writes the output ResourceList to stdout, exactly as
+// exec and container functions do.  If there is an error or validation
+// failure, the module must exit non-zero.
+//
+// The module is run by a WASI runtime cli (wasmtime by default) without
+// access to the host filesystem, the network or the host environment,
+// so the same module runs unchanged and sandboxed on every platform.
+type Filter struct {
+	runtimeutil.WasmSpec `json:",inline" yaml:",inline"`
+
+	Exec runtimeexec.Filter
+
+	// Runtime is the WASI runtime command used to run the module.
+	// Defaults to "wasmtime".
+	Runtime string
+}
+
+func (c Filter) String() string {
+	if c.Exec.DeferFailure {
+		return fmt.Sprintf("%s deferFailure: %v", c.Path, c.Exec.DeferFailure)
+	}
+	return c.Path
+}
+
+func (c Filter) GetExit() error {
+	return c.Exec.GetExit()
+}
+
+func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	c.setupExec()
+	return c.Exec.Filter(nodes)
+}
+
+func (c *Filter) setupExec() {
+	// don't init 2x
+	if c.Exec.Path != "" {
+		return
+	}
+	path, args := c.getCommand()
+	c.Exec.Path = path
+	c.Exec.Args = args
+}
+
+// getCommand returns the command + args to run the module
+func (c *Filter) getCommand() (string, []string) {
+	runtime := c.Runtime
+	if runtime == "" {
+		runtime = DefaultRuntime
+	}
+	// no --dir, --env or --tcplisten flags are passed, so the module
+	// only sees stdin, stdout and stderr.
+	return runtime, []string{"run", c.Path}
+}
+
+// NewWasm returns a new wasm filter
+func NewWasm(spec runtimeutil.WasmSpec, workingDir string) Filter {
+	f := Filter{WasmSpec: spec}
+	f.Exec.WorkingDir = workingDir
+	return f
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestFilter_setupExec(t *testing.T) {
+	instance := NewWasm(runtimeutil.WasmSpec{Path: "/fns/transform.wasm"}, "/work")
+	instance.setupExec()
+	assert.Equal(t, "wasmtime", instance.Exec.Path)
+	assert.Equal(t, []string{"run", "/fns/transform.wasm"}, instance.Exec.Args)
+	assert.Equal(t, "/work", instance.Exec.WorkingDir)
+
+	instance = NewWasm(runtimeutil.WasmSpec{Path: "/fns/transform.wasm"}, "/work")
+	instance.Runtime = "wasmer"
+	instance.setupExec()
+	assert.Equal(t, "wasmer", instance.Exec.Path)
+}
+
+func TestFilter_Filter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake runtime is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "kyaml-wasm-test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	// The fake runtime echoes the ResourceList back, adding an annotation.
+	fakeRuntime := filepath.Join(dir, "fake-runtime")
+	err = ioutil.WriteFile(fakeRuntime, []byte(`#!/bin/sh
+sed 's/name: foo/name: foo\n    annotations:\n      a: b/'
+`), 0700)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	instance := NewWasm(runtimeutil.WasmSpec{Path: "transform.wasm"}, dir)
+	instance.Runtime = fakeRuntime
+	instance.Exec.FunctionConfig = yaml.MustParse(`apiVersion: example.com/v1
+kind: Fn
+metadata:
+  name: fn
+`)
+	nodes, err := instance.Filter([]*yaml.RNode{yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`)})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, nodes, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, "b", nodes[0].GetAnnotations()["a"])
+}
+
+func TestFilter_String(t *testing.T) {
+	instance := NewWasm(runtimeutil.WasmSpec{Path: "transform.wasm"}, "/work")
+	assert.Equal(t, "transform.wasm", instance.String())
+
+	instance.Exec.DeferFailure = true
+	assert.Equal(t, "transform.wasm deferFailure: true", instance.String())
+}
@@ -288,6 +288,12 @@ func (e *Encoder) DefaultSeqIndent() {
 	e.encoder.emitter.compact_sequence_indent = false
 }
 
+// SetWidth changes the width past which long strings are folded.
+// A negative width, the default, never folds them.
+func (e *Encoder) SetWidth(width int) {
+	yaml_emitter_set_width(&e.encoder.emitter, width)
+}
+
 // Close closes the encoder by writing any remaining data.
 // It does not write a stream terminating string "...".
 func (e *Encoder) Close() (err error) {
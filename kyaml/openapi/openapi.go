@@ -143,6 +143,10 @@ var precomputedIsNamespaceScoped = map[yaml.TypeMeta]bool{
 type ResourceSchema struct {
 	// Schema is the OpenAPI schema for a Resource or field
 	Schema *spec.Schema
+
+	// listTypes is true if lists without a patch strategy are
+	// merged per their list type, see listTypeStrategyAndKeys.
+	listTypes bool
 }
 
 // IsEmpty returns true if the ResourceSchema is empty
@@ -163,7 +167,8 @@ func SchemaForResourceType(t yaml.TypeMeta) *ResourceSchema {
 	if !found {
 		return nil
 	}
-	return &ResourceSchema{Schema: rs}
+	_, builtin := precomputedIsNamespaceScoped[t]
+	return &ResourceSchema{Schema: rs, listTypes: !builtin}
 }
 
 // SupplementaryOpenAPIFieldName is the conventional field name (JSON/YAML) containing
@@ -349,7 +354,21 @@ func GetSchema(s string, schema *spec.Schema) (*ResourceSchema, error) {
 		sc = *r
 	}
 
-	return &ResourceSchema{Schema: &sc}, nil
+	return &ResourceSchema{Schema: &sc, listTypes: true}, nil
+}
+
+// discoveredIsNamespaceScoped holds the scopes of the resource
+// types a cluster serves, per its discovery API.
+var discoveredIsNamespaceScoped map[yaml.TypeMeta]bool
+
+// SetDiscoveredScopes sets whether resource types, e.g. the
+// custom resources of a cluster, are namespace scoped, per
+// the discovery API of the cluster.  These take precedence over
+// the openapi data, which doesn't know custom resources.  Nil
+// clears them.  Must be called before resources are read, as
+// their ids keep their scope.
+func SetDiscoveredScopes(scopes map[yaml.TypeMeta]bool) {
+	discoveredIsNamespaceScoped = scopes
 }
 
 // IsNamespaceScoped determines whether a resource is namespace or
@@ -360,6 +379,9 @@ func GetSchema(s string, schema *spec.Schema) (*ResourceSchema, error) {
 // be true if the resource is namespace-scoped, and false if the type is
 // cluster-scoped.
 func IsNamespaceScoped(typeMeta yaml.TypeMeta) (bool, bool) {
+	if res, f := discoveredIsNamespaceScoped[typeMeta]; f {
+		return res, true
+	}
 	if res, f := precomputedIsNamespaceScoped[typeMeta]; f {
 		return res, true
 	}
@@ -409,7 +431,7 @@ func (rs *ResourceSchema) Elements() *ResourceSchema {
 		}
 		s = *sc
 	}
-	return &ResourceSchema{Schema: &s}
+	return &ResourceSchema{Schema: &s, listTypes: rs.listTypes}
 }
 
 const Elements = "[]"
@@ -463,15 +485,14 @@ func (rs *ResourceSchema) Field(field string) *ResourceSchema {
 	}
 
 	// return the merged Schema
-	return &ResourceSchema{Schema: &s}
+	return &ResourceSchema{Schema: &s, listTypes: rs.listTypes}
 }
 
 // PatchStrategyAndKeyList returns the patch strategy and complete merge key list
 func (rs *ResourceSchema) PatchStrategyAndKeyList() (string, []string) {
 	ps, found := rs.Schema.Extensions[kubernetesPatchStrategyExtensionKey]
 	if !found {
-		// empty patch strategy
-		return "", []string{}
+		return rs.listTypeStrategyAndKeys()
 	}
 	mkList, found := rs.Schema.Extensions[kubernetesMergeKeyMapList]
 	if found {
@@ -494,8 +515,11 @@ func (rs *ResourceSchema) PatchStrategyAndKeyList() (string, []string) {
 func (rs *ResourceSchema) PatchStrategyAndKey() (string, string) {
 	ps, found := rs.Schema.Extensions[kubernetesPatchStrategyExtensionKey]
 	if !found {
-		// empty patch strategy
-		return "", ""
+		strategy, keys := rs.listTypeStrategyAndKeys()
+		if len(keys) == 0 {
+			return strategy, ""
+		}
+		return strategy, keys[0]
 	}
 
 	mk, found := rs.Schema.Extensions[kubernetesMergeKeyExtensionKey]
@@ -506,6 +530,38 @@ func (rs *ResourceSchema) PatchStrategyAndKey() (string, string) {
 	return ps.(string), mk.(string)
 }
 
+// listTypeStrategyAndKeys returns the patch strategy and merge
+// keys implied by the list type extension, which structural
+// schemas, e.g. of CustomResourceDefinitions, have rather than
+// patch strategies: lists of type map are merged by their map
+// keys, and lists of type set merge their values, as are the
+// lists of builtin types with the merge patch strategy.
+// Builtin types keep an empty patch strategy for the lists
+// that don't declare one, whatever their list type, so that
+// they merge as with kubectl.
+func (rs *ResourceSchema) listTypeStrategyAndKeys() (string, []string) {
+	if !rs.listTypes {
+		return "", []string{}
+	}
+	lt, _ := rs.Schema.Extensions[kubernetesListTypeExtensionKey].(string)
+	switch lt {
+	case "map":
+		keys, _ := rs.Schema.Extensions[kubernetesMergeKeyMapList].([]interface{})
+		result := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if s, ok := k.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return "merge", result
+	case "set":
+		return "merge", []string{}
+	default:
+		// empty patch strategy
+		return "", []string{}
+	}
+}
+
 const (
 	// kubernetesOpenAPIDefaultVersion is the latest version number of the statically compiled in
 	// OpenAPI schema for kubernetes built-in types
@@ -531,6 +587,10 @@ const (
 	// -- the extension is an array of strings
 	kubernetesMergeKeyMapList = "x-kubernetes-list-map-keys"
 
+	// kubernetesListTypeExtensionKey is the key to lookup the list type
+	// of structural schemas: atomic, set or map -- the extension is a string
+	kubernetesListTypeExtensionKey = "x-kubernetes-list-type"
+
 	// groupKey is the key to lookup the group from the GVK extension
 	groupKey = "group"
 	// versionKey is the key to lookup the version from the GVK extension
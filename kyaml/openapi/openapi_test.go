@@ -325,3 +325,100 @@ func TestIsNamespaceScoped_custom(t *testing.T) {
 	assert.True(t, isFound)
 	assert.True(t, isNamespaceable)
 }
+
+func TestIsNamespaceScoped_discovered(t *testing.T) {
+	SetDiscoveredScopes(map[yaml.TypeMeta]bool{
+		{APIVersion: "widgets.io/v1", Kind: "Widget"}: false,
+		{APIVersion: "v1", Kind: "Namespace"}:         true,
+	})
+	defer SetDiscoveredScopes(nil)
+
+	isNamespaceable, isFound := IsNamespaceScoped(yaml.TypeMeta{
+		APIVersion: "widgets.io/v1",
+		Kind:       "Widget",
+	})
+	assert.True(t, isFound)
+	assert.False(t, isNamespaceable)
+
+	// Discovery takes precedence over the openapi data.
+	isNamespaceable, isFound = IsNamespaceScoped(yaml.TypeMeta{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+	})
+	assert.True(t, isFound)
+	assert.True(t, isNamespaceable)
+
+	SetDiscoveredScopes(nil)
+	_, isFound = IsNamespaceScoped(yaml.TypeMeta{
+		APIVersion: "widgets.io/v1",
+		Kind:       "Widget",
+	})
+	assert.False(t, isFound)
+}
+
+func TestPatchStrategyAndKeyListType(t *testing.T) {
+	testCases := map[string]struct {
+		schema   string
+		strategy string
+		keys     []string
+		key      string
+	}{
+		"map": {
+			schema: `{"type": "array", "x-kubernetes-list-type": "map",
+"x-kubernetes-list-map-keys": ["port", "protocol"]}`,
+			strategy: "merge",
+			keys:     []string{"port", "protocol"},
+			key:      "port",
+		},
+		"set": {
+			schema:   `{"type": "array", "x-kubernetes-list-type": "set"}`,
+			strategy: "merge",
+			keys:     []string{},
+		},
+		"atomic": {
+			schema: `{"type": "array", "x-kubernetes-list-type": "atomic"}`,
+			keys:   []string{},
+		},
+		"patch strategy first": {
+			schema: `{"type": "array", "x-kubernetes-list-type": "map",
+"x-kubernetes-list-map-keys": ["port"],
+"x-kubernetes-patch-strategy": "replace"}`,
+			strategy: "replace",
+			keys:     []string{"port"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s, err := GetSchema(tc.schema, nil)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			strategy, keys := s.PatchStrategyAndKeyList()
+			assert.Equal(t, tc.strategy, strategy)
+			assert.Equal(t, tc.keys, keys)
+			strategy, key := s.PatchStrategyAndKey()
+			assert.Equal(t, tc.strategy, strategy)
+			assert.Equal(t, tc.key, key)
+		})
+	}
+}
+
+func TestPatchStrategyAndKeyListTypeBuiltin(t *testing.T) {
+	// reset package vars
+	globalSchema = openapiData{}
+
+	s := SchemaForResourceType(
+		yaml.TypeMeta{APIVersion: "storage.k8s.io/v1", Kind: "CSIDriver"})
+	if !assert.NotNil(t, s) {
+		t.FailNow()
+	}
+	modes := s.Field("spec").Field("volumeLifecycleModes")
+	if !assert.NotNil(t, modes) {
+		t.FailNow()
+	}
+	assert.Equal(t, "set",
+		modes.Schema.Extensions[kubernetesListTypeExtensionKey])
+	strategy, keys := modes.PatchStrategyAndKeyList()
+	assert.Equal(t, "", strategy)
+	assert.Equal(t, []string{}, keys)
+}
@@ -185,25 +185,42 @@ var orderLast = []string{
 	"MutatingWebhookConfiguration",
 	"ValidatingWebhookConfiguration",
 }
-var typeOrders = func() map[string]int {
+var legacyKindOrder = NewKindOrder(orderFirst, orderLast)
+
+// KindOrder orders Gvks by kind, putting the kinds it was
+// made with first, or last, and all other kinds in between.
+type KindOrder struct {
+	orders map[string]int
+}
+
+// NewKindOrder returns a KindOrder putting the kinds in first
+// before all others, and those in last after all others,
+// each in the order given.
+func NewKindOrder(first, last []string) KindOrder {
 	m := map[string]int{}
-	for i, n := range orderFirst {
-		m[n] = -len(orderFirst) + i
+	for i, n := range first {
+		m[n] = -len(first) + i
 	}
-	for i, n := range orderLast {
+	for i, n := range last {
 		m[n] = 1 + i
 	}
-	return m
-}()
+	return KindOrder{orders: m}
+}
 
-// IsLessThan returns true if self is less than the argument.
-func (x Gvk) IsLessThan(o Gvk) bool {
-	indexI := typeOrders[x.Kind]
-	indexJ := typeOrders[o.Kind]
+// IsLess returns true if x comes before y.  Gvks with kinds
+// of the same order are ordered by group, version and kind.
+func (ko KindOrder) IsLess(x, y Gvk) bool {
+	indexI := ko.orders[x.Kind]
+	indexJ := ko.orders[y.Kind]
 	if indexI != indexJ {
 		return indexI < indexJ
 	}
-	return x.legacySortString() < o.legacySortString()
+	return x.legacySortString() < y.legacySortString()
+}
+
+// IsLessThan returns true if self is less than the argument.
+func (x Gvk) IsLessThan(o Gvk) bool {
+	return legacyKindOrder.IsLess(x, o)
 }
 
 // IsSelected returns true if `selector` selects `x`; otherwise, false.
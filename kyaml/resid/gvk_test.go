@@ -82,6 +82,25 @@ func TestIsLessThan1(t *testing.T) {
 	}
 }
 
+func TestKindOrder(t *testing.T) {
+	ko := NewKindOrder([]string{"Secret", "Namespace"}, []string{"Service"})
+	ordered := []Gvk{
+		{Version: "v1", Kind: "Secret"},
+		{Version: "v1", Kind: "Namespace"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Version: "v1", Kind: "ConfigMap"},
+		{Version: "v1", Kind: "Service"},
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		if !ko.IsLess(ordered[i], ordered[i+1]) {
+			t.Fatalf("%v should be less than %v", ordered[i], ordered[i+1])
+		}
+		if ko.IsLess(ordered[i+1], ordered[i]) {
+			t.Fatalf("%v should not be less than %v", ordered[i+1], ordered[i])
+		}
+	}
+}
+
 var stringTests = []struct {
 	x Gvk
 	s string
@@ -20,6 +20,7 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -67,9 +68,21 @@ type RunFns struct {
 	// EnableExec will enable exec functions
 	EnableExec bool
 
+	// EnableWasm will enable functions run as WebAssembly modules
+	EnableWasm bool
+
+	// WasmRuntime is the WASI runtime command used to run wasm
+	// functions.  Defaults to "wasmtime".
+	WasmRuntime string
+
 	// DisableContainers will disable functions run as containers
 	DisableContainers bool
 
+	// ContainerRuntime is the container engine command used to run
+	// container functions, e.g. "docker" or "podman".
+	// Defaults to "docker".
+	ContainerRuntime string
+
 	// ResultsDir is where to write each functions results
 	ResultsDir string
 
@@ -211,6 +224,8 @@ func (r RunFns) runFunctions(
 				identifier = filter.Path
 			case *starlark.Filter:
 				identifier = filter.String()
+			case *wasm.Filter:
+				identifier = filter.String()
 			default:
 				identifier = "unknown-type function"
 			}
@@ -478,6 +493,7 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 			uidgid,
 		)
 		cf := &c
+		cf.Runtime = r.ContainerRuntime
 		cf.Exec.FunctionConfig = api
 		cf.Exec.GlobalScope = r.GlobalScope
 		cf.Exec.ResultsFile = resultsFile
@@ -485,30 +501,13 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 		return cf, nil
 	}
 	if r.EnableStarlark && (spec.Starlark.Path != "" || spec.Starlark.URL != "") {
-		// the script path is relative to the function config file
-		m, err := api.GetMeta()
-		if err != nil {
-			return nil, errors.Wrap(err)
-		}
-
 		var p string
 		if spec.Starlark.Path != "" {
-			pathAnno := m.Annotations[kioutil.PathAnnotation]
-			if pathAnno == "" {
-				pathAnno = m.Annotations[kioutil.LegacyPathAnnotation]
-			}
-			p = filepath.ToSlash(path.Clean(pathAnno))
-
-			spec.Starlark.Path = filepath.ToSlash(path.Clean(spec.Starlark.Path))
-			if filepath.IsAbs(spec.Starlark.Path) || path.IsAbs(spec.Starlark.Path) {
-				return nil, errors.Errorf(
-					"absolute function path %s not allowed", spec.Starlark.Path)
-			}
-			if strings.HasPrefix(spec.Starlark.Path, "..") {
-				return nil, errors.Errorf(
-					"function path %s not allowed to start with ../", spec.Starlark.Path)
+			var err error
+			p, err = r.functionPath(api, spec.Starlark.Path)
+			if err != nil {
+				return nil, err
 			}
-			p = filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), spec.Starlark.Path))
 		}
 
 		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL}
@@ -520,6 +519,24 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 		return sf, nil
 	}
 
+	if r.EnableWasm && spec.Wasm.Path != "" {
+		p, err := r.functionPath(api, spec.Wasm.Path)
+		if err != nil {
+			return nil, err
+		}
+		wd := r.WorkingDir
+		if wd == "" {
+			wd = r.Path
+		}
+		wf := wasm.NewWasm(runtimeutil.WasmSpec{Path: p}, wd)
+		wf.Runtime = r.WasmRuntime
+		wf.Exec.FunctionConfig = api
+		wf.Exec.GlobalScope = r.GlobalScope
+		wf.Exec.ResultsFile = resultsFile
+		wf.Exec.DeferFailure = spec.DeferFailure
+		return &wf, nil
+	}
+
 	if r.EnableExec && spec.Exec.Path != "" {
 		ef := &exec.Filter{
 			Path:       spec.Exec.Path,
@@ -535,3 +552,28 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 
 	return nil, nil
 }
+
+// functionPath resolves the path of a function script or module, which
+// is relative to the function config file.
+func (r *RunFns) functionPath(api *yaml.RNode, fnPath string) (string, error) {
+	m, err := api.GetMeta()
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	pathAnno := m.Annotations[kioutil.PathAnnotation]
+	if pathAnno == "" {
+		pathAnno = m.Annotations[kioutil.LegacyPathAnnotation]
+	}
+	p := filepath.ToSlash(path.Clean(pathAnno))
+
+	fnPath = filepath.ToSlash(path.Clean(fnPath))
+	if filepath.IsAbs(fnPath) || path.IsAbs(fnPath) {
+		return "", errors.Errorf(
+			"absolute function path %s not allowed", fnPath)
+	}
+	if strings.HasPrefix(fnPath, "..") {
+		return "", errors.Errorf(
+			"function path %s not allowed to start with ../", fnPath)
+	}
+	return filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), fnPath)), nil
+}
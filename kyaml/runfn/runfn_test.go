@@ -258,6 +258,8 @@ func TestRunFns_getFilters(t *testing.T) {
 
 		enableStarlark bool
 
+		enableWasm bool
+
 		disableContainers bool
 	}{
 		// Test
@@ -625,6 +627,67 @@ metadata:
 			error:          "function path ../a/b/c not allowed to start with ../",
 		},
 
+		// Test
+		//
+		//
+		{name: "wasm-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: fn/transform.wasm
+`,
+				},
+			},
+			enableWasm: true,
+			outFn: func(path string) []string {
+				return []string{
+					fmt.Sprintf("%s/foo/fn/transform.wasm", filepath.ToSlash(path))}
+			},
+		},
+
+		{name: "wasm-function-escape-parent",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: ../transform.wasm
+`,
+				},
+			},
+			enableWasm: true,
+			error:      "function path ../transform.wasm not allowed to start with ../",
+		},
+
+		{name: "wasm-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: transform.wasm
+`,
+				},
+			},
+		},
+
 		{name: "starlark-function-disabled",
 			in: []f{
 				{
@@ -694,6 +757,7 @@ metadata:
 			// init the instance
 			r := &RunFns{
 				EnableStarlark:       tt.enableStarlark,
+				EnableWasm:           tt.enableWasm,
 				DisableContainers:    tt.disableContainers,
 				FunctionPaths:        fnPaths,
 				Functions:            parsedFns,
@@ -28,6 +28,12 @@ type SequenceIndentStyle string
 type EncoderOptions struct {
 	// SeqIndent is the indentation style for YAML Sequence nodes
 	SeqIndent SequenceIndentStyle
+	// Indent is the number of spaces nested nodes are indented
+	// by, or DefaultIndent if 0
+	Indent int
+	// LineWidth is the width past which long strings are folded,
+	// or 0 to never fold them
+	LineWidth int
 }
 
 // Expose the yaml.v3 functions so this package can be used as a replacement
@@ -72,7 +78,14 @@ func MarshalWithOptions(in interface{}, opts *EncoderOptions) ([]byte, error) {
 // NewEncoderWithOptions returns the encoder with provided options
 func NewEncoderWithOptions(w io.Writer, opts *EncoderOptions) *yaml.Encoder {
 	encoder := NewEncoder(w)
-	encoder.SetIndent(DefaultIndent)
+	if opts.Indent > 0 {
+		encoder.SetIndent(opts.Indent)
+	} else {
+		encoder.SetIndent(DefaultIndent)
+	}
+	if opts.LineWidth > 0 {
+		encoder.SetWidth(opts.LineWidth)
+	}
 	if opts.SeqIndent == WideSequenceStyle {
 		encoder.DefaultSeqIndent()
 	} else {
@@ -0,0 +1,66 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalWithOptions(t *testing.T) {
+	input := `spec:
+  args:
+  - foo
+  text: aaaa bbbb cccc dddd eeee ffff
+`
+	testCases := []struct {
+		name           string
+		opts           EncoderOptions
+		expectedOutput string
+	}{
+		{
+			name: "default options",
+			expectedOutput: `spec:
+  args:
+  - foo
+  text: aaaa bbbb cccc dddd eeee ffff
+`,
+		},
+		{
+			name: "wide sequences indented by four",
+			opts: EncoderOptions{SeqIndent: WideSequenceStyle, Indent: 4},
+			expectedOutput: `spec:
+    args:
+        - foo
+    text: aaaa bbbb cccc dddd eeee ffff
+`,
+		},
+		{
+			name: "folded past twenty",
+			opts: EncoderOptions{LineWidth: 20},
+			expectedOutput: `spec:
+  args:
+  - foo
+  text: aaaa bbbb cccc
+    dddd eeee ffff
+`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var node Node
+			if !assert.NoError(t, Unmarshal([]byte(input), &node)) {
+				t.FailNow()
+			}
+			out, err := MarshalWithOptions(&node, &tc.opts)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expectedOutput, string(out))
+		})
+	}
+}
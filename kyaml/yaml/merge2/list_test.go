@@ -487,4 +487,93 @@ items: []
 			ListIncreaseDirection: yaml.MergeOptionsListAppend,
 		},
 	},
+
+	//
+	// Test Case
+	//
+	{description: `append to list without merge key`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        args:
+        - --b
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        args:
+        - --a
+      - name: bar
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        args:
+        - --a
+        - --b
+      - name: bar
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListPrepend,
+			ListStrategy:          yaml.MergeOptionsListAppendAll,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `replace list with merge key`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:2
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:1
+        args:
+        - --a
+      - name: bar
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo:2
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListPrepend,
+			ListStrategy:          yaml.MergeOptionsListReplaceAll,
+		},
+	},
 }
@@ -232,9 +232,25 @@ const (
 	MergeOptionsListPrepend
 )
 
+// MergeOptionsListStrategy is the way lists are merged
+type MergeOptionsListStrategy int
+
+const (
+	// MergeOptionsListMergeByKey merges the elements of lists that
+	// have merge keys by key, and replaces other lists.
+	MergeOptionsListMergeByKey MergeOptionsListStrategy = iota
+	// MergeOptionsListAppendAll is MergeOptionsListMergeByKey, but
+	// appends to lists without merge keys rather than replace them.
+	MergeOptionsListAppendAll
+	// MergeOptionsListReplaceAll replaces all lists.
+	MergeOptionsListReplaceAll
+)
+
 // MergeOptions is a struct which contains the options for merge
 type MergeOptions struct {
 	// ListIncreaseDirection indicates should merge function prepend the items from
 	// source list to destination or append.
 	ListIncreaseDirection MergeOptionsListIncreaseDirection
+	// ListStrategy is the way lists are merged.
+	ListStrategy MergeOptionsListStrategy
 }
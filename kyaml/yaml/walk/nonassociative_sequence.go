@@ -9,5 +9,24 @@ import (
 
 // walkNonAssociativeSequence returns the value of VisitList
 func (l Walker) walkNonAssociativeSequence() (*yaml.RNode, error) {
+	if l.MergeOptions.ListStrategy == yaml.MergeOptionsListAppendAll {
+		return l.VisitList(l.appendedSources(), l.Schema, NonAssociateList)
+	}
 	return l.VisitList(l.Sources, l.Schema, NonAssociateList)
 }
+
+// appendedSources returns the sources with the elements of the
+// destination list put ahead of those of the origin list.
+func (l Walker) appendedSources() Sources {
+	dest, origin := l.Sources.Dest(), l.Sources.Origin()
+	if yaml.IsMissingOrNull(dest) || yaml.IsMissingOrNull(origin) {
+		return l.Sources
+	}
+	appended := yaml.NewListRNode()
+	appended.YNode().Style = origin.YNode().Style
+	appended.YNode().Content = append(
+		append(appended.YNode().Content, dest.Content()...), origin.Content()...)
+	sources := append(Sources{}, l.Sources...)
+	sources[OriginIndex] = appended
+	return sources
+}
@@ -67,6 +67,9 @@ func (l Walker) Walk() (*yaml.RNode, error) {
 		if err := yaml.ErrorIfAnyInvalidAndNonNull(yaml.SequenceNode, l.Sources...); err != nil {
 			return nil, err
 		}
+		if l.MergeOptions.ListStrategy == yaml.MergeOptionsListReplaceAll {
+			return l.walkNonAssociativeSequence()
+		}
 		// AssociativeSequence means the items in the sequence are associative. They can be merged
 		// according to merge key.
 		if schema.IsAssociative(l.Schema, l.Sources, l.InferAssociativeLists) {
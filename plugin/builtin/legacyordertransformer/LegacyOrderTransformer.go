@@ -10,6 +10,8 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
 )
 
 // Sort the resources using an ordering defined in the Gvk class.
@@ -17,30 +19,48 @@ import (
 // dependencies (like Namespace, StorageClass, etc.)
 // first, and resources with a high number of dependencies
 // (like ValidatingWebhookConfiguration) last.
-type plugin struct{}
+// If OrderFirst or OrderLast are set, they replace the
+// kinds the Gvk class puts first and last.
+type plugin struct {
+	OrderFirst []string `json:"orderFirst,omitempty" yaml:"orderFirst,omitempty"`
+	OrderLast  []string `json:"orderLast,omitempty" yaml:"orderLast,omitempty"`
+}
 
 //noinspection GoUnusedGlobalVariable
 var KustomizePlugin plugin
 
-// Nothing needed for configuration.
 func (p *plugin) Config(
-	_ *resmap.PluginHelpers, _ []byte) (err error) {
-	return nil
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.OrderFirst = nil
+	p.OrderLast = nil
+	return yaml.Unmarshal(c, p)
 }
 
 func (p *plugin) Transform(m resmap.ResMap) (err error) {
 	resources := make([]*resource.Resource, m.Size())
 	ids := m.AllIds()
-	sort.Sort(resmap.IdSlice(ids))
+	if p.OrderFirst == nil && p.OrderLast == nil {
+		sort.Sort(resmap.IdSlice(ids))
+	} else {
+		order := resid.NewKindOrder(p.OrderFirst, p.OrderLast)
+		sort.Slice(ids, func(i, j int) bool {
+			if !ids[i].Gvk.Equals(ids[j].Gvk) {
+				return order.IsLess(ids[i].Gvk, ids[j].Gvk)
+			}
+			return ids[i].LegacySortString() < ids[j].LegacySortString()
+		})
+	}
+	x := resmap.NewIndex(m)
 	for i, id := range ids {
-		resources[i], err = m.GetByCurrentId(id)
+		resources[i], err = x.GetByCurrentId(id)
 		if err != nil {
 			return errors.Wrap(err, "expected match for sorting")
 		}
 	}
-	m.Clear()
-	for _, r := range resources {
-		m.Append(r)
+	sorted, err := resmap.NewFromResources(resources)
+	if err != nil {
+		return err
 	}
-	return nil
+	m.Clear()
+	return m.AppendAll(sorted)
 }
@@ -113,3 +113,52 @@ metadata:
   name: pomegranate
 `)
 }
+
+func TestLegacyOrderTransformerOrderFirstAndLast(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("LegacyOrderTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: LegacyOrderTransformer
+metadata:
+  name: notImportantHere
+orderFirst:
+- Secret
+orderLast:
+- Namespace
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+`)
+
+	th.AssertActualEqualsExpectedNoIdAnnotations(rm, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+`)
+}
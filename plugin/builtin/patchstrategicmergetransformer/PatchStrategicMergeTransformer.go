@@ -58,6 +58,14 @@ func loadFromPaths(
 	result []*resource.Resource, err error) {
 	var patches []*resource.Resource
 	for _, path := range paths {
+		if e, ok := path.Entry(); ok {
+			patches, err = loadEntry(h, e)
+			if err != nil {
+				return
+			}
+			result = append(result, patches...)
+			continue
+		}
 		// For legacy reasons, attempt to treat the path string as
 		// actual patch content.
 		patches, err = h.ResmapFactory().RF().SliceFromBytes([]byte(path))
@@ -74,6 +82,33 @@ func loadFromPaths(
 	return
 }
 
+// loadEntry loads the patches of an entry written as an
+// object, giving them its options.
+func loadEntry(
+	h *resmap.PluginHelpers,
+	e *types.PatchStrategicMergeEntry) ([]*resource.Resource, error) {
+	var patches []*resource.Resource
+	var err error
+	if e.Path != "" {
+		patches, err = h.ResmapFactory().RF().SliceFromPatches(
+			h.Loader(), []types.PatchStrategicMerge{types.PatchStrategicMerge(e.Path)})
+	} else {
+		patches, err = h.ResmapFactory().RF().SliceFromBytes([]byte(e.Patch))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, patch := range patches {
+		if e.MergeLists != "" {
+			patch.SetMergeLists(e.MergeLists)
+		}
+		if e.Force {
+			patch.ForcePatch()
+		}
+	}
+	return patches, nil
+}
+
 func (p *plugin) Transform(m resmap.ResMap) error {
 	for _, patch := range p.loadedPatches {
 		target, err := m.GetById(patch.OrgId())
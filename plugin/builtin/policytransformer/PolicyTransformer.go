@@ -0,0 +1,553 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// Fail the build if resources violate Rego policies, which
+// are evaluated by opa.  Policies are Gatekeeper
+// ConstraintTemplates, with the Constraints that apply them
+// to resources, or plain Rego files that, as conftest's, put
+// deny or violation messages about the input resource in
+// package main.  Resources aren't modified, so the plugin
+// may be listed as a validator.
+type plugin struct {
+	h *resmap.PluginHelpers
+
+	// Policies are .rego and YAML files, or directories
+	// of them.
+	Policies []string `json:"policies,omitempty" yaml:"policies,omitempty"`
+
+	// V0Compatible makes opa v1 parse the policies as Rego v0,
+	// e.g. to evaluate Gatekeeper's library of templates.
+	V0Compatible bool `json:"v0Compatible,omitempty" yaml:"v0Compatible,omitempty"`
+
+	modules     []string
+	templates   map[string]string
+	constraints []constraint
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+type constraintTemplate struct {
+	Spec struct {
+		CRD struct {
+			Spec struct {
+				Names struct {
+					Kind string `json:"kind"`
+				} `json:"names"`
+			} `json:"spec"`
+		} `json:"crd"`
+		Targets []struct {
+			Rego string   `json:"rego"`
+			Libs []string `json:"libs"`
+		} `json:"targets"`
+	} `json:"spec"`
+}
+
+type constraint struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		EnforcementAction string          `json:"enforcementAction"`
+		Match             constraintMatch `json:"match"`
+		Parameters        interface{}     `json:"parameters"`
+	} `json:"spec"`
+}
+
+type constraintMatch struct {
+	Kinds []struct {
+		APIGroups []string `json:"apiGroups"`
+		Kinds     []string `json:"kinds"`
+	} `json:"kinds"`
+	Name               string         `json:"name"`
+	Namespaces         []string       `json:"namespaces"`
+	ExcludedNamespaces []string       `json:"excludedNamespaces"`
+	LabelSelector      *labelSelector `json:"labelSelector"`
+}
+
+type labelSelector struct {
+	MatchLabels      map[string]string `json:"matchLabels"`
+	MatchExpressions []struct {
+		Key      string   `json:"key"`
+		Operator string   `json:"operator"`
+		Values   []string `json:"values"`
+	} `json:"matchExpressions"`
+}
+
+// policyInput is the input of the query.  The constraints
+// list the indices of the resources they match.
+type policyInput struct {
+	Resources   []map[string]interface{} `json:"resources"`
+	Reviews     []map[string]interface{} `json:"reviews"`
+	Constraints []map[string]interface{} `json:"constraints"`
+}
+
+const query = "data.kustomize.policy.violations"
+
+var regoPackage = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)`)
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	if h.GeneralConfig() == nil {
+		return fmt.Errorf("unable to access general config")
+	}
+	if !h.GeneralConfig().OpaConfig.Enabled {
+		return fmt.Errorf("must specify --enable-opa")
+	}
+	if h.GeneralConfig().OpaConfig.Command == "" {
+		return fmt.Errorf("must specify --opa-command")
+	}
+	p.h = h
+	p.Policies = nil
+	p.V0Compatible = false
+	p.modules = nil
+	p.templates = make(map[string]string)
+	p.constraints = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if len(p.Policies) == 0 {
+		return fmt.Errorf("must specify policies in\n%s", string(c))
+	}
+	for _, path := range p.Policies {
+		if err = p.loadPath(h.Loader(), path, true); err != nil {
+			return err
+		}
+	}
+	for _, k := range p.constraints {
+		if _, ok := p.templates[k.Kind]; !ok {
+			return fmt.Errorf(
+				"constraint %s has no template of kind %s",
+				k.Metadata.Name, k.Kind)
+		}
+	}
+	return nil
+}
+
+// loadPath loads the policy file, or the policy files
+// below the directory.  Files named explicitly must be
+// policy files; other files of directories are skipped.
+func (p *plugin) loadPath(ldr ifc.Loader, path string, explicit bool) error {
+	if dl, ok := ldr.(ifc.DirLoader); ok && dl.IsDir(path) {
+		names, err := dl.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err = p.loadPath(ldr, filepath.Join(path, name), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch filepath.Ext(path) {
+	case ".rego":
+		content, err := ldr.Load(path)
+		if err != nil {
+			return err
+		}
+		p.modules = append(p.modules, string(content))
+		return nil
+	case ".yaml", ".yml":
+		content, err := ldr.Load(path)
+		if err != nil {
+			return err
+		}
+		return errors.Wrapf(p.loadObjects(content), "policy file %s", path)
+	}
+	if explicit {
+		return fmt.Errorf("policy file %s isn't a .rego or YAML file", path)
+	}
+	return nil
+}
+
+// loadObjects loads the ConstraintTemplates and Constraints.
+func (p *plugin) loadObjects(content []byte) error {
+	m, err := p.h.ResmapFactory().NewResMapFromBytes(content)
+	if err != nil {
+		return err
+	}
+	for _, r := range m.Resources() {
+		y, err := r.AsYAML()
+		if err != nil {
+			return err
+		}
+		switch {
+		case r.GetKind() == "ConstraintTemplate":
+			var t constraintTemplate
+			if err = yaml.Unmarshal(y, &t); err != nil {
+				return err
+			}
+			if err = p.addTemplate(r.GetName(), &t); err != nil {
+				return err
+			}
+		case r.GetGvk().Group == "constraints.gatekeeper.sh":
+			var c constraint
+			if err = yaml.Unmarshal(y, &c); err != nil {
+				return err
+			}
+			p.constraints = append(p.constraints, c)
+		default:
+			return fmt.Errorf(
+				"%s %s is neither a ConstraintTemplate nor a Constraint",
+				r.GetKind(), r.GetName())
+		}
+	}
+	return nil
+}
+
+func (p *plugin) addTemplate(name string, t *constraintTemplate) error {
+	kind := t.Spec.CRD.Spec.Names.Kind
+	if kind == "" {
+		return fmt.Errorf("template %s has no kind", name)
+	}
+	if len(t.Spec.Targets) != 1 {
+		return fmt.Errorf("template %s must have exactly one target", name)
+	}
+	target := t.Spec.Targets[0]
+	match := regoPackage.FindStringSubmatch(target.Rego)
+	if match == nil {
+		return fmt.Errorf("the rego of template %s has no package", name)
+	}
+	if _, ok := p.templates[kind]; ok {
+		return fmt.Errorf("multiple templates of kind %s", kind)
+	}
+	p.templates[kind] = match[1]
+	p.modules = append(p.modules, target.Rego)
+	p.modules = append(p.modules, target.Libs...)
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	input, err := p.input(m)
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "kustomize-opa-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	modules := append([]string{p.queryModule()}, p.modules...)
+	for i, module := range modules {
+		err = ioutil.WriteFile(
+			filepath.Join(dir, fmt.Sprintf("policy%d.rego", i)),
+			[]byte(module), 0600)
+		if err != nil {
+			return err
+		}
+	}
+	violations, err := p.evaluate(dir, input)
+	if err != nil {
+		return err
+	}
+	resources := m.Resources()
+	var msgs []string
+	for _, v := range violations {
+		r := resources[v.resource]
+		msg := fmt.Sprintf("%s %s: %s", r.GetKind(), r.GetName(), v.msg)
+		if v.constraint >= 0 {
+			c := input.Constraints[v.constraint]
+			msg = fmt.Sprintf("%s %s: [%s %s] %s",
+				r.GetKind(), r.GetName(), c["kind"], c["name"], v.msg)
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("resources violate policies:\n%s",
+		strings.Join(msgs, "\n"))
+}
+
+// input returns the resources, their admission reviews,
+// as Gatekeeper passes them to templates, and the enforced
+// constraints.
+func (p *plugin) input(m resmap.ResMap) (*policyInput, error) {
+	input := &policyInput{
+		Resources:   []map[string]interface{}{},
+		Reviews:     []map[string]interface{}{},
+		Constraints: []map[string]interface{}{},
+	}
+	for _, r := range m.Resources() {
+		obj, err := r.Map()
+		if err != nil {
+			return nil, err
+		}
+		gvk := r.GetGvk()
+		input.Resources = append(input.Resources, obj)
+		input.Reviews = append(input.Reviews, map[string]interface{}{
+			"kind": map[string]interface{}{
+				"group":   gvk.Group,
+				"version": gvk.Version,
+				"kind":    gvk.Kind,
+			},
+			"name":      r.GetName(),
+			"namespace": r.GetNamespace(),
+			"operation": "CREATE",
+			"object":    obj,
+		})
+	}
+	for _, c := range p.constraints {
+		action := c.Spec.EnforcementAction
+		if action != "" && action != "deny" {
+			continue
+		}
+		matches := []int{}
+		for i, r := range m.Resources() {
+			ok, err := c.Spec.Match.matches(r)
+			if err != nil {
+				return nil, errors.Wrapf(err, "constraint %s", c.Metadata.Name)
+			}
+			if ok {
+				matches = append(matches, i)
+			}
+		}
+		parameters := c.Spec.Parameters
+		if parameters == nil {
+			parameters = map[string]interface{}{}
+		}
+		input.Constraints = append(input.Constraints, map[string]interface{}{
+			"kind":       c.Kind,
+			"name":       c.Metadata.Name,
+			"parameters": parameters,
+			"matches":    matches,
+		})
+	}
+	return input, nil
+}
+
+// queryModule returns the module of the query, which collects
+// the violations of all policies as [constraint, resource,
+// message] triples.  Plain Rego policies have no constraint,
+// i.e. -1.
+func (p *plugin) queryModule() string {
+	var b strings.Builder
+	b.WriteString(`package kustomize.policy
+
+import future.keywords.contains
+import future.keywords.if
+
+violations contains [-1, i, msg] if {
+	some i
+	resource := input.resources[i]
+	msg := data.main.deny[_] with input as resource
+}
+
+violations contains [-1, i, msg] if {
+	some i
+	resource := input.resources[i]
+	msg := data.main.violation[_] with input as resource
+}
+`)
+	var kinds []string
+	for kind := range p.templates {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, `
+violations contains [c, i, v.msg] if {
+	some c
+	constraint := input.constraints[c]
+	constraint.kind == %q
+	i := constraint.matches[_]
+	review := {"review": input.reviews[i], "parameters": constraint.parameters}
+	v := data.%s.violation[_] with input as review
+}
+`, kind, p.templates[kind])
+	}
+	return b.String()
+}
+
+type violation struct {
+	constraint int
+	resource   int
+	msg        string
+}
+
+// evaluate runs opa on the policies in the directory.
+func (p *plugin) evaluate(
+	dir string, input *policyInput) ([]violation, error) {
+	in, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	opa := p.h.GeneralConfig().OpaConfig.Command
+	args := []string{"eval", "--format", "json", "--stdin-input", "--data", dir}
+	if p.V0Compatible {
+		args = append(args, "--v0-compatible")
+	}
+	args = append(args, query)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command(opa, args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err = cmd.Run(); err != nil {
+		return nil, errors.Wrap(
+			fmt.Errorf("unable to run: '%s %s' (is '%s' installed?)",
+				opa, strings.Join(args, " "), opa),
+			stderr.String()+stdout.String())
+	}
+	var output struct {
+		Result []struct {
+			Expressions []struct {
+				Value [][]interface{} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err = json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, errors.Wrap(err, "parsing the output of opa")
+	}
+	var result []violation
+	for _, r := range output.Result {
+		for _, e := range r.Expressions {
+			for _, v := range e.Value {
+				x, err := parseViolation(v, input)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, x)
+			}
+		}
+	}
+	return result, nil
+}
+
+func parseViolation(v []interface{}, input *policyInput) (violation, error) {
+	var x violation
+	if len(v) != 3 {
+		return x, fmt.Errorf("unexpected violation %v", v)
+	}
+	c, cOk := v[0].(float64)
+	r, rOk := v[1].(float64)
+	if !cOk || !rOk || int(c) >= len(input.Constraints) ||
+		r < 0 || int(r) >= len(input.Resources) {
+		return x, fmt.Errorf("unexpected violation %v", v)
+	}
+	x.constraint, x.resource = int(c), int(r)
+	if msg, ok := v[2].(string); ok {
+		x.msg = msg
+		return x, nil
+	}
+	if obj, ok := v[2].(map[string]interface{}); ok {
+		if msg, ok := obj["msg"].(string); ok {
+			x.msg = msg
+			return x, nil
+		}
+	}
+	b, _ := json.Marshal(v[2])
+	x.msg = string(b)
+	return x, nil
+}
+
+// matches returns true if the constraint applies to
+// the resource, as Gatekeeper's match would.
+func (m *constraintMatch) matches(r *resource.Resource) (bool, error) {
+	gvk := r.GetGvk()
+	if len(m.Kinds) > 0 {
+		found := false
+		for _, k := range m.Kinds {
+			if matchesAny(k.APIGroups, gvk.Group) &&
+				matchesAny(k.Kinds, gvk.Kind) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	if m.Name != "" && !matchesPattern(m.Name, r.GetName()) {
+		return false, nil
+	}
+	ns := r.GetNamespace()
+	if len(m.Namespaces) > 0 && !matchesAny(m.Namespaces, ns) {
+		return false, nil
+	}
+	for _, pattern := range m.ExcludedNamespaces {
+		if matchesPattern(pattern, ns) {
+			return false, nil
+		}
+	}
+	if m.LabelSelector == nil {
+		return true, nil
+	}
+	return r.MatchesLabelSelector(m.LabelSelector.String())
+}
+
+// String returns the selector in the syntax of kubectl's
+// --selector flag.
+func (s *labelSelector) String() string {
+	var parts []string
+	for k, v := range s.MatchLabels {
+		parts = append(parts, k+"="+v)
+	}
+	for _, e := range s.MatchExpressions {
+		values := strings.Join(e.Values, ",")
+		switch e.Operator {
+		case "In":
+			parts = append(parts, e.Key+" in ("+values+")")
+		case "NotIn":
+			parts = append(parts, e.Key+" notin ("+values+")")
+		case "Exists":
+			parts = append(parts, e.Key)
+		case "DoesNotExist":
+			parts = append(parts, "!"+e.Key)
+		default:
+			// Fails to parse.
+			parts = append(parts, e.Key+" "+e.Operator+" ("+values+")")
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// matchesAny returns true if the list is empty, holds "*",
+// or a pattern that matches the value.
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchesPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern matches the value against the pattern,
+// which, as in Gatekeeper, may end in a * wildcard.
+func matchesPattern(pattern, value string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(value, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == value
+}
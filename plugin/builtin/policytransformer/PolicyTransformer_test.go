@@ -0,0 +1,166 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// fakeOpa records its input and finds no violations.
+const fakeOpa = `#!/bin/sh
+cat > "$(dirname "$0")/input.json"
+echo '{"result": [{"expressions": [{"value": []}]}]}'
+`
+
+const resources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: frontend
+  name: web
+  namespace: prod
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: prod
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: frontend
+  name: web
+  namespace: kube-system
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+`
+
+func TestPolicyTransformerMatch(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarnessWithTmpRoot(t).
+		PrepBuiltin("PolicyTransformer")
+	defer th.Reset()
+	root := th.GetRoot()
+	th.GetPluginConfig().OpaConfig.Enabled = true
+	th.GetPluginConfig().OpaConfig.Command = filepath.Join(root, "opa")
+	th.WriteF(filepath.Join(root, "opa"), fakeOpa)
+	require.NoError(t, os.Chmod(filepath.Join(root, "opa"), 0777))
+	th.WriteF(filepath.Join(root, "template.yaml"), `
+apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8sdenyall
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sDenyAll
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    rego: |
+      package k8sdenyall
+      violation[{"msg": "denied"}] { true }
+`)
+	th.WriteF(filepath.Join(root, "constraints.yaml"), `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDenyAll
+metadata:
+  name: all
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDenyAll
+metadata:
+  name: frontend-deployments
+spec:
+  match:
+    kinds:
+    - apiGroups: ["apps"]
+      kinds: ["Deployment"]
+    excludedNamespaces: ["kube-*"]
+    labelSelector:
+      matchExpressions:
+      - key: tier
+        operator: In
+        values: ["frontend"]
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDenyAll
+metadata:
+  name: named-web
+spec:
+  match:
+    name: we*
+    namespaces: ["prod"]
+`)
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: PolicyTransformer
+metadata:
+  name: notImportantHere
+policies:
+- template.yaml
+- constraints.yaml
+`, resources, resources)
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "input.json"))
+	require.NoError(t, err)
+	var input struct {
+		Constraints []struct {
+			Name    string `json:"name"`
+			Matches []int  `json:"matches"`
+		} `json:"constraints"`
+	}
+	require.NoError(t, json.Unmarshal(content, &input))
+	require.Len(t, input.Constraints, 3)
+	assert.Equal(t, []int{0, 1, 2, 3}, input.Constraints[0].Matches)
+	assert.Equal(t, []int{0}, input.Constraints[1].Matches)
+	assert.Equal(t, []int{0, 3}, input.Constraints[2].Matches)
+}
+
+func TestPolicyTransformerConfigErrors(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarnessWithTmpRoot(t).
+		PrepBuiltin("PolicyTransformer")
+	defer th.Reset()
+	root := th.GetRoot()
+	th.GetPluginConfig().OpaConfig.Enabled = true
+	th.WriteF(filepath.Join(root, "constraint.yaml"), `
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-team
+`)
+	th.WriteF(filepath.Join(root, "notes.txt"), `
+Not a policy.
+`)
+	for policy, expected := range map[string]string{
+		"constraint.yaml": "constraint must-have-team has no template of kind K8sRequiredLabels",
+		"notes.txt":       "policy file notes.txt isn't a .rego or YAML file",
+	} {
+		err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: PolicyTransformer
+metadata:
+  name: notImportantHere
+policies:
+- `+policy+`
+`, resources)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), expected)
+	}
+}
@@ -0,0 +1,14 @@
+module sigs.k8s.io/kustomize/plugin/builtin/policytransformer
+
+go 1.16
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.7.0
+	sigs.k8s.io/kustomize/api v0.8.9
+	sigs.k8s.io/yaml v1.2.0
+)
+
+replace sigs.k8s.io/kustomize/kyaml => ../../../kyaml
+
+replace sigs.k8s.io/kustomize/api => ../../../api
@@ -164,3 +164,48 @@ metadata:
   name: cm
 `)
 }
+
+func TestPrefixTransformerFieldSpecsToSkip(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PrefixTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: PrefixTransformer
+metadata:
+  name: notImportantHere
+prefix: baked-
+fieldSpecs:
+  - path: metadata/name
+fieldSpecsToSkip:
+  - kind: ConfigMap
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+    internal.config.kubernetes.io/prefixes: baked-
+    internal.config.kubernetes.io/previousKinds: Secret
+    internal.config.kubernetes.io/previousNames: secret
+    internal.config.kubernetes.io/previousNamespaces: default
+  name: baked-secret
+`)
+}
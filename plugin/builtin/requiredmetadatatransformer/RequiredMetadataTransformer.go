@@ -0,0 +1,111 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Fail the build if resources lack required labels or
+// annotations.  Resources aren't modified, so the plugin
+// may be listed as a validator.  A required key may be a
+// pattern, e.g. app.kubernetes.io/*, that at least one key
+// of the resource must match.
+type plugin struct {
+	Target      *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+	Labels      []string        `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations []string        `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Target = nil
+	p.Labels = nil
+	p.Annotations = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if len(p.Labels) == 0 && len(p.Annotations) == 0 {
+		return fmt.Errorf(
+			"must specify required labels or annotations in\n%s", string(c))
+	}
+	for _, key := range append(p.Labels, p.Annotations...) {
+		if _, err = path.Match(key, ""); err != nil {
+			return fmt.Errorf("invalid required key %q: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	resources := m.Resources()
+	if p.Target != nil {
+		var err error
+		resources, err = m.Select(*p.Target)
+		if err != nil {
+			return err
+		}
+	}
+	var msgs []string
+	for _, r := range resources {
+		if msg := p.missingMetadata(r); msg != "" {
+			msgs = append(msgs, fmt.Sprintf(
+				"%s %s: %s", r.GetKind(), r.GetName(), msg))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("resources lack required metadata:\n%s",
+		strings.Join(msgs, "\n"))
+}
+
+// missingMetadata describes the required keys the resource
+// lacks, e.g. "labels team; annotations cost-center".
+func (p *plugin) missingMetadata(r *resource.Resource) string {
+	var parts []string
+	if missing := missingKeys(p.Labels, r.GetLabels()); len(missing) > 0 {
+		parts = append(parts, "labels "+strings.Join(missing, ", "))
+	}
+	if missing := missingKeys(p.Annotations, r.GetAnnotations()); len(missing) > 0 {
+		parts = append(parts, "annotations "+strings.Join(missing, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// missingKeys returns the required keys, or patterns, that
+// no key of the map matches.
+func missingKeys(required []string, m map[string]string) []string {
+	var result []string
+	for _, key := range required {
+		if _, ok := m[key]; ok {
+			continue
+		}
+		found := false
+		for k := range m {
+			// Patterns were checked by Config.
+			if matched, _ := path.Match(key, k); matched {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, key)
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    cost-center: "42"
+  labels:
+    app.kubernetes.io/name: web
+    team: storefront
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: storefront
+  name: worker
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestRequiredMetadataTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RequiredMetadataTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: RequiredMetadataTransformer
+metadata:
+  name: notImportantHere
+target:
+  name: web
+  kind: Deployment
+labels:
+- team
+- app.kubernetes.io/*
+annotations:
+- cost-center
+`, resources, resources)
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: RequiredMetadataTransformer
+metadata:
+  name: notImportantHere
+labels:
+- team
+- app.kubernetes.io/*
+annotations:
+- cost-center
+`, resources)
+	assert.EqualError(t, err, `resources lack required metadata:
+Deployment worker: labels app.kubernetes.io/*; annotations cost-center
+Service web: labels team, app.kubernetes.io/*; annotations cost-center`)
+}
+
+func TestRequiredMetadataTransformerConfig(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RequiredMetadataTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: RequiredMetadataTransformer
+metadata:
+  name: notImportantHere
+target:
+  kind: Deployment
+`, resources)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must specify required labels or annotations")
+
+	err = th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: RequiredMetadataTransformer
+metadata:
+  name: notImportantHere
+labels:
+- team[
+`, resources)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid required key "team["`)
+}
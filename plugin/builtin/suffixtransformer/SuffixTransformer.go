@@ -18,12 +18,15 @@ import (
 type plugin struct {
 	Suffix     string        `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 	FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+	// FieldSpecsToSkip select, by Gvk, more resources whose
+	// fields are left alone.
+	FieldSpecsToSkip types.FsSlice `json:"fieldSpecsToSkip,omitempty" yaml:"fieldSpecsToSkip,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
 var KustomizePlugin plugin
 
-// TODO: Make this gvk skip list part of the config.
+// Resources that are always skipped, whatever the config.
 var suffixFieldSpecsToSkip = types.FsSlice{
 	{Gvk: resid.Gvk{Kind: "CustomResourceDefinition"}},
 	{Gvk: resid.Gvk{Group: "apiregistration.k8s.io", Kind: "APIService"}},
@@ -34,6 +37,7 @@ func (p *plugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.Suffix = ""
 	p.FieldSpecs = nil
+	p.FieldSpecsToSkip = nil
 	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return
@@ -87,7 +91,7 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 }
 
 func (p *plugin) shouldSkip(id resid.ResId) bool {
-	for _, path := range suffixFieldSpecsToSkip {
+	for _, path := range append(suffixFieldSpecsToSkip, p.FieldSpecsToSkip...) {
 		if id.IsSelected(&path.Gvk) {
 			return true
 		}